@@ -0,0 +1,359 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+// Package asyncapi reads the channels and message payloads of AsyncAPI 2.x and 3.x documents, so the messages of a
+// websocket can be mocked and validated with the same schema tooling used for OpenAPI contracts.
+package asyncapi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"go.yaml.in/yaml/v4"
+)
+
+const (
+	// ActionSend is a message the application sends to its clients.
+	ActionSend = "send"
+	// ActionReceive is a message the application receives from its clients.
+	ActionReceive = "receive"
+)
+
+// payloadSchemaPrefix names the payload schemas lifted into the components of the document used to build them.
+const payloadSchemaPrefix = "x-wiretap-payload-"
+
+// Document is an AsyncAPI document, reduced to its channels and the messages they carry.
+type Document struct {
+	Version  string
+	Channels []*Channel
+}
+
+// Channel is a named channel of an AsyncAPI document.
+type Channel struct {
+	Name     string
+	Address  string
+	Messages []*Message
+}
+
+// Message is a message carried by a channel. The action is ActionSend or ActionReceive, from the point of view of the
+// application described by the document, or empty if no operation uses the message.
+type Message struct {
+	Name   string
+	Action string
+	Schema *base.Schema
+}
+
+// Channel returns the channel with a name or address, or nil if there is none.
+func (d *Document) Channel(name string) *Channel {
+	for _, channel := range d.Channels {
+		if channel.Name == name || (channel.Address != "" && channel.Address == name) {
+			return channel
+		}
+	}
+	return nil
+}
+
+// Message returns the message of a channel with a name, or the first message if the name is empty.
+func (c *Channel) Message(name string) *Message {
+	for _, message := range c.Messages {
+		if name == "" || message.Name == name {
+			return message
+		}
+	}
+	return nil
+}
+
+// MessagesFor returns the messages of a channel with an action, messages not used by any operation are included.
+func (c *Channel) MessagesFor(action string) []*Message {
+	var messages []*Message
+	for _, message := range c.Messages {
+		if message.Action == "" || message.Action == action {
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+// payload is a message payload found while walking the document, before its schema is built.
+type payload struct {
+	message *Message
+	node    *yaml.Node
+}
+
+// Parse reads an AsyncAPI 2.x or 3.x document. Payload schemas are built by lifting them into the components of an
+// OpenAPI 3.1 document that shares the rest of the AsyncAPI document, so local references resolve as written.
+func Parse(spec []byte) (*Document, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(spec, &root); err != nil {
+		return nil, fmt.Errorf("unable to parse AsyncAPI document: %w", err)
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("unable to parse AsyncAPI document: document is not an object")
+	}
+	top := root.Content[0]
+
+	version := valueOf(top, "asyncapi")
+	if version == nil {
+		return nil, errors.New("unable to parse AsyncAPI document: missing 'asyncapi' version")
+	}
+	doc := &Document{Version: version.Value}
+
+	var payloads []*payload
+	if strings.HasPrefix(doc.Version, "2.") {
+		payloads = readV2Channels(top, doc)
+	} else {
+		payloads = readV3Channels(top, doc)
+	}
+	if len(payloads) == 0 {
+		return doc, nil
+	}
+
+	schemas, err := buildSchemas(top, payloads)
+	if err != nil {
+		return nil, err
+	}
+	for i, p := range payloads {
+		p.message.Schema = schemas[i]
+	}
+	return doc, nil
+}
+
+// readV2Channels reads the messages of the publish and subscribe operations of each channel. A subscribe operation
+// is a message the application sends, a publish operation one it receives.
+func readV2Channels(top *yaml.Node, doc *Document) []*payload {
+	var payloads []*payload
+	forEach(valueOf(top, "channels"), func(name string, channelNode *yaml.Node) {
+		channel := &Channel{Name: name, Address: name}
+		for _, op := range []struct{ key, action string }{{"subscribe", ActionSend}, {"publish", ActionReceive}} {
+			operation := resolve(top, valueOf(channelNode, op.key))
+			if operation == nil {
+				continue
+			}
+			messageNode := valueOf(operation, "message")
+			candidates := []*yaml.Node{messageNode}
+			if oneOf := valueOf(resolve(top, messageNode), "oneOf"); oneOf != nil {
+				candidates = oneOf.Content
+			}
+			for i, candidate := range candidates {
+				resolved := resolve(top, candidate)
+				if resolved == nil {
+					continue
+				}
+				message := &Message{Name: messageName(candidate, resolved, name, i), Action: op.action}
+				channel.Messages = append(channel.Messages, message)
+				if node := payloadOf(resolved); node != nil {
+					payloads = append(payloads, &payload{message: message, node: node})
+				}
+			}
+		}
+		doc.Channels = append(doc.Channels, channel)
+	})
+	return payloads
+}
+
+// readV3Channels reads the messages of each channel, with the action of the operations that use them.
+func readV3Channels(top *yaml.Node, doc *Document) []*payload {
+	var payloads []*payload
+	forEach(valueOf(top, "channels"), func(name string, channelNode *yaml.Node) {
+		channelNode = resolve(top, channelNode)
+		channel := &Channel{Name: name}
+		if address := valueOf(channelNode, "address"); address != nil {
+			channel.Address = address.Value
+		}
+		forEach(valueOf(channelNode, "messages"), func(key string, messageNode *yaml.Node) {
+			message := &Message{Name: key}
+			channel.Messages = append(channel.Messages, message)
+			if node := payloadOf(resolve(top, messageNode)); node != nil {
+				payloads = append(payloads, &payload{message: message, node: node})
+			}
+		})
+		doc.Channels = append(doc.Channels, channel)
+	})
+
+	forEach(valueOf(top, "operations"), func(_ string, operationNode *yaml.Node) {
+		operationNode = resolve(top, operationNode)
+		action := valueOf(operationNode, "action")
+		channel := doc.Channel(lastSegment(refOf(valueOf(operationNode, "channel"))))
+		if action == nil || channel == nil {
+			return
+		}
+		messages := valueOf(operationNode, "messages")
+		if messages == nil || len(messages.Content) == 0 {
+			for _, message := range channel.Messages {
+				message.Action = action.Value
+			}
+			return
+		}
+		for _, ref := range messages.Content {
+			if name := lastSegment(refOf(ref)); name != "" {
+				if message := channel.Message(name); message != nil {
+					message.Action = action.Value
+				}
+			}
+		}
+	})
+	return payloads
+}
+
+// buildSchemas builds the payload schemas, in the order of the payloads.
+func buildSchemas(top *yaml.Node, payloads []*payload) ([]*base.Schema, error) {
+	components := valueOf(top, "components")
+	if components == nil {
+		components = &yaml.Node{Kind: yaml.MappingNode}
+	}
+	schemas := valueOf(components, "schemas")
+	if schemas == nil {
+		schemas = &yaml.Node{Kind: yaml.MappingNode}
+		components.Content = append(components.Content, scalar("schemas"), schemas)
+	}
+	for i, p := range payloads {
+		schemas.Content = append(schemas.Content, scalar(fmt.Sprintf("%s%d", payloadSchemaPrefix, i)), p.node)
+	}
+
+	// everything but the parts of an AsyncAPI document that clash with the shape of an OpenAPI one is kept, so
+	// references into channels or components still resolve.
+	openapi := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+		scalar("openapi"), scalar("3.1.0"),
+		scalar("info"), {Kind: yaml.MappingNode, Content: []*yaml.Node{
+			scalar("title"), scalar("asyncapi"), scalar("version"), scalar("1.0"),
+		}},
+		scalar("paths"), {Kind: yaml.MappingNode},
+		scalar("components"), components,
+	}}
+	for i := 0; i+1 < len(top.Content); i += 2 {
+		switch top.Content[i].Value {
+		case "asyncapi", "info", "servers", "components", "tags", "externalDocs", "id", "defaultContentType":
+		default:
+			openapi.Content = append(openapi.Content, top.Content[i], top.Content[i+1])
+		}
+	}
+
+	spec, err := yaml.Marshal(openapi)
+	if err != nil {
+		return nil, fmt.Errorf("unable to build AsyncAPI payload schemas: %w", err)
+	}
+	document, err := libopenapi.NewDocument(spec)
+	if err != nil {
+		return nil, fmt.Errorf("unable to build AsyncAPI payload schemas: %w", err)
+	}
+	model, err := document.BuildV3Model()
+	if err != nil && model == nil {
+		return nil, fmt.Errorf("unable to build AsyncAPI payload schemas: %w", err)
+	}
+
+	built := make([]*base.Schema, len(payloads))
+	for i := range payloads {
+		if proxy := model.Model.Components.Schemas.GetOrZero(fmt.Sprintf("%s%d", payloadSchemaPrefix, i)); proxy != nil {
+			built[i] = proxy.Schema()
+		}
+	}
+	return built, nil
+}
+
+// payloadOf returns the JSON schema payload of a message, unwrapping an AsyncAPI 3 multi format schema. Payloads in
+// other schema formats (like Avro or Protobuf) are ignored.
+func payloadOf(message *yaml.Node) *yaml.Node {
+	node := valueOf(message, "payload")
+	if node == nil {
+		return nil
+	}
+	format := valueOf(message, "schemaFormat")
+	if schema := valueOf(node, "schema"); schema != nil && valueOf(node, "schemaFormat") != nil {
+		format, node = valueOf(node, "schemaFormat"), schema
+	}
+	if format != nil && !isJSONSchemaFormat(format.Value) {
+		return nil
+	}
+	return node
+}
+
+func isJSONSchemaFormat(format string) bool {
+	return strings.HasPrefix(format, "application/vnd.aai.asyncapi") ||
+		strings.HasPrefix(format, "application/schema+json") ||
+		strings.HasPrefix(format, "application/schema+yaml")
+}
+
+// messageName names an AsyncAPI 2 message by its name, message id or reference, falling back to the channel.
+func messageName(ref, message *yaml.Node, channel string, index int) string {
+	for _, key := range []string{"name", "messageId"} {
+		if v := valueOf(message, key); v != nil && v.Value != "" {
+			return v.Value
+		}
+	}
+	if name := lastSegment(refOf(ref)); name != "" {
+		return name
+	}
+	if index > 0 {
+		return fmt.Sprintf("%s-%d", channel, index)
+	}
+	return channel
+}
+
+// resolve follows local references until it reaches a node that is not a reference.
+func resolve(top, node *yaml.Node) *yaml.Node {
+	for depth := 0; node != nil && depth < 32; depth++ {
+		ref := refOf(node)
+		if ref == "" {
+			return node
+		}
+		node = lookup(top, ref)
+	}
+	return node
+}
+
+// lookup resolves a local JSON pointer reference, like '#/components/messages/ping'.
+func lookup(top *yaml.Node, ref string) *yaml.Node {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil
+	}
+	node := top
+	for _, segment := range strings.Split(ref[2:], "/") {
+		segment = strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+		if node = valueOf(node, segment); node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+func refOf(node *yaml.Node) string {
+	if ref := valueOf(node, "$ref"); ref != nil {
+		return ref.Value
+	}
+	return ""
+}
+
+func lastSegment(ref string) string {
+	if i := strings.LastIndex(ref, "/"); i >= 0 {
+		return strings.ReplaceAll(strings.ReplaceAll(ref[i+1:], "~1", "/"), "~0", "~")
+	}
+	return ""
+}
+
+func valueOf(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func forEach(node *yaml.Node, fn func(key string, value *yaml.Node)) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		fn(node.Content[i].Value, node.Content[i+1])
+	}
+}
+
+func scalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
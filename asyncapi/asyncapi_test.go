@@ -0,0 +1,167 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package asyncapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var v3Spec = `asyncapi: 3.0.0
+info:
+  title: prices
+  version: 1.0.0
+servers:
+  production:
+    host: prices.pb33f.io
+    protocol: wss
+channels:
+  prices:
+    address: /prices/{symbol}
+    parameters:
+      symbol:
+        description: the symbol to stream
+    messages:
+      priceUpdate:
+        $ref: '#/components/messages/priceUpdate'
+      subscribe:
+        payload:
+          type: object
+          required: [action]
+          properties:
+            action:
+              type: string
+              const: subscribe
+operations:
+  sendPrices:
+    action: send
+    channel:
+      $ref: '#/channels/prices'
+    messages:
+      - $ref: '#/channels/prices/messages/priceUpdate'
+  receiveSubscriptions:
+    action: receive
+    channel:
+      $ref: '#/channels/prices'
+    messages:
+      - $ref: '#/channels/prices/messages/subscribe'
+components:
+  securitySchemes:
+    token:
+      type: httpApiKey
+      name: token
+      in: query
+  messages:
+    priceUpdate:
+      payload:
+        $ref: '#/components/schemas/price'
+  schemas:
+    price:
+      type: object
+      required: [symbol, price]
+      properties:
+        symbol:
+          type: string
+        price:
+          type: number
+`
+
+var v2Spec = `asyncapi: 2.6.0
+info:
+  title: chat
+  version: 1.0.0
+channels:
+  chat:
+    subscribe:
+      message:
+        oneOf:
+          - $ref: '#/components/messages/said'
+          - name: left
+            payload:
+              type: object
+              properties:
+                user:
+                  type: string
+    publish:
+      message:
+        messageId: say
+        payload:
+          type: string
+  avro:
+    subscribe:
+      message:
+        schemaFormat: application/vnd.apache.avro;version=1.9.0
+        payload:
+          type: record
+components:
+  messages:
+    said:
+      payload:
+        type: object
+        required: [text]
+        properties:
+          text:
+            type: string
+`
+
+func TestParse_V3(t *testing.T) {
+	doc, err := Parse([]byte(v3Spec))
+	require.NoError(t, err)
+	assert.Equal(t, "3.0.0", doc.Version)
+
+	channel := doc.Channel("prices")
+	require.NotNil(t, channel)
+	assert.Same(t, channel, doc.Channel("/prices/{symbol}"))
+	require.Len(t, channel.Messages, 2)
+
+	update := channel.Message("priceUpdate")
+	require.NotNil(t, update)
+	assert.Equal(t, ActionSend, update.Action)
+	require.NotNil(t, update.Schema)
+	assert.Equal(t, []string{"symbol", "price"}, update.Schema.Required)
+
+	subscribe := channel.Message("subscribe")
+	require.NotNil(t, subscribe)
+	assert.Equal(t, ActionReceive, subscribe.Action)
+	require.NotNil(t, subscribe.Schema)
+
+	assert.Equal(t, []*Message{update}, channel.MessagesFor(ActionSend))
+	assert.Same(t, update, channel.Message(""))
+	assert.Nil(t, doc.Channel("missing"))
+}
+
+func TestParse_V2(t *testing.T) {
+	doc, err := Parse([]byte(v2Spec))
+	require.NoError(t, err)
+
+	chat := doc.Channel("chat")
+	require.NotNil(t, chat)
+	require.Len(t, chat.Messages, 3)
+
+	said := chat.Message("said")
+	require.NotNil(t, said)
+	assert.Equal(t, ActionSend, said.Action)
+	require.NotNil(t, said.Schema)
+	assert.Equal(t, []string{"text"}, said.Schema.Required)
+
+	assert.Equal(t, ActionSend, chat.Message("left").Action)
+	assert.Equal(t, ActionReceive, chat.Message("say").Action)
+	assert.Len(t, chat.MessagesFor(ActionSend), 2)
+
+	// payloads that are not JSON schema are left without a schema.
+	avro := doc.Channel("avro")
+	require.NotNil(t, avro)
+	require.Len(t, avro.Messages, 1)
+	assert.Nil(t, avro.Messages[0].Schema)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	_, err := Parse([]byte(`openapi: 3.1.0`))
+	assert.Error(t, err)
+
+	_, err = Parse([]byte(`- not an object`))
+	assert.Error(t, err)
+}
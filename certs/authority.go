@@ -0,0 +1,220 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+// Package certs manages the local certificate authority wiretap uses to issue certificates, so traffic can be
+// intercepted and served over TLS without hand-made certificates.
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultAuthorityCertificate is the file name of the exported CA certificate.
+	DefaultAuthorityCertificate = "wiretap-ca.pem"
+	// DefaultAuthorityKey is the file name of the CA private key.
+	DefaultAuthorityKey = "wiretap-ca-key.pem"
+
+	authorityValidity = 10 * 365 * 24 * time.Hour
+	leafValidity      = 365 * 24 * time.Hour
+)
+
+// Authority is a certificate authority that issues leaf certificates for hosts, on demand. Leaf certificates are
+// cached for the life of the authority.
+type Authority struct {
+	Certificate     *x509.Certificate
+	CertificatePath string
+	key             *ecdsa.PrivateKey
+	leaves          sync.Map
+	issue           sync.Mutex
+}
+
+// DefaultAuthorityPaths returns the paths of the CA certificate and key in the wiretap configuration directory.
+func DefaultAuthorityPaths() (string, string) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+	dir = filepath.Join(dir, "wiretap")
+	return filepath.Join(dir, DefaultAuthorityCertificate), filepath.Join(dir, DefaultAuthorityKey)
+}
+
+// LoadOrCreateAuthority loads the CA certificate and key at the paths given. When neither exist, a new CA is
+// generated and written to them, and created is true.
+func LoadOrCreateAuthority(certificatePath, keyPath string) (authority *Authority, created bool, err error) {
+	certificatePEM, certErr := os.ReadFile(certificatePath)
+	keyPEM, keyErr := os.ReadFile(keyPath)
+	if errors.Is(certErr, os.ErrNotExist) && errors.Is(keyErr, os.ErrNotExist) {
+		authority, err = createAuthority(certificatePath, keyPath)
+		return authority, err == nil, err
+	}
+	if certErr != nil {
+		return nil, false, fmt.Errorf("unable to read CA certificate: %w", certErr)
+	}
+	if keyErr != nil {
+		return nil, false, fmt.Errorf("unable to read CA key: %w", keyErr)
+	}
+
+	certificateBlock, _ := pem.Decode(certificatePEM)
+	if certificateBlock == nil {
+		return nil, false, fmt.Errorf("CA certificate '%s' is not PEM encoded", certificatePath)
+	}
+	certificate, err := x509.ParseCertificate(certificateBlock.Bytes)
+	if err != nil {
+		return nil, false, fmt.Errorf("unable to parse CA certificate: %w", err)
+	}
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return nil, false, fmt.Errorf("CA key '%s' is not PEM encoded", keyPath)
+	}
+	key, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, false, fmt.Errorf("unable to parse CA key: %w", err)
+	}
+	return &Authority{Certificate: certificate, CertificatePath: certificatePath, key: key}, false, nil
+}
+
+func createAuthority(certificatePath, keyPath string) (*Authority, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber(),
+		Subject:               pkix.Name{CommonName: "wiretap local CA", Organization: []string{"pb33f wiretap"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(authorityValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(certificatePath), 0o755); err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(filepath.Dir(keyPath), 0o700); err != nil {
+		return nil, err
+	}
+	if err = os.WriteFile(certificatePath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644); err != nil {
+		return nil, err
+	}
+	if err = os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		return nil, err
+	}
+	return &Authority{Certificate: certificate, CertificatePath: certificatePath, key: key}, nil
+}
+
+// LeafCertificate returns a certificate for a host signed by the authority, the host may be a name or an IP address.
+func (a *Authority) LeafCertificate(host string) (*tls.Certificate, error) {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if leaf, ok := a.leaves.Load(host); ok {
+		return leaf.(*tls.Certificate), nil
+	}
+
+	// issue one certificate per host, even when many connections to it arrive together.
+	a.issue.Lock()
+	defer a.issue.Unlock()
+	if leaf, ok := a.leaves.Load(host); ok {
+		return leaf.(*tls.Certificate), nil
+	}
+
+	leaf, err := a.issueLeaf([]string{host})
+	if err != nil {
+		return nil, err
+	}
+	a.leaves.Store(host, leaf)
+	return leaf, nil
+}
+
+// issueLeaf signs a new certificate for hosts, the first host is the common name.
+func (a *Authority) issueLeaf(hosts []string) (*tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	notAfter := now.Add(leafValidity)
+	if notAfter.After(a.Certificate.NotAfter) {
+		notAfter = a.Certificate.NotAfter
+	}
+	template := &x509.Certificate{
+		SerialNumber: serialNumber(),
+		Subject:      pkix.Name{CommonName: hosts[0], Organization: []string{"pb33f wiretap"}},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, a.Certificate, &key.PublicKey, a.key)
+	if err != nil {
+		return nil, err
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Certificate{
+		Certificate: [][]byte{der, a.Certificate.Raw},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}, nil
+}
+
+// TLSConfig returns a server TLS configuration that presents a certificate for the server name the client asks for,
+// or for the fallback host when the client sends no server name.
+func (a *Authority) TLSConfig(fallbackHost string) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: []string{"http/1.1"},
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			if hello.ServerName != "" {
+				return a.LeafCertificate(hello.ServerName)
+			}
+			return a.LeafCertificate(fallbackHost)
+		},
+	}
+}
+
+func serialNumber() *big.Int {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return big.NewInt(time.Now().UnixNano())
+	}
+	return serial
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package certs
+
+import (
+	"crypto/x509"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadOrCreateAuthorityCreatesThenLoads(t *testing.T) {
+	dir := t.TempDir()
+	certificatePath := filepath.Join(dir, "ca", DefaultAuthorityCertificate)
+	keyPath := filepath.Join(dir, "ca", DefaultAuthorityKey)
+
+	created, isNew, err := LoadOrCreateAuthority(certificatePath, keyPath)
+	require.NoError(t, err)
+	assert.True(t, isNew)
+	assert.True(t, created.Certificate.IsCA)
+	assert.Equal(t, certificatePath, created.CertificatePath)
+
+	loaded, isNew, err := LoadOrCreateAuthority(certificatePath, keyPath)
+	require.NoError(t, err)
+	assert.False(t, isNew)
+	assert.Equal(t, created.Certificate.Raw, loaded.Certificate.Raw)
+}
+
+func TestLoadOrCreateAuthorityFailsWithoutKey(t *testing.T) {
+	dir := t.TempDir()
+	certificatePath := filepath.Join(dir, DefaultAuthorityCertificate)
+	_, _, err := LoadOrCreateAuthority(certificatePath, filepath.Join(dir, DefaultAuthorityKey))
+	require.NoError(t, err)
+
+	_, _, err = LoadOrCreateAuthority(certificatePath, filepath.Join(dir, "missing.pem"))
+	assert.Error(t, err)
+}
+
+func TestLeafCertificateIsSignedByTheAuthority(t *testing.T) {
+	dir := t.TempDir()
+	authority, _, err := LoadOrCreateAuthority(filepath.Join(dir, "ca.pem"), filepath.Join(dir, "ca-key.pem"))
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(authority.Certificate)
+
+	for _, host := range []string{"api.example.com", "127.0.0.1"} {
+		leaf, err := authority.LeafCertificate(host)
+		require.NoError(t, err)
+		parsed, err := x509.ParseCertificate(leaf.Certificate[0])
+		require.NoError(t, err)
+		_, err = parsed.Verify(x509.VerifyOptions{DNSName: host, Roots: roots})
+		assert.NoError(t, err, host)
+	}
+
+	first, _ := authority.LeafCertificate("api.example.com")
+	again, _ := authority.LeafCertificate("API.example.com.")
+	assert.Same(t, first, again)
+}
@@ -0,0 +1,42 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+const (
+	// ClientAuthRequire refuses clients that do not present a certificate signed by the client CA.
+	ClientAuthRequire = "require"
+	// ClientAuthOptional verifies the certificates clients present, but allows clients without one.
+	ClientAuthOptional = "optional"
+)
+
+// ClientAuthTLSConfig returns a server TLS configuration that verifies client certificates against the CA bundle at
+// a path. The mode is require or optional, require is used when no mode is given.
+func ClientAuthTLSConfig(caPath, mode string) (*tls.Config, error) {
+	bundle, err := os.ReadFile(caPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read client CA bundle: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bundle) {
+		return nil, fmt.Errorf("client CA bundle '%s' contains no PEM certificates", caPath)
+	}
+
+	tlsConfig := &tls.Config{ClientCAs: pool}
+	switch mode {
+	case "", ClientAuthRequire:
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	case ClientAuthOptional:
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	default:
+		return nil, fmt.Errorf("unknown client auth mode '%s', use '%s' or '%s'", mode, ClientAuthRequire, ClientAuthOptional)
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,41 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package certs
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientAuthTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	authority, _, err := LoadOrCreateAuthority(filepath.Join(dir, "ca.pem"), filepath.Join(dir, "ca-key.pem"))
+	require.NoError(t, err)
+
+	tlsConfig, err := ClientAuthTLSConfig(authority.CertificatePath, "")
+	require.NoError(t, err)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, tlsConfig.ClientAuth)
+	assert.NotNil(t, tlsConfig.ClientCAs)
+
+	tlsConfig, err = ClientAuthTLSConfig(authority.CertificatePath, ClientAuthOptional)
+	require.NoError(t, err)
+	assert.Equal(t, tls.VerifyClientCertIfGiven, tlsConfig.ClientAuth)
+
+	_, err = ClientAuthTLSConfig(authority.CertificatePath, "sometimes")
+	assert.ErrorContains(t, err, "unknown client auth mode 'sometimes'")
+
+	_, err = ClientAuthTLSConfig(filepath.Join(dir, "missing.pem"), ClientAuthRequire)
+	assert.ErrorContains(t, err, "unable to read client CA bundle")
+
+	empty := filepath.Join(dir, "empty.pem")
+	require.NoError(t, os.WriteFile(empty, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte{1}}), 0o600))
+	_, err = ClientAuthTLSConfig(empty, ClientAuthRequire)
+	assert.ErrorContains(t, err, "contains no PEM certificates")
+}
@@ -0,0 +1,110 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultServerCertificate is the file name of the certificate wiretap serves with automatic TLS.
+	DefaultServerCertificate = "wiretap-localhost.pem"
+	// DefaultServerKey is the file name of the private key of the automatic TLS certificate.
+	DefaultServerKey = "wiretap-localhost-key.pem"
+
+	// server certificates are re-issued when they expire within this window.
+	serverRenewal = 30 * 24 * time.Hour
+)
+
+// DefaultServerCertificatePaths returns the paths of the automatic TLS certificate and key, next to the CA in the
+// wiretap configuration directory.
+func DefaultServerCertificatePaths() (string, string) {
+	authorityPath, _ := DefaultAuthorityPaths()
+	dir := filepath.Dir(authorityPath)
+	return filepath.Join(dir, DefaultServerCertificate), filepath.Join(dir, DefaultServerKey)
+}
+
+// ServerHosts returns the hosts a server certificate is issued for: localhost, the loopback addresses and the
+// configured hosts, without duplicates.
+func ServerHosts(configured ...string) []string {
+	hosts := []string{"localhost", "127.0.0.1", "::1"}
+	seen := map[string]bool{"localhost": true, "127.0.0.1": true, "::1": true}
+	for _, host := range configured {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host == "" || seen[host] {
+			continue
+		}
+		seen[host] = true
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+// LoadOrCreateServerCertificate makes sure the certificate and key at the paths given are signed by the authority
+// and valid for every host. A cached certificate is kept when it still is, otherwise a new one is issued and written
+// to the paths, and created is true.
+func (a *Authority) LoadOrCreateServerCertificate(certificatePath, keyPath string, hosts []string) (created bool, err error) {
+	if len(hosts) == 0 {
+		return false, fmt.Errorf("no hosts to issue a server certificate for")
+	}
+	if a.serverCertificateValid(certificatePath, keyPath, hosts) {
+		return false, nil
+	}
+
+	leaf, err := a.issueLeaf(hosts)
+	if err != nil {
+		return false, err
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(leaf.PrivateKey)
+	if err != nil {
+		return false, err
+	}
+	var chain []byte
+	for _, der := range leaf.Certificate {
+		chain = append(chain, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+
+	if err = os.MkdirAll(filepath.Dir(certificatePath), 0o755); err != nil {
+		return false, err
+	}
+	if err = os.MkdirAll(filepath.Dir(keyPath), 0o700); err != nil {
+		return false, err
+	}
+	if err = os.WriteFile(certificatePath, chain, 0o644); err != nil {
+		return false, err
+	}
+	if err = os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (a *Authority) serverCertificateValid(certificatePath, keyPath string, hosts []string) bool {
+	pair, err := tls.LoadX509KeyPair(certificatePath, keyPath)
+	if err != nil {
+		return false
+	}
+	leaf := pair.Leaf
+	if leaf == nil {
+		if leaf, err = x509.ParseCertificate(pair.Certificate[0]); err != nil {
+			return false
+		}
+	}
+	if time.Now().Add(serverRenewal).After(leaf.NotAfter) || leaf.CheckSignatureFrom(a.Certificate) != nil {
+		return false
+	}
+	for _, host := range hosts {
+		if leaf.VerifyHostname(host) != nil {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerHosts(t *testing.T) {
+	assert.Equal(t, []string{"localhost", "127.0.0.1", "::1", "wiretap.local"},
+		ServerHosts("", "Wiretap.local", "localhost", "wiretap.local"))
+}
+
+func TestLoadOrCreateServerCertificateIsCached(t *testing.T) {
+	dir := t.TempDir()
+	authority, _, err := LoadOrCreateAuthority(filepath.Join(dir, DefaultAuthorityCertificate), filepath.Join(dir, DefaultAuthorityKey))
+	require.NoError(t, err)
+
+	certificatePath := filepath.Join(dir, DefaultServerCertificate)
+	keyPath := filepath.Join(dir, DefaultServerKey)
+	hosts := ServerHosts("wiretap.local")
+	created, err := authority.LoadOrCreateServerCertificate(certificatePath, keyPath, hosts)
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	pair, err := tls.LoadX509KeyPair(certificatePath, keyPath)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(pair.Certificate[0])
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	roots.AddCert(authority.Certificate)
+	for _, host := range hosts {
+		_, err = leaf.Verify(x509.VerifyOptions{DNSName: host, Roots: roots})
+		assert.NoError(t, err, host)
+	}
+
+	// the certificate is reused while it covers every host.
+	original, _ := os.ReadFile(certificatePath)
+	created, err = authority.LoadOrCreateServerCertificate(certificatePath, keyPath, hosts)
+	require.NoError(t, err)
+	assert.False(t, created)
+	unchanged, _ := os.ReadFile(certificatePath)
+	assert.Equal(t, original, unchanged)
+
+	// a new host issues a new certificate.
+	created, err = authority.LoadOrCreateServerCertificate(certificatePath, keyPath, ServerHosts("wiretap.local", "api.test"))
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	// as does a different authority.
+	other, _, err := LoadOrCreateAuthority(filepath.Join(dir, "other", DefaultAuthorityCertificate), filepath.Join(dir, "other", DefaultAuthorityKey))
+	require.NoError(t, err)
+	created, err = other.LoadOrCreateServerCertificate(certificatePath, keyPath, ServerHosts("wiretap.local", "api.test"))
+	require.NoError(t, err)
+	assert.True(t, created)
+}
@@ -65,16 +65,25 @@ func handleHttpTraffic(hht *HandleHttpTraffic) {
 			mux.HandleFunc(websocket, handleWebsocket)
 		}
 
+		var handler http.Handler = handlers.CompressHandler(mux)
+
+		// intercept CONNECT tunnels when running as a forward proxy.
+		if wiretapConfig.ForwardProxy {
+			handler = daemon.NewForwardProxyHandler(wiretapConfig.ForwardProxyAuthority, handler, wiretapConfig.Logger)
+		}
+
 		commandLogger(wiretapConfig).Info(fmt.Sprintf("API Gateway UI booting on port %s...", wiretapConfig.Port))
 
+		server := &http.Server{Addr: fmt.Sprintf(":%s", wiretapConfig.Port), Handler: handler}
+
 		var httpErr error
 		if wiretapConfig.CertificateKey != "" && wiretapConfig.Certificate != "" {
-			httpErr = http.ListenAndServeTLS(fmt.Sprintf(":%s", wiretapConfig.Port),
-				wiretapConfig.Certificate,
-				wiretapConfig.CertificateKey,
-				handlers.CompressHandler(mux))
+
+			// verify client certificates, if a client CA is configured.
+			server.TLSConfig = wiretapConfig.ClientTLSConfig
+			httpErr = server.ListenAndServeTLS(wiretapConfig.Certificate, wiretapConfig.CertificateKey)
 		} else {
-			httpErr = http.ListenAndServe(fmt.Sprintf(":%s", wiretapConfig.Port), handlers.CompressHandler(mux))
+			httpErr = server.ListenAndServe()
 		}
 
 		if httpErr != nil {
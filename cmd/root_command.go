@@ -11,10 +11,12 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/pb33f/doctor/terminal"
 	"github.com/pb33f/libopenapi"
 	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/wiretap/certs"
 	"github.com/pb33f/wiretap/har"
 	"github.com/pb33f/wiretap/shared"
 	wiretapSpecs "github.com/pb33f/wiretap/specs"
@@ -99,12 +101,41 @@ var (
 			}
 			staticMockDir, _ = flags.GetString("static-mock-dir")
 			mockMode, _ = flags.GetBool("mock-mode")
+			mockFallback, _ := flags.GetBool("mock-fallback")
 			mockBypassValidation, _ := flags.GetBool("mock-bypass-validation")
+			mockSeed, _ := flags.GetInt64("mock-seed")
+			mockSeedChanged := flags.Changed("mock-seed")
+			mockPagination, _ := flags.GetBool("mock-pagination")
+			mockPaginationTotal, _ := flags.GetInt("mock-pagination-total")
+			mockCallbacks, _ := flags.GetBool("mock-callbacks")
+			mockCallbackDelay, _ := flags.GetInt("mock-callback-delay")
+			maxValidatedBodySize, _ := flags.GetInt64("max-validated-body-size")
+			mockEventCount, _ := flags.GetInt("mock-event-count")
+			mockEventInterval, _ := flags.GetInt("mock-event-interval")
+			asyncAPIFlag, _ := flags.GetString("asyncapi")
+			forwardProxy, _ := flags.GetBool("forward-proxy")
+			forwardProxyCA, _ := flags.GetString("forward-proxy-ca")
+			forwardProxyCAKey, _ := flags.GetString("forward-proxy-ca-key")
+			routeBySpecServers, _ := flags.GetBool("route-by-spec-servers")
+			specUpstreams, _ := flags.GetStringToString("spec-upstream")
+			mirrorTarget, _ := flags.GetString("mirror")
+			mirrorReport, _ := flags.GetString("mirror-report")
+			upstreamTimeout, _ := flags.GetInt("upstream-timeout")
+			upstreamRetries, _ := flags.GetInt("upstream-retries")
+			upstreamCA, _ := flags.GetString("upstream-ca")
+			upstreamCert, _ := flags.GetString("upstream-cert")
+			upstreamKey, _ := flags.GetString("upstream-key")
+			upstreamVerify, _ := flags.GetBool("upstream-verify")
+			tlsAuto, _ := flags.GetBool("tls-auto")
+			tlsAutoHosts, _ := flags.GetStringSlice("tls-auto-host")
+			clientCA, _ := flags.GetString("client-ca")
+			clientAuth, _ := flags.GetString("client-auth")
 			useAllMockResponseFields, _ = flags.GetBool("enable-all-mock-response-fields")
 			hardError, _ = flags.GetBool("hard-validation")
 			hardErrorCode, _ = flags.GetInt("hard-validation-code")
 			hardErrorReturnCode, _ = flags.GetInt("hard-validation-return-code")
 			hardErrorReturnProblem, _ := flags.GetBool("hard-error-return-problem")
+			hardErrorUnauthorized, _ := flags.GetBool("hard-validation-unauthorized")
 			streamReport, _ := flags.GetBool("stream-report")
 			strictRedirectLocation, _ := flags.GetBool("strict-redirect-location")
 			strictMode, _ := flags.GetBool("strict-mode")
@@ -251,11 +282,108 @@ var (
 						config.MockMode = true
 					}
 				}
+				if mockFallback {
+					config.MockFallback = true
+				}
 				if mockBypassValidation {
 					if !config.MockBypassValidation {
 						config.MockBypassValidation = true
 					}
 				}
+				if mockSeedChanged {
+					config.MockSeed = &mockSeed
+				}
+				if mockPagination {
+					config.MockPagination = true
+				}
+				if mockPaginationTotal > 0 {
+					config.MockPaginationTotal = mockPaginationTotal
+				}
+				if mockCallbacks {
+					config.MockCallbacks = true
+				}
+				if mockCallbackDelay > 0 {
+					config.MockCallbackDelay = mockCallbackDelay
+				}
+				if maxValidatedBodySize > 0 {
+					config.MaxValidatedBodySize = maxValidatedBodySize
+				}
+				if mockEventCount > 0 {
+					config.MockEventCount = mockEventCount
+				}
+				if mockEventInterval > 0 {
+					config.MockEventInterval = mockEventInterval
+				}
+				if asyncAPIFlag != "" {
+					config.AsyncAPI = asyncAPIFlag
+				}
+				if forwardProxy {
+					config.ForwardProxy = true
+				}
+				if forwardProxyCA != "" {
+					config.ForwardProxyCA = forwardProxyCA
+				}
+				if forwardProxyCAKey != "" {
+					config.ForwardProxyCAKey = forwardProxyCAKey
+				}
+				if routeBySpecServers {
+					config.RouteBySpecServers = true
+				}
+				if len(specUpstreams) > 0 {
+					if config.SpecUpstreams == nil {
+						config.SpecUpstreams = make(map[string]string, len(specUpstreams))
+					}
+					for spec, upstream := range specUpstreams {
+						config.SpecUpstreams[spec] = upstream
+					}
+				}
+				if mirrorTarget != "" {
+					if config.Mirror == nil {
+						config.Mirror = &shared.WiretapMirrorConfig{}
+					}
+					config.Mirror.Target = mirrorTarget
+				}
+				if mirrorReport != "" {
+					config.MirrorReportFile = mirrorReport
+				}
+				if upstreamTimeout > 0 || upstreamRetries > 0 {
+					if config.Resilience == nil {
+						config.Resilience = &shared.WiretapResilienceConfig{}
+					}
+					if upstreamTimeout > 0 {
+						config.Resilience.ResponseTimeout = upstreamTimeout
+					}
+					if upstreamRetries > 0 {
+						config.Resilience.Retries = upstreamRetries
+					}
+				}
+				if upstreamCA != "" || upstreamCert != "" || upstreamKey != "" || upstreamVerify {
+					if config.UpstreamTLS == nil {
+						config.UpstreamTLS = &shared.WiretapTLSConfig{}
+					}
+					if upstreamCA != "" {
+						config.UpstreamTLS.CA = upstreamCA
+					}
+					if upstreamCert != "" {
+						config.UpstreamTLS.Certificate = upstreamCert
+						config.UpstreamTLS.Key = upstreamKey
+					}
+					if upstreamVerify {
+						config.UpstreamTLS.Verify = &upstreamVerify
+					}
+				}
+				if tlsAuto {
+					config.TLSAuto = true
+				}
+				if len(tlsAutoHosts) > 0 {
+					config.TLSAutoHosts = append(config.TLSAutoHosts, tlsAutoHosts...)
+				}
+				if clientCA != "" {
+					config.ClientCA = clientCA
+				}
+				if clientAuth != "" {
+					config.ClientAuth = clientAuth
+				}
 				if useAllMockResponseFields {
 					if !config.UseAllMockResponseFields {
 						config.UseAllMockResponseFields = true
@@ -309,9 +437,106 @@ var (
 				if mockMode {
 					config.MockMode = true
 				}
+				if mockFallback {
+					config.MockFallback = true
+				}
 				if mockBypassValidation {
 					config.MockBypassValidation = true
 				}
+				if mockSeedChanged {
+					config.MockSeed = &mockSeed
+				}
+				if mockPagination {
+					config.MockPagination = true
+				}
+				if mockPaginationTotal > 0 {
+					config.MockPaginationTotal = mockPaginationTotal
+				}
+				if mockCallbacks {
+					config.MockCallbacks = true
+				}
+				if mockCallbackDelay > 0 {
+					config.MockCallbackDelay = mockCallbackDelay
+				}
+				if maxValidatedBodySize > 0 {
+					config.MaxValidatedBodySize = maxValidatedBodySize
+				}
+				if mockEventCount > 0 {
+					config.MockEventCount = mockEventCount
+				}
+				if mockEventInterval > 0 {
+					config.MockEventInterval = mockEventInterval
+				}
+				if asyncAPIFlag != "" {
+					config.AsyncAPI = asyncAPIFlag
+				}
+				if forwardProxy {
+					config.ForwardProxy = true
+				}
+				if forwardProxyCA != "" {
+					config.ForwardProxyCA = forwardProxyCA
+				}
+				if forwardProxyCAKey != "" {
+					config.ForwardProxyCAKey = forwardProxyCAKey
+				}
+				if routeBySpecServers {
+					config.RouteBySpecServers = true
+				}
+				if len(specUpstreams) > 0 {
+					if config.SpecUpstreams == nil {
+						config.SpecUpstreams = make(map[string]string, len(specUpstreams))
+					}
+					for spec, upstream := range specUpstreams {
+						config.SpecUpstreams[spec] = upstream
+					}
+				}
+				if mirrorTarget != "" {
+					if config.Mirror == nil {
+						config.Mirror = &shared.WiretapMirrorConfig{}
+					}
+					config.Mirror.Target = mirrorTarget
+				}
+				if mirrorReport != "" {
+					config.MirrorReportFile = mirrorReport
+				}
+				if upstreamTimeout > 0 || upstreamRetries > 0 {
+					if config.Resilience == nil {
+						config.Resilience = &shared.WiretapResilienceConfig{}
+					}
+					if upstreamTimeout > 0 {
+						config.Resilience.ResponseTimeout = upstreamTimeout
+					}
+					if upstreamRetries > 0 {
+						config.Resilience.Retries = upstreamRetries
+					}
+				}
+				if upstreamCA != "" || upstreamCert != "" || upstreamKey != "" || upstreamVerify {
+					if config.UpstreamTLS == nil {
+						config.UpstreamTLS = &shared.WiretapTLSConfig{}
+					}
+					if upstreamCA != "" {
+						config.UpstreamTLS.CA = upstreamCA
+					}
+					if upstreamCert != "" {
+						config.UpstreamTLS.Certificate = upstreamCert
+						config.UpstreamTLS.Key = upstreamKey
+					}
+					if upstreamVerify {
+						config.UpstreamTLS.Verify = &upstreamVerify
+					}
+				}
+				if tlsAuto {
+					config.TLSAuto = true
+				}
+				if len(tlsAutoHosts) > 0 {
+					config.TLSAutoHosts = append(config.TLSAutoHosts, tlsAutoHosts...)
+				}
+				if clientCA != "" {
+					config.ClientCA = clientCA
+				}
+				if clientAuth != "" {
+					config.ClientAuth = clientAuth
+				}
 				if useAllMockResponseFields {
 					config.UseAllMockResponseFields = true
 				}
@@ -383,7 +608,13 @@ var (
 				return fmt.Errorf("cannot enable mock mode: no OpenAPI specification provided")
 			}
 
-			if !dryRun && !config.MockMode && redirectURL == "" && config.HAR == "" && !config.HARValidate {
+			if (config.MockFallback || len(config.MockFallbackList) > 0) && len(specs) == 0 {
+				cliLog.Warn("Mock fallback is enabled, but no OpenAPI specification is provided to generate mocks from. " +
+					"Failed upstream responses will be returned as they are")
+				fmt.Println()
+			}
+
+			if !dryRun && !config.MockMode && !config.ForwardProxy && redirectURL == "" && config.HAR == "" && !config.HARValidate {
 				fmt.Println()
 				cliLog.Error("No redirect URL provided. " +
 					"Please provide a URL to redirect API traffic to using the --url or -u flags.")
@@ -446,6 +677,9 @@ var (
 				config.ReportFile = reportFilename
 			}
 			reportFilename = config.ReportFile
+			if config.MirrorReportFile == "" && mirroring(&config) {
+				config.MirrorReportFile = "wiretap-mirror-report.json"
+			}
 			config.FS = FS
 
 			if config.HardErrors || hardError {
@@ -462,6 +696,9 @@ var (
 			if !config.HardErrorReturnProblem && hardErrorReturnProblem {
 				config.HardErrorReturnProblem = true
 			}
+			if !config.HardErrorUnauthorized && hardErrorUnauthorized {
+				config.HardErrorUnauthorized = true
+			}
 
 			// certs
 			if config.Certificate == "" && config.CertificateKey == "" {
@@ -469,6 +706,29 @@ var (
 				config.CertificateKey = certKey
 			}
 
+			// generate a certificate for all listeners, when TLS is automatic and no certificate is provided.
+			var tlsAutoCA string
+			if config.TLSAuto && config.Certificate == "" && config.CertificateKey == "" {
+				caPath, caKeyPath := certs.DefaultAuthorityPaths()
+				if config.ForwardProxyCA != "" {
+					caPath, caKeyPath = config.ForwardProxyCA, config.ForwardProxyCAKey
+				}
+				authority, _, err := certs.LoadOrCreateAuthority(caPath, caKeyPath)
+				if err != nil {
+					cliLog.Error(fmt.Sprintf("Failed to load the local CA for automatic TLS: %s", err.Error()))
+					return err
+				}
+				certPath, keyPath := certs.DefaultServerCertificatePaths()
+				hosts := certs.ServerHosts(append([]string{config.WebSocketHost}, config.TLSAutoHosts...)...)
+				if _, err = authority.LoadOrCreateServerCertificate(certPath, keyPath, hosts); err != nil {
+					cliLog.Error(fmt.Sprintf("Failed to generate a certificate for automatic TLS: %s", err.Error()))
+					return err
+				}
+				config.Certificate = certPath
+				config.CertificateKey = keyPath
+				tlsAutoCA = authority.CertificatePath
+			}
+
 			// variables
 			if len(config.Variables) > 0 {
 				config.CompileVariables()
@@ -519,6 +779,11 @@ var (
 				printLoadedMockModeList(config.MockModeList)
 			}
 
+			if len(config.MockFallbackList) > 0 && !config.MockFallback && !config.MockMode {
+				config.CompileMockFallbackList()
+				printLoadedMockFallbackList(config.MockFallbackList)
+			}
+
 			if len(config.HardErrorsList) > 0 && !config.HardErrors {
 				config.CompileHardErrorList()
 				printLoadedHardErrorList(config.MockModeList)
@@ -560,6 +825,10 @@ var (
 				fmt.Printf("❌  Hard validation mode enabled. HTTP error %s for requests and error %s for responses that "+
 					"fail to pass validation.\n",
 					style.Error(config.HardErrorCode), style.Error(config.HardErrorReturnCode))
+				if config.HardErrorUnauthorized {
+					fmt.Printf("🔐 Requests failing their security requirements are refused with the %s response of "+
+						"their operation.\n", style.Error(401))
+				}
 				fmt.Println()
 			}
 
@@ -577,6 +846,13 @@ var (
 				fmt.Println()
 			}
 
+			// mock fallback
+			if config.MockFallback && !config.MockMode {
+				fmt.Printf("Ⓜ️ %s. Requests the target API fails to answer will be answered with mocks.\n",
+					style.Primary("Mock fallback enabled"))
+				fmt.Println()
+			}
+
 			// strict mode
 			if config.StrictMode {
 				fmt.Printf("🔬 %s. Undeclared properties, parameters, headers, and cookies will be reported as validation errors.\n",
@@ -588,6 +864,108 @@ var (
 			if config.CertificateKey != "" && config.Certificate != "" {
 				fmt.Printf("🔐 Running over %s using certificate: %s and key: %s\n",
 					style.Warning("TLS/HTTPS & HTTP/2"), style.Secondary(config.Certificate), style.Primary(config.CertificateKey))
+				if tlsAutoCA != "" {
+					fmt.Printf("📜 Certificate generated automatically, trust the local CA once to avoid browser warnings: %s\n",
+						style.Secondary(tlsAutoCA))
+				}
+				if config.ClientCA != "" {
+					tlsConfig, err := certs.ClientAuthTLSConfig(config.ClientCA, config.ClientAuth)
+					if err != nil {
+						cliLog.Error(fmt.Sprintf("Unable to configure client certificates: %s", err.Error()))
+						return fmt.Errorf("unable to configure client certificates: %w", err)
+					}
+					config.ClientTLSConfig = tlsConfig
+					mode := config.ClientAuth
+					if mode == "" {
+						mode = certs.ClientAuthRequire
+					}
+					fmt.Printf("🪪 Verifying client certificates (%s) using CA: %s\n",
+						style.Primary(mode), style.Secondary(config.ClientCA))
+				}
+				fmt.Println()
+			} else if config.ClientCA != "" {
+				cliLog.Error("A client CA is configured, but client certificates are only verified over TLS. " +
+					"Please provide a certificate and key using the --cert and --key flags.")
+				fmt.Println()
+				return fmt.Errorf("cannot verify client certificates: no TLS certificate and key provided")
+			}
+
+			// forward proxy?
+			if config.ForwardProxy {
+				defaultCA, defaultCAKey := certs.DefaultAuthorityPaths()
+				if config.ForwardProxyCA == "" {
+					config.ForwardProxyCA = defaultCA
+				}
+				if config.ForwardProxyCAKey == "" {
+					config.ForwardProxyCAKey = defaultCAKey
+				}
+				authority, created, err := certs.LoadOrCreateAuthority(config.ForwardProxyCA, config.ForwardProxyCAKey)
+				if err != nil {
+					cliLog.Error(fmt.Sprintf("Unable to load the forward proxy CA: %s", err.Error()))
+					return fmt.Errorf("unable to load the forward proxy CA: %w", err)
+				}
+				if created {
+					fmt.Printf("📜 Generated a new forward proxy CA, exported to %s\n", style.Secondary(authority.CertificatePath))
+				}
+				config.ForwardProxyAuthority = authority
+				fmt.Printf("🔀 %s. Set HTTP_PROXY / HTTPS_PROXY to the API gateway, and trust the CA certificate: %s\n",
+					style.Primary("Forward proxy mode enabled"), style.Secondary(config.ForwardProxyCA))
+				fmt.Println()
+			}
+
+			// upstream resilience?
+			if config.Resilience != nil {
+				fmt.Printf("🛟 %s: %s\n", style.Primary("Upstream resilience policy"),
+					style.Secondary(describeResilience(config.Resilience)))
+				fmt.Println()
+			}
+
+			// mirroring?
+			if mirroring(&config) {
+				if config.Mirror != nil && config.Mirror.Target != "" {
+					fmt.Printf("🪞 %s to shadow upstream: %s\n", style.Primary("Mirroring proxied traffic"),
+						style.Secondary(config.Mirror.Target))
+				}
+				fmt.Printf("🪞 Shadow responses are compared with primary responses, summarized in: %s\n",
+					style.Secondary(config.MirrorReportFile))
+				fmt.Println()
+			}
+
+			// upstream tokens?
+			if config.AuthProvider != nil {
+				fmt.Printf("🎟️  Bearer tokens from the '%s' auth provider are sent to upstreams\n",
+					style.Primary(config.AuthProvider.Type))
+				fmt.Println()
+			}
+
+			// inspecting tokens?
+			if config.JWT != nil {
+				fmt.Printf("🪪 %s: %s\n", style.Primary("JWTs sent with requests are decoded"),
+					style.Secondary(describeJWT(config.JWT)))
+				fmt.Println()
+			}
+
+			// redacting captures?
+			if config.Redaction != nil {
+				fmt.Printf("🙈 %s: %s\n", style.Primary("Sensitive values are redacted from transactions and reports"),
+					style.Secondary(describeRedaction(config.Redaction)))
+				fmt.Println()
+			}
+
+			// plugins?
+			if len(config.Plugins) > 0 {
+				fmt.Printf("🧩 %s: %s\n", style.Primary("Transactions are checked and transformed by plugins"),
+					style.Secondary(strings.Join(config.Plugins, ", ")))
+				fmt.Println()
+			}
+
+			// routing by specification?
+			if config.RouteBySpecServers || len(config.SpecUpstreams) > 0 {
+				fmt.Printf("🧭 %s. Requests are sent to the upstream of the specification they match, "+
+					"others are sent to the redirect URL.\n", style.Primary("Specification routing enabled"))
+				for spec, upstream := range config.SpecUpstreams {
+					fmt.Printf("   %s -> %s\n", style.Secondary(spec), style.Primary(upstream))
+				}
 				fmt.Println()
 			}
 
@@ -854,10 +1232,38 @@ func registerRootFlags(cmd *cobra.Command) {
 	flags.BoolP("hard-validation", "e", false, "Return a HTTP error for non-compliant request/response")
 	flags.IntP("hard-validation-code", "q", 400, "Set a custom http error code for non-compliant requests when using the hard-error flag")
 	flags.IntP("hard-validation-return-code", "y", 502, "Set a custom http error code for non-compliant responses when using the hard-error flag")
+	flags.Bool("hard-validation-unauthorized", false, "When hard-validation is on, refuse requests that fail their security requirements with the operation's 401 response, without calling the target API (default is false)")
 	flags.Bool("hard-error-return-problem", false, "When hard-validation triggers, return an RFC 9457 application/problem+json body describing the validation failures (default is false)")
 	flags.StringP("static-mock-dir", "", "", "Directory containing static mock definitions. All requests matching these definitions will return mocked responses.")
 	flags.BoolP("mock-mode", "x", false, "Run in mock mode, responses are mocked and no traffic is sent to the target API (requires OpenAPI spec)")
+	flags.Bool("mock-fallback", false, "Answer requests with mocks when the target API errors, times out, or responds with a 404 or 5xx (requires OpenAPI spec)")
 	flags.Bool("mock-bypass-validation", false, "In mock mode, bypass request validation so Preferred / wiretap-status-code examples are returned even for malformed requests (default is false)")
+	flags.Int64("mock-seed", 0, "Seed mock data generated from schemas, so the same seed always returns the same mock for an operation. Can be overridden per request with the 'wiretap-mock-seed' header")
+	flags.Bool("mock-pagination", false, "Return the page asked for by paginated mock operations, instead of the example or schema as it is. Can be turned on or off per request with 'Prefer: paginate=true'")
+	flags.Int("mock-pagination-total", 0, "Set the number of items paginated mock collections hold (default 100)")
+	flags.Bool("mock-callbacks", false, "Send the callbacks declared by mocked operations to the URL resolved from the callback expression")
+	flags.Int("mock-callback-delay", 0, "Set a delay (in milliseconds) before mock callbacks are sent")
+	flags.Int("mock-event-count", 0, "Set the number of events mocked server-sent event streams hold (default 5)")
+	flags.Int("mock-event-interval", 0, "Set the time (in milliseconds) between the events of mocked server-sent event streams (default 1000)")
+	flags.String("asyncapi", "", "Path to an AsyncAPI document, used to validate websocket frames and mock websocket messages")
+	flags.Bool("forward-proxy", false, "Run as a forward proxy, clients set HTTP_PROXY / HTTPS_PROXY to wiretap and HTTPS traffic is intercepted using a local CA")
+	flags.String("forward-proxy-ca", "", "Path to the CA certificate used to intercept HTTPS traffic, generated when missing (default is wiretap-ca.pem in the user config directory)")
+	flags.String("forward-proxy-ca-key", "", "Path to the key of the CA certificate used to intercept HTTPS traffic, generated when missing")
+	flags.Bool("route-by-spec-servers", false, "Send each request to the upstream in the servers of the OpenAPI specification it matches, instead of the redirect URL")
+	flags.String("mirror", "", "Send a copy of each proxied request to a shadow upstream URL, and compare its responses with the primary upstream")
+	flags.Int("upstream-timeout", 0, "Fail requests when the upstream has not sent response headers within this many milliseconds")
+	flags.Int("upstream-retries", 0, "Retry idempotent requests that fail, or are answered with a 502, 503 or 504, this many times")
+	flags.String("mirror-report", "", "Set the file the comparison of mirrored responses is summarized in (default is wiretap-mirror-report.json)")
+	flags.StringToString("spec-upstream", nil, "Send requests matching a specification to an upstream URL, as spec=url (the spec file name can be used), can use arg multiple times")
+	flags.Bool("upstream-verify", false, "Verify the TLS certificates of upstream APIs (default is false)")
+	flags.String("upstream-ca", "", "Path to a PEM CA bundle used to verify the TLS certificates of upstream APIs")
+	flags.String("upstream-cert", "", "Path to the client certificate presented to upstream APIs that require mutual TLS")
+	flags.String("upstream-key", "", "Path to the key of the client certificate presented to upstream APIs")
+	flags.Bool("tls-auto", false, "Serve the API gateway, monitor and websocket over TLS with a certificate signed by a generated local CA")
+	flags.StringSlice("tls-auto-host", nil, "Add a hostname to the certificate generated by --tls-auto, localhost is always included")
+	flags.String("client-ca", "", "Path to a PEM CA bundle used to verify the client certificates presented to the API gateway (requires --cert and --key)")
+	flags.String("client-auth", "", "Set how client certificates are verified, 'require' or 'optional' (default is require)")
+	flags.Int64("max-validated-body-size", 0, "Set the largest response body (in bytes) held in memory and validated, larger and streaming responses are streamed to the client without validation (default 10MB)")
 	flags.BoolP("enable-all-mock-response-fields", "o", true, "Enable usage of all property examples in mock responses. When set to false, only required field examples will be used.")
 	flags.StringP("config", "c", "", "Location of wiretap configuration file to use (default is .wiretap in current directory)")
 	flags.StringP("base", "b", "", "Set a base path to resolve relative file references from, or a overriding base URL to resolve remote references from")
@@ -883,6 +1289,91 @@ func printLoadedIgnorePathRewrite(ignoreRewritePaths []*shared.IgnoreRewriteConf
 	fmt.Println()
 }
 
+// mirroring returns true if requests are mirrored globally or on any path.
+func mirroring(config *shared.WiretapConfiguration) bool {
+	if config.Mirror != nil && config.Mirror.Target != "" {
+		return true
+	}
+	if config.PathConfigurations == nil {
+		return false
+	}
+	for x := config.PathConfigurations.First(); x != nil; x = x.Next() {
+		if x.Value().Mirror != nil && x.Value().Mirror.Target != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// describeResilience summarizes a resilience policy in one line.
+func describeResilience(resilience *shared.WiretapResilienceConfig) string {
+	var parts []string
+	if resilience.ConnectTimeout > 0 {
+		parts = append(parts, fmt.Sprintf("%dms connect timeout", resilience.ConnectTimeout))
+	}
+	if resilience.ResponseTimeout > 0 {
+		parts = append(parts, fmt.Sprintf("%dms response timeout", resilience.ResponseTimeout))
+	}
+	if resilience.Retries > 0 {
+		parts = append(parts, fmt.Sprintf("%d %s", resilience.Retries,
+			shared.Pluralize(resilience.Retries, "retry", "retries")))
+	}
+	if resilience.CircuitBreaker != nil {
+		fallback := resilience.CircuitBreaker.Fallback
+		if fallback == "" {
+			fallback = shared.ResilienceFallbackProblem
+		}
+		parts = append(parts, fmt.Sprintf("circuit breaker with %s fallback", fallback))
+	}
+	if len(parts) == 0 {
+		return "no timeouts or retries"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func describeJWT(jwt *shared.WiretapJWTConfig) string {
+	var parts []string
+	if len(jwt.Cookies) > 0 {
+		parts = append(parts, fmt.Sprintf("read from the %s '%s'",
+			shared.Pluralize(len(jwt.Cookies), "cookie", "cookies"), strings.Join(jwt.Cookies, "', '")))
+	}
+	if jwt.JWKS != "" || len(jwt.Keys) > 0 || jwt.Secret != "" {
+		parts = append(parts, "signatures verified")
+	} else {
+		parts = append(parts, "signatures not verified")
+	}
+	if len(jwt.RedactClaims) > 0 {
+		parts = append(parts, fmt.Sprintf("%d %s redacted", len(jwt.RedactClaims),
+			shared.Pluralize(len(jwt.RedactClaims), "claim", "claims")))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func describeRedaction(redaction *shared.WiretapRedactionConfig) string {
+	strategy := redaction.Strategy
+	if strategy == "" {
+		strategy = shared.RedactMask
+	}
+	parts := []string{strategy}
+	for _, rule := range []struct {
+		count        int
+		single, many string
+	}{
+		{len(redaction.Headers), "header", "headers"},
+		{len(redaction.Cookies), "cookie", "cookies"},
+		{len(redaction.JSONPaths), "JSONPath", "JSONPaths"},
+		{len(redaction.Patterns), "pattern", "patterns"},
+	} {
+		if rule.count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", rule.count, shared.Pluralize(rule.count, rule.single, rule.many)))
+		}
+	}
+	if redaction.SpecProperties {
+		parts = append(parts, "sensitive specification properties")
+	}
+	return strings.Join(parts, ", ")
+}
+
 func printLoadedPathConfigurations(configs *orderedmap.Map[string, *shared.WiretapPathConfig]) {
 	cliLog.Info(fmt.Sprintf("Loaded %d path %s", configs.Len(),
 		shared.Pluralize(configs.Len(), "configuration", "configurations")))
@@ -909,6 +1400,35 @@ func printLoadedPathConfigurations(configs *orderedmap.Map[string, *shared.Wiret
 		if v.Auth != "" {
 			fmt.Printf("🔒 Basic authentication implemented for '%s'\n", style.Secondary(k))
 		}
+		if v.AuthProvider != nil {
+			fmt.Printf("🎟️  Bearer tokens from the '%s' auth provider sent for '%s'\n",
+				style.Primary(v.AuthProvider.Type), style.Secondary(k))
+		}
+
+		if v.Resilience != nil {
+			fmt.Printf("🛟 Resilience policy for '%s': %s\n", style.Secondary(k), style.Primary(describeResilience(v.Resilience)))
+		}
+		if v.Mirror != nil && v.Mirror.Target != "" {
+			fmt.Printf("🪞 Requests mirrored to '%s'\n", style.Primary(v.Mirror.Target))
+		}
+		if v.Transforms != nil {
+			fmt.Printf("🔧 Bodies transformed for '%s' (%s request, %s response)\n", style.Secondary(k),
+				style.Primary(len(v.Transforms.Request)), style.Primary(len(v.Transforms.Response)))
+		}
+		if v.Script != "" {
+			fmt.Printf("📜 Script '%s' runs on requests to '%s'\n", style.Primary(v.Script), style.Secondary(k))
+		}
+		if v.Signing != nil && v.Signing.SigV4 != nil {
+			fmt.Printf("✍️  Requests signed with AWS SigV4 for service '%s' in '%s'\n",
+				style.Primary(v.Signing.SigV4.Service), style.Primary(v.Signing.SigV4.Region))
+		}
+		if v.Signing != nil && v.Signing.HMAC != nil {
+			header := v.Signing.HMAC.Header
+			if header == "" {
+				header = "X-Signature"
+			}
+			fmt.Printf("✍️  Requests signed with an HMAC in the '%s' header\n", style.Primary(header))
+		}
 
 		if v.RewriteId != "" {
 			fmt.Printf("💳  Identifier '%s' registered for this configuration\n", style.Primary(v.RewriteId))
@@ -1007,6 +1527,16 @@ func printLoadedMockModeList(mockModeList []string) {
 	fmt.Println()
 }
 
+func printLoadedMockFallbackList(mockFallbackList []string) {
+	cliLog.Info(fmt.Sprintf("Loaded %d %s from mock fallback list", len(mockFallbackList),
+		shared.Pluralize(len(mockFallbackList), "path", "paths")))
+
+	for _, x := range mockFallbackList {
+		fmt.Printf("️Ⓜ️  Paths matching '%s' will be %s when the target API fails.\n", x, style.Secondary("answered with mocks"))
+	}
+	fmt.Println()
+}
+
 func printLoadedHardErrorList(HardErrorList []string) {
 	cliLog.Info(fmt.Sprintf("Loaded %d %s from hard validation list", len(HardErrorList),
 		shared.Pluralize(len(HardErrorList), "path", "paths")))
@@ -75,7 +75,10 @@ func runWiretapService(wiretapConfig *shared.WiretapConfiguration, docs []shared
 	if len(conflictReports) > 0 {
 		conflictReport = conflictReports[0]
 	}
-	wtService := daemon.NewWiretapService(docs, wiretapConfig, storeManager, conflictReport)
+	wtService, err := daemon.NewWiretapService(docs, wiretapConfig, storeManager, conflictReport)
+	if err != nil {
+		return platformServer, err
+	}
 
 	// register wiretap service
 	if err := registerPlatformService(platformServer, "wiretap", daemon.WiretapServiceChan, wtService); err != nil {
@@ -94,7 +97,9 @@ func runWiretapService(wiretapConfig *shared.WiretapConfiguration, docs []shared
 	}
 
 	// register control service
-	if err := registerPlatformService(platformServer, "control", controls.ControlServiceChan, controls.NewControlsService(storeManager)); err != nil {
+	controlService := controls.NewControlsService(storeManager)
+	controlService.SetWebhookTrigger(wtService)
+	if err := registerPlatformService(platformServer, "control", controls.ControlServiceChan, controlService); err != nil {
 		return platformServer, err
 	}
 
@@ -56,6 +56,34 @@ func TestRootCommandRejectsInvalidRedirectURL(t *testing.T) {
 	assert.Contains(t, err.Error(), "invalid redirect URL")
 }
 
+func TestRootCommandRejectsClientCAWithoutTLS(t *testing.T) {
+	err := executeTestRootCommand(t, "--url", "http://example.com", "--client-ca", t.TempDir()+"/ca.pem")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot verify client certificates: no TLS certificate and key provided")
+}
+
+func TestRootCommandRejectsInvalidClientCA(t *testing.T) {
+	tmpDir := t.TempDir()
+	err := executeTestRootCommand(t, "--url", "http://example.com", "--cert", tmpDir+"/cert.pem",
+		"--key", tmpDir+"/key.pem", "--client-ca", tmpDir+"/missing-ca.pem")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to configure client certificates")
+}
+
+func TestRootCommandRejectsInvalidForwardProxyCA(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(tmpDir+"/ca.pem", []byte("not a certificate"), 0o600))
+	require.NoError(t, os.WriteFile(tmpDir+"/ca-key.pem", []byte("not a key"), 0o600))
+
+	err := executeTestRootCommand(t, "--url", "http://example.com", "--forward-proxy",
+		"--forward-proxy-ca", tmpDir+"/ca.pem", "--forward-proxy-ca-key", tmpDir+"/ca-key.pem")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to load the forward proxy CA")
+}
+
 func TestRootCommandFailsNormalRunWhenSpecLoadFails(t *testing.T) {
 	err := executeTestRootCommand(t, "--spec", "missing.yaml", "--url", "http://example.com")
 
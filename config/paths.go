@@ -47,6 +47,34 @@ func FindPaths(path string, configuration *shared.WiretapConfiguration) []*share
 	return foundConfigurations
 }
 
+// FindPathsForRequest returns the path configurations matching the path of a request. Path configurations for a
+// client identity only match requests sent with a client certificate that has a matching name.
+func FindPathsForRequest(path string, req *http.Request, configuration *shared.WiretapConfiguration) []*shared.WiretapPathConfig {
+	var identity *shared.ClientIdentity
+	var foundConfigurations []*shared.WiretapPathConfig
+	for _, pathConfig := range FindPaths(path, configuration) {
+		if pathConfig.CompiledClientIdentity != nil {
+			if identity == nil {
+				identity = shared.RequestClientIdentity(req)
+			}
+			if !clientIdentityMatches(pathConfig, identity) {
+				continue
+			}
+		}
+		foundConfigurations = append(foundConfigurations, pathConfig)
+	}
+	return foundConfigurations
+}
+
+func clientIdentityMatches(pathConfig *shared.WiretapPathConfig, identity *shared.ClientIdentity) bool {
+	for _, name := range identity.Names() {
+		if pathConfig.CompiledClientIdentity.Match(name) {
+			return true
+		}
+	}
+	return false
+}
+
 func FindPathDelay(path string, configuration *shared.WiretapConfiguration) int {
 	var foundMatch int
 	for key := range configuration.CompiledPathDelays {
@@ -84,6 +112,19 @@ func IncludePathOnMockMode(path string, configuration *shared.WiretapConfigurati
 	return false
 }
 
+// IncludePathOnMockFallback returns true if failed upstream responses to a path are answered with mocks.
+func IncludePathOnMockFallback(path string, configuration *shared.WiretapConfiguration) bool {
+	if configuration.MockFallback {
+		return true
+	}
+	for _, mockFallbackPath := range configuration.CompiledMockFallbackList {
+		if mockFallbackPath.Match(path) {
+			return true
+		}
+	}
+	return false
+}
+
 func hardErrorOnPath(path string, configuration *shared.WiretapConfiguration) bool {
 	for _, mockModePath := range configuration.CompiledHardErrorList {
 		if mockModePath.Match(path) {
@@ -200,7 +241,7 @@ func FindPathWithRewriteId(paths []*shared.WiretapPathConfig, req *http.Request)
 }
 
 func RewritePath(path string, req *http.Request, configuration *shared.WiretapConfiguration) *PathRewrite {
-	paths := FindPaths(path, configuration)
+	paths := FindPathsForRequest(path, req, configuration)
 
 	// If there are no configurations that match the request path, we should crash out early
 	if len(paths) == 0 {
@@ -4,6 +4,9 @@
 package config
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
 	"net/http"
 	"testing"
@@ -103,6 +106,28 @@ mockModeList:
 
 }
 
+func TestIncludePathOnMockFallback(t *testing.T) {
+	config := `variables:
+  half: /half/deployed
+mockFallbackList:
+  - /pb33f/howdy/**
+  - ${half}/*`
+
+	var wcConfig shared.WiretapConfiguration
+	_ = yaml.Unmarshal([]byte(config), &wcConfig)
+
+	wcConfig.CompileVariables()
+	wcConfig.CompileMockFallbackList()
+
+	assert.True(t, IncludePathOnMockFallback("/pb33f/howdy/partner", &wcConfig))
+	assert.True(t, IncludePathOnMockFallback("/half/deployed/orders", &wcConfig))
+	assert.False(t, IncludePathOnMockFallback("/not-registered", &wcConfig))
+
+	// all paths fall back when enabled globally.
+	wcConfig.MockFallback = true
+	assert.True(t, IncludePathOnMockFallback("/not-registered", &wcConfig))
+}
+
 func TestFindPath(t *testing.T) {
 
 	config := `
@@ -678,3 +703,43 @@ func TestGetRewriteHeaderValues_MissingHeader(t *testing.T) {
 	}
 
 }
+
+func TestFindPathsForRequest_ClientIdentity(t *testing.T) {
+
+	config := `
+paths:
+  /pb33f/test/**:
+    target: localhost:9093/
+    clientIdentity: 'billing-*'
+  /pb33f/test/open/**:
+    target: localhost:9094/`
+
+	var wcConfig shared.WiretapConfiguration
+	_ = yaml.Unmarshal([]byte(config), &wcConfig)
+
+	wcConfig.CompilePaths()
+
+	withCertificate := func(cert *x509.Certificate) *http.Request {
+		req, _ := http.NewRequest(http.MethodGet, "https://localhost/pb33f/test/open/123", nil)
+		if cert != nil {
+			req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+		}
+		return req
+	}
+
+	res := FindPathsForRequest("/pb33f/test/open/123", withCertificate(nil), &wcConfig)
+	assert.Len(t, res, 1)
+	assert.Equal(t, "localhost:9094/", res[0].Target)
+
+	res = FindPathsForRequest("/pb33f/test/open/123",
+		withCertificate(&x509.Certificate{Subject: pkix.Name{CommonName: "billing-api"}}), &wcConfig)
+	assert.Len(t, res, 2)
+
+	res = FindPathsForRequest("/pb33f/test/open/123",
+		withCertificate(&x509.Certificate{Subject: pkix.Name{CommonName: "svc"}, DNSNames: []string{"billing-worker"}}), &wcConfig)
+	assert.Len(t, res, 2)
+
+	res = FindPathsForRequest("/pb33f/test/open/123",
+		withCertificate(&x509.Certificate{Subject: pkix.Name{CommonName: "orders-api"}}), &wcConfig)
+	assert.Len(t, res, 1)
+}
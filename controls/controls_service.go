@@ -15,21 +15,34 @@ const (
 	ControlServiceChan = "controls"
 	ChangeDelayRequest = "change-delay-request"
 	ResetStateRequest  = "reset-state-request"
+	WebhookRequest     = "trigger-webhook-request"
 )
 
+// WebhookTrigger sends a webhook declared by a specification to a subscriber.
+type WebhookTrigger interface {
+	TriggerWebhook(name, target string) error
+}
+
 type ControlService struct {
 	controlsStore    store.BusStore
 	transactionStore store.BusStore
 	harStore         store.BusStore
+	webhookTrigger   WebhookTrigger
 }
 
 type ChangeGlobalDelayRequest struct {
 	Delay int `json:"delay,omitempty"`
 }
 
+type TriggerWebhookRequest struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
 type ControlResponse struct {
-	Config *shared.WiretapConfiguration `json:"config,omitempty"`
-	Reset  bool                         `json:"reset,omitempty"`
+	Config  *shared.WiretapConfiguration `json:"config,omitempty"`
+	Reset   bool                         `json:"reset,omitempty"`
+	Webhook string                       `json:"webhook,omitempty"`
 }
 
 func NewControlsService(storeManager store.Manager) *ControlService {
@@ -43,12 +56,19 @@ func NewControlsService(storeManager store.Manager) *ControlService {
 	}
 }
 
+// SetWebhookTrigger sets what sends webhooks requested with the trigger-webhook-request command.
+func (cs *ControlService) SetWebhookTrigger(trigger WebhookTrigger) {
+	cs.webhookTrigger = trigger
+}
+
 func (cs *ControlService) HandleServiceRequest(request *model.Request, core service.FabricServiceCore) {
 	switch request.RequestCommand {
 	case ChangeDelayRequest:
 		cs.changeDelay(request, core)
 	case ResetStateRequest:
 		cs.resetState(request, core)
+	case WebhookRequest:
+		cs.triggerWebhook(request, core)
 	default:
 		core.HandleUnknownRequest(request)
 	}
@@ -86,6 +106,30 @@ func (cs *ControlService) resetState(request *model.Request, core service.Fabric
 	})
 }
 
+func (cs *ControlService) triggerWebhook(request *model.Request, core service.FabricServiceCore) {
+	wh, ok := request.Payload.(map[string]interface{})
+	if !ok {
+		core.SendErrorResponse(request, 400, "Invalid webhook request")
+		return
+	}
+
+	var r TriggerWebhookRequest
+	_ = mapstructure.Decode(wh, &r)
+	if r.Name == "" || r.URL == "" {
+		core.SendErrorResponse(request, 400, "A webhook name and url are required")
+		return
+	}
+	if cs.webhookTrigger == nil {
+		core.SendErrorResponse(request, 500, "Webhooks are not available")
+		return
+	}
+	if err := cs.webhookTrigger.TriggerWebhook(r.Name, r.URL); err != nil {
+		core.SendErrorResponse(request, 400, err.Error())
+		return
+	}
+	core.SendResponse(request, &ControlResponse{Webhook: r.Name})
+}
+
 func (cs *ControlService) resetRuntimeState() *shared.WiretapConfiguration {
 	if cs.transactionStore != nil {
 		cs.transactionStore.Reset()
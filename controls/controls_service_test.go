@@ -4,9 +4,12 @@
 package controls
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/pb33f/ranch/bus"
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/ranch/service"
 	"github.com/pb33f/ranch/store"
 	"github.com/pb33f/wiretap/shared"
 	"github.com/stretchr/testify/assert"
@@ -31,3 +34,63 @@ func TestResetRuntimeStateClearsTransactionAndHARState(t *testing.T) {
 	assert.Equal(t, 0, resetConfig.GlobalAPIDelay)
 	assert.Equal(t, 0, config.GlobalAPIDelay)
 }
+
+type fakeWebhookTrigger struct {
+	name, target string
+	err          error
+}
+
+func (f *fakeWebhookTrigger) TriggerWebhook(name, target string) error {
+	f.name, f.target = name, target
+	return f.err
+}
+
+type fakeServiceCore struct {
+	service.FabricServiceCore
+	response  any
+	errorCode int
+}
+
+func (f *fakeServiceCore) SendResponse(_ *model.Request, response any) {
+	f.response = response
+}
+
+func (f *fakeServiceCore) SendErrorResponse(_ *model.Request, code int, _ string) {
+	f.errorCode = code
+}
+
+func TestTriggerWebhook(t *testing.T) {
+	controlService := NewControlsService(store.NewManager(bus.NewEventBus()))
+	trigger := &fakeWebhookTrigger{}
+	controlService.SetWebhookTrigger(trigger)
+
+	core := &fakeServiceCore{}
+	controlService.HandleServiceRequest(&model.Request{
+		RequestCommand: WebhookRequest,
+		Payload:        map[string]interface{}{"name": "newPet", "url": "http://localhost:9999/pets"},
+	}, core)
+
+	assert.Equal(t, "newPet", trigger.name)
+	assert.Equal(t, "http://localhost:9999/pets", trigger.target)
+	assert.Equal(t, &ControlResponse{Webhook: "newPet"}, core.response)
+}
+
+func TestTriggerWebhook_Invalid(t *testing.T) {
+	controlService := NewControlsService(store.NewManager(bus.NewEventBus()))
+
+	core := &fakeServiceCore{}
+	controlService.HandleServiceRequest(&model.Request{
+		RequestCommand: WebhookRequest,
+		Payload:        map[string]interface{}{"name": "newPet"},
+	}, core)
+	assert.Equal(t, 400, core.errorCode)
+
+	core = &fakeServiceCore{}
+	controlService.SetWebhookTrigger(&fakeWebhookTrigger{err: errors.New("no webhook")})
+	controlService.HandleServiceRequest(&model.Request{
+		RequestCommand: WebhookRequest,
+		Payload:        map[string]interface{}{"name": "missing", "url": "http://localhost:9999"},
+	}, core)
+	assert.Equal(t, 400, core.errorCode)
+	assert.Nil(t, core.response)
+}
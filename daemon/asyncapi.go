@@ -0,0 +1,62 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pb33f/wiretap/asyncapi"
+	"github.com/pb33f/wiretap/shared"
+)
+
+// asyncAPIDocument returns the AsyncAPI document at a path, loading it the first time it is used. Documents that fail
+// to load are logged once, and nil is returned from then on.
+func (ws *WiretapService) asyncAPIDocument(path string) *asyncapi.Document {
+	if path == "" {
+		return nil
+	}
+	if document, ok := ws.asyncAPIDocuments.Load(path); ok {
+		return document.(*asyncapi.Document)
+	}
+
+	spec, err := os.ReadFile(path)
+	var document *asyncapi.Document
+	if err == nil {
+		document, err = asyncapi.Parse(spec)
+	}
+	if err != nil {
+		ws.config.Logger.Error(fmt.Sprintf("Unable to load AsyncAPI document '%s': %s", path, err))
+	}
+
+	loaded, _ := ws.asyncAPIDocuments.LoadOrStore(path, document)
+	return loaded.(*asyncapi.Document)
+}
+
+// websocketAsyncAPI returns the AsyncAPI document path for a websocket, the websocket's own document replaces the
+// global one.
+func websocketAsyncAPI(config *shared.WiretapConfiguration, websocketConfig *shared.WiretapWebsocketConfig) string {
+	if websocketConfig != nil && websocketConfig.AsyncAPI != "" {
+		return websocketConfig.AsyncAPI
+	}
+	return config.AsyncAPI
+}
+
+// websocketChannel returns the AsyncAPI channel a websocket carries, the channel named by its configuration, or the
+// channel with the address of the websocket path. A document with a single channel is used for every websocket.
+func websocketChannel(document *asyncapi.Document, websocketConfig *shared.WiretapWebsocketConfig, path string) *asyncapi.Channel {
+	if document == nil {
+		return nil
+	}
+	if websocketConfig != nil && websocketConfig.Channel != "" {
+		return document.Channel(websocketConfig.Channel)
+	}
+	if channel := document.Channel(path); channel != nil {
+		return channel
+	}
+	if len(document.Channels) == 1 {
+		return document.Channels[0]
+	}
+	return nil
+}
@@ -31,6 +31,8 @@ type HttpTransactionConfig struct {
 	BasePath          string
 	BodyBytes         []byte
 	SpecConflict      *transaction.SpecConflict
+	Transforms        *transaction.TransformRecord
+	ScriptErrors      []string
 }
 
 func BuildHttpTransaction(build HttpTransactionConfig) *transaction.HttpTransaction {
@@ -49,7 +51,7 @@ func BuildHttpTransaction(build HttpTransactionConfig) *transaction.HttpTransact
 			injectHeaders = mergeInjectHeaders(nil, cf.Headers.InjectHeaders)
 		}
 
-		matchedPaths := config.FindPaths(build.OriginalRequest.URL.Path, cf)
+		matchedPaths := config.FindPathsForRequest(build.OriginalRequest.URL.Path, build.OriginalRequest, cf)
 		if len(matchedPaths) > 0 {
 			var matchedPath *shared.WiretapPathConfig
 			matchedPath = config.FindPathWithRewriteId(matchedPaths, build.NewRequest)
@@ -159,19 +161,22 @@ func BuildHttpTransaction(build HttpTransactionConfig) *transaction.HttpTransact
 	return &transaction.HttpTransaction{
 		Id:           build.ID.String(),
 		SpecConflict: build.SpecConflict,
+		Transforms:   build.Transforms,
+		ScriptErrors: build.ScriptErrors,
 		Request: &transaction.HttpRequest{
-			URL:             newUrl.String(),
-			Method:          build.NewRequest.Method,
-			Path:            newUrl.Path,
-			Host:            newUrl.Host,
-			Query:           newUrl.RawQuery,
-			DroppedHeaders:  dropHeaders,
-			InjectedHeaders: injectHeaders,
-			OriginalPath:    originalPath,
-			Cookies:         cookies,
-			Headers:         headers,
-			Body:            string(requestBody),
-			Timestamp:       time.Now().UnixMilli(),
+			URL:               newUrl.String(),
+			Method:            build.NewRequest.Method,
+			Path:              newUrl.Path,
+			Host:              newUrl.Host,
+			Query:             newUrl.RawQuery,
+			DroppedHeaders:    dropHeaders,
+			InjectedHeaders:   injectHeaders,
+			OriginalPath:      originalPath,
+			ClientCertificate: shared.RequestClientIdentity(build.OriginalRequest),
+			Cookies:           cookies,
+			Headers:           headers,
+			Body:              string(requestBody),
+			Timestamp:         time.Now().UnixMilli(),
 		},
 	}
 }
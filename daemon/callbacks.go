@@ -0,0 +1,115 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pb33f/ranch/model"
+	daemonvalidator "github.com/pb33f/wiretap/daemon/validator"
+	"github.com/pb33f/wiretap/mock"
+	"github.com/pb33f/wiretap/shared"
+)
+
+// callbackTimeout is how long a subscriber has to respond to a callback or webhook.
+const callbackTimeout = 30 * time.Second
+
+// dispatchCallbacks sends the callbacks declared by a mocked operation, if mock callbacks are enabled. Callbacks are
+// only sent for successful (2xx) mocks, a request the operation refused never registered a subscriber.
+func (ws *WiretapService) dispatchCallbacks(docValidator *daemonvalidator.DocumentValidator, exchange *mock.CallbackExchange) {
+	if ws.config == nil || !ws.config.MockCallbacks || docValidator == nil || docValidator.MockEngine == nil {
+		return
+	}
+	if exchange.StatusCode < http.StatusOK || exchange.StatusCode >= http.StatusMultipleChoices {
+		return
+	}
+	callbacks, errs := docValidator.MockEngine.BuildCallbacks(exchange)
+	for _, err := range errs {
+		wiretapLogger(ws.config).Warn("[wiretap] unable to send mock callback", "error", err.Error())
+	}
+	for _, callback := range callbacks {
+		go ws.sendCallback(docValidator, callback, time.Duration(ws.config.MockCallbackDelay)*time.Millisecond)
+	}
+}
+
+// TriggerWebhook sends a webhook declared by a specification to target, the webhook is recorded as a
+// transaction, and the response from the subscriber is validated.
+func (ws *WiretapService) TriggerWebhook(name, target string) error {
+	for _, docValidator := range ws.validator.DocumentValidators() {
+		if docValidator.MockEngine == nil {
+			continue
+		}
+		callbacks, err := docValidator.MockEngine.BuildWebhook(name, target)
+		if err != nil {
+			return fmt.Errorf("unable to build webhook '%s': %w", name, err)
+		}
+		if len(callbacks) == 0 {
+			continue
+		}
+		for _, callback := range callbacks {
+			go ws.sendCallback(&docValidator, callback, 0)
+		}
+		return nil
+	}
+	return fmt.Errorf("no specification declares a webhook named '%s'", name)
+}
+
+func (ws *WiretapService) sendCallback(docValidator *daemonvalidator.DocumentValidator, callback *mock.Callback, delay time.Duration) {
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+
+	id, _ := uuid.NewUUID()
+	request := &model.Request{Id: &id, HttpRequest: callback.Request}
+
+	var body []byte
+	if callback.Request.Body != nil {
+		body, _ = io.ReadAll(callback.Request.Body)
+		callback.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	// record the callback, as it was sent.
+	txn := BuildHttpTransaction(HttpTransactionConfig{
+		OriginalRequest:   callback.Request,
+		NewRequest:        callback.Request,
+		DisplayURL:        callback.Request.URL,
+		ID:                &id,
+		TransactionConfig: ws.config,
+		DropHeaders:       []string{},
+		BodyBytes:         body,
+	})
+	ws.storeRequestTransaction(id.String(), txn)
+	ws.broadcastRequest(request, txn)
+
+	wiretapLogger(ws.config).Info("[wiretap] sending mock callback", "name", callback.Name,
+		"method", callback.Request.Method, "url", callback.Request.URL.String())
+
+	client := &http.Client{Transport: ws.transport, Timeout: callbackTimeout}
+	response, err := client.Do(callback.Request)
+	if err != nil {
+		ws.broadcastResponseError(request, nil, err)
+		return
+	}
+	defer response.Body.Close()
+
+	responseBody, _ := io.ReadAll(response.Body)
+	response.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	validationErrors := shared.ConvertValidationErrors(docValidator.DocumentName,
+		docValidator.MockEngine.ValidateCallbackResponse(callback, response))
+
+	responseTxn := BuildResponseFromBytes(request, response, responseBody)
+	if len(validationErrors) > 0 {
+		responseTxn.ResponseValidation = validationErrors
+		sendToStreamChan(ws, validationErrors)
+		ws.broadcastResponseValidationErrors(request, responseTxn, validationErrors)
+	} else {
+		ws.broadcastResponse(request, responseTxn)
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/ranch/bus"
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/ranch/store"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var callbackTestSpec = []byte(`openapi: 3.1.0
+info:
+  title: callbacks
+  version: "1.0"
+paths:
+  /subscriptions:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                callbackUrl:
+                  type: string
+      responses:
+        "201":
+          description: subscribed
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  id:
+                    type: string
+                    example: sub-1
+        "400":
+          description: invalid subscription
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  error:
+                    type: string
+                    example: invalid callback url
+      callbacks:
+        onEvent:
+          "{$request.body#/callbackUrl}":
+            post:
+              requestBody:
+                content:
+                  application/json:
+                    schema:
+                      type: object
+                      required: [type]
+                      properties:
+                        type:
+                          type: string
+                          enum: [created]
+              responses:
+                "200":
+                  content:
+                    application/json:
+                      schema:
+                        type: object
+                        required: [received]
+                        properties:
+                          received:
+                            type: boolean
+webhooks:
+  newSubscription:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                id:
+                  type: string
+      responses:
+        "204":
+          description: ok
+`)
+
+func newCallbackWiretapService(t *testing.T, config *shared.WiretapConfiguration) *WiretapService {
+	t.Helper()
+	doc, err := libopenapi.NewDocument(callbackTestSpec)
+	require.NoError(t, err)
+
+	config.MockMode = true
+	config.ReportFile = t.TempDir() + "/violations.ndjson"
+	config.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	config.PathConfigurations = orderedmap.New[string, *shared.WiretapPathConfig]()
+	config.CompilePaths()
+
+	eventBus := bus.NewEventBus()
+	storeManager := store.NewManager(eventBus)
+	ws, err := NewWiretapService([]shared.ApiDocument{{
+		DocumentName: "callbacks.yaml",
+		Document:     doc,
+	}}, config, storeManager)
+	require.NoError(t, err)
+	ws.setBroadcastChannel(eventBus.GetChannelManager().CreateChannel(WiretapBroadcastChan))
+	ws.controlsStore.Put(shared.ConfigKey, config, nil)
+	return ws
+}
+
+// subscriber records the requests it receives and responds with body.
+func subscriber(t *testing.T, code int, body string) (*httptest.Server, chan *http.Request) {
+	received := make(chan *http.Request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		_, _ = w.Write([]byte(body))
+		received <- r
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+func callbackTransaction(t *testing.T, ws *WiretapService, url string) *transaction.HttpTransaction {
+	t.Helper()
+	var found *transaction.HttpTransaction
+	require.Eventually(t, func() bool {
+		for _, value := range ws.transactionStore.AllValues() {
+			if txn, ok := value.(*transaction.HttpTransaction); ok && txn.Request != nil &&
+				txn.Request.URL == url && txn.Response != nil {
+				found = txn
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 10*time.Millisecond)
+	return found
+}
+
+func sendSubscription(t *testing.T, ws *WiretapService, callbackURL string, prefer ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	payload := []byte(`{"callbackUrl":"` + callbackURL + `"}`)
+	req := httptest.NewRequest(http.MethodPost, "http://wiretap.local/subscriptions", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	for _, preference := range prefer {
+		req.Header.Add("Prefer", preference)
+	}
+	rec := httptest.NewRecorder()
+	id := uuid.New()
+	ws.handleHttpRequest(&model.Request{Id: &id, HttpRequest: req, HttpResponseWriter: rec})
+	return rec
+}
+
+func TestHandleHttpRequest_MockModeSendsCallbacks(t *testing.T) {
+	ws := newCallbackWiretapService(t, &shared.WiretapConfiguration{MockCallbacks: true, MockCallbackDelay: 10})
+	server, received := subscriber(t, http.StatusOK, `{"received":"yes"}`)
+
+	rec := sendSubscription(t, ws, server.URL+"/events")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	select {
+	case r := <-received:
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/events", r.URL.Path)
+	case <-time.After(5 * time.Second):
+		t.Fatal("callback was not sent")
+	}
+
+	txn := callbackTransaction(t, ws, server.URL+"/events")
+	assert.Contains(t, txn.Request.Body, `"type"`)
+	assert.Equal(t, http.StatusOK, txn.Response.StatusCode)
+	assert.NotEmpty(t, txn.ResponseValidation)
+}
+
+func TestHandleHttpRequest_MockModeCallbacksDisabled(t *testing.T) {
+	ws := newCallbackWiretapService(t, &shared.WiretapConfiguration{})
+	server, received := subscriber(t, http.StatusOK, `{"received":true}`)
+
+	rec := sendSubscription(t, ws, server.URL+"/events")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	select {
+	case <-received:
+		t.Fatal("callback sent, but callbacks are not enabled")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestHandleHttpRequest_MockModeSkipsCallbacksForFailedMocks(t *testing.T) {
+	ws := newCallbackWiretapService(t, &shared.WiretapConfiguration{MockCallbacks: true})
+	server, received := subscriber(t, http.StatusOK, `{"received":true}`)
+
+	rec := sendSubscription(t, ws, server.URL+"/events", "code=400")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	select {
+	case <-received:
+		t.Fatal("callback sent, but the subscription was refused")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWiretapService_TriggerWebhook(t *testing.T) {
+	ws := newCallbackWiretapService(t, &shared.WiretapConfiguration{})
+	server, received := subscriber(t, http.StatusNoContent, "")
+
+	require.NoError(t, ws.TriggerWebhook("newSubscription", server.URL+"/hooks"))
+	select {
+	case r := <-received:
+		assert.Equal(t, "/hooks", r.URL.Path)
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not sent")
+	}
+
+	txn := callbackTransaction(t, ws, server.URL+"/hooks")
+	assert.Equal(t, http.StatusNoContent, txn.Response.StatusCode)
+	assert.Empty(t, txn.ResponseValidation)
+
+	assert.Error(t, ws.TriggerWebhook("missing", server.URL))
+	assert.Error(t, ws.TriggerWebhook("newSubscription", "/relative"))
+}
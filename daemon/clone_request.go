@@ -61,6 +61,9 @@ func CloneExistingRequest(request CloneRequest) *http.Request {
 	newReq.GetBody = getBody
 	newReq.ContentLength = int64(len(b))
 
+	// keep the client's TLS state, so clones can be matched by client identity; clients ignore it.
+	newReq.TLS = request.Request.TLS
+
 	newReq.Header = prepareHeaders(
 		request.Request.Header,
 		request.DropHeaders,
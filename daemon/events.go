@@ -0,0 +1,135 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/sse"
+	"github.com/pb33f/wiretap/transaction"
+)
+
+// maxResponseEvents caps the events kept on a response, events beyond it are still validated.
+const maxResponseEvents = 1000
+
+// eventRecorder records the server-sent events of a streamed response on its transaction, validating each one as it
+// arrives. Events (and the body rebuilt from them) are kept up to maxResponseEvents and the maximum validated body size,
+// events beyond them are still validated. Each event is stored once, and broadcast on its own to be appended to the
+// response.
+type eventRecorder struct {
+	ws                *WiretapService
+	request           *model.Request
+	validationRequest *http.Request
+	validate          func(*sse.Event) []*shared.WiretapValidationError
+	resolved          bool
+	response          *transaction.HttpTransaction
+	count             int
+
+	// what is stored, already redacted. Events and violations are only ever appended, so the stored response shares
+	// them without copying.
+	events  []*transaction.ResponseEvent
+	errors  []*shared.WiretapValidationError
+	body    strings.Builder
+	dropped int
+}
+
+func (ws *WiretapService) newEventRecorder(request *model.Request, validationRequest *http.Request) *eventRecorder {
+	return &eventRecorder{ws: ws, request: request, validationRequest: validationRequest}
+}
+
+// record records an event on the transaction, returning true if the contract declares a schema to validate it with.
+func (r *eventRecorder) record(response *http.Response, event *sse.Event) bool {
+	if !r.resolved {
+		r.resolved = true
+		if r.ws.validator != nil {
+			r.validate = r.ws.validator.EventValidator(r.validationRequest, response.StatusCode)
+		}
+		r.response = BuildResponseFromBytes(r.request, response, nil)
+	}
+	r.count++
+
+	var errs []*shared.WiretapValidationError
+	if r.validate != nil {
+		errs = r.validate(event)
+		for _, err := range errs {
+			err.Message = fmt.Sprintf("Event %d: %s", r.count, err.Message)
+		}
+	}
+
+	recorded := &transaction.ResponseEvent{Event: *event, Timestamp: time.Now().UnixMilli(), Validation: errs}
+	formatted := string(event.Format())
+	kept := len(r.events) < maxResponseEvents &&
+		int64(r.body.Len()+len(formatted)) <= r.ws.config.GetMaxValidatedBodySize()
+
+	// only the latest event is broadcast, the redacting broadcaster redacts it.
+	live := *r.response
+	liveResponse := *r.response.Response
+	liveResponse.AppendEvents = true
+	if kept {
+		liveResponse.Body = formatted
+		liveResponse.Events = []*transaction.ResponseEvent{recorded}
+	} else {
+		r.dropped++
+		liveResponse.DroppedEvents = r.dropped
+	}
+	live.Response = &liveResponse
+	live.ResponseValidation = errs
+
+	r.store(r.ws.redactTransaction(&live), kept)
+	if len(errs) > 0 {
+		sendToStreamChan(r.ws, errs)
+		r.ws.activeBroadcaster().ResponseValidationErrors(r.request, &live, errs)
+	} else {
+		r.ws.activeBroadcaster().Response(r.request, &live)
+	}
+	return r.validate != nil
+}
+
+// store appends the (redacted) latest event and its violations to the stored response.
+func (r *eventRecorder) store(update *transaction.HttpTransaction, kept bool) {
+	if kept {
+		r.events = append(r.events, update.Response.Events...)
+		r.body.WriteString(update.Response.Body)
+	}
+	if len(r.errors) < maxResponseEvents {
+		r.errors = append(r.errors, update.ResponseValidation...)
+	}
+
+	id := r.request.Id.String()
+	merged := &transaction.HttpTransaction{Id: id}
+	if existing, ok := r.ws.transactionStore.Get(id); ok {
+		if txn, ok := existing.(*transaction.HttpTransaction); ok && txn != nil {
+			*merged = *txn
+		}
+	}
+	stored := *update.Response
+	stored.AppendEvents = false
+	stored.Events = r.events[:len(r.events):len(r.events)]
+	stored.Body = r.body.String()
+	stored.DroppedEvents = r.dropped
+	merged.Response = &stored
+	if len(r.errors) > 0 {
+		merged.ResponseValidation = r.errors[:len(r.errors):len(r.errors)]
+	}
+	r.ws.transactionStore.Put(id, merged, nil)
+}
+
+// recordBodyEvents parses the events of a server-sent event stream body (like a mocked stream) onto the response.
+func recordBodyEvents(txn *transaction.HttpTransaction) *transaction.HttpTransaction {
+	if txn == nil || txn.Response == nil {
+		return txn
+	}
+	if contentType, _ := txn.Response.Headers["Content-Type"].(string); !sse.IsEventStream(contentType) {
+		return txn
+	}
+	for _, event := range sse.NewParser().Feed([]byte(txn.Response.Body)) {
+		txn.Response.Events = append(txn.Response.Events, &transaction.ResponseEvent{Event: *event})
+	}
+	return txn
+}
@@ -0,0 +1,143 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/ranch/bus"
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/ranch/store"
+	"github.com/pb33f/wiretap/daemon/proxy"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/sse"
+	"github.com/pb33f/wiretap/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleHttpRequestValidatesServerSentEvents(t *testing.T) {
+	doc, err := libopenapi.NewDocument([]byte(`openapi: 3.2.0
+info:
+  title: events
+  version: "1.0"
+paths:
+  /prices:
+    get:
+      responses:
+        "200":
+          description: price updates
+          content:
+            text/event-stream:
+              schema:
+                type: object
+                required: [price]
+                properties:
+                  price:
+                    type: number
+`))
+	require.NoError(t, err)
+	docModel, err := doc.BuildV3Model()
+	require.NoError(t, err)
+
+	config := &shared.WiretapConfiguration{
+		RedirectProtocol:   "http",
+		RedirectHost:       "upstream.local",
+		ReportFile:         t.TempDir() + "/violations.jsonl",
+		Logger:             slog.New(slog.NewTextHandler(io.Discard, nil)),
+		PathConfigurations: orderedmap.New[string, *shared.WiretapPathConfig](),
+	}
+	config.CompilePaths()
+
+	eventBus := bus.NewEventBus()
+	storeManager := store.NewManager(eventBus)
+	ws, err := NewWiretapService([]shared.ApiDocument{{
+		DocumentName:  "events.yaml",
+		Document:      doc,
+		DocumentModel: docModel,
+	}}, config, storeManager)
+	require.NoError(t, err)
+	ws.setBroadcastChannel(eventBus.GetChannelManager().CreateChannel(WiretapBroadcastChan))
+	ws.controlsStore.Put(shared.ConfigKey, config, nil)
+	ws.proxy = proxy.NewHandler(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode:    http.StatusOK,
+			Header:        http.Header{"Content-Type": []string{"text/event-stream"}},
+			ContentLength: -1,
+			Body:          io.NopCloser(strings.NewReader("data: {\"price\":1}\n\ndata: {\"price\":\"one\"}\n\n")),
+		}, nil
+	}))
+
+	id := uuid.New()
+	rec := httptest.NewRecorder()
+	ws.handleHttpRequest(&model.Request{
+		Id:                 &id,
+		HttpRequest:        httptest.NewRequest(http.MethodGet, "http://wiretap.local/prices", nil),
+		HttpResponseWriter: rec,
+	})
+	assert.Equal(t, "data: {\"price\":1}\n\ndata: {\"price\":\"one\"}\n\n", rec.Body.String())
+
+	stored, ok := ws.transactionStore.Get(id.String())
+	require.True(t, ok)
+	txn := stored.(*transaction.HttpTransaction)
+	assert.Empty(t, txn.ResponseValidationSkipped)
+	require.NotNil(t, txn.Response)
+	require.Len(t, txn.Response.Events, 2)
+	assert.Empty(t, txn.Response.Events[0].Validation)
+	assert.NotEmpty(t, txn.Response.Events[1].Validation)
+	require.NotEmpty(t, txn.ResponseValidation)
+	assert.True(t, strings.HasPrefix(txn.ResponseValidation[0].Message, "Event 2: "))
+}
+
+func TestRecordBodyEvents(t *testing.T) {
+	txn := recordBodyEvents(&transaction.HttpTransaction{Response: &transaction.HttpResponse{
+		Headers: map[string]any{"Content-Type": "text/event-stream"},
+		Body:    "id: 1\ndata: one\n\nid: 2\ndata: two\n\n",
+	}})
+	require.Len(t, txn.Response.Events, 2)
+	assert.Equal(t, "2", txn.Response.Events[1].ID)
+	assert.Equal(t, "two", txn.Response.Events[1].Data)
+
+	txn = recordBodyEvents(&transaction.HttpTransaction{Response: &transaction.HttpResponse{
+		Headers: map[string]any{"Content-Type": "application/json"},
+		Body:    "data: one\n\n",
+	}})
+	assert.Empty(t, txn.Response.Events)
+}
+
+func TestEventRecorderCapsTheEventsOfAResponse(t *testing.T) {
+	config := &shared.WiretapConfiguration{
+		ReportFile: t.TempDir() + "/violations.jsonl",
+		Logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	eventBus := bus.NewEventBus()
+	ws, err := NewWiretapService(nil, config, store.NewManager(eventBus))
+	require.NoError(t, err)
+	ws.setBroadcastChannel(eventBus.GetChannelManager().CreateChannel(WiretapBroadcastChan))
+
+	id := uuid.New()
+	recorder := ws.newEventRecorder(&model.Request{Id: &id}, nil)
+	response := &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Content-Type": {"text/event-stream"}}}
+	for range maxResponseEvents + 2 {
+		recorder.record(response, &sse.Event{Data: "tick"})
+	}
+
+	// events beyond the cap are counted, but not kept.
+	stored, ok := ws.transactionStore.Get(id.String())
+	require.True(t, ok)
+	txn := stored.(*transaction.HttpTransaction)
+	assert.Len(t, txn.Response.Events, maxResponseEvents)
+	assert.Equal(t, strings.Repeat("data: tick\n\n", maxResponseEvents), txn.Response.Body)
+	assert.Equal(t, 2, txn.Response.DroppedEvents)
+	assert.False(t, txn.Response.AppendEvents)
+	assert.Equal(t, http.StatusOK, txn.Response.StatusCode)
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"crypto/tls"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/pb33f/wiretap/certs"
+	"github.com/pb33f/wiretap/shared"
+)
+
+// ForwardProxyHandler lets clients use wiretap as their HTTP_PROXY / HTTPS_PROXY. CONNECT tunnels are intercepted:
+// wiretap terminates TLS with a certificate for the tunnelled host, issued by the local authority, and hands each
+// decrypted request to the gateway handler. Plain requests in absolute form go straight to the gateway handler, as
+// does everything else, so the gateway keeps working as a reverse proxy.
+type ForwardProxyHandler struct {
+	authority *certs.Authority
+	gateway   http.Handler
+	logger    *slog.Logger
+}
+
+func NewForwardProxyHandler(authority *certs.Authority, gateway http.Handler, logger *slog.Logger) *ForwardProxyHandler {
+	return &ForwardProxyHandler{authority: authority, gateway: gateway, logger: logger}
+}
+
+func (fp *ForwardProxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodConnect {
+		fp.gateway.ServeHTTP(w, r)
+		return
+	}
+
+	target := r.Host
+	if _, _, err := net.SplitHostPort(target); err != nil {
+		target = net.JoinHostPort(target, "443")
+	}
+	host, _, _ := net.SplitHostPort(target)
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "wiretap is unable to intercept this connection", http.StatusInternalServerError)
+		return
+	}
+	conn, buffered, err := hijacker.Hijack()
+	if err != nil {
+		fp.logger.Error("[wiretap] unable to hijack CONNECT request", "host", target, "error", err.Error())
+		return
+	}
+	if _, err = buffered.WriteString("HTTP/1.1 200 Connection Established\r\n\r\n"); err == nil {
+		err = buffered.Flush()
+	}
+	if err != nil {
+		_ = conn.Close()
+		return
+	}
+	fp.logger.Info("[wiretap] intercepting CONNECT tunnel", "host", target)
+
+	tlsConn := tls.Server(conn, fp.authority.TLSConfig(host))
+	tunnel := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.URL.Scheme = "https"
+			r.URL.Host = target
+			fp.gateway.ServeHTTP(w, r)
+		}),
+		ErrorLog: slog.NewLogLogger(fp.logger.Handler(), slog.LevelDebug),
+	}
+	_ = tunnel.Serve(newTunnelListener(tlsConn))
+}
+
+// tunnelListener hands a single intercepted connection to an http.Server, and then blocks until that connection
+// closes, so the server keeps serving the tunnel for as long as the client uses it.
+type tunnelListener struct {
+	conn   net.Conn
+	once   sync.Once
+	closed chan struct{}
+}
+
+func newTunnelListener(conn net.Conn) *tunnelListener {
+	return &tunnelListener{conn: conn, closed: make(chan struct{})}
+}
+
+func (l *tunnelListener) Accept() (net.Conn, error) {
+	var conn net.Conn
+	l.once.Do(func() {
+		conn = &tunnelConn{Conn: l.conn, listener: l}
+	})
+	if conn != nil {
+		return conn, nil
+	}
+	<-l.closed
+	return nil, net.ErrClosed
+}
+
+func (l *tunnelListener) Close() error {
+	return nil
+}
+
+func (l *tunnelListener) Addr() net.Addr {
+	return l.conn.LocalAddr()
+}
+
+type tunnelConn struct {
+	net.Conn
+	listener  *tunnelListener
+	closeOnce sync.Once
+}
+
+func (c *tunnelConn) Close() error {
+	err := c.Conn.Close()
+	c.closeOnce.Do(func() { close(c.listener.closed) })
+	return err
+}
+
+// forwardProxyTarget returns the upstream of a request sent to wiretap as a forward proxy, the host it was addressed
+// to. Returns nil for requests to the gateway itself.
+func forwardProxyTarget(config *shared.WiretapConfiguration, request *http.Request) *upstreamTarget {
+	if !config.ForwardProxy || request.URL.Host == "" {
+		return nil
+	}
+	return newUpstreamTarget(request.URL, "")
+}
@@ -0,0 +1,117 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/ranch/bus"
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/ranch/store"
+	"github.com/pb33f/wiretap/certs"
+	"github.com/pb33f/wiretap/daemon/proxy"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForwardProxyHandlerInterceptsTunnelsAndPlainRequests(t *testing.T) {
+	dir := t.TempDir()
+	authority, _, err := certs.LoadOrCreateAuthority(filepath.Join(dir, "ca.pem"), filepath.Join(dir, "ca-key.pem"))
+	require.NoError(t, err)
+
+	config := &shared.WiretapConfiguration{
+		RedirectProtocol:   "http",
+		RedirectHost:       "gateway.local",
+		RedirectBasePath:   "/base",
+		ForwardProxy:       true,
+		ReportFile:         filepath.Join(dir, "violations.jsonl"),
+		Logger:             slog.New(slog.NewTextHandler(io.Discard, nil)),
+		PathConfigurations: orderedmap.New[string, *shared.WiretapPathConfig](),
+	}
+	config.CompilePaths()
+
+	eventBus := bus.NewEventBus()
+	ws, err := NewWiretapService(nil, config, store.NewManager(eventBus))
+	require.NoError(t, err)
+	ws.setBroadcastChannel(eventBus.GetChannelManager().CreateChannel(WiretapBroadcastChan))
+	ws.controlsStore.Put(shared.ConfigKey, config, nil)
+
+	var mu sync.Mutex
+	var upstreams []string
+	ws.proxy = proxy.NewHandler(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		upstreams = append(upstreams, req.URL.String())
+		mu.Unlock()
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"text/plain"}},
+			Body:       io.NopCloser(strings.NewReader("hello from " + req.URL.Host)),
+		}, nil
+	}))
+
+	gateway := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := uuid.New()
+		ws.handleHttpRequest(&model.Request{Id: &id, HttpRequest: r, HttpResponseWriter: w})
+	})
+	server := httptest.NewServer(NewForwardProxyHandler(authority, gateway, config.Logger))
+	defer server.Close()
+
+	proxyURL, _ := url.Parse(server.URL)
+	roots := x509.NewCertPool()
+	roots.AddCert(authority.Certificate)
+	client := &http.Client{Transport: &http.Transport{
+		Proxy:           http.ProxyURL(proxyURL),
+		TLSClientConfig: &tls.Config{RootCAs: roots},
+	}}
+
+	for _, target := range []string{"https://api.example.com/pets?limit=1", "http://plain.example.com:8080/pets"} {
+		response, err := client.Get(target)
+		require.NoError(t, err, target)
+		body, _ := io.ReadAll(response.Body)
+		_ = response.Body.Close()
+		assert.Equal(t, http.StatusOK, response.StatusCode, target)
+		assert.Contains(t, string(body), "hello from", target)
+	}
+
+	// requests without a target host still go to the redirect URL.
+	response, err := http.Get(server.URL + "/pets")
+	require.NoError(t, err)
+	_ = response.Body.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{
+		"https://api.example.com/pets?limit=1",
+		"http://plain.example.com:8080/pets",
+		"http://gateway.local/base/pets",
+	}, upstreams)
+}
+
+func TestForwardProxyTargetIgnoresGatewayRequests(t *testing.T) {
+	config := &shared.WiretapConfiguration{ForwardProxy: true}
+
+	request := httptest.NewRequest(http.MethodGet, "/pets", nil)
+	assert.Nil(t, forwardProxyTarget(config, request))
+
+	request = httptest.NewRequest(http.MethodGet, "https://api.example.com:443/pets", nil)
+	target := forwardProxyTarget(config, request)
+	require.NotNil(t, target)
+	assert.Equal(t, &upstreamTarget{Protocol: "https", Host: "api.example.com"}, target)
+
+	config.ForwardProxy = false
+	assert.Nil(t, forwardProxyTarget(config, request))
+}
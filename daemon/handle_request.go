@@ -4,8 +4,10 @@
 package daemon
 
 import (
+	"bytes"
 	_ "embed"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -14,6 +16,7 @@ import (
 	"github.com/pb33f/ranch/model"
 	"github.com/pb33f/wiretap/daemon/mockproxy"
 	"github.com/pb33f/wiretap/daemon/proxy"
+	"github.com/pb33f/wiretap/mock"
 	"github.com/pb33f/wiretap/shared"
 )
 
@@ -23,8 +26,10 @@ var staticTemplate string
 var parsedStaticTemplate = template.Must(template.New("index").Parse(staticTemplate))
 
 type staticTemplateModel struct {
-	OriginalContent string
-	WebSocketPort   string
+	OriginalContent   string
+	WebSocketProtocol string
+	WebSocketHost     string
+	WebSocketPort     string
 }
 
 func (ws *WiretapService) handleHttpRequest(request *model.Request) {
@@ -63,8 +68,10 @@ func (ws *WiretapService) handleHttpRequest(request *model.Request) {
 
 				// prep a model
 				m := staticTemplateModel{
-					OriginalContent: string(indexBytes),
-					WebSocketPort:   ws.config.WebSocketPort,
+					OriginalContent:   string(indexBytes),
+					WebSocketProtocol: ws.config.GetWebSocketProtocol(),
+					WebSocketHost:     ws.config.WebSocketHost,
+					WebSocketPort:     ws.config.WebSocketPort,
 				}
 
 				// execute the new template
@@ -91,6 +98,12 @@ func (ws *WiretapService) handleHttpRequest(request *model.Request) {
 		return
 	}
 
+	// requests answered by a script are neither mocked nor sent upstream.
+	if prep.ScriptResponse != nil {
+		ws.respondFromScript(request, prep)
+		return
+	}
+
 	ws.config.Logger.Info("[wiretap] handling API request", "url", request.HttpRequest.URL.String())
 
 	// short-circuit if we're using mock mode, there is no API call to make.
@@ -106,29 +119,47 @@ func (ws *WiretapService) handleHttpRequest(request *model.Request) {
 			ValidateRequest: func() []*shared.WiretapValidationError {
 				return ws.ValidateRequest(request, prep.NewReq, prep.TxnConfig)
 			},
-			GenerateMock: func(httpReq *http.Request) ([]byte, int, error) {
+			GenerateMock: func(httpReq *http.Request) ([]byte, int, http.Header, error) {
 				docValidator, mockReq := ws.getValidatorAndRequestForHTTPRequest(httpReq)
 				if docValidator != nil {
-					return docValidator.MockEngine.GenerateResponse(mockReq)
+					if !ws.config.MockCallbacks {
+						return docValidator.MockEngine.GenerateResponseWithHeaders(mockReq)
+					}
+					var requestBody []byte
+					if mockReq.Body != nil {
+						requestBody, _ = io.ReadAll(mockReq.Body)
+						mockReq.Body = io.NopCloser(bytes.NewReader(requestBody))
+					}
+					mockBody, status, headers, err := docValidator.MockEngine.GenerateResponseWithHeaders(mockReq)
+					if err == nil {
+						ws.dispatchCallbacks(docValidator, &mock.CallbackExchange{
+							Request:         mockReq,
+							RequestBody:     requestBody,
+							StatusCode:      status,
+							ResponseHeaders: headers,
+							ResponseBody:    mockBody,
+						})
+					}
+					return mockBody, status, headers, err
 				}
-				return nil, http.StatusInternalServerError,
+				return nil, http.StatusInternalServerError, nil,
 					fmt.Errorf("mock engine has not been initialized; configure an OpenAPI specification to use this option")
 			},
 			BroadcastResponse: func(response *http.Response) {
-				ws.broadcastResponse(request, BuildResponse(request, response))
+				ws.broadcastResponse(request, recordBodyEvents(BuildResponse(request, response)))
 			},
 		})
 		return
 	}
 
 	if ws.proxy == nil {
-		ws.proxy = proxy.NewHandler(ws.transport)
+		ws.proxy = proxy.NewHandler(ws.upstream)
 	}
 	ws.proxy.Handle(request, &proxy.PreparedRequest{
 		Config:      prep.Config,
 		NewReq:      prep.NewReq,
 		APIRequest:  prep.APIRequest,
-		BodyBytes:   prep.BodyBytes,
+		BodyBytes:   prep.UpstreamBody,
 		ControlPath: prep.ControlPath,
 		IsHardError: prep.IsHardError,
 		Validator: proxyValidator{
@@ -142,6 +173,21 @@ func (ws *WiretapService) handleHttpRequest(request *model.Request) {
 		BroadcastResponseError: func(response *http.Response, err error) {
 			ws.broadcastResponseError(request, CloneExistingResponse(response), err)
 		},
+		SkipResponseValidation: func(response *http.Response, body []byte, reason string) {
+			ws.broadcastSkippedResponseValidation(request, response, body, reason)
+		},
+		RecordEvent:           ws.newEventRecorder(request, prep.NewReq).record,
+		MirrorResponse:        ws.mirrorResponder(request, prep),
+		SkipMirror:            ws.mirrorSkipper(request, prep),
+		Resilience:            prep.Resilience,
+		MockFallback:          ws.mockFallback(prep),
+		RecordResilience:      ws.resilienceRecorder(request),
+		FallbackToMock:        prep.FallbackToMock,
+		RecordMockFallback:    ws.mockFallbackRecorder(request),
+		TransformResponse:     ws.responseScripter(request, prep, ws.responseTransformer(request, prep)),
+		SkipResponseTransform: ws.responseScriptSkipper(request, prep, ws.responseTransformSkipper(request, prep)),
+		BeforeClientWrite:     ws.clientWriteScripter(request, prep),
+		RespondUnauthorized:   ws.unauthorizedResponder(request, prep),
 	})
 }
 
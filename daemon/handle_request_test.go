@@ -0,0 +1,54 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pb33f/ranch/bus"
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/ranch/store"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleHttpRequestInjectsSocketIncludeIntoStaticIndex(t *testing.T) {
+	staticDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(staticDir, "index.html"), []byte("<h1>hello</h1>"), 0o644))
+
+	for certificate, brokerURL := range map[string]string{
+		"":         "ws://wiretap.local:9092/ranch",
+		"cert.pem": "wss://wiretap.local:9092/ranch",
+	} {
+		config := &shared.WiretapConfiguration{
+			StaticDir:      staticDir,
+			WebSocketHost:  "wiretap.local",
+			WebSocketPort:  "9092",
+			Certificate:    certificate,
+			CertificateKey: certificate,
+			Logger:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+		}
+		ws, err := NewWiretapService(nil, config, store.NewManager(bus.NewEventBus()))
+		require.NoError(t, err)
+
+		id := uuid.New()
+		rec := httptest.NewRecorder()
+		ws.handleHttpRequest(&model.Request{
+			Id:                 &id,
+			HttpRequest:        httptest.NewRequest(http.MethodGet, "/", nil),
+			HttpResponseWriter: rec,
+		})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "<h1>hello</h1>")
+		assert.Contains(t, rec.Body.String(), "brokerURL: '"+brokerURL+"'")
+	}
+}
@@ -0,0 +1,380 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gobwas/glob"
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/wiretap/daemon/proxy"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/transaction"
+)
+
+const (
+	mirrorTimeout = 30 * time.Second
+	// mirrorMaxDifferences caps the differences recorded for one request.
+	mirrorMaxDifferences = 100
+	// mirrorMaxValue caps the length of the values recorded for a difference.
+	mirrorMaxValue = 256
+)
+
+// mirrorVolatileHeaders change between any two responses, and are never compared.
+var mirrorVolatileHeaders = []string{"Date", "Content-Length", "Connection", "Keep-Alive", "Transfer-Encoding"}
+
+// effectiveMirror returns the mirror of requests to a path, the mirror of the path config or else the global one.
+func effectiveMirror(config *shared.WiretapConfiguration, pathConfig *shared.WiretapPathConfig) *shared.WiretapMirrorConfig {
+	if pathConfig != nil && pathConfig.Mirror != nil {
+		return pathConfig.Mirror
+	}
+	return config.Mirror
+}
+
+// mirrorResponder returns the proxy hook that mirrors a request once its primary response has been sent, or nil when
+// the request is not mirrored.
+func (ws *WiretapService) mirrorResponder(request *model.Request, prep *PreparedRequest) proxy.ResponseMirror {
+	if prep.Mirror == nil || prep.Mirror.Target == "" {
+		return nil
+	}
+	return func(primary *http.Response, primaryBody []byte) {
+		result := ws.mirrorRequest(prep, primary, primaryBody)
+		ws.mirrorReport.record(ws.mirrorOperation(prep.NewReq), result)
+		ws.broadcastResponse(request, &transaction.HttpTransaction{Id: request.Id.String(), Mirror: result})
+	}
+}
+
+// mirrorSkipper returns the proxy hook that records a request that was not mirrored, as its primary response was
+// streamed to the client without a whole body to compare, or nil when the request is not mirrored.
+func (ws *WiretapService) mirrorSkipper(request *model.Request, prep *PreparedRequest) proxy.MirrorSkipRecorder {
+	if prep.Mirror == nil || prep.Mirror.Target == "" {
+		return nil
+	}
+	return func(reason string) {
+		result := &transaction.MirrorResult{URL: prep.Mirror.Target, Skipped: reason}
+		ws.mirrorReport.record(ws.mirrorOperation(prep.NewReq), result)
+		ws.broadcastResponse(request, &transaction.HttpTransaction{Id: request.Id.String(), Mirror: result})
+	}
+}
+
+// mirrorRequest sends a copy of the upstream request to the shadow upstream, and compares its response with the
+// primary response.
+func (ws *WiretapService) mirrorRequest(prep *PreparedRequest, primary *http.Response, primaryBody []byte) *transaction.MirrorResult {
+	shadowRequest, err := newShadowRequest(prep)
+	if err != nil {
+		return &transaction.MirrorResult{URL: prep.Mirror.Target, Error: err.Error()}
+	}
+	defer shadowRequest.cancel()
+	result := &transaction.MirrorResult{URL: shadowRequest.URL.String()}
+
+	shadow, err := ws.upstream.RoundTrip(shadowRequest.Request)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer shadow.Body.Close()
+	maxBody := prep.Config.GetMaxValidatedBodySize()
+	shadowBody, err := io.ReadAll(io.LimitReader(shadow.Body, maxBody+1))
+	if err != nil {
+		result.Error = fmt.Sprintf("unable to read shadow response: %s", err.Error())
+		return result
+	}
+	if int64(len(shadowBody)) > maxBody {
+		result.Error = "shadow response is larger than the maximum validated body size"
+		return result
+	}
+	result.StatusCode = shadow.StatusCode
+	result.Differences = diffMirrorResponses(prep.Mirror, primary, primaryBody, shadow, shadowBody)
+
+	if ws.validator != nil {
+		shadow.Body = io.NopCloser(bytes.NewReader(shadowBody))
+		_, result.ResponseValidation = ws.validator.ValidateResponseForRequest(prep.NewReq, shadow)
+	}
+	return result
+}
+
+type shadowRequest struct {
+	*http.Request
+	cancel context.CancelFunc
+}
+
+// newShadowRequest copies the upstream request for the mirror target. The copy outlives the client request, keeps its
+// auth provider and signing, but uses the default TLS settings as it goes to a different host.
+func newShadowRequest(prep *PreparedRequest) (*shadowRequest, error) {
+	target, err := url.Parse(prep.Mirror.Target)
+	if err != nil || target.Host == "" {
+		return nil, fmt.Errorf("invalid mirror target '%s'", prep.Mirror.Target)
+	}
+	shadowURL := *prep.APIRequest.URL
+	shadowURL.Scheme = target.Scheme
+	shadowURL.Host = target.Host
+	shadowURL.Path = strings.TrimSuffix(target.Path, "/") + prep.NewReq.URL.Path
+	shadowURL.RawPath = ""
+
+	ctx := context.WithValue(context.WithoutCancel(prep.APIRequest.Context()), tlsProfileKey{}, nil)
+	ctx, cancel := context.WithTimeout(ctx, mirrorTimeout)
+	request, err := http.NewRequestWithContext(ctx, prep.APIRequest.Method, shadowURL.String(), bytes.NewReader(prep.UpstreamBody))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	request.Header = prep.APIRequest.Header.Clone()
+	return &shadowRequest{Request: request, cancel: cancel}, nil
+}
+
+// diffMirrorResponses returns the differences between a primary and a shadow response: their status codes, the headers
+// not ignored, and their bodies. JSON bodies are compared value by value, other bodies as a whole.
+func diffMirrorResponses(mirror *shared.WiretapMirrorConfig, primary *http.Response, primaryBody []byte,
+	shadow *http.Response, shadowBody []byte) []*transaction.MirrorDifference {
+
+	var differences []*transaction.MirrorDifference
+	if primary.StatusCode != shadow.StatusCode {
+		differences = append(differences, &transaction.MirrorDifference{
+			Kind:    transaction.MirrorDifferenceStatus,
+			Primary: strconv.Itoa(primary.StatusCode),
+			Shadow:  strconv.Itoa(shadow.StatusCode),
+		})
+	}
+
+	ignoredHeaders := make(map[string]bool)
+	for _, header := range append(append([]string(nil), mirrorVolatileHeaders...), mirror.IgnoreHeaders...) {
+		ignoredHeaders[http.CanonicalHeaderKey(header)] = true
+	}
+	names := make(map[string]bool)
+	for name := range primary.Header {
+		names[http.CanonicalHeaderKey(name)] = true
+	}
+	for name := range shadow.Header {
+		names[http.CanonicalHeaderKey(name)] = true
+	}
+	sortedNames := make([]string, 0, len(names))
+	for name := range names {
+		if !ignoredHeaders[name] {
+			sortedNames = append(sortedNames, name)
+		}
+	}
+	sort.Strings(sortedNames)
+	for _, name := range sortedNames {
+		primaryValue := strings.Join(primary.Header.Values(name), ", ")
+		shadowValue := strings.Join(shadow.Header.Values(name), ", ")
+		if primaryValue != shadowValue {
+			differences = append(differences, &transaction.MirrorDifference{
+				Kind:     transaction.MirrorDifferenceHeader,
+				Location: name,
+				Primary:  truncateMirrorValue(primaryValue),
+				Shadow:   truncateMirrorValue(shadowValue),
+			})
+		}
+	}
+
+	var primaryJSON, shadowJSON any
+	if decodeMirrorJSON(primaryBody, &primaryJSON) && decodeMirrorJSON(shadowBody, &shadowJSON) {
+		ignorePaths := make([]glob.Glob, 0, len(mirror.IgnorePaths))
+		for _, path := range mirror.IgnorePaths {
+			if compiled, err := glob.Compile(path, '.'); err == nil {
+				ignorePaths = append(ignorePaths, compiled)
+			}
+		}
+		differences = diffMirrorJSON(differences, "", primaryJSON, shadowJSON, ignorePaths)
+	} else if !bytes.Equal(primaryBody, shadowBody) {
+		differences = append(differences, &transaction.MirrorDifference{
+			Kind:    transaction.MirrorDifferenceBody,
+			Primary: truncateMirrorValue(string(primaryBody)),
+			Shadow:  truncateMirrorValue(string(shadowBody)),
+		})
+	}
+	if len(differences) > mirrorMaxDifferences {
+		differences = differences[:mirrorMaxDifferences]
+	}
+	return differences
+}
+
+func decodeMirrorJSON(body []byte, value *any) bool {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	return len(bytes.TrimSpace(body)) > 0 && decoder.Decode(value) == nil
+}
+
+// diffMirrorJSON appends the differences between two JSON values at a path. Objects and arrays are compared member by
+// member; paths matching an ignore path are skipped, with everything beneath them.
+func diffMirrorJSON(differences []*transaction.MirrorDifference, path string, primary, shadow any,
+	ignorePaths []glob.Glob) []*transaction.MirrorDifference {
+
+	if len(differences) > mirrorMaxDifferences {
+		return differences
+	}
+	for _, ignore := range ignorePaths {
+		if path != "" && ignore.Match(path) {
+			return differences
+		}
+	}
+	childPath := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+
+	switch primaryValue := primary.(type) {
+	case map[string]any:
+		if shadowValue, ok := shadow.(map[string]any); ok {
+			keys := make(map[string]bool)
+			for key := range primaryValue {
+				keys[key] = true
+			}
+			for key := range shadowValue {
+				keys[key] = true
+			}
+			sortedKeys := make([]string, 0, len(keys))
+			for key := range keys {
+				sortedKeys = append(sortedKeys, key)
+			}
+			sort.Strings(sortedKeys)
+			for _, key := range sortedKeys {
+				differences = diffMirrorJSON(differences, childPath(key), primaryValue[key], shadowValue[key], ignorePaths)
+			}
+			return differences
+		}
+	case []any:
+		if shadowValue, ok := shadow.([]any); ok {
+			for i := 0; i < max(len(primaryValue), len(shadowValue)); i++ {
+				var primaryItem, shadowItem any
+				if i < len(primaryValue) {
+					primaryItem = primaryValue[i]
+				}
+				if i < len(shadowValue) {
+					shadowItem = shadowValue[i]
+				}
+				differences = diffMirrorJSON(differences, childPath(strconv.Itoa(i)), primaryItem, shadowItem, ignorePaths)
+			}
+			return differences
+		}
+	}
+
+	if reflect.DeepEqual(primary, shadow) {
+		return differences
+	}
+	location := path
+	if location == "" {
+		location = "$"
+	}
+	return append(differences, &transaction.MirrorDifference{
+		Kind:     transaction.MirrorDifferenceBody,
+		Location: location,
+		Primary:  mirrorJSONValue(primary),
+		Shadow:   mirrorJSONValue(shadow),
+	})
+}
+
+func mirrorJSONValue(value any) string {
+	if value == nil {
+		return ""
+	}
+	encoded, _ := json.Marshal(value)
+	return truncateMirrorValue(string(encoded))
+}
+
+func truncateMirrorValue(value string) string {
+	if len(value) > mirrorMaxValue {
+		return value[:mirrorMaxValue] + "..."
+	}
+	return value
+}
+
+// mirrorOperation names the operation of a request in the mirror report, by the path of the specification it matches
+// when there is one.
+func (ws *WiretapService) mirrorOperation(request *http.Request) string {
+	path := request.URL.Path
+	if match := ws.getRouteMatchForHTTPRequest(request); match != nil && match.MatchedPath != "" {
+		path = match.MatchedPath
+	}
+	return request.Method + " " + path
+}
+
+// mirrorReport summarizes the requests mirrored so far, and rewrites the report file after each one.
+type mirrorReport struct {
+	lock       sync.Mutex
+	file       string
+	Compared   int                                `json:"compared"`
+	Matched    int                                `json:"matched"`
+	Differed   int                                `json:"differed"`
+	Failed     int                                `json:"failed"`
+	Skipped    int                                `json:"skipped,omitempty"`
+	Operations map[string]*mirrorOperationSummary `json:"operations"`
+}
+
+// mirrorOperationSummary counts the mirrored requests of an operation, and how often each difference was seen.
+type mirrorOperationSummary struct {
+	Compared    int            `json:"compared"`
+	Differed    int            `json:"differed"`
+	Failed      int            `json:"failed"`
+	Skipped     int            `json:"skipped,omitempty"`
+	Differences map[string]int `json:"differences,omitempty"`
+}
+
+func newMirrorReport(file string) *mirrorReport {
+	return &mirrorReport{file: file, Operations: make(map[string]*mirrorOperationSummary)}
+}
+
+func (r *mirrorReport) record(operation string, result *transaction.MirrorResult) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	summary := r.Operations[operation]
+	if summary == nil {
+		summary = &mirrorOperationSummary{}
+		r.Operations[operation] = summary
+	}
+	// skipped requests were never compared.
+	if result.Skipped != "" {
+		r.Skipped++
+		summary.Skipped++
+	} else {
+		r.Compared++
+		summary.Compared++
+	}
+	switch {
+	case result.Skipped != "":
+	case result.Error != "":
+		r.Failed++
+		summary.Failed++
+	case len(result.Differences) > 0:
+		r.Differed++
+		summary.Differed++
+		if summary.Differences == nil {
+			summary.Differences = make(map[string]int)
+		}
+		for _, difference := range result.Differences {
+			key := difference.Kind
+			if difference.Location != "" {
+				key += " " + difference.Location
+			}
+			summary.Differences[key]++
+		}
+	default:
+		r.Matched++
+	}
+
+	if r.file == "" {
+		return
+	}
+	report, _ := json.MarshalIndent(r, "", "  ")
+	_ = os.WriteFile(r.file, report, 0o644)
+}
@@ -0,0 +1,156 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/ranch/bus"
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/ranch/store"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffMirrorResponses(t *testing.T) {
+	primary := &http.Response{StatusCode: 200, Header: http.Header{
+		"Content-Type": {"application/json"},
+		"Date":         {"Mon, 19 Oct 2026 10:00:00 GMT"},
+		"X-Request-Id": {"a"},
+		"X-Version":    {"1"},
+	}}
+	shadow := &http.Response{StatusCode: 201, Header: http.Header{
+		"Content-Type": {"application/json"},
+		"Date":         {"Mon, 19 Oct 2026 10:00:01 GMT"},
+		"X-Request-Id": {"b"},
+	}}
+	mirror := &shared.WiretapMirrorConfig{
+		IgnoreHeaders: []string{"x-request-id"},
+		IgnorePaths:   []string{"meta", "items.*.updatedAt"},
+	}
+
+	differences := diffMirrorResponses(mirror, primary,
+		[]byte(`{"meta":{"took":3},"total":2,"items":[{"id":1,"updatedAt":"a"},{"id":2,"name":"pb33f"}]}`), shadow,
+		[]byte(`{"meta":{"took":9},"total":2.0,"items":[{"id":1,"updatedAt":"b"},{"id":3,"name":"pb33f"}],"next":null}`))
+
+	assert.Equal(t, []*transaction.MirrorDifference{
+		{Kind: transaction.MirrorDifferenceStatus, Primary: "200", Shadow: "201"},
+		{Kind: transaction.MirrorDifferenceHeader, Location: "X-Version", Primary: "1"},
+		{Kind: transaction.MirrorDifferenceBody, Location: "items.1.id", Primary: "2", Shadow: "3"},
+		{Kind: transaction.MirrorDifferenceBody, Location: "total", Primary: "2", Shadow: "2.0"},
+	}, differences)
+
+	// other bodies are compared as a whole.
+	differences = diffMirrorResponses(mirror, &http.Response{StatusCode: 200}, []byte("one"), &http.Response{StatusCode: 200}, []byte("two"))
+	assert.Equal(t, []*transaction.MirrorDifference{
+		{Kind: transaction.MirrorDifferenceBody, Primary: "one", Shadow: "two"},
+	}, differences)
+	assert.Empty(t, diffMirrorResponses(mirror, &http.Response{StatusCode: 204}, nil, &http.Response{StatusCode: 204}, nil))
+}
+
+func TestHandleHttpRequestMirrorsToShadowUpstream(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name":"primary","method":"` + r.Method + `"}`))
+	}))
+	defer primary.Close()
+
+	var shadowPath, shadowBody string
+	shadowRequests := make(chan struct{}, 1)
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		shadowPath = r.URL.RequestURI()
+		body, _ := io.ReadAll(r.Body)
+		shadowBody = string(body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name":"shadow","method":"` + r.Method + `"}`))
+		shadowRequests <- struct{}{}
+	}))
+	defer shadow.Close()
+
+	report := filepath.Join(t.TempDir(), "mirror.json")
+	config := &shared.WiretapConfiguration{
+		RedirectURL:        primary.URL,
+		RedirectProtocol:   "http",
+		RedirectHost:       primary.Listener.Addr().String(),
+		Mirror:             &shared.WiretapMirrorConfig{Target: shadow.URL + "/v2"},
+		MirrorReportFile:   report,
+		ReportFile:         t.TempDir() + "/violations.jsonl",
+		Logger:             slog.New(slog.NewTextHandler(io.Discard, nil)),
+		PathConfigurations: orderedmap.New[string, *shared.WiretapPathConfig](),
+	}
+	config.CompilePaths()
+
+	eventBus := bus.NewEventBus()
+	ws, err := NewWiretapService(nil, config, store.NewManager(eventBus))
+	require.NoError(t, err)
+	ws.setBroadcastChannel(eventBus.GetChannelManager().CreateChannel(WiretapBroadcastChan))
+	ws.controlsStore.Put(shared.ConfigKey, config, nil)
+
+	id := uuid.New()
+	rec := httptest.NewRecorder()
+	ws.handleHttpRequest(&model.Request{
+		Id:                 &id,
+		HttpRequest:        httptest.NewRequest(http.MethodPost, "/pets?limit=1", strings.NewReader(`{"a":1}`)),
+		HttpResponseWriter: rec,
+	})
+
+	// the client only sees the primary response.
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"name":"primary","method":"POST"}`, rec.Body.String())
+
+	select {
+	case <-shadowRequests:
+	case <-time.After(5 * time.Second):
+		t.Fatal("request was not mirrored")
+	}
+	assert.Equal(t, "/v2/pets?limit=1", shadowPath)
+	assert.Equal(t, `{"a":1}`, shadowBody)
+
+	var mirrored *transaction.MirrorResult
+	require.Eventually(t, func() bool {
+		stored, ok := ws.transactionStore.Get(id.String())
+		if ok {
+			mirrored = stored.(*transaction.HttpTransaction).Mirror
+		}
+		return mirrored != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 200, mirrored.StatusCode)
+	assert.Equal(t, []*transaction.MirrorDifference{
+		{Kind: transaction.MirrorDifferenceBody, Location: "name", Primary: `"primary"`, Shadow: `"shadow"`},
+	}, mirrored.Differences)
+
+	contents, err := os.ReadFile(report)
+	require.NoError(t, err)
+	var summary map[string]any
+	require.NoError(t, json.Unmarshal(contents, &summary))
+	assert.Equal(t, float64(1), summary["differed"])
+	assert.Equal(t, map[string]any{"compared": float64(1), "differed": float64(1), "failed": float64(0),
+		"differences": map[string]any{"body name": float64(1)}}, summary["operations"].(map[string]any)["POST /pets"])
+}
+
+func TestMirrorReportCountsSkippedRequests(t *testing.T) {
+	report := newMirrorReport("")
+	report.record("GET /events", &transaction.MirrorResult{Skipped: transaction.ValidationSkippedStreaming})
+	report.record("GET /events", &transaction.MirrorResult{StatusCode: 200})
+
+	// skipped requests are neither compared nor failed.
+	assert.Equal(t, 1, report.Skipped)
+	assert.Equal(t, 1, report.Compared)
+	assert.Equal(t, 1, report.Matched)
+	assert.Equal(t, 0, report.Failed)
+	assert.Equal(t, &mirrorOperationSummary{Compared: 1, Skipped: 1}, report.Operations["GET /events"])
+}
@@ -0,0 +1,47 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/wiretap/daemon/proxy"
+)
+
+// mockFallback returns the proxy hook that mocks a response from the specification, for requests to an open circuit or
+// a failed upstream.
+func (ws *WiretapService) mockFallback(prep *PreparedRequest) proxy.MockFallback {
+	return func() (*http.Response, error) {
+		// a new request, as request validation may be reading the body of prep.NewReq.
+		request, err := http.NewRequestWithContext(prep.NewReq.Context(), prep.NewReq.Method, prep.NewReq.URL.String(),
+			bytes.NewReader(prep.BodyBytes))
+		if err != nil {
+			return nil, err
+		}
+		request.Header = prep.NewReq.Header.Clone()
+		docValidator, mockReq := ws.getValidatorAndRequestForHTTPRequest(request)
+		if docValidator == nil {
+			return nil, fmt.Errorf("no specification matches '%s'", prep.NewReq.URL.Path)
+		}
+		body, status, headers, err := docValidator.MockEngine.GenerateResponseWithHeaders(mockReq)
+		if err != nil {
+			return nil, err
+		}
+		return &http.Response{StatusCode: status, Header: headers, Body: io.NopCloser(bytes.NewReader(body))}, nil
+	}
+}
+
+// mockFallbackRecorder returns the proxy hook that records a mock answering a request in place of a failed upstream
+// response, marking the transaction with why the upstream failed.
+func (ws *WiretapService) mockFallbackRecorder(request *model.Request) proxy.MockFallbackRecorder {
+	return func(response *http.Response, body []byte, reason string) {
+		txn := BuildResponseFromBytes(request, response, body)
+		txn.MockFallback = reason
+		ws.broadcastResponse(request, txn)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleHttpRequestFallsBackToMocksWhenTheUpstreamFails(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer upstream.Close()
+
+	config := &shared.WiretapConfiguration{
+		RedirectURL:      upstream.URL,
+		RedirectProtocol: "http",
+		RedirectHost:     upstream.Listener.Addr().String(),
+		MockFallbackList: []string{"/wiretap/giftshop/products/**"},
+	}
+	config.CompileMockFallbackList()
+	ws := newMockModeWiretapService(t, config)
+
+	send := func(path string) (*uuid.UUID, *httptest.ResponseRecorder) {
+		request, err := http.NewRequest(http.MethodGet, "https://api.pb33f.io"+path, nil)
+		require.NoError(t, err)
+		request.Header.Set("X-API-Key", "doesnotmatter")
+		id := uuid.New()
+		rec := httptest.NewRecorder()
+		ws.handleHttpRequest(&model.Request{Id: &id, HttpRequest: request, HttpResponseWriter: rec})
+		return &id, rec
+	}
+
+	id, rec := send("/wiretap/giftshop/products/d1404c5c-69bd-4ea4-9e00-2dfa1b4c1a1d")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var product map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &product))
+	assert.Contains(t, product, "id")
+
+	var stored *transaction.HttpTransaction
+	require.Eventually(t, func() bool {
+		txn, ok := ws.transactionStore.Get(id.String())
+		if ok {
+			stored = txn.(*transaction.HttpTransaction)
+		}
+		return stored != nil && stored.MockFallback != ""
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "upstream responded 502", stored.MockFallback)
+	assert.Equal(t, http.StatusOK, stored.Response.StatusCode)
+
+	// paths not in the list keep the upstream failure.
+	_, rec = send("/wiretap/giftshop/search")
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+}
@@ -48,10 +48,11 @@ func newMockModeWiretapService(t *testing.T, config *shared.WiretapConfiguration
 
 	eventBus := bus.NewEventBus()
 	storeManager := store.NewManager(eventBus)
-	ws := NewWiretapService([]shared.ApiDocument{{
+	ws, err := NewWiretapService([]shared.ApiDocument{{
 		DocumentName: "giftshop-openapi.yaml",
 		Document:     doc,
 	}}, config, storeManager)
+	require.NoError(t, err)
 	ws.setBroadcastChannel(eventBus.GetChannelManager().CreateChannel(WiretapBroadcastChan))
 	ws.controlsStore.Put(shared.ConfigKey, config, nil)
 	return ws
@@ -162,10 +163,11 @@ paths:
 
 	eventBus := bus.NewEventBus()
 	storeManager := store.NewManager(eventBus)
-	ws := NewWiretapService([]shared.ApiDocument{{
+	ws, err := NewWiretapService([]shared.ApiDocument{{
 		DocumentName: "scoped.yaml",
 		Document:     doc,
 	}}, config, storeManager)
+	require.NoError(t, err)
 	ws.setBroadcastChannel(eventBus.GetChannelManager().CreateChannel(WiretapBroadcastChan))
 	ws.controlsStore.Put(shared.ConfigKey, config, nil)
 
@@ -231,10 +233,11 @@ paths:
 
 	eventBus := bus.NewEventBus()
 	storeManager := store.NewManager(eventBus)
-	ws := NewWiretapService([]shared.ApiDocument{{
+	ws, err := NewWiretapService([]shared.ApiDocument{{
 		DocumentName: "split.yaml",
 		Document:     doc,
 	}}, config, storeManager)
+	require.NoError(t, err)
 	ws.setBroadcastChannel(eventBus.GetChannelManager().CreateChannel(WiretapBroadcastChan))
 	ws.controlsStore.Put(shared.ConfigKey, config, nil)
 
@@ -14,11 +14,12 @@ import (
 	configModel "github.com/pb33f/wiretap/config"
 	"github.com/pb33f/wiretap/daemon/problems"
 	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/sse"
 )
 
 type RequestValidator func() []*shared.WiretapValidationError
 type ResponseBroadcaster func(*http.Response)
-type MockGenerator func(*http.Request) ([]byte, int, error)
+type MockGenerator func(*http.Request) ([]byte, int, http.Header, error)
 
 type PreparedRequest struct {
 	Config            *shared.WiretapConfiguration
@@ -29,6 +30,9 @@ type PreparedRequest struct {
 	BroadcastResponse ResponseBroadcaster
 }
 
+// DefaultEventInterval is the time between the events of a mocked server-sent event stream.
+const DefaultEventInterval = time.Second
+
 type Handler struct{}
 
 func NewHandler() *Handler {
@@ -90,7 +94,13 @@ func (h *Handler) Handle(request *model.Request, prep *PreparedRequest) {
 	if mockRequest == nil {
 		mockRequest = request.HttpRequest
 	}
-	mock, mockStatus, mockErr := prep.GenerateMock(mockRequest)
+	mock, mockStatus, mockHeaders, mockErr := prep.GenerateMock(mockRequest)
+
+	// headers from the mock engine (content type, applied preferences) override the defaults.
+	for k, v := range mockHeaders {
+		headers[k] = v
+		request.HttpResponseWriter.Header()[k] = v
+	}
 	resp := newMockResponse(mockStatus, headers, mock)
 
 	if mockErr != nil && len(mock) == 0 {
@@ -120,12 +130,36 @@ func (h *Handler) Handle(request *model.Request, prep *PreparedRequest) {
 		return
 	}
 
+	if sse.IsEventStream(request.HttpResponseWriter.Header().Get("Content-Type")) {
+		h.writeEvents(request.HttpResponseWriter, mock, config)
+		return
+	}
+
 	_, err := request.HttpResponseWriter.Write(mock)
 	if err != nil {
 		config.Logger.Error("[wiretap] mock mode response body write failed", "error", err)
 	}
 }
 
+// writeEvents writes a mocked server-sent event stream to the client one event at a time, at the configured interval.
+func (h *Handler) writeEvents(w http.ResponseWriter, stream []byte, config *shared.WiretapConfiguration) {
+	interval := DefaultEventInterval
+	if config.MockEventInterval > 0 {
+		interval = time.Duration(config.MockEventInterval) * time.Millisecond
+	}
+	controller := http.NewResponseController(w)
+	for i, event := range sse.NewParser().Feed(stream) {
+		if i > 0 {
+			time.Sleep(interval)
+		}
+		if _, err := w.Write(event.Format()); err != nil {
+			config.Logger.Warn("[wiretap] mock event stream closed by client", "error", err)
+			return
+		}
+		_ = controller.Flush()
+	}
+}
+
 func newMockResponse(status int, headers map[string][]string, body []byte) *http.Response {
 	resp := &http.Response{
 		StatusCode: status,
@@ -36,8 +36,8 @@ func TestHandlerWritesMockResponse(t *testing.T) {
 		ValidateRequest: func() []*shared.WiretapValidationError {
 			return nil
 		},
-		GenerateMock: func(_ *http.Request) ([]byte, int, error) {
-			return []byte(`{"ok":true}`), http.StatusCreated, nil
+		GenerateMock: func(_ *http.Request) ([]byte, int, http.Header, error) {
+			return []byte(`{"ok":true}`), http.StatusCreated, nil, nil
 		},
 		BroadcastResponse: func(resp *http.Response) {
 			broadcastedC <- resp
@@ -72,9 +72,9 @@ func TestHandlerGeneratesMockFromPreparedRequest(t *testing.T) {
 		ValidateRequest: func() []*shared.WiretapValidationError {
 			return nil
 		},
-		GenerateMock: func(req *http.Request) ([]byte, int, error) {
+		GenerateMock: func(req *http.Request) ([]byte, int, http.Header, error) {
 			generatedFrom = req
-			return []byte(`{"ok":true}`), http.StatusOK, nil
+			return []byte(`{"ok":true}`), http.StatusOK, nil, nil
 		},
 		BroadcastResponse: func(_ *http.Response) {},
 	})
@@ -106,9 +106,9 @@ func TestHandlerWritesValidationProblemForHardRequestErrors(t *testing.T) {
 				SpecName:        "spec.yaml",
 			}}
 		},
-		GenerateMock: func(_ *http.Request) ([]byte, int, error) {
+		GenerateMock: func(_ *http.Request) ([]byte, int, http.Header, error) {
 			generatedMock = true
-			return []byte(`{"ok":true}`), http.StatusOK, nil
+			return []byte(`{"ok":true}`), http.StatusOK, nil, nil
 		},
 		BroadcastResponse: func(_ *http.Response) {},
 	})
@@ -137,8 +137,8 @@ func TestHandlerDoesNotPanicOnMockBodyWriteError(t *testing.T) {
 			ValidateRequest: func() []*shared.WiretapValidationError {
 				return nil
 			},
-			GenerateMock: func(_ *http.Request) ([]byte, int, error) {
-				return []byte(`{"ok":true}`), http.StatusCreated, nil
+			GenerateMock: func(_ *http.Request) ([]byte, int, http.Header, error) {
+				return []byte(`{"ok":true}`), http.StatusCreated, nil, nil
 			},
 			BroadcastResponse: func(_ *http.Response) {},
 		})
@@ -153,3 +153,69 @@ func testConfig() *shared.WiretapConfiguration {
 		Logger:              slog.New(slog.NewTextHandler(io.Discard, nil)),
 	}
 }
+
+func TestHandlerAppliesMockEngineHeaders(t *testing.T) {
+	id := uuid.New()
+	request := &model.Request{
+		Id:                 &id,
+		HttpRequest:        httptest.NewRequest(http.MethodGet, "http://wiretap.local/products", nil),
+		HttpResponseWriter: httptest.NewRecorder(),
+	}
+
+	broadcastedC := make(chan *http.Response, 1)
+	NewHandler().Handle(request, &PreparedRequest{
+		Config: testConfig(),
+		ValidateRequest: func() []*shared.WiretapValidationError {
+			return nil
+		},
+		GenerateMock: func(_ *http.Request) ([]byte, int, http.Header, error) {
+			return []byte(`<ok/>`), http.StatusOK, http.Header{
+				"Content-Type":       {"application/xml"},
+				"Preference-Applied": {"code=200"},
+			}, nil
+		},
+		BroadcastResponse: func(resp *http.Response) {
+			broadcastedC <- resp
+		},
+	})
+
+	rec := request.HttpResponseWriter.(*httptest.ResponseRecorder)
+	assert.Equal(t, "application/xml", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "code=200", rec.Header().Get("Preference-Applied"))
+	select {
+	case broadcasted := <-broadcastedC:
+		assert.Equal(t, []string{"application/xml"}, broadcasted.Header.Values("Content-Type"))
+	case <-time.After(500 * time.Millisecond):
+		require.Fail(t, "expected mock response to be broadcast")
+	}
+}
+
+func TestHandlerWritesMockEventsAtTheConfiguredInterval(t *testing.T) {
+	id := uuid.New()
+	request := &model.Request{
+		Id:                 &id,
+		HttpRequest:        httptest.NewRequest(http.MethodGet, "http://wiretap.local/prices", nil),
+		HttpResponseWriter: httptest.NewRecorder(),
+	}
+	config := testConfig()
+	config.MockEventInterval = 20
+
+	start := time.Now()
+	NewHandler().Handle(request, &PreparedRequest{
+		Config: config,
+		ValidateRequest: func() []*shared.WiretapValidationError {
+			return nil
+		},
+		GenerateMock: func(_ *http.Request) ([]byte, int, http.Header, error) {
+			return []byte("id: 1\ndata: one\n\nid: 2\ndata: two\n\nid: 3\ndata: three\n\n"), http.StatusOK,
+				http.Header{"Content-Type": {"text/event-stream"}}, nil
+		},
+		BroadcastResponse: func(_ *http.Response) {},
+	})
+
+	rec := request.HttpResponseWriter.(*httptest.ResponseRecorder)
+	assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "id: 1\ndata: one\n\nid: 2\ndata: two\n\nid: 3\ndata: three\n\n", rec.Body.String())
+	assert.True(t, rec.Flushed)
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+}
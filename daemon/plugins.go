@@ -0,0 +1,251 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/pb33f/libopenapi-validator/errors"
+	"github.com/pb33f/wiretap/plugin"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/transaction"
+)
+
+// pluginValidation is the validation type of the violations returned by plugins, their sub type is the plugin name.
+const pluginValidation = "plugin"
+
+// loadPlugins loads the configured plugins. A plugin that fails to load stops wiretap starting, rather than passing
+// transactions it was meant to check.
+func loadPlugins(config *shared.WiretapConfiguration) ([]*plugin.Plugin, error) {
+	plugins := make([]*plugin.Plugin, 0, len(config.Plugins))
+	for _, path := range config.Plugins {
+		loaded, err := plugin.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		plugins = append(plugins, loaded)
+	}
+	return plugins, nil
+}
+
+// hasPlugin returns true when any plugin defines an entry point.
+func (ws *WiretapService) hasPlugin(entry string) bool {
+	for _, p := range ws.plugins {
+		if p.Has(entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// pluginRequest returns a request as plugins receive it.
+func pluginRequest(request *http.Request, body []byte) *plugin.Message {
+	return &plugin.Message{
+		Method:  request.Method,
+		Path:    request.URL.Path,
+		Query:   request.URL.RawQuery,
+		Headers: request.Header.Clone(),
+		Body:    string(body),
+	}
+}
+
+// pluginResponse returns a response as plugins receive it.
+func pluginResponse(response *http.Response, body []byte) *plugin.Message {
+	return &plugin.Message{
+		Status:  response.StatusCode,
+		Headers: response.Header.Clone(),
+		Body:    string(body),
+	}
+}
+
+// requestBody returns the body of a request, leaving it to be read again.
+func requestBody(request *http.Request) []byte {
+	if request.GetBody == nil {
+		return nil
+	}
+	body, err := request.GetBody()
+	if err != nil {
+		return nil
+	}
+	defer body.Close()
+	b, _ := io.ReadAll(body)
+	return b
+}
+
+// pluginRequestViolations returns the violations the plugins found in a request.
+func (ws *WiretapService) pluginRequestViolations(request *http.Request) []*shared.WiretapValidationError {
+	if !ws.hasPlugin(plugin.ValidateRequest) {
+		return nil
+	}
+	input := &plugin.Input{
+		OperationID: ws.matchedOperation(request).ID,
+		Request:     pluginRequest(request, requestBody(request)),
+	}
+	return ws.pluginViolations(plugin.ValidateRequest, input, request)
+}
+
+// pluginResponseViolations returns the violations the plugins found in the response to a request.
+func (ws *WiretapService) pluginResponseViolations(request *http.Request, response *http.Response,
+	preReadBody ...[]byte) []*shared.WiretapValidationError {
+	if response == nil || !ws.hasPlugin(plugin.ValidateResponse) {
+		return nil
+	}
+	var body []byte
+	if len(preReadBody) > 0 {
+		body = preReadBody[0]
+	} else if response.Body != nil {
+		body, _ = io.ReadAll(response.Body)
+		_ = response.Body.Close()
+		response.Body = io.NopCloser(bytes.NewBuffer(body))
+	}
+	input := &plugin.Input{
+		OperationID: ws.matchedOperation(request).ID,
+		Request:     pluginRequest(request, requestBody(request)),
+		Response:    pluginResponse(response, body),
+	}
+	return ws.pluginViolations(plugin.ValidateResponse, input, request)
+}
+
+// pluginViolations calls a validate entry point of every plugin defining it. A plugin that fails is a violation
+// itself, so it can't let transactions through unchecked.
+func (ws *WiretapService) pluginViolations(entry string, input *plugin.Input,
+	request *http.Request) []*shared.WiretapValidationError {
+	var violations []*shared.WiretapValidationError
+	for _, p := range ws.plugins {
+		if !p.Has(entry) {
+			continue
+		}
+		found, err := p.Validate(entry, input)
+		if err != nil {
+			serviceLogger(ws).Warn("[wiretap] plugin failed", "plugin", p.Name, "entry", entry, "error", err.Error())
+			found = []*plugin.Violation{{
+				Message:  fmt.Sprintf("Plugin '%s' failed to validate the %s", p.Name, validatedMessage(entry)),
+				Reason:   err.Error(),
+				HowToFix: "Fix the plugin, so it returns a list of violations",
+			}}
+		}
+		for _, violation := range found {
+			if violation == nil {
+				continue
+			}
+			violations = append(violations, &shared.WiretapValidationError{
+				ValidationError: errors.ValidationError{
+					Message:           violation.Message,
+					Reason:            violation.Reason,
+					ValidationType:    pluginValidation,
+					ValidationSubType: p.Name,
+					SpecLine:          -1,
+					SpecCol:           -1,
+					HowToFix:          violation.HowToFix,
+					RequestPath:       request.URL.Path,
+					RequestMethod:     request.Method,
+				},
+			})
+		}
+	}
+	return violations
+}
+
+func validatedMessage(entry string) string {
+	if entry == plugin.ValidateResponse {
+		return "response"
+	}
+	return "request"
+}
+
+// transformRequestWithPlugins passes the request sent upstream through the plugins transforming requests, which may
+// change its headers and body. Plugins that fail are recorded on the transforms of the transaction, and leave the
+// request unchanged.
+func (ws *WiretapService) transformRequestWithPlugins(request, validationRequest *http.Request, body []byte,
+	record *transaction.TransformRecord) ([]byte, *transaction.TransformRecord) {
+	if !ws.hasPlugin(plugin.TransformRequest) {
+		return body, record
+	}
+	operationID := ws.matchedOperation(validationRequest).ID
+	changed := false
+	for _, p := range ws.plugins {
+		if !p.Has(plugin.TransformRequest) {
+			continue
+		}
+		transformed, err := p.Transform(plugin.TransformRequest, &plugin.Input{
+			OperationID: operationID,
+			Request:     pluginRequest(request, body),
+		})
+		if err != nil {
+			serviceLogger(ws).Warn("[wiretap] plugin failed", "plugin", p.Name, "entry", plugin.TransformRequest,
+				"error", err.Error())
+			if record == nil {
+				record = &transaction.TransformRecord{}
+			}
+			record.Errors = append(record.Errors, fmt.Sprintf("request: plugin '%s': %s", p.Name, err.Error()))
+			continue
+		}
+		if transformed == nil {
+			continue
+		}
+		request.Header = transformed.Headers
+		body = []byte(transformed.Body)
+		changed = true
+	}
+	if !changed {
+		return body, record
+	}
+
+	request.Body = io.NopCloser(bytes.NewReader(body))
+	request.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	request.ContentLength = int64(len(body))
+	if request.Header.Get("Content-Length") != "" {
+		request.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	}
+	if record == nil {
+		record = &transaction.TransformRecord{}
+	}
+	record.UpstreamRequestBody = string(body)
+	return body, record
+}
+
+// transformResponseWithPlugins passes an upstream response through the plugins transforming responses, which may
+// change its status, headers and body. It returns the body to send the client, and the errors of the plugins that
+// failed, which leave the response unchanged.
+func (ws *WiretapService) transformResponseWithPlugins(request *http.Request, response *http.Response,
+	body []byte) ([]byte, []string) {
+	if !ws.hasPlugin(plugin.TransformResponse) {
+		return body, nil
+	}
+	var failures []string
+	operationID := ws.matchedOperation(request).ID
+	for _, p := range ws.plugins {
+		if !p.Has(plugin.TransformResponse) {
+			continue
+		}
+		transformed, err := p.Transform(plugin.TransformResponse, &plugin.Input{
+			OperationID: operationID,
+			Request:     pluginRequest(request, requestBody(request)),
+			Response:    pluginResponse(response, body),
+		})
+		if err == nil && transformed != nil && http.StatusText(transformed.Status) == "" {
+			err = fmt.Errorf("invalid status '%d'", transformed.Status)
+		}
+		if err != nil {
+			serviceLogger(ws).Warn("[wiretap] plugin failed", "plugin", p.Name, "entry", plugin.TransformResponse,
+				"error", err.Error())
+			failures = append(failures, fmt.Sprintf("response: plugin '%s': %s", p.Name, err.Error()))
+			continue
+		}
+		if transformed == nil {
+			continue
+		}
+		response.StatusCode = transformed.Status
+		response.Status = strconv.Itoa(transformed.Status) + " " + http.StatusText(transformed.Status)
+		response.Header = transformed.Headers
+		body = []byte(transformed.Body)
+	}
+	return body, failures
+}
@@ -0,0 +1,139 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pb33f/ranch/bus"
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/ranch/store"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const rulesPlugin = `
+function validateRequest(input) {
+	if (!input.request.headers["X-Correlation-Id"]) {
+		return [{message: "Correlation id missing", howToFix: "Send an X-Correlation-Id header"}];
+	}
+}
+function validateResponse(input) {
+	if (input.response.body.indexOf("ssn") >= 0) {
+		return [{message: "Response contains PII", reason: "the 'ssn' field is banned"}];
+	}
+}
+function transformRequest(input) {
+	var request = input.request;
+	request.headers["X-Tenant"] = ["acme"];
+	return request;
+}
+function transformResponse(input) {
+	var response = input.response;
+	response.body = response.body.replace("secret", "******");
+	return response;
+}`
+
+func newPluginService(t *testing.T, upstreamURL string, hardErrors bool) *WiretapService {
+	path := filepath.Join(t.TempDir(), "rules.js")
+	require.NoError(t, os.WriteFile(path, []byte(rulesPlugin), 0o600))
+
+	config := &shared.WiretapConfiguration{
+		RedirectURL:            upstreamURL,
+		RedirectProtocol:       "http",
+		RedirectHost:           strings.TrimPrefix(upstreamURL, "http://"),
+		ReportFile:             t.TempDir() + "/violations.jsonl",
+		Logger:                 slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Plugins:                []string{path},
+		HardErrors:             hardErrors,
+		HardErrorCode:          http.StatusBadRequest,
+		HardErrorReturnCode:    http.StatusBadGateway,
+		HardErrorReturnProblem: hardErrors,
+	}
+
+	eventBus := bus.NewEventBus()
+	ws, err := NewWiretapService(nil, config, store.NewManager(eventBus))
+	require.NoError(t, err)
+	ws.setBroadcastChannel(eventBus.GetChannelManager().CreateChannel(WiretapBroadcastChan))
+	ws.controlsStore.Put(shared.ConfigKey, config, nil)
+	return ws
+}
+
+func TestHandleHttpRequestRunsPlugins(t *testing.T) {
+	var upstreamTenant string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamTenant = r.Header.Get("X-Tenant")
+		_, _ = w.Write([]byte(`{"ssn":"secret"}`))
+	}))
+	defer upstream.Close()
+	ws := newPluginService(t, upstream.URL, false)
+
+	id := uuid.New()
+	rec := httptest.NewRecorder()
+	ws.handleHttpRequest(&model.Request{
+		Id:                 &id,
+		HttpRequest:        httptest.NewRequest(http.MethodGet, "/pets", nil),
+		HttpResponseWriter: rec,
+	})
+
+	assert.Equal(t, "acme", upstreamTenant)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"ssn":"******"}`, rec.Body.String())
+
+	stored := storedTransaction(t, ws, id, func(txn *transaction.HttpTransaction) bool {
+		return len(txn.RequestValidation) > 0 && len(txn.ResponseValidation) > 0 && txn.Transforms != nil &&
+			txn.Transforms.UpstreamResponseBody != ""
+	})
+	assert.Equal(t, "Correlation id missing", stored.RequestValidation[0].Message)
+	assert.Equal(t, pluginValidation, stored.RequestValidation[0].ValidationType)
+	assert.Equal(t, "rules.js", stored.RequestValidation[0].ValidationSubType)
+	assert.Equal(t, "Response contains PII", stored.ResponseValidation[0].Message)
+	assert.Equal(t, `{"ssn":"secret"}`, stored.Transforms.UpstreamResponseBody)
+}
+
+func TestHandleHttpRequestBlocksPluginViolationsWithHardValidation(t *testing.T) {
+	upstreamCalled := false
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamCalled = true
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer upstream.Close()
+	ws := newPluginService(t, upstream.URL, true)
+
+	id := uuid.New()
+	rec := httptest.NewRecorder()
+	ws.handleHttpRequest(&model.Request{
+		Id:                 &id,
+		HttpRequest:        httptest.NewRequest(http.MethodGet, "/pets", nil),
+		HttpResponseWriter: rec,
+	})
+
+	assert.True(t, upstreamCalled)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Correlation id missing")
+}
+
+func TestNewWiretapServiceRefusesPluginsThatDoNotLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.wasm")
+	require.NoError(t, os.WriteFile(path, []byte("\x00asm"), 0o600))
+	config := &shared.WiretapConfiguration{
+		Logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Plugins: []string{path},
+	}
+
+	_, err := NewWiretapService(nil, config, store.NewManager(bus.NewEventBus()))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to load plugins: unable to compile plugin")
+	assert.Contains(t, err.Error(), "invalid module: unexpected end")
+}
@@ -8,24 +8,35 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 
 	"github.com/pb33f/ranch/model"
 	configModel "github.com/pb33f/wiretap/config"
+	"github.com/pb33f/wiretap/script"
 	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/transaction"
 )
 
 type PreparedRequest struct {
-	Config        *shared.WiretapConfiguration
-	NewReq        *http.Request
-	APIRequest    *http.Request
-	BodyBytes     []byte
-	DropHeaders   []string
-	InjectHeaders map[string]string
-	Auth          string
-	ControlPath   string
-	IsHardError   bool
-	UseMock       bool
-	TxnConfig     HttpTransactionConfig
+	Config         *shared.WiretapConfiguration
+	NewReq         *http.Request
+	APIRequest     *http.Request
+	BodyBytes      []byte
+	UpstreamBody   []byte
+	DropHeaders    []string
+	InjectHeaders  map[string]string
+	Auth           string
+	ControlPath    string
+	IsHardError    bool
+	UseMock        bool
+	FallbackToMock bool
+	Mirror         *shared.WiretapMirrorConfig
+	Resilience     *shared.WiretapResilienceConfig
+	Transforms     *shared.WiretapTransformsConfig
+	Transform      *transaction.TransformRecord
+	Script         *scriptRun
+	ScriptResponse *script.Message
+	TxnConfig      HttpTransactionConfig
 }
 
 func (ws *WiretapService) prepareRequest(request *model.Request) *PreparedRequest {
@@ -38,8 +49,6 @@ func (ws *WiretapService) prepareRequest(request *model.Request) *PreparedReques
 		}
 	}
 
-	dropHeaders, injectHeaders, auth := ws.getHeadersAndAuth(config, request)
-
 	// Read body once. The same bytes are reused for display, validation, and
 	// upstream request clones so request preparation owns all body rewind work.
 	var bodyBytes []byte
@@ -49,26 +58,48 @@ func (ws *WiretapService) prepareRequest(request *model.Request) *PreparedReques
 	}
 	request.HttpRequest.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
 
-	// newReq intentionally has no RedirectBasePath; validator and display paths
-	// should match the OpenAPI paths instead of the upstream deployment path.
-	newReq := CloneExistingRequest(CloneRequest{
+	// the script bound to the path may change the request before it is routed, or answer it itself.
+	pathConfig := matchedPathConfig(config, request.HttpRequest)
+	scripted := ws.newScriptRun(pathConfig, request.HttpRequest)
+	var scriptResponse *script.Message
+	if scripted.has(script.StageBeforeRouting) {
+		exchange := scripted.requestExchange(request.HttpRequest, bodyBytes)
+		if scripted.run(config, script.StageBeforeRouting, exchange) {
+			bodyBytes = applyRequest(request.HttpRequest, exchange.Request)
+			scriptResponse = exchange.Respond
+			pathConfig = matchedPathConfig(config, request.HttpRequest)
+		}
+	}
+
+	dropHeaders, injectHeaders, auth := ws.getHeadersAndAuth(config, request)
+	upstreamBody, transformRecord := transformRequestBody(config, pathConfig, bodyBytes)
+
+	protocol, host, port, basePath := config.RedirectProtocol, config.RedirectHost, config.RedirectPort, config.RedirectBasePath
+	if target := ws.upstreamTargetForRequest(config, request.HttpRequest); target != nil {
+		protocol, host, port, basePath = target.Protocol, target.Host, target.Port, target.BasePath
+	}
+
+	// apiRequest includes RedirectBasePath and is the only request sent upstream, with the transformed body.
+	// It is cloned first, so the original request is left holding the body the client sent.
+	apiRequest := CloneExistingRequest(CloneRequest{
 		Request:       request.HttpRequest,
-		Protocol:      config.RedirectProtocol,
-		Host:          config.RedirectHost,
-		Port:          config.RedirectPort,
+		Protocol:      protocol,
+		Host:          host,
+		BasePath:      basePath,
+		Port:          port,
 		DropHeaders:   dropHeaders,
 		InjectHeaders: injectHeaders,
 		Auth:          auth,
-		BodyBytes:     bodyBytes,
+		BodyBytes:     upstreamBody,
 	})
 
-	// apiRequest includes RedirectBasePath and is the only request sent upstream.
-	apiRequest := CloneExistingRequest(CloneRequest{
+	// newReq intentionally has no RedirectBasePath; validator and display paths
+	// should match the OpenAPI paths instead of the upstream deployment path.
+	newReq := CloneExistingRequest(CloneRequest{
 		Request:       request.HttpRequest,
-		Protocol:      config.RedirectProtocol,
-		Host:          config.RedirectHost,
-		BasePath:      config.RedirectBasePath,
-		Port:          config.RedirectPort,
+		Protocol:      protocol,
+		Host:          host,
+		Port:          port,
 		DropHeaders:   dropHeaders,
 		InjectHeaders: injectHeaders,
 		Auth:          auth,
@@ -79,8 +110,28 @@ func (ws *WiretapService) prepareRequest(request *model.Request) *PreparedReques
 		ws.config.Logger.Error("[wiretap] unable to clone API request, failed", "url", request.HttpRequest.URL.String())
 		return nil
 	}
+	if apiRequest.Header.Get("Content-Length") != "" {
+		apiRequest.Header.Set("Content-Length", strconv.Itoa(len(upstreamBody)))
+	}
 	controlPath := request.HttpRequest.URL.Path
 	displayURL := prepareRequestURLs(newReq, apiRequest, config)
+	useMock := config.MockMode || configModel.IncludePathOnMockMode(controlPath, config)
+	if !useMock {
+		upstreamBody, transformRecord = ws.transformRequestWithPlugins(apiRequest, newReq, upstreamBody, transformRecord)
+	}
+
+	// the script may change the request sent upstream, after it has been rewritten and transformed.
+	if scriptResponse == nil && !useMock && scripted.has(script.StageBeforeUpstream) {
+		scripted.operation = ws.matchedOperation(newReq)
+		exchange := scripted.requestExchange(apiRequest, upstreamBody)
+		if scripted.run(config, script.StageBeforeUpstream, exchange) {
+			upstreamBody = applyRequest(apiRequest, exchange.Request)
+			scriptResponse = exchange.Respond
+		}
+	}
+	apiRequest = withTLSProfile(apiRequest, effectiveTLSProfile(config, pathConfig))
+	apiRequest = withAuthProvider(apiRequest, effectiveAuthProvider(config, pathConfig))
+	apiRequest = withSigning(apiRequest, effectiveSigning(config, pathConfig))
 
 	isHardError := configModel.IsHardErrorsSet(controlPath, config)
 	txnConfig := HttpTransactionConfig{
@@ -93,22 +144,32 @@ func (ws *WiretapService) prepareRequest(request *model.Request) *PreparedReques
 		DropHeaders:       dropHeaders,
 		InjectHeaders:     injectHeaders,
 		Auth:              auth,
-		BasePath:          config.RedirectBasePath,
+		BasePath:          basePath,
 		BodyBytes:         bodyBytes,
+		Transforms:        transformRecord,
+		ScriptErrors:      scripted.recorded(),
 	}
 
 	return &PreparedRequest{
-		Config:        config,
-		NewReq:        newReq,
-		APIRequest:    apiRequest,
-		BodyBytes:     bodyBytes,
-		DropHeaders:   dropHeaders,
-		InjectHeaders: injectHeaders,
-		Auth:          auth,
-		ControlPath:   controlPath,
-		IsHardError:   isHardError,
-		UseMock:       config.MockMode || configModel.IncludePathOnMockMode(controlPath, config),
-		TxnConfig:     txnConfig,
+		Config:         config,
+		NewReq:         newReq,
+		APIRequest:     apiRequest,
+		BodyBytes:      bodyBytes,
+		UpstreamBody:   upstreamBody,
+		DropHeaders:    dropHeaders,
+		InjectHeaders:  injectHeaders,
+		Auth:           auth,
+		ControlPath:    controlPath,
+		IsHardError:    isHardError,
+		UseMock:        useMock,
+		FallbackToMock: configModel.IncludePathOnMockFallback(controlPath, config),
+		Mirror:         effectiveMirror(config, pathConfig),
+		Resilience:     effectiveResilience(config, pathConfig),
+		Transforms:     pathTransforms(pathConfig),
+		Transform:      transformRecord,
+		Script:         scripted,
+		ScriptResponse: scriptResponse,
+		TxnConfig:      txnConfig,
 	}
 }
 
@@ -134,19 +195,8 @@ func (ws *WiretapService) getHeadersAndAuth(config *shared.WiretapConfiguration,
 	}
 
 	// now add path specific headers.
-	matchedPaths := configModel.FindPaths(request.HttpRequest.URL.Path, config)
 	auth := ""
-	if len(matchedPaths) > 0 {
-		var matchedPath *shared.WiretapPathConfig
-
-		// First check if we have a path matching our RewriteId
-		matchedPath = configModel.FindPathWithRewriteId(matchedPaths, request.HttpRequest)
-
-		// Get the first matched value in the list, if we don't have a rewriteId that fits
-		if matchedPath == nil {
-			matchedPath = matchedPaths[0]
-		}
-
+	if matchedPath := matchedPathConfig(config, request.HttpRequest); matchedPath != nil {
 		auth = matchedPath.Auth
 		if matchedPath.Headers != nil {
 			dropHeaders = append(dropHeaders, matchedPath.Headers.DropHeaders...)
@@ -168,6 +218,23 @@ func (ws *WiretapService) getHeadersAndAuth(config *shared.WiretapConfiguration,
 	return dropHeaders, injectHeaders, auth
 }
 
+// matchedPathConfig returns the path config for a request, the one matching its rewrite id, or else the first that
+// matches its path.
+func matchedPathConfig(config *shared.WiretapConfiguration, request *http.Request) *shared.WiretapPathConfig {
+	matchedPaths := configModel.FindPathsForRequest(request.URL.Path, request, config)
+	if len(matchedPaths) == 0 {
+		return nil
+	}
+
+	// First check if we have a path matching our RewriteId
+	if matchedPath := configModel.FindPathWithRewriteId(matchedPaths, request); matchedPath != nil {
+		return matchedPath
+	}
+
+	// Get the first matched value in the list, if we don't have a rewriteId that fits
+	return matchedPaths[0]
+}
+
 func prepareRequestURLs(
 	newReq *http.Request,
 	apiRequest *http.Request,
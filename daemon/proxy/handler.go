@@ -9,17 +9,44 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/pb33f/ranch/model"
 	configModel "github.com/pb33f/wiretap/config"
 	"github.com/pb33f/wiretap/daemon/problems"
 	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/sse"
+	"github.com/pb33f/wiretap/transaction"
 )
 
 type APICaller func(*http.Request, ...*shared.WiretapConfiguration) (*http.Response, error)
 type ResponseErrorBroadcaster func(*http.Response, error)
 
+// ValidationSkipRecorder records a response that was streamed to the client without validation, and why.
+type ValidationSkipRecorder func(response *http.Response, body []byte, reason string)
+
+// EventRecorder records each server-sent event of a streamed response as it arrives, returning true if the event was
+// validated against the contract.
+type EventRecorder func(response *http.Response, event *sse.Event) bool
+
+// ResponseMirror compares a response with the response of a shadow upstream, after it has been sent to the client.
+type ResponseMirror func(response *http.Response, body []byte)
+
+// ResponseTransformer returns the body to send the client in place of the body of an upstream response.
+type ResponseTransformer func(response *http.Response, body []byte) []byte
+
+// MirrorSkipRecorder records a response that was streamed to the client without being mirrored, and why.
+type MirrorSkipRecorder func(reason string)
+
+// TransformSkipRecorder records a response that was streamed to the client without being transformed, and why.
+type TransformSkipRecorder func(reason string)
+
+// UnauthorizedResponder returns the response refusing a request that failed its security requirements, or nil if the
+// request is not refused.
+type UnauthorizedResponder func(requestErrors []*shared.WiretapValidationError) *http.Response
+
 // Validator returns errors for hard validation; soft validation intentionally
 // discards the returned slice after the validator records any side effects.
 type Validator interface {
@@ -37,11 +64,25 @@ type PreparedRequest struct {
 	CallAPI                APICaller
 	Validator              Validator
 	BroadcastResponseError ResponseErrorBroadcaster
+	SkipResponseValidation ValidationSkipRecorder
+	RecordEvent            EventRecorder
+	MirrorResponse         ResponseMirror
+	SkipMirror             MirrorSkipRecorder
+	Resilience             *shared.WiretapResilienceConfig
+	MockFallback           MockFallback
+	RecordResilience       ResilienceRecorder
+	FallbackToMock         bool
+	RecordMockFallback     MockFallbackRecorder
+	TransformResponse      ResponseTransformer
+	SkipResponseTransform  TransformSkipRecorder
+	BeforeClientWrite      ResponseTransformer
+	RespondUnauthorized    UnauthorizedResponder
 }
 
 type Handler struct {
 	transport     http.RoundTripper
 	validationSem chan struct{}
+	breakers      sync.Map // upstream host -> *circuitBreaker
 }
 
 const defaultValidationConcurrency = 8
@@ -69,6 +110,17 @@ func (h *Handler) Handle(request *model.Request, prep *PreparedRequest) {
 			fmt.Sprintf("Request on validation ignored path: %s ; skipping validation", controlPath))
 	} else if prep.IsHardError {
 		requestErrors = prep.validateRequest()
+		// requests failing their security requirements may be refused, without calling the upstream.
+		if prep.RespondUnauthorized != nil {
+			if refused := prep.RespondUnauthorized(requestErrors); refused != nil {
+				body, _ := io.ReadAll(refused.Body)
+				_ = refused.Body.Close()
+				config.Logger.Info("[wiretap] request refused, security requirements not met", "url",
+					request.HttpRequest.URL.String(), "code", refused.StatusCode)
+				writeResponse(request.HttpResponseWriter, refused.StatusCode, refused.Header, body)
+				return
+			}
+		}
 	} else {
 		h.runValidationAsync(config, "request", func() {
 			_ = prep.validateRequest()
@@ -79,7 +131,30 @@ func (h *Handler) Handle(request *model.Request, prep *PreparedRequest) {
 	if callAPI == nil {
 		callAPI = h.callAPI
 	}
-	returnedResponse, returnedError := callAPI(prep.APIRequest, config)
+	var returnedResponse *http.Response
+	var returnedError error
+	if prep.Resilience != nil {
+		var record *transaction.ResilienceRecord
+		returnedResponse, record, returnedError = h.callWithResilience(callAPI, prep)
+		if returnedResponse == nil && returnedError == nil {
+			config.Logger.Info("[wiretap] upstream circuit open", "url", prep.APIRequest.URL.String())
+			response, body := h.writeFallback(request.HttpResponseWriter, prep, record)
+			if prep.RecordResilience != nil {
+				go prep.RecordResilience(response, body, record)
+			}
+			return
+		}
+		if prep.RecordResilience != nil {
+			go prep.RecordResilience(nil, nil, record)
+		}
+	} else {
+		returnedResponse, returnedError = callAPI(prep.APIRequest, config)
+	}
+
+	// a failed upstream may be answered with a mock, leaving its failure out of the transaction.
+	if prep.FallbackToMock && h.fallBackToMock(request, prep, returnedResponse, returnedError) {
+		return
+	}
 
 	if returnedResponse == nil && returnedError != nil {
 		config.Logger.Info("[wiretap] request failed", "url", prep.APIRequest.URL.String(), "code", 500,
@@ -91,25 +166,61 @@ func (h *Handler) Handle(request *model.Request, prep *PreparedRequest) {
 		return
 	}
 
-	respBody, _ := io.ReadAll(returnedResponse.Body)
-	_ = returnedResponse.Body.Close()
-	returnedResponse.Body = io.NopCloser(bytes.NewBuffer(respBody))
-
-	if prep.IsHardError {
+	// streamed responses are validated (or skipped) once they have been written to the client. JSON responses of
+	// unknown length are read when they are to be transformed, so they are only streamed when too large.
+	transformable := prep.TransformResponse != nil && isJSONResponse(returnedResponse)
+	respBody, stream := readResponse(returnedResponse, config, prep.IsHardError || transformable)
+	upstreamBody := respBody
+	switch {
+	case prep.TransformResponse == nil:
+	case !stream:
+		respBody = prep.TransformResponse(returnedResponse, respBody)
+		setContentLength(returnedResponse, len(respBody))
+	case prep.SkipResponseTransform != nil:
+		reason := transaction.ValidationSkippedStreaming
+		if transformable && !isStreamingResponse(returnedResponse) {
+			reason = transaction.ValidationSkippedTooLarge
+		}
+		go prep.SkipResponseTransform(reason)
+	}
+	switch {
+	case stream:
+	case prep.IsHardError:
+		_ = returnedResponse.Body.Close()
+		returnedResponse.Body = io.NopCloser(bytes.NewBuffer(respBody))
 		responseErrors = prep.validateResponse(returnedResponse, respBody)
-	} else {
+	default:
+		_ = returnedResponse.Body.Close()
+		returnedResponse.Body = io.NopCloser(bytes.NewBuffer(respBody))
 		// Clone headers for async validation; http.Header is a map and the main
 		// goroutine continues to read and rewrite returnedResponse.Header below.
+		validatedBody := respBody
 		clonedResp := &http.Response{
 			StatusCode: returnedResponse.StatusCode,
 			Header:     returnedResponse.Header.Clone(),
-			Body:       io.NopCloser(bytes.NewBuffer(respBody)),
+			Body:       io.NopCloser(bytes.NewBuffer(validatedBody)),
 		}
 		h.runValidationAsync(config, "response", func() {
-			_ = prep.validateResponse(clonedResp, respBody)
+			_ = prep.validateResponse(clonedResp, validatedBody)
 		})
 	}
 
+	// streamed responses are mirrored once they have been written to the client. The shadow is compared with the body
+	// the upstream responded with, before any transforms.
+	if !stream && prep.MirrorResponse != nil {
+		mirroredResp := &http.Response{
+			StatusCode: returnedResponse.StatusCode,
+			Header:     returnedResponse.Header.Clone(),
+		}
+		go prep.MirrorResponse(mirroredResp, upstreamBody)
+	}
+
+	// the validated response may be changed one last time, before it is written to the client.
+	if !stream && prep.BeforeClientWrite != nil {
+		respBody = prep.BeforeClientWrite(returnedResponse, respBody)
+		setContentLength(returnedResponse, len(respBody))
+	}
+
 	delay := configModel.FindPathDelay(request.HttpRequest.URL.Path, config)
 	if delay > 0 {
 		time.Sleep(time.Duration(delay) * time.Millisecond)
@@ -139,6 +250,9 @@ func (h *Handler) Handle(request *model.Request, prep *PreparedRequest) {
 	statusCode := problems.PickHardErrorStatus(prep.IsHardError, requestErrors, responseErrors, config, returnedResponse.StatusCode)
 
 	if prep.IsHardError && problems.ShouldReturnValidationProblem(config, requestErrors, responseErrors) {
+		if stream {
+			_ = returnedResponse.Body.Close()
+		}
 		problems.WriteValidationProblemResponse(
 			request.HttpResponseWriter,
 			statusCode,
@@ -149,6 +263,11 @@ func (h *Handler) Handle(request *model.Request, prep *PreparedRequest) {
 		return
 	}
 
+	if stream {
+		h.streamResponse(request.HttpResponseWriter, statusCode, returnedResponse, respBody, prep)
+		return
+	}
+
 	request.HttpResponseWriter.WriteHeader(statusCode)
 	_, _ = request.HttpResponseWriter.Write(respBody)
 }
@@ -202,6 +321,14 @@ func (h *Handler) runValidationAsync(config *shared.WiretapConfiguration, phase
 	}
 }
 
+// setContentLength updates the length of a response, and its Content-Length header when it has one.
+func setContentLength(response *http.Response, length int) {
+	response.ContentLength = int64(length)
+	if response.Header.Get("Content-Length") != "" {
+		response.Header.Set("Content-Length", strconv.Itoa(length))
+	}
+}
+
 func extractHeaders(resp *http.Response) map[string][]string {
 	headers := make(map[string][]string)
 	for k, v := range resp.Header {
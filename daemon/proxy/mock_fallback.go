@@ -0,0 +1,64 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package proxy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/wiretap/shared"
+)
+
+// MockFallbackRecorder records a response mocked in place of a failed upstream response, and why the upstream failed.
+type MockFallbackRecorder func(response *http.Response, body []byte, reason string)
+
+// mockFallbackReason returns why an upstream call failed badly enough to answer it with a mock, or an empty string if
+// it did not. Errors (including timeouts) always fail, as do the configured status codes, or else a 404 or any 5xx.
+func mockFallbackReason(config *shared.WiretapConfiguration, response *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	if response == nil {
+		return ""
+	}
+	failed := response.StatusCode == http.StatusNotFound || response.StatusCode >= http.StatusInternalServerError
+	if len(config.MockFallbackStatusCodes) > 0 {
+		failed = slices.Contains(config.MockFallbackStatusCodes, response.StatusCode)
+	}
+	if !failed {
+		return ""
+	}
+	return fmt.Sprintf("upstream responded %d", response.StatusCode)
+}
+
+// fallBackToMock answers a request with a mock from the specification when its upstream call failed, returning true
+// if it did. The failed response is discarded. When no mock can be generated, the failure is passed on unchanged.
+func (h *Handler) fallBackToMock(request *model.Request, prep *PreparedRequest, response *http.Response, err error) bool {
+	reason := mockFallbackReason(prep.Config, response, err)
+	if reason == "" || prep.MockFallback == nil {
+		return false
+	}
+	mocked, mockErr := prep.MockFallback()
+	if mockErr != nil || mocked == nil {
+		prep.Config.Logger.Warn("[wiretap] unable to mock a failed upstream response", "url",
+			request.HttpRequest.URL.String(), "reason", reason, "error", fmt.Sprint(mockErr))
+		return false
+	}
+	if response != nil {
+		_ = response.Body.Close()
+	}
+
+	body, _ := io.ReadAll(mocked.Body)
+	_ = mocked.Body.Close()
+	prep.Config.Logger.Info("[wiretap] upstream failed, answered with a mock", "url", request.HttpRequest.URL.String(),
+		"reason", reason, "code", mocked.StatusCode)
+	writeResponse(request.HttpResponseWriter, mocked.StatusCode, mocked.Header, body)
+	if prep.RecordMockFallback != nil {
+		go prep.RecordMockFallback(mocked, body, reason)
+	}
+	return true
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package proxy
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMockFallbackReason(t *testing.T) {
+	config := testConfig()
+	assert.Equal(t, "connection refused", mockFallbackReason(config, nil, errors.New("connection refused")))
+	assert.Equal(t, "upstream responded 404", mockFallbackReason(config, &http.Response{StatusCode: 404}, nil))
+	assert.Equal(t, "upstream responded 502", mockFallbackReason(config, &http.Response{StatusCode: 502}, nil))
+	assert.Empty(t, mockFallbackReason(config, &http.Response{StatusCode: 400}, nil))
+
+	// configured status codes replace the defaults.
+	config.MockFallbackStatusCodes = []int{http.StatusTooManyRequests}
+	assert.Equal(t, "upstream responded 429", mockFallbackReason(config, &http.Response{StatusCode: 429}, nil))
+	assert.Empty(t, mockFallbackReason(config, &http.Response{StatusCode: 502}, nil))
+}
+
+func TestHandlerFallsBackToMocks(t *testing.T) {
+	send := func(status int, mock MockFallback) (*httptest.ResponseRecorder, string) {
+		id := uuid.New()
+		rec := httptest.NewRecorder()
+		reasons := make(chan string, 1)
+		NewHandler().Handle(&model.Request{
+			Id:                 &id,
+			HttpRequest:        httptest.NewRequest(http.MethodGet, "http://wiretap.local/pets", nil),
+			HttpResponseWriter: rec,
+		}, &PreparedRequest{
+			Config:     testConfig(),
+			APIRequest: httptest.NewRequest(http.MethodGet, "http://upstream.local/pets", nil),
+			CallAPI: func(_ *http.Request, _ ...*shared.WiretapConfiguration) (*http.Response, error) {
+				return &http.Response{StatusCode: status, Header: http.Header{},
+					Body: io.NopCloser(strings.NewReader("upstream"))}, nil
+			},
+			FallbackToMock:         true,
+			MockFallback:           mock,
+			BroadcastResponseError: func(_ *http.Response, _ error) {},
+			RecordMockFallback: func(_ *http.Response, _ []byte, reason string) {
+				reasons <- reason
+			},
+		})
+		select {
+		case reason := <-reasons:
+			return rec, reason
+		case <-time.After(100 * time.Millisecond):
+			return rec, ""
+		}
+	}
+	mock := func() (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Content-Type": {"application/json"}},
+			Body: io.NopCloser(strings.NewReader(`{"name":"mocked"}`))}, nil
+	}
+
+	rec, reason := send(http.StatusServiceUnavailable, mock)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"name":"mocked"}`, rec.Body.String())
+	assert.Equal(t, "upstream responded 503", reason)
+
+	// healthy responses are not mocked.
+	rec, reason = send(http.StatusCreated, mock)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "upstream", rec.Body.String())
+	assert.Empty(t, reason)
+
+	// failures that cannot be mocked are passed on.
+	rec, reason = send(http.StatusNotFound, func() (*http.Response, error) { return nil, errors.New("no spec") })
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "upstream", rec.Body.String())
+	assert.Empty(t, reason)
+}
@@ -0,0 +1,287 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pb33f/wiretap/daemon/problems"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/transaction"
+)
+
+const (
+	defaultRetryBackoff     = 100 * time.Millisecond
+	defaultFailureThreshold = 5
+	defaultOpenDuration     = 30 * time.Second
+	// retryDrainLimit caps how much of a response that is retried is read, so its connection can be reused.
+	retryDrainLimit = 64 << 10
+)
+
+var defaultRetryStatusCodes = []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+// idempotentMethods are the only methods that are retried, sending them twice has the same effect as sending them once.
+var idempotentMethods = []string{http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete,
+	http.MethodTrace}
+
+// MockFallback mocks the response to a request from the specification, in place of the response of its upstream.
+type MockFallback func() (*http.Response, error)
+
+// ResilienceRecorder records how a request was sent under a resilience policy. The response and its body are only
+// set when the circuit was open and the request was answered with a fallback instead of being sent upstream.
+type ResilienceRecorder func(response *http.Response, body []byte, record *transaction.ResilienceRecord)
+
+// circuitBreaker tracks the failures of one upstream host.
+type circuitBreaker struct {
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// allow returns the state of the circuit, and whether a request may be sent upstream. Once the circuit has been open
+// long enough, it is half-open and lets a single request through to probe the upstream.
+func (b *circuitBreaker) allow(config *shared.WiretapCircuitBreakerConfig, now time.Time) (string, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case transaction.BreakerOpen:
+		if now.Sub(b.openedAt) < openDuration(config) {
+			return b.state, false
+		}
+		b.state = transaction.BreakerHalfOpen
+		b.probing = true
+		return b.state, true
+	case transaction.BreakerHalfOpen:
+		if b.probing {
+			return b.state, false
+		}
+		b.probing = true
+		return b.state, true
+	default:
+		return transaction.BreakerClosed, true
+	}
+}
+
+// record closes the circuit after a success, and opens it after a failed probe or too many failures in a row.
+func (b *circuitBreaker) record(config *shared.WiretapCircuitBreakerConfig, failed bool, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if !failed {
+		b.state = transaction.BreakerClosed
+		b.failures = 0
+		return
+	}
+	b.failures++
+	threshold := config.FailureThreshold
+	if threshold <= 0 {
+		threshold = defaultFailureThreshold
+	}
+	if b.state == transaction.BreakerHalfOpen || b.failures >= threshold {
+		b.state = transaction.BreakerOpen
+		b.openedAt = now
+	}
+}
+
+// release lets another request probe a half-open circuit, when the probe ended without telling whether the upstream
+// recovered, like a request cancelled by its client.
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// retryAfter returns how long until the circuit lets a probe through.
+func (b *circuitBreaker) retryAfter(config *shared.WiretapCircuitBreakerConfig, now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return max(openDuration(config)-now.Sub(b.openedAt), 0)
+}
+
+func openDuration(config *shared.WiretapCircuitBreakerConfig) time.Duration {
+	if config.OpenDuration <= 0 {
+		return defaultOpenDuration
+	}
+	return time.Duration(config.OpenDuration) * time.Millisecond
+}
+
+func (h *Handler) breakerFor(host string) *circuitBreaker {
+	breaker, _ := h.breakers.LoadOrStore(host, &circuitBreaker{state: transaction.BreakerClosed})
+	return breaker.(*circuitBreaker)
+}
+
+// callWithResilience calls the upstream API under the resilience policy of the request. It returns a nil response
+// and error when the circuit to the upstream is open, and the request must be answered with a fallback.
+func (h *Handler) callWithResilience(callAPI APICaller, prep *PreparedRequest) (*http.Response, *transaction.ResilienceRecord, error) {
+	policy := prep.Resilience
+	record := &transaction.ResilienceRecord{}
+
+	var breaker *circuitBreaker
+	if policy.CircuitBreaker != nil {
+		breaker = h.breakerFor(prep.APIRequest.URL.Host)
+		state, allowed := breaker.allow(policy.CircuitBreaker, time.Now())
+		record.Breaker = state
+		if !allowed {
+			record.Breaker = transaction.BreakerOpen
+			return nil, record, nil
+		}
+		defer breaker.release()
+	}
+
+	attempts := 1
+	if slices.Contains(idempotentMethods, prep.APIRequest.Method) {
+		attempts += max(policy.Retries, 0)
+	}
+	retryStatusCodes := policy.RetryStatusCodes
+	if len(retryStatusCodes) == 0 {
+		retryStatusCodes = defaultRetryStatusCodes
+	}
+	backoff := defaultRetryBackoff
+	if policy.RetryBackoff > 0 {
+		backoff = time.Duration(policy.RetryBackoff) * time.Millisecond
+	}
+
+	var response *http.Response
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-prep.APIRequest.Context().Done():
+				return nil, record, prep.APIRequest.Context().Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+		record.Attempts = attempt
+		response, err = h.callAttempt(callAPI, prep, attempt)
+		if err != nil {
+			record.Failures = append(record.Failures, err.Error())
+			continue
+		}
+		if !slices.Contains(retryStatusCodes, response.StatusCode) {
+			break
+		}
+		record.Failures = append(record.Failures, fmt.Sprintf("upstream responded %d", response.StatusCode))
+		if attempt < attempts {
+			_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, retryDrainLimit))
+			_ = response.Body.Close()
+		}
+	}
+
+	// a request cancelled by its client says nothing of the upstream.
+	if breaker != nil && prep.APIRequest.Context().Err() == nil {
+		breaker.record(policy.CircuitBreaker, err != nil || response.StatusCode >= http.StatusInternalServerError, time.Now())
+	}
+	return response, record, err
+}
+
+// callAttempt sends one attempt of a request upstream, with its own copy of the body and its own timeouts. The
+// response timeout is stopped once the headers arrive; the attempt is only cancelled when its body is closed.
+func (h *Handler) callAttempt(callAPI APICaller, prep *PreparedRequest, attempt int) (*http.Response, error) {
+	policy := prep.Resilience
+	ctx, cancel := context.WithCancelCause(prep.APIRequest.Context())
+
+	var timers []*time.Timer
+	if policy.ConnectTimeout > 0 {
+		timeout := time.Duration(policy.ConnectTimeout) * time.Millisecond
+		connectTimer := time.AfterFunc(timeout, func() {
+			cancel(fmt.Errorf("upstream connect timeout after %s", timeout))
+		})
+		timers = append(timers, connectTimer)
+		ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+			GotConn: func(httptrace.GotConnInfo) { connectTimer.Stop() },
+		})
+	}
+	if policy.ResponseTimeout > 0 {
+		timeout := time.Duration(policy.ResponseTimeout) * time.Millisecond
+		timers = append(timers, time.AfterFunc(timeout, func() {
+			cancel(fmt.Errorf("upstream response timeout after %s", timeout))
+		}))
+	}
+
+	request := prep.APIRequest.Clone(ctx)
+	if attempt > 1 && request.Body != nil && request.Body != http.NoBody {
+		request.Body = io.NopCloser(bytes.NewReader(prep.BodyBytes))
+	}
+
+	response, err := callAPI(request, prep.Config)
+	for _, timer := range timers {
+		timer.Stop()
+	}
+	if err != nil {
+		// report the timeout, rather than the cancelled context it caused.
+		if cause := context.Cause(ctx); cause != nil && !errors.Is(cause, context.Canceled) &&
+			prep.APIRequest.Context().Err() == nil {
+			err = cause
+		}
+		cancel(nil)
+		return nil, err
+	}
+	response.Body = &cancelOnClose{ReadCloser: response.Body, cancel: func() { cancel(nil) }}
+	return response, nil
+}
+
+// cancelOnClose cancels the context of an attempt once its response body has been read and closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel func()
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// writeFallback answers a request to an open circuit, with a mocked response when the policy asks for one and the
+// specification can mock it, or else with a problem+json 503.
+func (h *Handler) writeFallback(w http.ResponseWriter, prep *PreparedRequest, record *transaction.ResilienceRecord) (*http.Response, []byte) {
+	breakerConfig := prep.Resilience.CircuitBreaker
+	if breakerConfig.Fallback == shared.ResilienceFallbackMock && prep.MockFallback != nil {
+		if response, err := prep.MockFallback(); err == nil && response != nil {
+			body, _ := io.ReadAll(response.Body)
+			_ = response.Body.Close()
+			record.Fallback = shared.ResilienceFallbackMock
+			writeResponse(w, response.StatusCode, response.Header, body)
+			return response, body
+		}
+	}
+
+	record.Fallback = shared.ResilienceFallbackProblem
+	host := prep.APIRequest.URL.Host
+	retryAfter := h.breakerFor(host).retryAfter(breakerConfig, time.Now())
+	body := shared.MarshalError(shared.GenerateError("Upstream circuit open", http.StatusServiceUnavailable,
+		fmt.Sprintf("requests to '%s' failed repeatedly, they are not sent upstream until the circuit closes", host),
+		prepControlPath(prep), nil))
+	header := http.Header{
+		"Content-Type": {problems.JSONContentType},
+		"Retry-After":  {strconv.Itoa(int(retryAfter.Round(time.Second).Seconds()))},
+	}
+	writeResponse(w, http.StatusServiceUnavailable, header, body)
+	return &http.Response{StatusCode: http.StatusServiceUnavailable, Header: header}, body
+}
+
+func writeResponse(w http.ResponseWriter, statusCode int, header http.Header, body []byte) {
+	headers := extractHeaders(&http.Response{Header: header})
+	shared.SetCORSHeaders(headers)
+	for k, v := range headers {
+		for _, j := range v {
+			w.Header().Add(k, j)
+		}
+	}
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(body)
+}
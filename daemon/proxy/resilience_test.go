@@ -0,0 +1,217 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/wiretap/daemon/problems"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resilientRequest proxies one request to an upstream under a resilience policy, and returns the response written
+// to the client along with the recorded resilience.
+func resilientRequest(h *Handler, method, upstream string, policy *shared.WiretapResilienceConfig,
+	mockFallback MockFallback) (*httptest.ResponseRecorder, *transaction.ResilienceRecord) {
+	id := uuid.New()
+	rec := httptest.NewRecorder()
+	records := make(chan *transaction.ResilienceRecord, 1)
+	body := []byte(`{"name":"pb33f"}`)
+	apiRequest, _ := http.NewRequest(method, upstream+"/pets", bytes.NewReader(body))
+	h.Handle(&model.Request{
+		Id:                 &id,
+		HttpRequest:        httptest.NewRequest(method, "http://wiretap.local/pets", bytes.NewReader(body)),
+		HttpResponseWriter: rec,
+	}, &PreparedRequest{
+		Config:                 testConfig(),
+		APIRequest:             apiRequest,
+		BodyBytes:              body,
+		Resilience:             policy,
+		MockFallback:           mockFallback,
+		BroadcastResponseError: func(_ *http.Response, _ error) {},
+		RecordResilience: func(_ *http.Response, _ []byte, record *transaction.ResilienceRecord) {
+			records <- record
+		},
+	})
+	select {
+	case record := <-records:
+		return rec, record
+	case <-time.After(5 * time.Second):
+		return rec, nil
+	}
+}
+
+func TestHandlerRetriesIdempotentRequests(t *testing.T) {
+	var calls atomic.Int32
+	var bodies []string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer upstream.Close()
+
+	policy := &shared.WiretapResilienceConfig{Retries: 3, RetryBackoff: 1}
+	rec, record := resilientRequest(NewHandler(), http.MethodPut, upstream.URL, policy, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "ok", rec.Body.String())
+	assert.Equal(t, &transaction.ResilienceRecord{
+		Attempts: 3,
+		Failures: []string{"upstream responded 503", "upstream responded 503"},
+	}, record)
+	// every attempt sends the whole body.
+	assert.Equal(t, []string{`{"name":"pb33f"}`, `{"name":"pb33f"}`, `{"name":"pb33f"}`}, bodies)
+
+	// requests that are not idempotent are sent once.
+	calls.Store(0)
+	rec, record = resilientRequest(NewHandler(), http.MethodPost, upstream.URL, policy, nil)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, 1, record.Attempts)
+
+	// as are responses with other status codes.
+	calls.Store(0)
+	policy.RetryStatusCodes = []int{http.StatusTooManyRequests}
+	rec, record = resilientRequest(NewHandler(), http.MethodGet, upstream.URL, policy, nil)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, 1, record.Attempts)
+}
+
+func TestHandlerTimesOutHungUpstreams(t *testing.T) {
+	release := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer upstream.Close()
+	defer close(release)
+
+	started := time.Now()
+	rec, record := resilientRequest(NewHandler(), http.MethodGet, upstream.URL,
+		&shared.WiretapResilienceConfig{ResponseTimeout: 50, Retries: 1, RetryBackoff: 1}, nil)
+	assert.Less(t, time.Since(started), 5*time.Second)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Contains(t, rec.Body.String(), "upstream response timeout after 50ms")
+	assert.Equal(t, &transaction.ResilienceRecord{
+		Attempts: 2,
+		Failures: []string{"upstream response timeout after 50ms", "upstream response timeout after 50ms"},
+	}, record)
+}
+
+func TestHandlerOpensTheCircuitAfterRepeatedFailures(t *testing.T) {
+	var healthy atomic.Bool
+	var calls atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer upstream.Close()
+
+	h := NewHandler()
+	policy := &shared.WiretapResilienceConfig{CircuitBreaker: &shared.WiretapCircuitBreakerConfig{
+		FailureThreshold: 2,
+		OpenDuration:     100,
+	}}
+	for range 2 {
+		rec, record := resilientRequest(h, http.MethodGet, upstream.URL, policy, nil)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, transaction.BreakerClosed, record.Breaker)
+	}
+
+	// the open circuit answers with a problem, without calling the upstream.
+	rec, record := resilientRequest(h, http.MethodGet, upstream.URL, policy, nil)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, problems.JSONContentType, rec.Header().Get("Content-Type"))
+	assert.Equal(t, "0", rec.Header().Get("Retry-After"))
+	assert.Contains(t, rec.Body.String(), "Upstream circuit open")
+	assert.Equal(t, &transaction.ResilienceRecord{Breaker: transaction.BreakerOpen,
+		Fallback: shared.ResilienceFallbackProblem}, record)
+	assert.Equal(t, int32(2), calls.Load())
+
+	// once open long enough, a probe that succeeds closes the circuit.
+	time.Sleep(150 * time.Millisecond)
+	healthy.Store(true)
+	rec, record = resilientRequest(h, http.MethodGet, upstream.URL, policy, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, transaction.BreakerHalfOpen, record.Breaker)
+	_, record = resilientRequest(h, http.MethodGet, upstream.URL, policy, nil)
+	assert.Equal(t, transaction.BreakerClosed, record.Breaker)
+}
+
+func TestHandlerAnswersOpenCircuitsWithMocks(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer upstream.Close()
+
+	h := NewHandler()
+	policy := &shared.WiretapResilienceConfig{CircuitBreaker: &shared.WiretapCircuitBreakerConfig{
+		FailureThreshold: 1,
+		Fallback:         shared.ResilienceFallbackMock,
+	}}
+	mock := func() (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"name":"mocked"}`)),
+		}, nil
+	}
+	rec, _ := resilientRequest(h, http.MethodGet, upstream.URL, policy, mock)
+	require.Equal(t, http.StatusBadGateway, rec.Code)
+
+	rec, record := resilientRequest(h, http.MethodGet, upstream.URL, policy, mock)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"name":"mocked"}`, rec.Body.String())
+	assert.Equal(t, shared.ResilienceFallbackMock, record.Fallback)
+}
+
+func TestHandlerReleasesProbesCancelledByTheClient(t *testing.T) {
+	h := NewHandler()
+	breaker := h.breakerFor("upstream.local")
+	breaker.state = transaction.BreakerOpen
+	breaker.openedAt = time.Now().Add(-time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	apiRequest, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://upstream.local/pets", nil)
+	unavailable := func(_ *http.Request, _ ...*shared.WiretapConfiguration) (*http.Response, error) {
+		time.AfterFunc(10*time.Millisecond, cancel)
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader(""))}, nil
+	}
+	_, record, err := h.callWithResilience(unavailable, &PreparedRequest{
+		Config:     testConfig(),
+		APIRequest: apiRequest,
+		Resilience: &shared.WiretapResilienceConfig{Retries: 1, RetryBackoff: 5000,
+			CircuitBreaker: &shared.WiretapCircuitBreakerConfig{}},
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, transaction.BreakerHalfOpen, record.Breaker)
+
+	// the client gave up during the backoff, the next request probes the upstream instead.
+	state, allowed := breaker.allow(&shared.WiretapCircuitBreakerConfig{}, time.Now())
+	assert.Equal(t, transaction.BreakerHalfOpen, state)
+	assert.True(t, allowed)
+}
@@ -0,0 +1,194 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package proxy
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/sse"
+	"github.com/pb33f/wiretap/transaction"
+)
+
+const streamChunkSize = 32 * 1024
+
+// streamingMediaTypes are long-lived responses, written as they arrive and never validated as a whole.
+var streamingMediaTypes = map[string]bool{
+	"text/event-stream":         true,
+	"application/x-ndjson":      true,
+	"application/ndjson":        true,
+	"application/jsonl":         true,
+	"application/stream+json":   true,
+	"application/json-seq":      true,
+	"multipart/x-mixed-replace": true,
+}
+
+func isStreamingResponse(response *http.Response) bool {
+	mediaType, _, err := mime.ParseMediaType(response.Header.Get("Content-Type"))
+	return err == nil && streamingMediaTypes[mediaType]
+}
+
+// isJSONResponse returns true for responses with a JSON body, that is not a stream of JSON values.
+func isJSONResponse(response *http.Response) bool {
+	mediaType, _, err := mime.ParseMediaType(response.Header.Get("Content-Type"))
+	return err == nil && !streamingMediaTypes[mediaType] &&
+		(mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
+
+// readResponse reads the upstream response body, when it can be validated before it's written to the client. Streaming
+// responses, responses larger than the maximum validated body size and (unless readUnknownLength is set) responses of
+// unknown length are streamed instead; stream is true, and body holds whatever was read before the decision was made.
+func readResponse(response *http.Response, config *shared.WiretapConfiguration, readUnknownLength bool) (body []byte,
+	stream bool) {
+	maxBody := config.GetMaxValidatedBodySize()
+	if isStreamingResponse(response) || response.ContentLength > maxBody ||
+		(!readUnknownLength && response.ContentLength < 0) {
+		return nil, true
+	}
+	body, _ = io.ReadAll(io.LimitReader(response.Body, maxBody+1))
+	return body, int64(len(body)) > maxBody
+}
+
+// boundedBuffer keeps the first max bytes written to it, and remembers if anything was dropped.
+type boundedBuffer struct {
+	buf        bytes.Buffer
+	max        int64
+	overflowed bool
+}
+
+func (b *boundedBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	remaining := b.max - int64(b.buf.Len())
+	if int64(n) > remaining {
+		b.overflowed = true
+		p = p[:max(remaining, 0)]
+	}
+	b.buf.Write(p)
+	return n, nil
+}
+
+// streamResponse copies the upstream response to the client as it arrives, flushing each chunk. A bounded copy of the
+// body is tee'd off for validation and mirroring; if the body outgrew it, or the response is a stream, both are skipped
+// and the transaction is marked with the reason. Server-sent events are parsed from the stream, and recorded one at a
+// time.
+func (h *Handler) streamResponse(w http.ResponseWriter, statusCode int, response *http.Response, prefix []byte,
+	prep *PreparedRequest) {
+
+	config := prep.Config
+	defer response.Body.Close()
+
+	// the headers are cloned; validation runs after the handler returns.
+	clonedResp := &http.Response{
+		StatusCode: response.StatusCode,
+		Header:     response.Header.Clone(),
+	}
+
+	var events *sse.Parser
+	if prep.RecordEvent != nil && sse.IsEventStream(response.Header.Get("Content-Type")) {
+		events = sse.NewParser()
+	}
+	validatedEvents := 0
+
+	tee := &boundedBuffer{max: config.GetMaxValidatedBodySize()}
+	controller := http.NewResponseController(w)
+
+	w.WriteHeader(statusCode)
+	_ = controller.Flush()
+
+	write := func(p []byte) error {
+		_, _ = tee.Write(p)
+		if _, err := w.Write(p); err != nil {
+			return err
+		}
+		_ = controller.Flush()
+		if events != nil {
+			for _, event := range events.Feed(p) {
+				if prep.RecordEvent(clonedResp, event) {
+					validatedEvents++
+				}
+			}
+		}
+		return nil
+	}
+
+	var copyErr error
+	if len(prefix) > 0 {
+		copyErr = write(prefix)
+	}
+
+	chunk := make([]byte, streamChunkSize)
+	for copyErr == nil {
+		n, readErr := response.Body.Read(chunk)
+		if n > 0 {
+			if copyErr = write(chunk[:n]); copyErr != nil {
+				break
+			}
+		}
+		if readErr != nil {
+			if !errors.Is(readErr, io.EOF) {
+				copyErr = readErr
+			}
+			break
+		}
+	}
+
+	capturedBody := tee.buf.Bytes()
+	clonedResp.Body = io.NopCloser(bytes.NewReader(capturedBody))
+
+	if copyErr == nil {
+		h.mirrorStreamedResponse(response, capturedBody, tee.overflowed, prep)
+	}
+
+	switch {
+	case copyErr != nil:
+		config.Logger.Warn("[wiretap] streamed response interrupted", "error", copyErr.Error())
+		if prep.BroadcastResponseError != nil {
+			go prep.BroadcastResponseError(clonedResp, copyErr)
+		}
+	case validatedEvents > 0:
+		// each event has been validated as it arrived.
+	case isStreamingResponse(response):
+		prep.skipResponseValidation(clonedResp, capturedBody, transaction.ValidationSkippedStreaming)
+	case tee.overflowed:
+		prep.skipResponseValidation(clonedResp, nil, transaction.ValidationSkippedTooLarge)
+	default:
+		h.runValidationAsync(config, "response", func() {
+			_ = prep.validateResponse(clonedResp, capturedBody)
+		})
+	}
+}
+
+// mirrorStreamedResponse mirrors a streamed response from the copy of its body, unless the response is a stream or the
+// copy is missing some of the body, in which case the skipped mirror is recorded.
+func (h *Handler) mirrorStreamedResponse(response *http.Response, body []byte, overflowed bool, prep *PreparedRequest) {
+	switch {
+	case prep.MirrorResponse == nil:
+	case isStreamingResponse(response):
+		if prep.SkipMirror != nil {
+			go prep.SkipMirror(transaction.ValidationSkippedStreaming)
+		}
+	case overflowed:
+		if prep.SkipMirror != nil {
+			go prep.SkipMirror(transaction.ValidationSkippedTooLarge)
+		}
+	default:
+		mirroredResp := &http.Response{
+			StatusCode: response.StatusCode,
+			Header:     response.Header.Clone(),
+		}
+		go prep.MirrorResponse(mirroredResp, body)
+	}
+}
+
+func (prep *PreparedRequest) skipResponseValidation(response *http.Response, body []byte, reason string) {
+	if prep == nil || prep.SkipResponseValidation == nil {
+		return
+	}
+	prep.SkipResponseValidation(response, body, reason)
+}
@@ -0,0 +1,332 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package proxy
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/sse"
+	"github.com/pb33f/wiretap/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chunkWriter hands each write to the test as it happens.
+type chunkWriter struct {
+	header http.Header
+	code   int
+	chunks chan string
+}
+
+func (w *chunkWriter) Header() http.Header        { return w.header }
+func (w *chunkWriter) WriteHeader(statusCode int) { w.code = statusCode }
+func (w *chunkWriter) Flush()                     {}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	w.chunks <- string(p)
+	return len(p), nil
+}
+
+type skipRecorder struct {
+	mu     sync.Mutex
+	reason string
+	body   []byte
+}
+
+func (s *skipRecorder) record(_ *http.Response, body []byte, reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reason, s.body = reason, body
+}
+
+func (s *skipRecorder) get() (string, []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reason, s.body
+}
+
+func streamRequest(writer http.ResponseWriter) *model.Request {
+	id := uuid.New()
+	return &model.Request{
+		Id:                 &id,
+		HttpRequest:        httptest.NewRequest(http.MethodGet, "http://wiretap.local/events", nil),
+		HttpResponseWriter: writer,
+	}
+}
+
+func upstream(contentType string, contentLength int64, body io.Reader) APICaller {
+	return func(_ *http.Request, _ ...*shared.WiretapConfiguration) (*http.Response, error) {
+		return &http.Response{
+			StatusCode:    http.StatusOK,
+			Header:        http.Header{"Content-Type": []string{contentType}},
+			ContentLength: contentLength,
+			Body:          io.NopCloser(body),
+		}, nil
+	}
+}
+
+func TestHandlerStreamsEventsBeforeUpstreamCloses(t *testing.T) {
+	pr, pw := io.Pipe()
+	writer := &chunkWriter{header: http.Header{}, chunks: make(chan string, 8)}
+	skipped := &skipRecorder{}
+
+	var validated bool
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		NewHandler().Handle(streamRequest(writer), &PreparedRequest{
+			Config:     testConfig(),
+			APIRequest: httptest.NewRequest(http.MethodGet, "http://upstream.local/events", nil),
+			CallAPI:    upstream("text/event-stream", -1, pr),
+			Validator: testValidator{
+				validateResponse: func(_ *http.Response, _ []byte) []*shared.WiretapValidationError {
+					validated = true
+					return nil
+				},
+			},
+			SkipResponseValidation: skipped.record,
+		})
+	}()
+
+	_, _ = pw.Write([]byte("data: one\n\n"))
+	select {
+	case chunk := <-writer.chunks:
+		assert.Equal(t, "data: one\n\n", chunk)
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not streamed before the upstream closed")
+	}
+	_, _ = pw.Write([]byte("data: two\n\n"))
+	assert.Equal(t, "data: two\n\n", <-writer.chunks)
+	_ = pw.Close()
+	<-done
+
+	reason, body := skipped.get()
+	assert.Equal(t, http.StatusOK, writer.code)
+	assert.Equal(t, transaction.ValidationSkippedStreaming, reason)
+	assert.Equal(t, "data: one\n\ndata: two\n\n", string(body))
+	assert.False(t, validated)
+}
+
+func TestHandlerRecordsEventsAsTheyStream(t *testing.T) {
+	pr, pw := io.Pipe()
+	writer := &chunkWriter{header: http.Header{}, chunks: make(chan string, 8)}
+	skipped := &skipRecorder{}
+	events := make(chan *sse.Event, 8)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		NewHandler().Handle(streamRequest(writer), &PreparedRequest{
+			Config:                 testConfig(),
+			APIRequest:             httptest.NewRequest(http.MethodGet, "http://upstream.local/events", nil),
+			CallAPI:                upstream("text/event-stream", -1, pr),
+			SkipResponseValidation: skipped.record,
+			RecordEvent: func(response *http.Response, event *sse.Event) bool {
+				assert.Equal(t, http.StatusOK, response.StatusCode)
+				events <- event
+				return true
+			},
+		})
+	}()
+
+	// an event split across chunks is recorded once it is complete.
+	_, _ = pw.Write([]byte("event: price\ndata: {\"price\""))
+	assert.Equal(t, "event: price\ndata: {\"price\"", <-writer.chunks)
+	_, _ = pw.Write([]byte(":1}\n\n"))
+	<-writer.chunks
+	select {
+	case event := <-events:
+		assert.Equal(t, "price", event.Event)
+		assert.Equal(t, `{"price":1}`, event.Data)
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not recorded while the stream was open")
+	}
+	_ = pw.Close()
+	<-done
+
+	// validated events stand in for the skipped body validation.
+	reason, _ := skipped.get()
+	assert.Empty(t, reason)
+}
+
+func TestHandlerStreamsBodiesLargerThanTheValidationLimit(t *testing.T) {
+	config := testConfig()
+	config.MaxValidatedBodySize = 8
+	body := strings.Repeat("x", 20)
+	skipped := &skipRecorder{}
+
+	var validated bool
+	request := streamRequest(httptest.NewRecorder())
+	NewHandler().Handle(request, &PreparedRequest{
+		Config:      config,
+		APIRequest:  httptest.NewRequest(http.MethodGet, "http://upstream.local/download", nil),
+		IsHardError: true,
+		CallAPI:     upstream("application/json", -1, strings.NewReader(body)),
+		Validator: testValidator{
+			validateResponse: func(_ *http.Response, _ []byte) []*shared.WiretapValidationError {
+				validated = true
+				return nil
+			},
+		},
+		SkipResponseValidation: skipped.record,
+	})
+
+	rec := request.HttpResponseWriter.(*httptest.ResponseRecorder)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, body, rec.Body.String())
+
+	reason, recorded := skipped.get()
+	assert.Equal(t, transaction.ValidationSkippedTooLarge, reason)
+	assert.Empty(t, recorded)
+	assert.False(t, validated)
+}
+
+func TestHandlerValidatesStreamedBodiesWithinTheLimit(t *testing.T) {
+	validated := make(chan []byte, 1)
+	skipped := &skipRecorder{}
+
+	request := streamRequest(httptest.NewRecorder())
+	NewHandler().Handle(request, &PreparedRequest{
+		Config:     testConfig(),
+		APIRequest: httptest.NewRequest(http.MethodGet, "http://upstream.local/products", nil),
+		CallAPI:    upstream("application/json", -1, bytes.NewBufferString(`{"ok":true}`)),
+		Validator: testValidator{
+			validateResponse: func(_ *http.Response, body []byte) []*shared.WiretapValidationError {
+				validated <- body
+				return nil
+			},
+		},
+		SkipResponseValidation: skipped.record,
+	})
+
+	rec := request.HttpResponseWriter.(*httptest.ResponseRecorder)
+	assert.JSONEq(t, `{"ok":true}`, rec.Body.String())
+	select {
+	case body := <-validated:
+		assert.JSONEq(t, `{"ok":true}`, string(body))
+	case <-time.After(5 * time.Second):
+		t.Fatal("streamed response was not validated")
+	}
+	reason, _ := skipped.get()
+	assert.Empty(t, reason)
+}
+
+func TestHandlerTransformsJSONBodiesOfUnknownLength(t *testing.T) {
+	request := streamRequest(httptest.NewRecorder())
+	NewHandler().Handle(request, &PreparedRequest{
+		Config:     testConfig(),
+		APIRequest: httptest.NewRequest(http.MethodGet, "http://upstream.local/products", nil),
+		CallAPI:    upstream("application/json", -1, bytes.NewBufferString(`{"ok":true}`)),
+		TransformResponse: func(_ *http.Response, body []byte) []byte {
+			return []byte(`{"ok":false}`)
+		},
+		SkipResponseTransform: func(reason string) {
+			t.Errorf("transform skipped: %s", reason)
+		},
+	})
+
+	rec := request.HttpResponseWriter.(*httptest.ResponseRecorder)
+	assert.JSONEq(t, `{"ok":false}`, rec.Body.String())
+}
+
+func TestHandlerRecordsTransformsSkippedForStreamedBodies(t *testing.T) {
+	for contentType, expected := range map[string]string{
+		"application/json":     transaction.ValidationSkippedTooLarge,
+		"application/x-ndjson": transaction.ValidationSkippedStreaming,
+	} {
+		t.Run(contentType, func(t *testing.T) {
+			config := testConfig()
+			config.MaxValidatedBodySize = 8
+			body := `{"items":["` + strings.Repeat("x", 20) + `"]}`
+			skipped := make(chan string, 1)
+
+			request := streamRequest(httptest.NewRecorder())
+			NewHandler().Handle(request, &PreparedRequest{
+				Config:     config,
+				APIRequest: httptest.NewRequest(http.MethodGet, "http://upstream.local/products", nil),
+				CallAPI:    upstream(contentType, -1, strings.NewReader(body)),
+				TransformResponse: func(_ *http.Response, body []byte) []byte {
+					t.Error("streamed body transformed")
+					return body
+				},
+				SkipResponseTransform: func(reason string) {
+					skipped <- reason
+				},
+			})
+
+			rec := request.HttpResponseWriter.(*httptest.ResponseRecorder)
+			assert.Equal(t, body, rec.Body.String())
+			select {
+			case reason := <-skipped:
+				assert.Equal(t, expected, reason)
+			case <-time.After(5 * time.Second):
+				t.Fatal("skipped transform was not recorded")
+			}
+		})
+	}
+}
+
+func TestHandlerMirrorsStreamedBodies(t *testing.T) {
+	for name, tc := range map[string]struct {
+		contentType, body, skipped string
+	}{
+		"within the limit": {contentType: "application/json", body: `{"ok":true}`},
+		"too large":        {contentType: "application/json", body: strings.Repeat("x", 20), skipped: transaction.ValidationSkippedTooLarge},
+		"streams":          {contentType: "application/x-ndjson", body: "{}\n", skipped: transaction.ValidationSkippedStreaming},
+	} {
+		t.Run(name, func(t *testing.T) {
+			config := testConfig()
+			config.MaxValidatedBodySize = 16
+			mirrored := make(chan string, 1)
+			skipped := make(chan string, 1)
+
+			request := streamRequest(httptest.NewRecorder())
+			NewHandler().Handle(request, &PreparedRequest{
+				Config:     config,
+				APIRequest: httptest.NewRequest(http.MethodGet, "http://upstream.local/products", nil),
+				CallAPI:    upstream(tc.contentType, -1, strings.NewReader(tc.body)),
+				MirrorResponse: func(_ *http.Response, body []byte) {
+					mirrored <- string(body)
+				},
+				SkipMirror: func(reason string) {
+					skipped <- reason
+				},
+			})
+
+			assert.Equal(t, tc.body, request.HttpResponseWriter.(*httptest.ResponseRecorder).Body.String())
+			select {
+			case body := <-mirrored:
+				assert.Empty(t, tc.skipped)
+				assert.Equal(t, tc.body, body)
+			case reason := <-skipped:
+				assert.Equal(t, tc.skipped, reason)
+			case <-time.After(5 * time.Second):
+				t.Fatal("streamed response was neither mirrored nor skipped")
+			}
+		})
+	}
+}
+
+func TestBoundedBuffer(t *testing.T) {
+	b := &boundedBuffer{max: 5}
+	n, err := b.Write([]byte("abc"))
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.False(t, b.overflowed)
+
+	n, _ = b.Write([]byte("defg"))
+	assert.Equal(t, 4, n)
+	assert.True(t, b.overflowed)
+	assert.Equal(t, "abcde", b.buf.String())
+}
@@ -0,0 +1,241 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"strings"
+
+	"github.com/pb33f/libopenapi-validator/errors"
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/wiretap/daemon/broadcast"
+	"github.com/pb33f/wiretap/redact"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/transaction"
+	"go.yaml.in/yaml/v4"
+)
+
+// newRedactor creates the redactor of a configuration, searching the specifications for sensitive properties.
+func newRedactor(config *shared.WiretapConfiguration, documents []shared.ApiDocument) (*redact.Redactor, error) {
+	if config.Redaction == nil {
+		return nil, nil
+	}
+	var specs []*yaml.Node
+	for _, document := range documents {
+		if document.Document != nil && document.Document.GetSpecInfo() != nil {
+			specs = append(specs, document.Document.GetSpecInfo().RootNode)
+		}
+	}
+	return redact.New(config.Redaction, specs...)
+}
+
+// redactTransaction returns a copy of a transaction with its sensitive values redacted. The same transaction is
+// stored and broadcast, so it is never redacted in place, and is returned as is when nothing is redacted.
+func (ws *WiretapService) redactTransaction(txn *transaction.HttpTransaction) *transaction.HttpTransaction {
+	r := ws.redactor
+	if r == nil || txn == nil {
+		return txn
+	}
+	redacted := *txn
+
+	if txn.Request != nil {
+		request := *txn.Request
+		request.URL = r.URL(request.URL)
+		request.Query = r.Query(request.Query)
+		request.Headers = redactHeaders(r, request.Headers)
+		if request.InjectedHeaders != nil {
+			request.InjectedHeaders = make(map[string]string, len(txn.Request.InjectedHeaders))
+			for name, value := range txn.Request.InjectedHeaders {
+				if value, keep := r.Header(name, value); keep {
+					request.InjectedHeaders[name] = value
+				}
+			}
+		}
+		request.Cookies = redactCookies(r, request.Cookies)
+		request.Body = r.Body(request.Body)
+		redacted.Request = &request
+	}
+
+	if txn.Response != nil {
+		response := *txn.Response
+		response.Headers = redactHeaders(r, response.Headers)
+		response.Cookies = redactCookies(r, response.Cookies)
+		response.Body = r.Body(response.Body)
+		if response.Events != nil {
+			response.Events = make([]*transaction.ResponseEvent, len(txn.Response.Events))
+			for i, event := range txn.Response.Events {
+				redactedEvent := *event
+				redactedEvent.Data = r.Body(event.Data)
+				redactedEvent.Validation = redactViolations(r, event.Validation)
+				response.Events[i] = &redactedEvent
+			}
+		}
+		redacted.Response = &response
+	}
+
+	redacted.RequestValidation = redactViolations(r, txn.RequestValidation)
+	redacted.ResponseValidation = redactViolations(r, txn.ResponseValidation)
+
+	if txn.Websocket != nil {
+		session := *txn.Websocket
+		session.Frames = make([]*transaction.WebsocketFrame, len(txn.Websocket.Frames))
+		for i, frame := range txn.Websocket.Frames {
+			redactedFrame := *frame
+			redactedFrame.Payload = r.Body(frame.Payload)
+			redactedFrame.Message = r.Text(frame.Message)
+			redactedFrame.Validation = redactViolations(r, frame.Validation)
+			session.Frames[i] = &redactedFrame
+		}
+		redacted.Websocket = &session
+	}
+
+	if txn.Mirror != nil {
+		mirror := *txn.Mirror
+		mirror.ResponseValidation = redactViolations(r, txn.Mirror.ResponseValidation)
+		if mirror.Differences != nil {
+			mirror.Differences = make([]*transaction.MirrorDifference, len(txn.Mirror.Differences))
+			for i, difference := range txn.Mirror.Differences {
+				mirror.Differences[i] = redactMirrorDifference(r, difference)
+			}
+		}
+		redacted.Mirror = &mirror
+	}
+
+	if txn.Transforms != nil {
+		transforms := *txn.Transforms
+		transforms.UpstreamRequestBody = r.Body(transforms.UpstreamRequestBody)
+		transforms.UpstreamResponseBody = r.Body(transforms.UpstreamResponseBody)
+		redacted.Transforms = &transforms
+	}
+
+	if txn.ScriptErrors != nil {
+		redacted.ScriptErrors = make([]string, len(txn.ScriptErrors))
+		for i, message := range txn.ScriptErrors {
+			redacted.ScriptErrors[i] = r.Text(message)
+		}
+	}
+
+	if txn.Tokens != nil {
+		redacted.Tokens = make([]*transaction.TokenRecord, len(txn.Tokens))
+		for i, token := range txn.Tokens {
+			redactedToken := *token
+			if token.Claims != nil {
+				redactedToken.Claims = make(map[string]any, len(token.Claims))
+				for claim, value := range token.Claims {
+					if text, ok := value.(string); ok {
+						value = r.Text(text)
+					}
+					redactedToken.Claims[claim] = value
+				}
+			}
+			redacted.Tokens[i] = &redactedToken
+		}
+	}
+	return &redacted
+}
+
+func redactHeaders(r *redact.Redactor, headers map[string]any) map[string]any {
+	if headers == nil {
+		return nil
+	}
+	redacted := make(map[string]any, len(headers))
+	for name, value := range headers {
+		if text, ok := value.(string); ok {
+			var keep bool
+			if value, keep = r.Header(name, text); !keep {
+				continue
+			}
+		}
+		redacted[name] = value
+	}
+	return redacted
+}
+
+func redactCookies(r *redact.Redactor, cookies map[string]*transaction.HttpCookie) map[string]*transaction.HttpCookie {
+	if cookies == nil {
+		return nil
+	}
+	redacted := make(map[string]*transaction.HttpCookie, len(cookies))
+	for name, cookie := range cookies {
+		value, keep := r.Cookie(name, cookie.Value)
+		if !keep {
+			continue
+		}
+		redactedCookie := *cookie
+		redactedCookie.Value = value
+		redacted[name] = &redactedCookie
+	}
+	return redacted
+}
+
+// redactMirrorDifference redacts the primary and shadow values of a difference, as header values or JSON body values.
+func redactMirrorDifference(r *redact.Redactor, difference *transaction.MirrorDifference) *transaction.MirrorDifference {
+	redacted := *difference
+	switch {
+	case difference.Kind == transaction.MirrorDifferenceHeader:
+		redacted.Primary, _ = r.Header(difference.Location, difference.Primary)
+		redacted.Shadow, _ = r.Header(difference.Location, difference.Shadow)
+	case difference.Kind == transaction.MirrorDifferenceBody &&
+		r.Property(difference.Location[strings.LastIndex(difference.Location, ".")+1:]):
+		redacted.Primary = r.Value(difference.Primary)
+		redacted.Shadow = r.Value(difference.Shadow)
+	default:
+		redacted.Primary = r.Body(difference.Primary)
+		redacted.Shadow = r.Body(difference.Shadow)
+	}
+	return &redacted
+}
+
+// redactViolations returns copies of violations with the patterns redacted from their messages and reasons, and the
+// objects that failed schema validation redacted like bodies.
+func redactViolations(r *redact.Redactor, violations []*shared.WiretapValidationError) []*shared.WiretapValidationError {
+	if r == nil || violations == nil {
+		return violations
+	}
+	redacted := make([]*shared.WiretapValidationError, len(violations))
+	for i, violation := range violations {
+		redactedViolation := *violation
+		redactedViolation.Message = r.Text(violation.Message)
+		redactedViolation.Reason = r.Text(violation.Reason)
+		if violation.SchemaValidationErrors != nil {
+			redactedViolation.SchemaValidationErrors = make([]*errors.SchemaValidationFailure,
+				len(violation.SchemaValidationErrors))
+			for j, failure := range violation.SchemaValidationErrors {
+				redactedFailure := *failure
+				redactedFailure.Reason = r.Text(failure.Reason)
+				redactedFailure.ReferenceObject = r.Body(failure.ReferenceObject)
+				redactedViolation.SchemaValidationErrors[j] = &redactedFailure
+			}
+		}
+		redacted[i] = &redactedViolation
+	}
+	return redacted
+}
+
+// redactingBroadcaster redacts transactions and violations before they are broadcast.
+type redactingBroadcaster struct {
+	broadcast.Broadcaster
+	ws *WiretapService
+}
+
+func (b *redactingBroadcaster) RequestValidationErrors(request *model.Request,
+	errors []*shared.WiretapValidationError, txn *transaction.HttpTransaction) {
+	b.Broadcaster.RequestValidationErrors(request, redactViolations(b.ws.redactor, errors), b.ws.redactTransaction(txn))
+}
+
+func (b *redactingBroadcaster) Request(request *model.Request, txn *transaction.HttpTransaction) {
+	b.Broadcaster.Request(request, b.ws.redactTransaction(txn))
+}
+
+func (b *redactingBroadcaster) Response(request *model.Request, txn *transaction.HttpTransaction) {
+	b.Broadcaster.Response(request, b.ws.redactTransaction(txn))
+}
+
+func (b *redactingBroadcaster) ResponseError(request *model.Request, txn *transaction.HttpTransaction, err error) {
+	b.Broadcaster.ResponseError(request, b.ws.redactTransaction(txn), err)
+}
+
+func (b *redactingBroadcaster) ResponseValidationErrors(request *model.Request, txn *transaction.HttpTransaction,
+	errors []*shared.WiretapValidationError) {
+	b.Broadcaster.ResponseValidationErrors(request, b.ws.redactTransaction(txn), redactViolations(b.ws.redactor, errors))
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pb33f/libopenapi-validator/errors"
+	"github.com/pb33f/ranch/bus"
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/ranch/store"
+	"github.com/pb33f/wiretap/redact"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleHttpRequestRedactsTransactions(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		received <- r
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=s3cret; Path=/")
+		_, _ = w.Write([]byte(`{"id":"1","owner":"dave@pb33f.io"}`))
+	}))
+	defer upstream.Close()
+
+	ws := newMockModeWiretapService(t, &shared.WiretapConfiguration{
+		RedirectURL:      upstream.URL,
+		RedirectProtocol: "http",
+		RedirectHost:     upstream.Listener.Addr().String(),
+		Redaction: &shared.WiretapRedactionConfig{
+			Headers:   []string{"X-API-Key"},
+			Cookies:   []string{"session"},
+			JSONPaths: []string{"$.shortCode"},
+			Patterns:  []string{"email"},
+		},
+	})
+
+	request, err := http.NewRequest(http.MethodPost, "https://api.pb33f.io/wiretap/giftshop/products?contact=dave@pb33f.io",
+		bytes.NewBufferString(`{"price":400.23,"shortCode":"pb0001"}`))
+	require.NoError(t, err)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-API-Key", "k3y")
+	request.AddCookie(&http.Cookie{Name: "session", Value: "s3cret"})
+	id := uuid.New()
+	ws.handleHttpRequest(&model.Request{Id: &id, HttpRequest: request, HttpResponseWriter: httptest.NewRecorder()})
+
+	// traffic is proxied as sent, only what is captured is redacted.
+	upstreamRequest := <-received
+	assert.Equal(t, "k3y", upstreamRequest.Header.Get("X-API-Key"))
+	upstreamBody, _ := io.ReadAll(upstreamRequest.Body)
+	assert.JSONEq(t, `{"price":400.23,"shortCode":"pb0001"}`, string(upstreamBody))
+
+	var stored *transaction.HttpTransaction
+	require.Eventually(t, func() bool {
+		txn, ok := ws.transactionStore.Get(id.String())
+		if ok {
+			stored = txn.(*transaction.HttpTransaction)
+		}
+		return stored != nil && stored.Request != nil && stored.Response != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, redact.Mask, stored.Request.Headers["X-Api-Key"])
+	assert.Equal(t, "session="+redact.Mask, stored.Request.Headers["Cookie"])
+	assert.Equal(t, redact.Mask, stored.Request.Cookies["session"].Value)
+	assert.Equal(t, "contact=%5Bredacted%5D", stored.Request.Query)
+	assert.NotContains(t, stored.Request.URL, "dave@pb33f.io")
+	assert.Equal(t, `{"price":400.23,"shortCode":"[redacted]"}`, stored.Request.Body)
+	assert.Equal(t, `{"id":"1","owner":"[redacted]"}`, stored.Response.Body)
+	assert.Equal(t, "session="+redact.Mask+"; Path=/", stored.Response.Headers["Set-Cookie"])
+	assert.Equal(t, redact.Mask, stored.Response.Cookies["session"].Value)
+}
+
+func TestRedactTransactionCopies(t *testing.T) {
+	r, err := redact.New(&shared.WiretapRedactionConfig{Strategy: shared.RedactHash, Patterns: []string{"email"}})
+	require.NoError(t, err)
+	ws := &WiretapService{redactor: r}
+
+	violation := &shared.WiretapValidationError{ValidationError: errors.ValidationError{
+		Message: "owner dave@pb33f.io is not valid",
+		SchemaValidationErrors: []*errors.SchemaValidationFailure{{
+			Reason:          "dave@pb33f.io is too long",
+			ReferenceObject: `{"owner":"dave@pb33f.io"}`,
+		}},
+	}}
+	txn := &transaction.HttpTransaction{
+		Request:           &transaction.HttpRequest{Body: `{"owner":"dave@pb33f.io"}`},
+		RequestValidation: []*shared.WiretapValidationError{violation},
+	}
+
+	// hashes are stable, so a transaction stored and broadcast is redacted the same way, and never redacted twice.
+	redacted := ws.redactTransaction(txn)
+	assert.Equal(t, redacted, ws.redactTransaction(txn))
+	assert.Equal(t, `{"owner":"dave@pb33f.io"}`, txn.Request.Body)
+	assert.Equal(t, "owner dave@pb33f.io is not valid", violation.Message)
+
+	hash := r.Value("dave@pb33f.io")
+	assert.Equal(t, `{"owner":"`+hash+`"}`, redacted.Request.Body)
+	assert.Equal(t, "owner "+hash+" is not valid", redacted.RequestValidation[0].Message)
+	assert.Equal(t, hash+" is too long", redacted.RequestValidation[0].SchemaValidationErrors[0].Reason)
+	assert.Equal(t, `{"owner":"`+hash+`"}`, redacted.RequestValidation[0].SchemaValidationErrors[0].ReferenceObject)
+
+	// without a redactor, transactions are stored and broadcast as they are.
+	assert.Same(t, txn, (&WiretapService{}).redactTransaction(txn))
+}
+
+func TestNewWiretapServiceRefusesInvalidRedaction(t *testing.T) {
+	ws, err := NewWiretapService(nil, &shared.WiretapConfiguration{
+		Redaction: &shared.WiretapRedactionConfig{Patterns: []string{"("}},
+	}, store.NewManager(bus.NewEventBus()))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to configure redaction: invalid redaction pattern '('")
+	assert.Nil(t, ws)
+}
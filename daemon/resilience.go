@@ -0,0 +1,35 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"net/http"
+
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/wiretap/daemon/proxy"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/transaction"
+)
+
+// effectiveResilience returns the resilience policy of requests to a path, the policy of the path config or else the
+// global one.
+func effectiveResilience(config *shared.WiretapConfiguration, pathConfig *shared.WiretapPathConfig) *shared.WiretapResilienceConfig {
+	if pathConfig != nil && pathConfig.Resilience != nil {
+		return pathConfig.Resilience
+	}
+	return config.Resilience
+}
+
+// resilienceRecorder returns the proxy hook that records the attempts and circuit state of a request on its
+// transaction, along with the fallback response when the circuit was open.
+func (ws *WiretapService) resilienceRecorder(request *model.Request) proxy.ResilienceRecorder {
+	return func(response *http.Response, body []byte, record *transaction.ResilienceRecord) {
+		txn := &transaction.HttpTransaction{Id: request.Id.String()}
+		if response != nil {
+			txn = BuildResponseFromBytes(request, response, body)
+		}
+		txn.Resilience = record
+		ws.broadcastResponse(request, txn)
+	}
+}
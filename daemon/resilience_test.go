@@ -0,0 +1,83 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/ranch/bus"
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/ranch/store"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEffectiveResilience(t *testing.T) {
+	global := &shared.WiretapResilienceConfig{Retries: 2}
+	path := &shared.WiretapResilienceConfig{ResponseTimeout: 100}
+	config := &shared.WiretapConfiguration{}
+
+	assert.Nil(t, effectiveResilience(config, nil))
+	config.Resilience = global
+	assert.Equal(t, global, effectiveResilience(config, &shared.WiretapPathConfig{}))
+	assert.Equal(t, path, effectiveResilience(config, &shared.WiretapPathConfig{Resilience: path}))
+}
+
+func TestHandleHttpRequestRecordsRetries(t *testing.T) {
+	var calls atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer upstream.Close()
+
+	config := &shared.WiretapConfiguration{
+		RedirectURL:        upstream.URL,
+		RedirectProtocol:   "http",
+		RedirectHost:       upstream.Listener.Addr().String(),
+		Resilience:         &shared.WiretapResilienceConfig{Retries: 1, RetryBackoff: 1},
+		ReportFile:         t.TempDir() + "/violations.jsonl",
+		Logger:             slog.New(slog.NewTextHandler(io.Discard, nil)),
+		PathConfigurations: orderedmap.New[string, *shared.WiretapPathConfig](),
+	}
+	config.CompilePaths()
+
+	eventBus := bus.NewEventBus()
+	ws, err := NewWiretapService(nil, config, store.NewManager(eventBus))
+	require.NoError(t, err)
+	ws.setBroadcastChannel(eventBus.GetChannelManager().CreateChannel(WiretapBroadcastChan))
+	ws.controlsStore.Put(shared.ConfigKey, config, nil)
+
+	id := uuid.New()
+	rec := httptest.NewRecorder()
+	ws.handleHttpRequest(&model.Request{
+		Id:                 &id,
+		HttpRequest:        httptest.NewRequest(http.MethodGet, "/pets", nil),
+		HttpResponseWriter: rec,
+	})
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	var record *transaction.ResilienceRecord
+	require.Eventually(t, func() bool {
+		stored, ok := ws.transactionStore.Get(id.String())
+		if ok {
+			record = stored.(*transaction.HttpTransaction).Resilience
+		}
+		return record != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, &transaction.ResilienceRecord{Attempts: 2, Failures: []string{"upstream responded 502"}}, record)
+}
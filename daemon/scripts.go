@@ -0,0 +1,246 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/wiretap/daemon/proxy"
+	"github.com/pb33f/wiretap/script"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/transaction"
+)
+
+// loadScripts loads the script bound to each path, all sharing one store. A script that fails to load stops wiretap
+// starting, rather than passing on requests it was meant to change.
+func loadScripts(config *shared.WiretapConfiguration) (map[*shared.WiretapPathConfig]*script.Script, error) {
+	if config.PathConfigurations == nil {
+		return nil, nil
+	}
+	var scripts map[*shared.WiretapPathConfig]*script.Script
+	store := script.NewStore()
+	for x := config.PathConfigurations.First(); x != nil; x = x.Next() {
+		pathConfig := x.Value()
+		if pathConfig == nil || pathConfig.Script == "" {
+			continue
+		}
+		loaded, err := script.Load(pathConfig.Script, store)
+		if err != nil {
+			return nil, fmt.Errorf("path '%s': %w", x.Key(), err)
+		}
+		if scripts == nil {
+			scripts = make(map[*shared.WiretapPathConfig]*script.Script)
+		}
+		scripts[pathConfig] = loaded
+	}
+	return scripts, nil
+}
+
+// scriptRun is the script bound to the path of a request, and the errors of the stages it has run. A stage that fails
+// leaves the request or response unchanged.
+type scriptRun struct {
+	script    *script.Script
+	operation script.Operation
+	mu        sync.Mutex
+	errors    []string
+}
+
+// newScriptRun returns the run of the script bound to a path, or nil when the path has none.
+func (ws *WiretapService) newScriptRun(pathConfig *shared.WiretapPathConfig, request *http.Request) *scriptRun {
+	if pathConfig == nil || ws.scripts[pathConfig] == nil {
+		return nil
+	}
+	return &scriptRun{script: ws.scripts[pathConfig], operation: ws.matchedOperation(request)}
+}
+
+// matchedOperation returns the operation a request matched, when it matched one.
+func (ws *WiretapService) matchedOperation(request *http.Request) script.Operation {
+	match := ws.getRouteMatchForHTTPRequest(request)
+	if match == nil || match.Document == nil || match.Document.DocModel == nil ||
+		match.Document.DocModel.Paths == nil {
+		return script.Operation{}
+	}
+	operation := script.Operation{Method: request.Method, Path: match.MatchedPath}
+	if pathItem := match.Document.DocModel.Paths.PathItems.GetOrZero(match.MatchedPath); pathItem != nil {
+		if op := pathItem.GetOperations().GetOrZero(strings.ToLower(request.Method)); op != nil {
+			operation.ID = op.OperationId
+		}
+	}
+	return operation
+}
+
+// has returns true when the script hooks into any of the stages.
+func (r *scriptRun) has(stages ...string) bool {
+	if r == nil {
+		return false
+	}
+	for _, stage := range stages {
+		if r.script.Has(stage) {
+			return true
+		}
+	}
+	return false
+}
+
+// run runs a stage of the script, returning true when it ran and its changes should be applied.
+func (r *scriptRun) run(config *shared.WiretapConfiguration, stage string, exchange *script.Exchange) bool {
+	if !r.has(stage) {
+		return false
+	}
+	if err := r.script.Run(stage, exchange); err != nil {
+		wiretapLogger(config).Warn("[wiretap] script failed", "script", r.script.Name, "stage", stage,
+			"error", err.Error())
+		r.fail(stage + ": " + err.Error())
+		return false
+	}
+	return true
+}
+
+func (r *scriptRun) fail(message string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errors = append(r.errors, message)
+}
+
+// recorded returns the errors of the stages run so far.
+func (r *scriptRun) recorded() []string {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.errors)
+}
+
+// requestExchange returns the exchange a request stage runs on.
+func (r *scriptRun) requestExchange(request *http.Request, body []byte) *script.Exchange {
+	return &script.Exchange{
+		Request: &script.Message{
+			Method:  request.Method,
+			Path:    request.URL.Path,
+			Query:   request.URL.RawQuery,
+			Headers: request.Header.Clone(),
+			Body:    body,
+		},
+		Operation: r.operation,
+	}
+}
+
+// applyRequest applies the changes a script made to a request, returning its new body.
+func applyRequest(request *http.Request, changed *script.Message) []byte {
+	request.Method = changed.Method
+	request.URL.Path = changed.Path
+	request.URL.RawPath = ""
+	request.URL.RawQuery = changed.Query
+	request.Header = changed.Headers
+	body := changed.Body
+	request.Body = io.NopCloser(bytes.NewReader(body))
+	request.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	request.ContentLength = int64(len(body))
+	if request.Header.Get("Content-Length") != "" {
+		request.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	}
+	return body
+}
+
+// respondFromScript answers a request a script responded to itself. The request and the response are validated and
+// recorded like any other.
+func (ws *WiretapService) respondFromScript(request *model.Request, prep *PreparedRequest) {
+	response := &http.Response{
+		StatusCode:    prep.ScriptResponse.Status,
+		Header:        prep.ScriptResponse.Headers,
+		Body:          io.NopCloser(bytes.NewReader(prep.ScriptResponse.Body)),
+		ContentLength: int64(len(prep.ScriptResponse.Body)),
+		Request:       prep.NewReq,
+	}
+	ws.config.Logger.Info("[wiretap] request answered by script", "url", request.HttpRequest.URL.String(),
+		"code", response.StatusCode)
+	_ = ws.ValidateRequest(request, prep.NewReq, prep.TxnConfig)
+	_ = ws.ValidateResponseForRequest(request, prep.NewReq, response, prep.ScriptResponse.Body)
+
+	headers := request.HttpResponseWriter.Header()
+	for name, values := range response.Header {
+		headers[name] = values
+	}
+	shared.SetCORSHeaders(headers)
+	request.HttpResponseWriter.WriteHeader(response.StatusCode)
+	_, _ = request.HttpResponseWriter.Write(prep.ScriptResponse.Body)
+}
+
+// responseScripter returns the proxy hook that runs the afterResponse stage on an upstream response, after its body has
+// been transformed and before it is validated. Scripts may change the status, headers and body.
+func (ws *WiretapService) responseScripter(request *model.Request, prep *PreparedRequest,
+	transformer proxy.ResponseTransformer) proxy.ResponseTransformer {
+	if !prep.Script.has(script.StageAfterResponse) {
+		return transformer
+	}
+	return func(response *http.Response, body []byte) []byte {
+		if transformer != nil {
+			body = transformer(response, body)
+		}
+		return ws.runResponseStage(request, prep, script.StageAfterResponse, response, body)
+	}
+}
+
+// clientWriteScripter returns the proxy hook that runs the beforeClientWrite stage on a response, after it has been
+// validated and just before it is written to the client.
+func (ws *WiretapService) clientWriteScripter(request *model.Request, prep *PreparedRequest) proxy.ResponseTransformer {
+	if !prep.Script.has(script.StageBeforeClientWrite) {
+		return nil
+	}
+	return func(response *http.Response, body []byte) []byte {
+		return ws.runResponseStage(request, prep, script.StageBeforeClientWrite, response, body)
+	}
+}
+
+func (ws *WiretapService) runResponseStage(request *model.Request, prep *PreparedRequest, stage string,
+	response *http.Response, body []byte) []byte {
+	exchange := prep.Script.requestExchange(prep.APIRequest, prep.UpstreamBody)
+	exchange.Response = &script.Message{
+		Status:  response.StatusCode,
+		Headers: response.Header.Clone(),
+		Body:    body,
+	}
+	if !prep.Script.run(prep.Config, stage, exchange) {
+		ws.broadcastScriptErrors(request, prep)
+		return body
+	}
+	response.StatusCode = exchange.Response.Status
+	response.Status = strconv.Itoa(exchange.Response.Status) + " " + http.StatusText(exchange.Response.Status)
+	response.Header = exchange.Response.Headers
+	return exchange.Response.Body
+}
+
+// responseScriptSkipper returns the proxy hook that records a response streamed to the client without running the
+// response stages of its script, as a script error on the transaction. Transforms skipped are recorded too.
+func (ws *WiretapService) responseScriptSkipper(request *model.Request, prep *PreparedRequest,
+	skipper proxy.TransformSkipRecorder) proxy.TransformSkipRecorder {
+	if !prep.Script.has(script.StageAfterResponse, script.StageBeforeClientWrite) {
+		return skipper
+	}
+	return func(reason string) {
+		if skipper != nil {
+			skipper(reason)
+		}
+		prep.Script.fail("response: " + reason)
+		ws.broadcastScriptErrors(request, prep)
+	}
+}
+
+// broadcastScriptErrors records the errors of the script of a request on its transaction, when there are any.
+func (ws *WiretapService) broadcastScriptErrors(request *model.Request, prep *PreparedRequest) {
+	if errors := prep.Script.recorded(); len(errors) > 0 {
+		go ws.broadcastResponse(request, &transaction.HttpTransaction{Id: request.Id.String(), ScriptErrors: errors})
+	}
+}
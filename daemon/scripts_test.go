@@ -0,0 +1,205 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/ranch/bus"
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/ranch/store"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newScriptedService(t *testing.T, upstreamURL, source string) *WiretapService {
+	path := filepath.Join(t.TempDir(), "pets.js")
+	require.NoError(t, os.WriteFile(path, []byte(source), 0o600))
+
+	config := &shared.WiretapConfiguration{
+		RedirectURL:        upstreamURL,
+		RedirectProtocol:   "http",
+		RedirectHost:       strings.TrimPrefix(upstreamURL, "http://"),
+		ReportFile:         t.TempDir() + "/violations.jsonl",
+		Logger:             slog.New(slog.NewTextHandler(io.Discard, nil)),
+		PathConfigurations: orderedmap.New[string, *shared.WiretapPathConfig](),
+	}
+	config.PathConfigurations.Set("/pets/**", &shared.WiretapPathConfig{Script: path})
+	config.CompilePaths()
+
+	eventBus := bus.NewEventBus()
+	ws, err := NewWiretapService(nil, config, store.NewManager(eventBus))
+	require.NoError(t, err)
+	ws.setBroadcastChannel(eventBus.GetChannelManager().CreateChannel(WiretapBroadcastChan))
+	ws.controlsStore.Put(shared.ConfigKey, config, nil)
+	return ws
+}
+
+func storedTransaction(t *testing.T, ws *WiretapService, id uuid.UUID,
+	done func(*transaction.HttpTransaction) bool) *transaction.HttpTransaction {
+	var stored *transaction.HttpTransaction
+	require.Eventually(t, func() bool {
+		value, ok := ws.transactionStore.Get(id.String())
+		if ok {
+			stored = value.(*transaction.HttpTransaction)
+		}
+		return ok && done(stored)
+	}, 5*time.Second, 10*time.Millisecond)
+	return stored
+}
+
+func TestHandleHttpRequestRunsScriptStages(t *testing.T) {
+	var upstreamPath, upstreamBody, upstreamRouted, upstreamTenant string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		upstreamPath, upstreamBody = r.URL.Path, string(body)
+		upstreamRouted, upstreamTenant = r.Header.Get("X-Routed"), r.Header.Get("X-Tenant")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":1}`))
+	}))
+	defer upstream.Close()
+
+	ws := newScriptedService(t, upstream.URL, `
+function beforeRouting(ctx) {
+	ctx.request.path = ctx.request.path.replace("/pets/v1", "/pets");
+	ctx.request.headers["X-Routed"] = "yes";
+}
+function beforeUpstream(ctx) {
+	var body = JSON.parse(ctx.request.body);
+	body.tenant = ctx.request.headers["X-Tenant"] = "acme";
+	ctx.request.body = body;
+	ctx.store.set("tenant", body.tenant);
+}
+function afterResponse(ctx) {
+	ctx.response.status = 201;
+	ctx.response.headers["X-Tenant"] = ctx.store.get("tenant");
+}
+function beforeClientWrite(ctx) {
+	ctx.response.body = ctx.response.body.replace("1", "2");
+}`)
+
+	id := uuid.New()
+	rec := httptest.NewRecorder()
+	ws.handleHttpRequest(&model.Request{
+		Id:                 &id,
+		HttpRequest:        httptest.NewRequest(http.MethodPost, "/pets/v1/dogs", strings.NewReader(`{"name":"fido"}`)),
+		HttpResponseWriter: rec,
+	})
+
+	assert.Equal(t, "/pets/dogs", upstreamPath)
+	assert.Equal(t, "yes", upstreamRouted)
+	assert.Equal(t, "acme", upstreamTenant)
+	assert.JSONEq(t, `{"name":"fido","tenant":"acme"}`, upstreamBody)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "acme", rec.Header().Get("X-Tenant"))
+	assert.Equal(t, `{"id":2}`, rec.Body.String())
+
+	// the response validated and recorded is the one after the afterResponse stage.
+	stored := storedTransaction(t, ws, id, func(txn *transaction.HttpTransaction) bool {
+		return txn.Request != nil && txn.Response != nil
+	})
+	assert.Equal(t, "/pets/dogs", stored.Request.Path)
+	assert.Equal(t, http.StatusCreated, stored.Response.StatusCode)
+	assert.Equal(t, `{"id":1}`, stored.Response.Body)
+	assert.Empty(t, stored.ScriptErrors)
+}
+
+func TestHandleHttpRequestAnswersRequestsFromScripts(t *testing.T) {
+	upstreamCalled := false
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamCalled = true
+	}))
+	defer upstream.Close()
+
+	ws := newScriptedService(t, upstream.URL, `
+function beforeRouting(ctx) {
+	if (ctx.request.method === "DELETE") {
+		ctx.respond(403, {error: "pets are forever"}, {"Content-Type": "application/json"});
+	}
+}`)
+
+	id := uuid.New()
+	rec := httptest.NewRecorder()
+	ws.handleHttpRequest(&model.Request{
+		Id:                 &id,
+		HttpRequest:        httptest.NewRequest(http.MethodDelete, "/pets/1", nil),
+		HttpResponseWriter: rec,
+	})
+
+	assert.False(t, upstreamCalled)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"error":"pets are forever"}`, rec.Body.String())
+
+	stored := storedTransaction(t, ws, id, func(txn *transaction.HttpTransaction) bool {
+		return txn.Request != nil && txn.Response != nil
+	})
+	assert.Equal(t, http.StatusForbidden, stored.Response.StatusCode)
+}
+
+func TestHandleHttpRequestRecordsScriptErrors(t *testing.T) {
+	var upstreamBody string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		upstreamBody = string(body)
+		_, _ = w.Write([]byte(`ok`))
+	}))
+	defer upstream.Close()
+
+	ws := newScriptedService(t, upstream.URL, `
+function beforeUpstream(ctx) {
+	ctx.request.body = "changed";
+	throw new Error("no tenant");
+}
+function beforeClientWrite(ctx) {
+	ctx.response.status = 0;
+}`)
+
+	id := uuid.New()
+	rec := httptest.NewRecorder()
+	ws.handleHttpRequest(&model.Request{
+		Id:                 &id,
+		HttpRequest:        httptest.NewRequest(http.MethodPost, "/pets/1", strings.NewReader(`original`)),
+		HttpResponseWriter: rec,
+	})
+
+	// failed stages leave the request and response unchanged.
+	assert.Equal(t, "original", upstreamBody)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "ok", rec.Body.String())
+
+	stored := storedTransaction(t, ws, id, func(txn *transaction.HttpTransaction) bool {
+		return txn.Request != nil && len(txn.ScriptErrors) == 2
+	})
+	assert.Equal(t, []string{
+		"beforeUpstream: Error: no tenant",
+		"beforeClientWrite: invalid status '0'",
+	}, stored.ScriptErrors)
+}
+
+func TestNewWiretapServiceRefusesScriptsThatDoNotLoad(t *testing.T) {
+	config := &shared.WiretapConfiguration{
+		Logger:             slog.New(slog.NewTextHandler(io.Discard, nil)),
+		PathConfigurations: orderedmap.New[string, *shared.WiretapPathConfig](),
+	}
+	config.PathConfigurations.Set("/pets/**", &shared.WiretapPathConfig{
+		Script: filepath.Join(t.TempDir(), "missing.js"),
+	})
+
+	_, err := NewWiretapService(nil, config, store.NewManager(bus.NewEventBus()))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to load scripts: path '/pets/**': unable to read script")
+}
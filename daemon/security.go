@@ -0,0 +1,56 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/pb33f/libopenapi-validator/helpers"
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/wiretap/daemon/proxy"
+	"github.com/pb33f/wiretap/shared"
+)
+
+// unauthorizedResponder returns the proxy hook that refuses requests failing their security requirements with the 401
+// response of their operation, when hard validation is set to do so.
+func (ws *WiretapService) unauthorizedResponder(request *model.Request, prep *PreparedRequest) proxy.UnauthorizedResponder {
+	if !prep.Config.HardErrorUnauthorized {
+		return nil
+	}
+	return func(requestErrors []*shared.WiretapValidationError) *http.Response {
+		if !hasSecurityViolation(requestErrors) {
+			return nil
+		}
+		docValidator, validationRequest := ws.getValidatorAndRequestForHTTPRequest(prep.NewReq)
+		if docValidator == nil || docValidator.MockEngine == nil {
+			return nil
+		}
+		body, status, headers, err := docValidator.MockEngine.GenerateUnauthorizedResponse(validationRequest)
+		if err != nil {
+			prep.Config.Logger.Warn("[wiretap] unable to generate a 401 response", "url",
+				request.HttpRequest.URL.String(), "error", err.Error())
+			return nil
+		}
+		if headers == nil {
+			headers = http.Header{}
+		}
+		if headers.Get("Content-Type") == "" {
+			headers.Set("Content-Type", "application/json")
+		}
+		response := &http.Response{StatusCode: status, Header: headers, Body: io.NopCloser(bytes.NewReader(body))}
+		go ws.broadcastResponse(request, BuildResponseFromBytes(request, response, body))
+		return response
+	}
+}
+
+func hasSecurityViolation(validationErrors []*shared.WiretapValidationError) bool {
+	for _, validationError := range validationErrors {
+		if validationError.ValidationType == helpers.SecurityValidation {
+			return true
+		}
+	}
+	return false
+}
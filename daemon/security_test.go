@@ -0,0 +1,85 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleHttpRequestRefusesUnauthorizedRequests(t *testing.T) {
+	var calls atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	config := &shared.WiretapConfiguration{
+		RedirectURL:           upstream.URL,
+		RedirectProtocol:      "http",
+		RedirectHost:          upstream.Listener.Addr().String(),
+		HardErrors:            true,
+		HardErrorCode:         http.StatusBadRequest,
+		HardErrorReturnCode:   http.StatusBadGateway,
+		HardErrorUnauthorized: true,
+	}
+	ws := newMockModeWiretapService(t, config)
+
+	send := func(apiKey string) (*uuid.UUID, *httptest.ResponseRecorder) {
+		request, err := http.NewRequest(http.MethodPost, "https://api.pb33f.io/wiretap/giftshop/products",
+			bytes.NewBufferString(`{"price":400.23,"shortCode":"pb0001"}`))
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", "application/json")
+		if apiKey != "" {
+			request.Header.Set("X-API-Key", apiKey)
+		}
+		id := uuid.New()
+		rec := httptest.NewRecorder()
+		ws.handleHttpRequest(&model.Request{Id: &id, HttpRequest: request, HttpResponseWriter: rec})
+		return &id, rec
+	}
+
+	// the 401 response of the operation is returned, and the upstream is not called.
+	id, rec := send("")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"message":"This request requires authentication. You are not authenticated.",`+
+		`"code":"authentication","url":"https://pb33f.io/errors/authentication"}`, rec.Body.String())
+	assert.Equal(t, int32(0), calls.Load())
+
+	var stored *transaction.HttpTransaction
+	require.Eventually(t, func() bool {
+		txn, ok := ws.transactionStore.Get(id.String())
+		if ok {
+			stored = txn.(*transaction.HttpTransaction)
+		}
+		return stored != nil && stored.Response != nil && stored.RequestValidation != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, http.StatusUnauthorized, stored.Response.StatusCode)
+	var reasons []string
+	for _, violation := range stored.RequestValidation {
+		if violation.ValidationType == "security" {
+			reasons = append(reasons, violation.Message+": "+violation.Reason)
+		}
+	}
+	assert.Equal(t, []string{"Authentication failed for security scheme 'ApiKeyAuth': " +
+		"no `X-API-Key` header found in the request"}, reasons)
+
+	// requests meeting their security requirements are sent upstream, whatever their other violations.
+	_, rec = send("doesnotmatter")
+	assert.NotEqual(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, int32(1), calls.Load())
+}
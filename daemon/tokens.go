@@ -0,0 +1,159 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pb33f/libopenapi-validator/errors"
+	"github.com/pb33f/libopenapi-validator/helpers"
+	"github.com/pb33f/wiretap/jwt"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/transaction"
+)
+
+// redactedClaim replaces the values of redacted claims.
+const redactedClaim = "[redacted]"
+
+// tokenInspector decodes the JSON Web Tokens sent with requests, and verifies them when keys are configured.
+type tokenInspector struct {
+	cookies []string
+	keys    *jwt.KeySet
+	redact  []string
+}
+
+// newTokenInspector creates the token inspector of a configuration, loading its keys. An error is returned when a key
+// cannot be loaded, rather than inspecting tokens without verifying them.
+func newTokenInspector(config *shared.WiretapConfiguration) (*tokenInspector, error) {
+	inspector := &tokenInspector{}
+	if config.JWT == nil {
+		return inspector, nil
+	}
+	inspector.cookies = config.JWT.Cookies
+	inspector.redact = config.JWT.RedactClaims
+
+	keys := &jwt.KeySet{}
+	if config.JWT.JWKS != "" {
+		data, err := os.ReadFile(config.JWT.JWKS)
+		if err == nil {
+			err = keys.AddJWKS(data)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to load JWKS '%s': %w", config.JWT.JWKS, err)
+		}
+	}
+	for _, file := range config.JWT.Keys {
+		data, err := os.ReadFile(file)
+		if err == nil {
+			err = keys.AddPEM(data)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to load key '%s': %w", file, err)
+		}
+	}
+	if config.JWT.Secret != "" {
+		keys.AddSecret([]byte(ReplaceWithVariables(config.CompiledVariables, config.JWT.Secret)))
+	}
+	if len(keys.Keys) > 0 {
+		inspector.keys = keys
+	}
+	return inspector, nil
+}
+
+// tokenCookies returns the cookies configured to hold tokens.
+func tokenCookies(config *shared.WiretapConfiguration) []string {
+	if config.JWT == nil {
+		return nil
+	}
+	return config.JWT.Cookies
+}
+
+// inspect decodes the tokens of a request, the bearer token of its Authorization header and the tokens of its token
+// cookies. Tokens that have expired, are not valid yet, or fail verification are returned as violations. Scopes are
+// checked by request validation, against the security requirements of the operation.
+func (ti *tokenInspector) inspect(request *http.Request, now time.Time) ([]*transaction.TokenRecord, []*errors.ValidationError) {
+	if ti == nil {
+		return nil, nil
+	}
+	var records []*transaction.TokenRecord
+	var violations []*errors.ValidationError
+
+	for _, sent := range ti.tokens(request) {
+		record := &transaction.TokenRecord{Source: sent.source}
+		records = append(records, record)
+		token, err := jwt.Parse(sent.raw)
+		if err != nil {
+			record.Errors = append(record.Errors, err.Error())
+			continue
+		}
+		record.Header = token.Header
+		record.Claims = ti.redactClaims(token.Claims)
+
+		var failures []error
+		if ti.keys != nil {
+			if err = token.Verify(ti.keys); err != nil {
+				failures = append(failures, err)
+			} else {
+				record.Verified = true
+			}
+		}
+		if err = token.CheckTime(now); err != nil {
+			failures = append(failures, err)
+		}
+		for _, failure := range failures {
+			record.Errors = append(record.Errors, failure.Error())
+			violations = append(violations, &errors.ValidationError{
+				Message:           fmt.Sprintf("JWT in the %s is not valid", sent.source),
+				Reason:            failure.Error(),
+				ValidationType:    helpers.SecurityValidation,
+				ValidationSubType: "jwt",
+				SpecLine:          -1,
+				SpecCol:           -1,
+				HowToFix:          "Send a current token, signed with a trusted key",
+				RequestPath:       request.URL.Path,
+				RequestMethod:     request.Method,
+			})
+		}
+	}
+	return records, violations
+}
+
+// sentToken is a token sent with a request, and where it was found.
+type sentToken struct {
+	source string
+	raw    string
+}
+
+// tokens returns the tokens of a request that look like JWTs.
+func (ti *tokenInspector) tokens(request *http.Request) []sentToken {
+	var tokens []sentToken
+	if scheme, credentials, ok := strings.Cut(request.Header.Get("Authorization"), " "); ok &&
+		strings.EqualFold(scheme, "bearer") && jwt.IsJWT(strings.TrimSpace(credentials)) {
+		tokens = append(tokens, sentToken{source: "Authorization header", raw: strings.TrimSpace(credentials)})
+	}
+	for _, name := range ti.cookies {
+		if cookie, err := request.Cookie(name); err == nil && jwt.IsJWT(cookie.Value) {
+			tokens = append(tokens, sentToken{source: fmt.Sprintf("'%s' cookie", name), raw: cookie.Value})
+		}
+	}
+	return tokens
+}
+
+func (ti *tokenInspector) redactClaims(claims map[string]any) map[string]any {
+	if len(ti.redact) == 0 {
+		return claims
+	}
+	redacted := maps.Clone(claims)
+	for _, claim := range ti.redact {
+		if _, ok := redacted[claim]; ok {
+			redacted[claim] = redactedClaim
+		}
+	}
+	return redacted
+}
@@ -0,0 +1,131 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pb33f/ranch/bus"
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/ranch/store"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signedToken returns a JWT with claims, signed with an HMAC secret.
+func signedToken(claims, secret string) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	input := encode([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + encode([]byte(claims))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(input))
+	return input + "." + encode(mac.Sum(nil))
+}
+
+func TestTokenInspector_Inspect(t *testing.T) {
+	inspector, err := newTokenInspector(&shared.WiretapConfiguration{
+		JWT: &shared.WiretapJWTConfig{
+			Cookies:      []string{"session"},
+			Secret:       "s3cret",
+			RedactClaims: []string{"email"},
+		},
+	})
+	require.NoError(t, err)
+
+	now := time.Unix(1767225600, 0)
+	request := httptest.NewRequest(http.MethodGet, "/wiretap/giftshop/products", nil)
+	request.Header.Set("Authorization", "Bearer "+signedToken(`{"sub":"pb33f","email":"dave@pb33f.io"}`, "s3cret"))
+	request.AddCookie(&http.Cookie{Name: "session", Value: signedToken(`{"sub":"pb33f","exp":1767225000}`, "guess")})
+	request.AddCookie(&http.Cookie{Name: "ignored", Value: signedToken(`{}`, "s3cret")})
+
+	records, violations := inspector.inspect(request, now)
+	require.Len(t, records, 2)
+
+	assert.Equal(t, "Authorization header", records[0].Source)
+	assert.True(t, records[0].Verified)
+	assert.Empty(t, records[0].Errors)
+	assert.Equal(t, "HS256", records[0].Header["alg"])
+	assert.Equal(t, map[string]any{"sub": "pb33f", "email": redactedClaim}, records[0].Claims)
+
+	assert.Equal(t, "'session' cookie", records[1].Source)
+	assert.False(t, records[1].Verified)
+	assert.Equal(t, []string{"the signature does not match", "the token expired at 2025-12-31T23:50:00Z"},
+		records[1].Errors)
+
+	require.Len(t, violations, 2)
+	for _, violation := range violations {
+		assert.Equal(t, "JWT in the 'session' cookie is not valid", violation.Message)
+		assert.Equal(t, "security", violation.ValidationType)
+		assert.Equal(t, "jwt", violation.ValidationSubType)
+	}
+
+	// a nil inspector finds nothing, and opaque bearer tokens are not decoded.
+	var nothing *tokenInspector
+	records, violations = nothing.inspect(request, now)
+	assert.Nil(t, records)
+	assert.Nil(t, violations)
+
+	inspector, err = newTokenInspector(&shared.WiretapConfiguration{})
+	require.NoError(t, err)
+	request.Header.Set("Authorization", "Bearer opaque")
+	records, _ = inspector.inspect(request, now)
+	assert.Empty(t, records)
+}
+
+func TestNewTokenInspector_MissingKeys(t *testing.T) {
+	inspector, err := newTokenInspector(&shared.WiretapConfiguration{
+		JWT: &shared.WiretapJWTConfig{Cookies: []string{"session"}, JWKS: t.TempDir() + "/missing.json"},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to load JWKS")
+	assert.Nil(t, inspector)
+
+	// the service refuses to start, rather than leave tokens unverified.
+	ws, err := NewWiretapService(nil, &shared.WiretapConfiguration{
+		JWT: &shared.WiretapJWTConfig{Keys: []string{t.TempDir() + "/missing.pem"}},
+	}, store.NewManager(bus.NewEventBus()))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to load JWT keys: unable to load key")
+	assert.Nil(t, ws)
+}
+
+func TestHandleHttpRequestRecordsTokens(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	ws := newMockModeWiretapService(t, &shared.WiretapConfiguration{
+		RedirectURL:      upstream.URL,
+		RedirectProtocol: "http",
+		RedirectHost:     upstream.Listener.Addr().String(),
+		JWT:              &shared.WiretapJWTConfig{Secret: "s3cret"},
+	})
+
+	request, err := http.NewRequest(http.MethodGet, "https://api.pb33f.io/wiretap/giftshop/products", nil)
+	require.NoError(t, err)
+	request.Header.Set("Authorization", "Bearer "+signedToken(`{"sub":"pb33f"}`, "s3cret"))
+	id := uuid.New()
+	ws.handleHttpRequest(&model.Request{Id: &id, HttpRequest: request, HttpResponseWriter: httptest.NewRecorder()})
+
+	var stored *transaction.HttpTransaction
+	require.Eventually(t, func() bool {
+		txn, ok := ws.transactionStore.Get(id.String())
+		if ok {
+			stored = txn.(*transaction.HttpTransaction)
+		}
+		return stored != nil && stored.Tokens != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Len(t, stored.Tokens, 1)
+	assert.True(t, stored.Tokens[0].Verified)
+	assert.Equal(t, "pb33f", stored.Tokens[0].Claims["sub"])
+}
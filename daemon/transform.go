@@ -0,0 +1,105 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/wiretap/daemon/proxy"
+	"github.com/pb33f/wiretap/plugin"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/transaction"
+	"github.com/pb33f/wiretap/transform"
+)
+
+// pathTransforms returns the transforms of the bodies of requests to a path, if it has any.
+func pathTransforms(pathConfig *shared.WiretapPathConfig) *shared.WiretapTransformsConfig {
+	if pathConfig == nil {
+		return nil
+	}
+	return pathConfig.Transforms
+}
+
+// transformRequestBody returns the body to send upstream in place of the body of a request, and a record of the
+// transform, or nil when the body was not transformed. A body that fails to transform is sent unchanged.
+func transformRequestBody(config *shared.WiretapConfiguration, pathConfig *shared.WiretapPathConfig,
+	body []byte) ([]byte, *transaction.TransformRecord) {
+	transforms := pathTransforms(pathConfig)
+	if transforms == nil || len(transforms.Request) == 0 || len(body) == 0 {
+		return body, nil
+	}
+	transformed, err := transform.Apply(body, transforms.Request)
+	if err != nil {
+		wiretapLogger(config).Warn("[wiretap] unable to transform request body", "path", pathConfig.Target,
+			"error", err.Error())
+		return body, &transaction.TransformRecord{Errors: []string{"request: " + err.Error()}}
+	}
+	return transformed, &transaction.TransformRecord{UpstreamRequestBody: string(transformed)}
+}
+
+// responseTransformer returns the proxy hook that transforms the body of an upstream response before it is validated
+// and sent to the client, then passes it through the plugins transforming responses, recording the body the upstream
+// responded with on the transaction. A body that fails to transform is sent unchanged.
+func (ws *WiretapService) responseTransformer(request *model.Request, prep *PreparedRequest) proxy.ResponseTransformer {
+	transforms := prep.Transforms != nil && len(prep.Transforms.Response) > 0
+	plugins := ws.hasPlugin(plugin.TransformResponse)
+	if !transforms && !plugins {
+		return nil
+	}
+	return func(response *http.Response, body []byte) []byte {
+		if len(body) == 0 && !plugins {
+			return body
+		}
+		record := responseTransformRecord(prep)
+		failed := len(record.Errors)
+		transformed := body
+		if transforms && len(body) > 0 {
+			var err error
+			if transformed, err = transform.Apply(body, prep.Transforms.Response); err != nil {
+				prep.Config.Logger.Warn("[wiretap] unable to transform response body", "url",
+					request.HttpRequest.URL.String(), "error", err.Error())
+				record.Errors = append(record.Errors, "response: "+err.Error())
+				transformed = body
+			} else {
+				record.UpstreamResponseBody = string(body)
+			}
+		}
+		transformed, failures := ws.transformResponseWithPlugins(prep.NewReq, response, transformed)
+		record.Errors = append(record.Errors, failures...)
+		if !bytes.Equal(transformed, body) {
+			record.UpstreamResponseBody = string(body)
+		}
+		if record.UpstreamResponseBody != "" || len(record.Errors) > failed {
+			go ws.broadcastResponse(request, &transaction.HttpTransaction{Id: request.Id.String(), Transforms: record})
+		}
+		return transformed
+	}
+}
+
+// responseTransformSkipper returns the proxy hook that records a response streamed to the client without being
+// transformed, like a response too large to read, as a transform error on the transaction.
+func (ws *WiretapService) responseTransformSkipper(request *model.Request, prep *PreparedRequest) proxy.TransformSkipRecorder {
+	if (prep.Transforms == nil || len(prep.Transforms.Response) == 0) && !ws.hasPlugin(plugin.TransformResponse) {
+		return nil
+	}
+	return func(reason string) {
+		prep.Config.Logger.Warn("[wiretap] response body not transformed", "url", request.HttpRequest.URL.String(),
+			"reason", reason)
+		record := responseTransformRecord(prep)
+		record.Errors = append(record.Errors, "response: "+reason)
+		ws.broadcastResponse(request, &transaction.HttpTransaction{Id: request.Id.String(), Transforms: record})
+	}
+}
+
+// responseTransformRecord returns a record of the transforms of a response, keeping what was done to its request.
+func responseTransformRecord(prep *PreparedRequest) *transaction.TransformRecord {
+	record := &transaction.TransformRecord{}
+	if prep.Transform != nil {
+		record.UpstreamRequestBody = prep.Transform.UpstreamRequestBody
+		record.Errors = append(record.Errors, prep.Transform.Errors...)
+	}
+	return record
+}
@@ -0,0 +1,164 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/ranch/bus"
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/ranch/store"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/transaction"
+	"github.com/pb33f/wiretap/transform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleHttpRequestTransformsBodies(t *testing.T) {
+	var upstreamBody, upstreamLength string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		upstreamBody = string(body)
+		upstreamLength = strconv.FormatInt(r.ContentLength, 10)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Length", "49")
+		_, _ = w.Write([]byte(`{"id":1,"internal":{"shard":7},"status":"active"}`))
+	}))
+	defer upstream.Close()
+
+	config := &shared.WiretapConfiguration{
+		RedirectURL:        upstream.URL,
+		RedirectProtocol:   "http",
+		RedirectHost:       upstream.Listener.Addr().String(),
+		ReportFile:         t.TempDir() + "/violations.jsonl",
+		Logger:             slog.New(slog.NewTextHandler(io.Discard, nil)),
+		PathConfigurations: orderedmap.New[string, *shared.WiretapPathConfig](),
+	}
+	config.PathConfigurations.Set("/pets", &shared.WiretapPathConfig{
+		Transforms: &shared.WiretapTransformsConfig{
+			Request: []*shared.WiretapTransform{
+				{Op: transform.OpMove, From: "/petName", Path: "/name"},
+				{Op: transform.OpAdd, Path: "/source", Value: "wiretap"},
+			},
+			Response: []*shared.WiretapTransform{
+				{Op: transform.OpRemove, JSONPath: "$.internal"},
+			},
+		},
+	})
+	config.CompilePaths()
+
+	eventBus := bus.NewEventBus()
+	ws, err := NewWiretapService(nil, config, store.NewManager(eventBus))
+	require.NoError(t, err)
+	ws.setBroadcastChannel(eventBus.GetChannelManager().CreateChannel(WiretapBroadcastChan))
+	ws.controlsStore.Put(shared.ConfigKey, config, nil)
+
+	id := uuid.New()
+	rec := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/pets", strings.NewReader(`{"petName":"pb33f"}`))
+	request.Header.Set("Content-Length", "19")
+	ws.handleHttpRequest(&model.Request{
+		Id:                 &id,
+		HttpRequest:        request,
+		HttpResponseWriter: rec,
+	})
+
+	assert.Equal(t, `{"name":"pb33f","source":"wiretap"}`, upstreamBody)
+	assert.Equal(t, "35", upstreamLength)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"id":1,"status":"active"}`, rec.Body.String())
+	assert.Equal(t, "26", rec.Header().Get("Content-Length"))
+
+	var stored *transaction.HttpTransaction
+	require.Eventually(t, func() bool {
+		value, ok := ws.transactionStore.Get(id.String())
+		if ok {
+			stored = value.(*transaction.HttpTransaction)
+		}
+		return ok && stored.Transforms != nil && stored.Transforms.UpstreamResponseBody != ""
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, &transaction.TransformRecord{
+		UpstreamRequestBody:  `{"name":"pb33f","source":"wiretap"}`,
+		UpstreamResponseBody: `{"id":1,"internal":{"shard":7},"status":"active"}`,
+	}, stored.Transforms)
+	// the transaction keeps the body the client sent.
+	assert.Equal(t, `{"petName":"pb33f"}`, stored.Request.Body)
+}
+
+func TestTransformRequestBodyFailures(t *testing.T) {
+	pathConfig := &shared.WiretapPathConfig{Transforms: &shared.WiretapTransformsConfig{
+		Request: []*shared.WiretapTransform{{Op: transform.OpRemove, Path: "/missing"}},
+	}}
+	config := &shared.WiretapConfiguration{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	body, record := transformRequestBody(config, pathConfig, []byte(`{"id":1}`))
+	assert.Equal(t, `{"id":1}`, string(body))
+	assert.Equal(t, []string{"request: transform 1 (remove): path '/missing' does not exist"}, record.Errors)
+
+	body, record = transformRequestBody(config, pathConfig, nil)
+	assert.Nil(t, body)
+	assert.Nil(t, record)
+
+	_, record = transformRequestBody(config, nil, []byte(`{"id":1}`))
+	assert.Nil(t, record)
+}
+
+func TestHandleHttpRequestRecordsSkippedResponseTransforms(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = w.Write([]byte("{\"id\":1,\"internal\":{\"shard\":7}}\n"))
+	}))
+	defer upstream.Close()
+
+	config := &shared.WiretapConfiguration{
+		RedirectURL:        upstream.URL,
+		RedirectProtocol:   "http",
+		RedirectHost:       upstream.Listener.Addr().String(),
+		ReportFile:         t.TempDir() + "/violations.jsonl",
+		Logger:             slog.New(slog.NewTextHandler(io.Discard, nil)),
+		PathConfigurations: orderedmap.New[string, *shared.WiretapPathConfig](),
+	}
+	config.PathConfigurations.Set("/pets", &shared.WiretapPathConfig{
+		Transforms: &shared.WiretapTransformsConfig{
+			Response: []*shared.WiretapTransform{{Op: transform.OpRemove, JSONPath: "$.internal"}},
+		},
+	})
+	config.CompilePaths()
+
+	eventBus := bus.NewEventBus()
+	ws, err := NewWiretapService(nil, config, store.NewManager(eventBus))
+	require.NoError(t, err)
+	ws.setBroadcastChannel(eventBus.GetChannelManager().CreateChannel(WiretapBroadcastChan))
+	ws.controlsStore.Put(shared.ConfigKey, config, nil)
+
+	id := uuid.New()
+	rec := httptest.NewRecorder()
+	ws.handleHttpRequest(&model.Request{
+		Id:                 &id,
+		HttpRequest:        httptest.NewRequest(http.MethodGet, "/pets", nil),
+		HttpResponseWriter: rec,
+	})
+
+	// streams are sent as they arrive, the transaction says why they were not transformed.
+	assert.Equal(t, "{\"id\":1,\"internal\":{\"shard\":7}}\n", rec.Body.String())
+	var stored *transaction.HttpTransaction
+	require.Eventually(t, func() bool {
+		value, ok := ws.transactionStore.Get(id.String())
+		if ok {
+			stored = value.(*transaction.HttpTransaction)
+		}
+		return ok && stored.Transforms != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"response: " + transaction.ValidationSkippedStreaming}, stored.Transforms.Errors)
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	daemonvalidator "github.com/pb33f/wiretap/daemon/validator"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/specs"
+)
+
+// upstreamTarget is where a request is sent, when it is not the configured redirect URL.
+type upstreamTarget struct {
+	Protocol string
+	Host     string
+	Port     string
+	BasePath string
+}
+
+func newUpstreamTarget(target *url.URL, basePath string) *upstreamTarget {
+	protocol := target.Scheme
+	if protocol == "" {
+		protocol = "http"
+	}
+	port := target.Port()
+	if (protocol == "https" && port == "443") || (protocol == "http" && port == "80") {
+		port = ""
+	}
+	return &upstreamTarget{
+		Protocol: protocol,
+		Host:     target.Hostname(),
+		Port:     port,
+		BasePath: strings.TrimSuffix(basePath, "/"),
+	}
+}
+
+// upstreamTargetForRequest returns the upstream a request is routed to, instead of the redirect URL. Requests sent to
+// wiretap as a forward proxy go to the host they were addressed to, requests that match a specification with an
+// upstream override, or that are routed by the servers of the specification, go to that upstream.
+func (ws *WiretapService) upstreamTargetForRequest(config *shared.WiretapConfiguration, request *http.Request) *upstreamTarget {
+	if target := forwardProxyTarget(config, request); target != nil {
+		return target
+	}
+	if !config.RouteBySpecServers && len(config.SpecUpstreams) == 0 {
+		return nil
+	}
+
+	match := ws.getRouteMatchForHTTPRequest(request)
+	if match == nil || match.Document == nil || match.MatchedPath == "" {
+		return nil
+	}
+	if upstream := specUpstreamOverride(config, match.Document.DocumentName); upstream != "" {
+		target, err := url.Parse(upstream)
+		if err != nil || target.Host == "" {
+			wiretapLogger(config).Error("[wiretap] invalid upstream for specification",
+				"spec", match.Document.DocumentName, "url", upstream)
+			return nil
+		}
+		return newUpstreamTarget(target, target.Path)
+	}
+	if !config.RouteBySpecServers || match.Document.DocModel == nil {
+		return nil
+	}
+	return specServerTarget(match, request.Method)
+}
+
+// specUpstreamOverride returns the upstream configured for a specification, by its name or its file name.
+func specUpstreamOverride(config *shared.WiretapConfiguration, documentName string) string {
+	if upstream, ok := config.SpecUpstreams[documentName]; ok {
+		return upstream
+	}
+	return config.SpecUpstreams[filepath.Base(documentName)]
+}
+
+// specServerTarget returns the upstream of the server the route was matched with. When the request path already
+// carries the server's base path it is sent as is, otherwise the base path of the first server is added.
+func specServerTarget(match *daemonvalidator.RouteMatch, method string) *upstreamTarget {
+	doc := match.Document.DocModel
+	pathItem := doc.Paths.PathItems.GetOrZero(match.MatchedPath)
+	var serverURLs []string
+	if pathItem != nil {
+		serverURLs = specs.EffectiveOperationServerURLs(doc, pathItem,
+			pathItem.GetOperations().GetOrZero(strings.ToLower(method)))
+	} else {
+		serverURLs = specs.EffectiveOperationServerURLs(doc, nil, nil)
+	}
+
+	var first *url.URL
+	for _, serverURL := range serverURLs {
+		target, err := url.Parse(serverURL)
+		if err != nil || target.Host == "" {
+			continue
+		}
+		if match.BasePath != "" && specs.ServerBasePath(serverURL) == match.BasePath {
+			return newUpstreamTarget(target, "")
+		}
+		if first == nil {
+			first = target
+		}
+	}
+	if first == nil {
+		return nil
+	}
+	if match.BasePath != "" {
+		return newUpstreamTarget(first, "")
+	}
+	return newUpstreamTarget(first, specs.ServerBasePath(first.String()))
+}
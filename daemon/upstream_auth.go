@@ -0,0 +1,206 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pb33f/wiretap/shared"
+)
+
+const (
+	// tokens are fetched again when they expire within this window.
+	tokenExpiryWindow = 30 * time.Second
+	// tokens without an expiry are reused for this long.
+	defaultTokenLifetime = time.Hour
+	tokenRequestTimeout  = 30 * time.Second
+)
+
+type authProviderKey struct{}
+
+// withAuthProvider returns the request with the auth provider of the tokens it is sent upstream with.
+func withAuthProvider(request *http.Request, provider *shared.WiretapAuthProvider) *http.Request {
+	if provider == nil {
+		return request
+	}
+	return request.WithContext(context.WithValue(request.Context(), authProviderKey{}, *provider))
+}
+
+// effectiveAuthProvider returns the auth provider of requests to a path: the provider of the path config, or the
+// global provider when the path config has no auth of its own. Variables are replaced in the credentials.
+func effectiveAuthProvider(config *shared.WiretapConfiguration, pathConfig *shared.WiretapPathConfig) *shared.WiretapAuthProvider {
+	provider := config.AuthProvider
+	if pathConfig != nil {
+		if pathConfig.AuthProvider != nil {
+			provider = pathConfig.AuthProvider
+		} else if pathConfig.Auth != "" {
+			return nil
+		}
+	}
+	if provider == nil {
+		return nil
+	}
+	resolved := *provider
+	if len(config.CompiledVariables) > 0 {
+		resolved.ClientID = ReplaceWithVariables(config.CompiledVariables, resolved.ClientID)
+		resolved.ClientSecret = ReplaceWithVariables(config.CompiledVariables, resolved.ClientSecret)
+		resolved.RefreshToken = ReplaceWithVariables(config.CompiledVariables, resolved.RefreshToken)
+	}
+	return &resolved
+}
+
+// tokenSource caches the token of an auth provider, and fetches a new one when it is about to expire.
+type tokenSource struct {
+	provider     shared.WiretapAuthProvider
+	client       *http.Client
+	lock         sync.Mutex
+	token        string
+	expiry       time.Time
+	refreshToken string
+	modified     time.Time
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (ts *tokenSource) Token(ctx context.Context) (string, error) {
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+
+	switch ts.provider.Type {
+	case shared.AuthProviderTokenFile:
+		return ts.fileToken()
+	case shared.AuthProviderClientCredentials, shared.AuthProviderRefreshToken:
+		if ts.token != "" && time.Now().Add(tokenExpiryWindow).Before(ts.expiry) {
+			return ts.token, nil
+		}
+		return ts.fetchToken(ctx)
+	default:
+		return "", fmt.Errorf("unknown auth provider type '%s'", ts.provider.Type)
+	}
+}
+
+// fileToken returns the token in the token file, reading it again when the file changes.
+func (ts *tokenSource) fileToken() (string, error) {
+	info, err := os.Stat(ts.provider.TokenFile)
+	if err != nil {
+		return "", fmt.Errorf("unable to read token file: %w", err)
+	}
+	if ts.token != "" && info.ModTime().Equal(ts.modified) {
+		return ts.token, nil
+	}
+	contents, err := os.ReadFile(ts.provider.TokenFile)
+	if err != nil {
+		return "", fmt.Errorf("unable to read token file: %w", err)
+	}
+	token := strings.TrimSpace(string(contents))
+	if token == "" {
+		return "", fmt.Errorf("token file '%s' is empty", ts.provider.TokenFile)
+	}
+	ts.token, ts.modified = token, info.ModTime()
+	return ts.token, nil
+}
+
+// fetchToken requests a new token from the token endpoint of the provider.
+func (ts *tokenSource) fetchToken(ctx context.Context) (string, error) {
+	form := url.Values{"grant_type": {ts.provider.Type}}
+	if ts.provider.Type == shared.AuthProviderRefreshToken {
+		refreshToken := ts.refreshToken
+		if refreshToken == "" {
+			refreshToken = ts.provider.RefreshToken
+		}
+		form.Set("refresh_token", refreshToken)
+	}
+	if ts.provider.ClientID != "" {
+		form.Set("client_id", ts.provider.ClientID)
+	}
+	if ts.provider.ClientSecret != "" {
+		form.Set("client_secret", ts.provider.ClientSecret)
+	}
+	if len(ts.provider.Scopes) > 0 {
+		form.Set("scope", strings.Join(ts.provider.Scopes, " "))
+	}
+	if ts.provider.Audience != "" {
+		form.Set("audience", ts.provider.Audience)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, tokenRequestTimeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, ts.provider.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("invalid token URL: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+
+	response, err := ts.client.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("unable to fetch token: %w", err)
+	}
+	defer response.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(response.Body, 64*1024))
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return "", fmt.Errorf("token endpoint '%s' returned %d: %s",
+			ts.provider.TokenURL, response.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var token tokenResponse
+	if err = json.Unmarshal(body, &token); err != nil {
+		return "", fmt.Errorf("unable to parse token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("token endpoint '%s' returned no access token", ts.provider.TokenURL)
+	}
+
+	lifetime := defaultTokenLifetime
+	if token.ExpiresIn > 0 {
+		lifetime = time.Duration(token.ExpiresIn) * time.Second
+	}
+	ts.token, ts.expiry = token.AccessToken, time.Now().Add(lifetime)
+	if token.RefreshToken != "" {
+		ts.refreshToken = token.RefreshToken
+	}
+	return ts.token, nil
+}
+
+// tokenSourceFor returns the cached token source of an auth provider.
+func (u *upstreamTransport) tokenSourceFor(provider shared.WiretapAuthProvider) *tokenSource {
+	key := fmt.Sprintf("%s|%s|%s|%s|%s|%s|%s|%s", provider.Type, provider.TokenURL, provider.ClientID,
+		provider.ClientSecret, strings.Join(provider.Scopes, " "), provider.Audience, provider.RefreshToken, provider.TokenFile)
+	if cached, ok := u.tokens.Load(key); ok {
+		return cached.(*tokenSource)
+	}
+	cached, _ := u.tokens.LoadOrStore(key, &tokenSource{
+		provider: provider,
+		client:   &http.Client{Transport: u.base},
+	})
+	return cached.(*tokenSource)
+}
+
+// authorize returns the request with the token of its auth provider, if it has one.
+func (u *upstreamTransport) authorize(request *http.Request) (*http.Request, error) {
+	provider, ok := request.Context().Value(authProviderKey{}).(shared.WiretapAuthProvider)
+	if !ok {
+		return request, nil
+	}
+	token, err := u.tokenSourceFor(provider).Token(request.Context())
+	if err != nil {
+		return nil, fmt.Errorf("unable to authorize upstream request: %w", err)
+	}
+	authorized := request.Clone(request.Context())
+	authorized.Header.Set("Authorization", "Bearer "+token)
+	return authorized, nil
+}
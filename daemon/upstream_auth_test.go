@@ -0,0 +1,208 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/ranch/bus"
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/ranch/store"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEffectiveAuthProvider(t *testing.T) {
+	global := &shared.WiretapAuthProvider{Type: shared.AuthProviderTokenFile, TokenFile: "token"}
+	path := &shared.WiretapAuthProvider{Type: shared.AuthProviderClientCredentials, ClientSecret: "${secret}"}
+	config := &shared.WiretapConfiguration{Variables: map[string]string{"secret": "s3cr3t"}}
+	config.CompileVariables()
+
+	assert.Nil(t, effectiveAuthProvider(config, nil))
+
+	config.AuthProvider = global
+	assert.Equal(t, global, effectiveAuthProvider(config, nil))
+	assert.Equal(t, global, effectiveAuthProvider(config, &shared.WiretapPathConfig{}))
+
+	// static auth on a path replaces the global provider.
+	assert.Nil(t, effectiveAuthProvider(config, &shared.WiretapPathConfig{Auth: "user:pass"}))
+
+	resolved := effectiveAuthProvider(config, &shared.WiretapPathConfig{AuthProvider: path})
+	assert.Equal(t, "s3cr3t", resolved.ClientSecret)
+	assert.Equal(t, "${secret}", path.ClientSecret)
+}
+
+// newTokenEndpoint stubs an OAuth2 token endpoint that issues numbered tokens, and records the forms it receives.
+func newTokenEndpoint(t *testing.T, expiresIn int) (*httptest.Server, *atomic.Int32, chan map[string]string) {
+	var issued atomic.Int32
+	forms := make(chan map[string]string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		form := map[string]string{}
+		for key := range r.PostForm {
+			form[key] = r.PostForm.Get(key)
+		}
+		forms <- form
+		if form["client_secret"] == "wrong" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+			return
+		}
+		n := issued.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token":  fmt.Sprintf("token-%d", n),
+			"token_type":    "Bearer",
+			"expires_in":    expiresIn,
+			"refresh_token": fmt.Sprintf("refresh-%d", n),
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &issued, forms
+}
+
+func TestTokenSourceCachesAndRefreshesTokens(t *testing.T) {
+	tokenServer, issued, forms := newTokenEndpoint(t, 3600)
+	transport := newUpstreamTransport(http.DefaultTransport.(*http.Transport).Clone())
+
+	source := transport.tokenSourceFor(shared.WiretapAuthProvider{
+		Type:         shared.AuthProviderClientCredentials,
+		TokenURL:     tokenServer.URL,
+		ClientID:     "wiretap",
+		ClientSecret: "s3cr3t",
+		Scopes:       []string{"read", "write"},
+	})
+	token, err := source.Token(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+	assert.Equal(t, map[string]string{
+		"grant_type":    "client_credentials",
+		"client_id":     "wiretap",
+		"client_secret": "s3cr3t",
+		"scope":         "read write",
+	}, <-forms)
+
+	// the token is cached until it is about to expire.
+	token, _ = source.Token(t.Context())
+	assert.Equal(t, "token-1", token)
+	assert.Equal(t, int32(1), issued.Load())
+
+	source.expiry = time.Now().Add(tokenExpiryWindow / 2)
+	token, _ = source.Token(t.Context())
+	assert.Equal(t, "token-2", token)
+	<-forms
+
+	// refresh tokens rotate.
+	refresh := transport.tokenSourceFor(shared.WiretapAuthProvider{
+		Type:         shared.AuthProviderRefreshToken,
+		TokenURL:     tokenServer.URL,
+		RefreshToken: "refresh-0",
+	})
+	_, err = refresh.Token(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "refresh-0", (<-forms)["refresh_token"])
+	refresh.expiry = time.Now()
+	_, err = refresh.Token(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "refresh-3", (<-forms)["refresh_token"])
+
+	// token endpoint errors are returned.
+	_, err = transport.tokenSourceFor(shared.WiretapAuthProvider{
+		Type:         shared.AuthProviderClientCredentials,
+		TokenURL:     tokenServer.URL,
+		ClientSecret: "wrong",
+	}).Token(t.Context())
+	assert.ErrorContains(t, err, "returned 401")
+}
+
+func TestTokenSourceReadsTokenFiles(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("first\n"), 0o600))
+
+	transport := newUpstreamTransport(http.DefaultTransport.(*http.Transport).Clone())
+	source := transport.tokenSourceFor(shared.WiretapAuthProvider{Type: shared.AuthProviderTokenFile, TokenFile: tokenFile})
+	token, err := source.Token(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "first", token)
+
+	// the file is read again when it changes.
+	require.NoError(t, os.WriteFile(tokenFile, []byte("second"), 0o600))
+	require.NoError(t, os.Chtimes(tokenFile, time.Now(), time.Now().Add(time.Minute)))
+	token, _ = source.Token(t.Context())
+	assert.Equal(t, "second", token)
+
+	require.NoError(t, os.Remove(tokenFile))
+	_, err = source.Token(t.Context())
+	assert.ErrorContains(t, err, "unable to read token file")
+}
+
+func TestHandleHttpRequestSendsAuthProviderTokens(t *testing.T) {
+	tokenServer, issued, _ := newTokenEndpoint(t, 3600)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer upstream.Close()
+
+	paths := orderedmap.New[string, *shared.WiretapPathConfig]()
+	paths.Set("/broken/**", &shared.WiretapPathConfig{
+		Target: upstream.Listener.Addr().String(),
+		AuthProvider: &shared.WiretapAuthProvider{
+			Type:         shared.AuthProviderClientCredentials,
+			TokenURL:     tokenServer.URL,
+			ClientSecret: "wrong",
+		},
+	})
+	config := &shared.WiretapConfiguration{
+		RedirectURL:        upstream.URL,
+		RedirectProtocol:   "http",
+		RedirectHost:       upstream.Listener.Addr().String(),
+		AuthProvider:       &shared.WiretapAuthProvider{Type: shared.AuthProviderClientCredentials, TokenURL: tokenServer.URL},
+		ReportFile:         t.TempDir() + "/violations.jsonl",
+		Logger:             slog.New(slog.NewTextHandler(io.Discard, nil)),
+		PathConfigurations: paths,
+	}
+	config.CompilePaths()
+
+	eventBus := bus.NewEventBus()
+	ws, err := NewWiretapService(nil, config, store.NewManager(eventBus))
+	require.NoError(t, err)
+	ws.setBroadcastChannel(eventBus.GetChannelManager().CreateChannel(WiretapBroadcastChan))
+	ws.controlsStore.Put(shared.ConfigKey, config, nil)
+
+	send := func(path string) *httptest.ResponseRecorder {
+		id := uuid.New()
+		rec := httptest.NewRecorder()
+		ws.handleHttpRequest(&model.Request{
+			Id:                 &id,
+			HttpRequest:        httptest.NewRequest(http.MethodGet, path, nil),
+			HttpResponseWriter: rec,
+		})
+		return rec
+	}
+
+	for range 2 {
+		rec := send("/pets")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "Bearer token-1", rec.Body.String())
+	}
+	assert.Equal(t, int32(1), issued.Load())
+
+	// token failures fail the request.
+	rec := send("/broken/pets")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Contains(t, rec.Body.String(), "unable to authorize upstream request")
+}
@@ -0,0 +1,366 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pb33f/wiretap/shared"
+)
+
+const (
+	sigV4Algorithm      = "AWS4-HMAC-SHA256"
+	sigV4DateFormat     = "20060102T150405Z"
+	defaultHMACHeader   = "X-Signature"
+	defaultHMACCanon    = "${method}\n${path}\n${query}\n${timestamp}\n${bodySha256}"
+	defaultHMACTSHeader = "X-Timestamp"
+)
+
+var hmacCanonicalVariable = regexp.MustCompile(`\$\{([A-Za-z0-9_.-]+)}`)
+
+type signingKey struct{}
+
+// withSigning returns the request with the signing config it is signed with before it is sent upstream.
+func withSigning(request *http.Request, signing *shared.WiretapSigningConfig) *http.Request {
+	if signing == nil || (signing.SigV4 == nil && signing.HMAC == nil) {
+		return request
+	}
+	return request.WithContext(context.WithValue(request.Context(), signingKey{}, *signing))
+}
+
+// effectiveSigning returns the signing config of a path config, with variables replaced in its secrets.
+func effectiveSigning(config *shared.WiretapConfiguration, pathConfig *shared.WiretapPathConfig) *shared.WiretapSigningConfig {
+	if pathConfig == nil || pathConfig.Signing == nil {
+		return nil
+	}
+	resolved := *pathConfig.Signing
+	if len(config.CompiledVariables) == 0 {
+		return &resolved
+	}
+	if resolved.SigV4 != nil {
+		sigV4 := *resolved.SigV4
+		sigV4.AccessKeyID = ReplaceWithVariables(config.CompiledVariables, sigV4.AccessKeyID)
+		sigV4.SecretAccessKey = ReplaceWithVariables(config.CompiledVariables, sigV4.SecretAccessKey)
+		sigV4.SessionToken = ReplaceWithVariables(config.CompiledVariables, sigV4.SessionToken)
+		resolved.SigV4 = &sigV4
+	}
+	if resolved.HMAC != nil {
+		hmacConfig := *resolved.HMAC
+		hmacConfig.Secret = ReplaceWithVariables(config.CompiledVariables, hmacConfig.Secret)
+		resolved.HMAC = &hmacConfig
+	}
+	return &resolved
+}
+
+// sign returns the request signed with its signing config, if it has one.
+func sign(request *http.Request) (*http.Request, error) {
+	signing, ok := request.Context().Value(signingKey{}).(shared.WiretapSigningConfig)
+	if !ok {
+		return request, nil
+	}
+	body, err := signedBody(request)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read request body for signing: %w", err)
+	}
+	signed := request.Clone(request.Context())
+	now := time.Now().UTC()
+	if signing.SigV4 != nil {
+		credentials, err := resolveSigV4Credentials(signing.SigV4)
+		if err != nil {
+			return nil, fmt.Errorf("unable to sign upstream request: %w", err)
+		}
+		signSigV4(signed, body, signing.SigV4, credentials, now)
+	}
+	if signing.HMAC != nil {
+		if err = signHMAC(signed, body, signing.HMAC, now); err != nil {
+			return nil, fmt.Errorf("unable to sign upstream request: %w", err)
+		}
+	}
+	return signed, nil
+}
+
+func signedBody(request *http.Request) ([]byte, error) {
+	if request.GetBody == nil {
+		return nil, nil
+	}
+	body, err := request.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+type sigV4Credentials struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+}
+
+// resolveSigV4Credentials returns the configured credentials, or those of the AWS environment variables, or those of
+// a profile in the shared credentials file.
+func resolveSigV4Credentials(config *shared.WiretapSigV4Config) (*sigV4Credentials, error) {
+	if config.AccessKeyID != "" && config.SecretAccessKey != "" {
+		return &sigV4Credentials{config.AccessKeyID, config.SecretAccessKey, config.SessionToken}, nil
+	}
+	if id, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); id != "" && secret != "" {
+		return &sigV4Credentials{id, secret, os.Getenv("AWS_SESSION_TOKEN")}, nil
+	}
+
+	path := config.CredentialsFile
+	if path == "" {
+		path = os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	}
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("no AWS credentials configured")
+		}
+		path = filepath.Join(home, ".aws", "credentials")
+	}
+	profile := config.Profile
+	if profile == "" {
+		profile = os.Getenv("AWS_PROFILE")
+	}
+	if profile == "" {
+		profile = "default"
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("no AWS credentials configured, and unable to read credentials file: %w", err)
+	}
+	defer file.Close()
+	values := map[string]string{}
+	section := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			section = strings.TrimSpace(line[1 : len(line)-1])
+		case section == profile:
+			if key, value, found := strings.Cut(line, "="); found {
+				values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+			}
+		}
+	}
+	if values["aws_access_key_id"] == "" || values["aws_secret_access_key"] == "" {
+		return nil, fmt.Errorf("no AWS credentials for profile '%s' in '%s'", profile, path)
+	}
+	return &sigV4Credentials{values["aws_access_key_id"], values["aws_secret_access_key"], values["aws_session_token"]}, nil
+}
+
+// signSigV4 adds the AWS Signature Version 4 headers to a request.
+func signSigV4(request *http.Request, body []byte, config *shared.WiretapSigV4Config, credentials *sigV4Credentials, now time.Time) {
+	amzDate := now.Format(sigV4DateFormat)
+	date := amzDate[:8]
+	payloadHash := sha256Hex(body)
+
+	request.Header.Set("X-Amz-Date", amzDate)
+	if credentials.sessionToken != "" {
+		request.Header.Set("X-Amz-Security-Token", credentials.sessionToken)
+	}
+	if config.Service == "s3" {
+		request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	host := request.Host
+	if host == "" {
+		host = request.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for name, values := range request.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || lower == "content-type" {
+			trimmed := make([]string, len(values))
+			for i, value := range values {
+				trimmed[i] = strings.Join(strings.Fields(value), " ")
+			}
+			headers[lower] = strings.Join(trimmed, ",")
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		sigV4CanonicalURI(request, config.Service),
+		sigV4CanonicalQuery(request),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, config.Region, config.Service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{sigV4Algorithm, amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+credentials.secretAccessKey), date)
+	key = hmacSHA256(key, config.Region)
+	key = hmacSHA256(key, config.Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, credentials.accessKeyID, scope, signedHeaders, signature))
+}
+
+func sigV4CanonicalQuery(request *http.Request) string {
+	query := request.URL.Query()
+	pairs := make([]string, 0, len(query))
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, sigV4Escape(key, true)+"="+sigV4Escape(value, true))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// sigV4CanonicalURI encodes each segment of the decoded path of a request once, as S3 expects, and the result once
+// more for every other service. Encoded slashes stay inside the segment they were sent in.
+func sigV4CanonicalURI(request *http.Request, service string) string {
+	segments := strings.Split(request.URL.EscapedPath(), "/")
+	for i, segment := range segments {
+		if decoded, err := url.PathUnescape(segment); err == nil {
+			segment = decoded
+		}
+		segments[i] = sigV4Escape(segment, true)
+	}
+	canonicalURI := strings.Join(segments, "/")
+	if canonicalURI == "" {
+		canonicalURI = "/"
+	}
+	if service != "s3" {
+		canonicalURI = sigV4Escape(canonicalURI, false)
+	}
+	return canonicalURI
+}
+
+// sigV4Escape percent encodes everything but unreserved characters, and slashes unless encodeSlash is set.
+func sigV4Escape(value string, encodeSlash bool) string {
+	var escaped strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			escaped.WriteByte(c)
+			continue
+		}
+		escaped.WriteString(fmt.Sprintf("%%%02X", c))
+	}
+	return escaped.String()
+}
+
+// signHMAC adds the HMAC signature of the canonical string of a request to its signature header.
+func signHMAC(request *http.Request, body []byte, config *shared.WiretapHMACConfig, now time.Time) error {
+	var newHash func() hash.Hash
+	switch strings.ToLower(config.Algorithm) {
+	case "", "sha256":
+		newHash = sha256.New
+	case "sha1":
+		newHash = sha1.New
+	case "sha512":
+		newHash = sha512.New
+	default:
+		return fmt.Errorf("unsupported HMAC algorithm '%s'", config.Algorithm)
+	}
+
+	canonical := config.CanonicalString
+	if canonical == "" {
+		canonical = defaultHMACCanon
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	if strings.Contains(canonical, "${timestamp}") {
+		timestampHeader := config.TimestampHeader
+		if timestampHeader == "" {
+			timestampHeader = defaultHMACTSHeader
+		}
+		request.Header.Set(timestampHeader, timestamp)
+	}
+	canonical = hmacCanonicalVariable.ReplaceAllStringFunc(canonical, func(match string) string {
+		name := match[2 : len(match)-1]
+		switch name {
+		case "method":
+			return request.Method
+		case "path":
+			return request.URL.EscapedPath()
+		case "query":
+			return request.URL.RawQuery
+		case "host":
+			if request.Host != "" {
+				return request.Host
+			}
+			return request.URL.Host
+		case "body":
+			return string(body)
+		case "bodySha256":
+			return sha256Hex(body)
+		case "timestamp":
+			return timestamp
+		}
+		if header, found := strings.CutPrefix(name, "header."); found {
+			return request.Header.Get(header)
+		}
+		return match
+	})
+
+	mac := hmac.New(newHash, []byte(config.Secret))
+	mac.Write([]byte(canonical))
+	var signature string
+	switch strings.ToLower(config.Encoding) {
+	case "", "hex":
+		signature = hex.EncodeToString(mac.Sum(nil))
+	case "base64":
+		signature = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	default:
+		return fmt.Errorf("unsupported HMAC encoding '%s'", config.Encoding)
+	}
+
+	header := config.Header
+	if header == "" {
+		header = defaultHMACHeader
+	}
+	request.Header.Set(header, config.Prefix+signature)
+	return nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
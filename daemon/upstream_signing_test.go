@@ -0,0 +1,172 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/ranch/bus"
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/ranch/store"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignSigV4MatchesTheAWSTestSuite(t *testing.T) {
+	// the get-vanilla case of the AWS Signature Version 4 test suite.
+	request, _ := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	now, _ := time.Parse(sigV4DateFormat, "20150830T123600Z")
+	signSigV4(request, nil, &shared.WiretapSigV4Config{Service: "service", Region: "us-east-1"},
+		&sigV4Credentials{accessKeyID: "AKIDEXAMPLE", secretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}, now)
+
+	assert.Equal(t, "20150830T123600Z", request.Header.Get("X-Amz-Date"))
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+		"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		request.Header.Get("Authorization"))
+}
+
+func TestSigV4CanonicalURI(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodGet, "https://bucket.s3.amazonaws.com/photos/my%20cat!(1)%2Bdog.jpg", nil)
+	assert.Equal(t, "/photos/my%20cat%21%281%29%2Bdog.jpg", sigV4CanonicalURI(request, "s3"))
+	assert.Equal(t, "/photos/my%2520cat%2521%25281%2529%252Bdog.jpg", sigV4CanonicalURI(request, "execute-api"))
+
+	// an encoded slash belongs to the key, it is not a separator.
+	request, _ = http.NewRequest(http.MethodGet, "https://bucket.s3.amazonaws.com/a%2Fb/c", nil)
+	assert.Equal(t, "/a%2Fb/c", sigV4CanonicalURI(request, "s3"))
+
+	request, _ = http.NewRequest(http.MethodGet, "https://bucket.s3.amazonaws.com", nil)
+	assert.Equal(t, "/", sigV4CanonicalURI(request, "s3"))
+}
+
+func TestResolveSigV4Credentials(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	t.Setenv("AWS_PROFILE", "")
+
+	credentialsFile := filepath.Join(t.TempDir(), "credentials")
+	require.NoError(t, os.WriteFile(credentialsFile, []byte(`[default]
+aws_access_key_id = default-id
+aws_secret_access_key = default-secret
+
+# staging
+[staging]
+aws_access_key_id=staging-id
+aws_secret_access_key=staging-secret
+aws_session_token=staging-token
+`), 0o600))
+
+	credentials, err := resolveSigV4Credentials(&shared.WiretapSigV4Config{CredentialsFile: credentialsFile})
+	require.NoError(t, err)
+	assert.Equal(t, &sigV4Credentials{"default-id", "default-secret", ""}, credentials)
+
+	credentials, err = resolveSigV4Credentials(&shared.WiretapSigV4Config{CredentialsFile: credentialsFile, Profile: "staging"})
+	require.NoError(t, err)
+	assert.Equal(t, &sigV4Credentials{"staging-id", "staging-secret", "staging-token"}, credentials)
+
+	_, err = resolveSigV4Credentials(&shared.WiretapSigV4Config{CredentialsFile: credentialsFile, Profile: "prod"})
+	assert.ErrorContains(t, err, "no AWS credentials for profile 'prod'")
+
+	// the environment comes before the credentials file.
+	t.Setenv("AWS_ACCESS_KEY_ID", "env-id")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "env-secret")
+	credentials, err = resolveSigV4Credentials(&shared.WiretapSigV4Config{CredentialsFile: credentialsFile})
+	require.NoError(t, err)
+	assert.Equal(t, "env-id", credentials.accessKeyID)
+
+	// and configured credentials before the environment.
+	credentials, err = resolveSigV4Credentials(&shared.WiretapSigV4Config{AccessKeyID: "id", SecretAccessKey: "secret"})
+	require.NoError(t, err)
+	assert.Equal(t, "id", credentials.accessKeyID)
+}
+
+func TestSignHMAC(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	request, _ := http.NewRequest(http.MethodPost, "https://api.internal/orders?page=2", bytes.NewReader([]byte(`{"sku":"abc"}`)))
+	request.Header.Set("X-Tenant", "acme")
+
+	require.NoError(t, signHMAC(request, []byte(`{"sku":"abc"}`), &shared.WiretapHMACConfig{
+		Secret:          "s3cr3t",
+		Header:          "X-Hub-Signature-256",
+		Prefix:          "sha256=",
+		CanonicalString: "${method} ${path}?${query} ${header.X-Tenant} ${timestamp} ${body}",
+	}, now))
+
+	mac := hmac.New(sha256.New, []byte("s3cr3t"))
+	mac.Write([]byte(`POST /orders?page=2 acme 1700000000 {"sku":"abc"}`))
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), request.Header.Get("X-Hub-Signature-256"))
+	assert.Equal(t, "1700000000", request.Header.Get("X-Timestamp"))
+
+	err := signHMAC(request, nil, &shared.WiretapHMACConfig{Secret: "s3cr3t", Algorithm: "md5"}, now)
+	assert.ErrorContains(t, err, "unsupported HMAC algorithm 'md5'")
+}
+
+func TestHandleHttpRequestSignsRewrittenRequests(t *testing.T) {
+	var signature, signedPath, injected string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get("X-Signature")
+		signedPath = r.URL.Path
+		injected = r.Header.Get("X-Injected")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer upstream.Close()
+
+	paths := orderedmap.New[string, *shared.WiretapPathConfig]()
+	paths.Set("/signed/**", &shared.WiretapPathConfig{
+		Target:      upstream.Listener.Addr().String(),
+		PathRewrite: map[string]string{"^/signed/": "/internal/"},
+		Headers:     &shared.WiretapHeaderConfig{InjectHeaders: map[string]string{"X-Injected": "yes"}},
+		Signing: &shared.WiretapSigningConfig{HMAC: &shared.WiretapHMACConfig{
+			Secret:          "${secret}",
+			CanonicalString: "${method}\n${path}\n${header.X-Injected}",
+		}},
+	})
+	config := &shared.WiretapConfiguration{
+		RedirectURL:        upstream.URL,
+		RedirectProtocol:   "http",
+		RedirectHost:       upstream.Listener.Addr().String(),
+		Variables:          map[string]string{"secret": "s3cr3t"},
+		ReportFile:         t.TempDir() + "/violations.jsonl",
+		Logger:             slog.New(slog.NewTextHandler(io.Discard, nil)),
+		PathConfigurations: paths,
+	}
+	config.CompileVariables()
+	config.CompilePaths()
+
+	eventBus := bus.NewEventBus()
+	ws, err := NewWiretapService(nil, config, store.NewManager(eventBus))
+	require.NoError(t, err)
+	ws.setBroadcastChannel(eventBus.GetChannelManager().CreateChannel(WiretapBroadcastChan))
+	ws.controlsStore.Put(shared.ConfigKey, config, nil)
+
+	id := uuid.New()
+	rec := httptest.NewRecorder()
+	ws.handleHttpRequest(&model.Request{
+		Id:                 &id,
+		HttpRequest:        httptest.NewRequest(http.MethodGet, "/signed/orders", nil),
+		HttpResponseWriter: rec,
+	})
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	// the signature covers the rewritten path and injected headers.
+	assert.Equal(t, "/internal/orders", signedPath)
+	assert.Equal(t, "yes", injected)
+	mac := hmac.New(sha256.New, []byte("s3cr3t"))
+	mac.Write([]byte(strings.Join([]string{http.MethodGet, "/internal/orders", "yes"}, "\n")))
+	assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), signature)
+}
@@ -0,0 +1,136 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/ranch/bus"
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/ranch/store"
+	"github.com/pb33f/wiretap/daemon/proxy"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleHttpRequestRoutesToSpecUpstreams(t *testing.T) {
+	document := func(name, spec string) shared.ApiDocument {
+		doc, err := libopenapi.NewDocument([]byte(spec))
+		require.NoError(t, err)
+		docModel, err := doc.BuildV3Model()
+		require.NoError(t, err)
+		return shared.ApiDocument{DocumentName: name, Document: doc, DocumentModel: docModel}
+	}
+	docs := []shared.ApiDocument{
+		document("specs/users.yaml", `openapi: 3.1.0
+info:
+  title: users
+  version: "1.0"
+servers:
+  - url: https://{region}.users.example.com/api
+    variables:
+      region:
+        default: eu
+paths:
+  /users:
+    get:
+      responses:
+        "200":
+          description: ok
+`),
+		document("specs/accounts.yaml", `openapi: 3.1.0
+info:
+  title: accounts
+  version: "1.0"
+servers:
+  - url: http://accounts.internal:8081/v2
+paths:
+  /accounts:
+    get:
+      responses:
+        "200":
+          description: ok
+  /accounts/{id}/audit:
+    get:
+      servers:
+        - url: https://audit.internal
+      responses:
+        "200":
+          description: ok
+`),
+		document("specs/orders.yaml", `openapi: 3.1.0
+info:
+  title: orders
+  version: "1.0"
+servers:
+  - url: https://orders.example.com
+paths:
+  /orders:
+    get:
+      responses:
+        "200":
+          description: ok
+`),
+	}
+
+	config := &shared.WiretapConfiguration{
+		RedirectProtocol:   "http",
+		RedirectHost:       "gateway.local",
+		RouteBySpecServers: true,
+		SpecUpstreams:      map[string]string{"orders.yaml": "http://localhost:9000/orders-svc/"},
+		ReportFile:         t.TempDir() + "/violations.jsonl",
+		Logger:             slog.New(slog.NewTextHandler(io.Discard, nil)),
+		PathConfigurations: orderedmap.New[string, *shared.WiretapPathConfig](),
+	}
+	config.CompilePaths()
+
+	eventBus := bus.NewEventBus()
+	ws, err := NewWiretapService(docs, config, store.NewManager(eventBus))
+	require.NoError(t, err)
+	ws.setBroadcastChannel(eventBus.GetChannelManager().CreateChannel(WiretapBroadcastChan))
+	ws.controlsStore.Put(shared.ConfigKey, config, nil)
+
+	var upstream string
+	ws.proxy = proxy.NewHandler(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		upstream = req.URL.String()
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"text/plain"}},
+			Body:       io.NopCloser(strings.NewReader("ok")),
+		}, nil
+	}))
+
+	for path, expected := range map[string]string{
+		// the request already carries the server base path.
+		"/api/users": "https://eu.users.example.com/api/users",
+		// the base path of the server is added.
+		"/accounts?page=2": "http://accounts.internal:8081/v2/accounts?page=2",
+		// operation servers replace document servers.
+		"/accounts/1/audit": "https://audit.internal/accounts/1/audit",
+		// configured upstreams replace servers.
+		"/orders": "http://localhost:9000/orders-svc/orders",
+		// requests no specification matches go to the redirect URL.
+		"/unknown": "http://gateway.local/unknown",
+	} {
+		upstream = ""
+		id := uuid.New()
+		rec := httptest.NewRecorder()
+		ws.handleHttpRequest(&model.Request{
+			Id:                 &id,
+			HttpRequest:        httptest.NewRequest(http.MethodGet, path, nil),
+			HttpResponseWriter: rec,
+		})
+		assert.Equal(t, http.StatusOK, rec.Code, path)
+		assert.Equal(t, expected, upstream, path)
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/pb33f/wiretap/shared"
+)
+
+type tlsProfileKey struct{}
+
+// withTLSProfile returns the request with the TLS profile it is sent upstream with.
+func withTLSProfile(request *http.Request, profile *shared.WiretapTLSConfig) *http.Request {
+	if profile == nil {
+		return request
+	}
+	return request.WithContext(context.WithValue(request.Context(), tlsProfileKey{}, *profile))
+}
+
+// effectiveTLSProfile returns the TLS profile of requests to a path, the global profile with the settings of the
+// path config on top. A secure path config verifies certificates, unless its profile says otherwise. Returns nil when
+// nothing is configured, so the default transport is used.
+func effectiveTLSProfile(config *shared.WiretapConfiguration, pathConfig *shared.WiretapPathConfig) *shared.WiretapTLSConfig {
+	var profile shared.WiretapTLSConfig
+	configured := false
+	if config.UpstreamTLS != nil {
+		profile = *config.UpstreamTLS
+		configured = true
+	}
+	if pathConfig != nil && pathConfig.Secure {
+		verify := true
+		profile.Verify = &verify
+		configured = true
+	}
+	if pathConfig != nil && pathConfig.TLS != nil {
+		override := pathConfig.TLS
+		if override.CA != "" {
+			profile.CA = override.CA
+		}
+		if override.Certificate != "" {
+			profile.Certificate = override.Certificate
+			profile.Key = override.Key
+		}
+		if override.ServerName != "" {
+			profile.ServerName = override.ServerName
+		}
+		if override.MinVersion != "" {
+			profile.MinVersion = override.MinVersion
+		}
+		if override.Verify != nil {
+			profile.Verify = override.Verify
+		}
+		configured = true
+	}
+	if !configured {
+		return nil
+	}
+	return &profile
+}
+
+// newUpstreamTLSConfig builds the client TLS configuration of a profile. Without a profile, certificates are not
+// verified.
+func newUpstreamTLSConfig(profile *shared.WiretapTLSConfig) (*tls.Config, error) {
+	if profile == nil {
+		return &tls.Config{InsecureSkipVerify: true}, nil
+	}
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: profile.Verify == nil || !*profile.Verify,
+		ServerName:         profile.ServerName,
+	}
+	if profile.CA != "" {
+		bundle, err := os.ReadFile(profile.CA)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read upstream CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(bundle) {
+			return nil, fmt.Errorf("upstream CA bundle '%s' contains no PEM certificates", profile.CA)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if profile.Certificate != "" || profile.Key != "" {
+		certificate, err := tls.LoadX509KeyPair(profile.Certificate, profile.Key)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load upstream client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	switch profile.MinVersion {
+	case "":
+	case "1.0":
+		tlsConfig.MinVersion = tls.VersionTLS10
+	case "1.1":
+		tlsConfig.MinVersion = tls.VersionTLS11
+	case "1.2":
+		tlsConfig.MinVersion = tls.VersionTLS12
+	case "1.3":
+		tlsConfig.MinVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("unsupported upstream minimum TLS version '%s'", profile.MinVersion)
+	}
+	return tlsConfig, nil
+}
+
+// upstreamTransport sends requests upstream with a transport for the TLS profile of each request, so connections are
+// only shared by requests with the same profile. Requests without a profile use the default transport. Requests with
+// an auth provider are sent with its token, and requests with a signing config are signed last.
+type upstreamTransport struct {
+	base       *http.Transport
+	transports sync.Map
+	tokens     sync.Map
+}
+
+type profileTransport struct {
+	transport *http.Transport
+	err       error
+}
+
+func newUpstreamTransport(base *http.Transport) *upstreamTransport {
+	return &upstreamTransport{base: base}
+}
+
+// preloadTLSProfiles builds the transport of every path config with its own TLS profile, so a certificate or CA
+// bundle that can't be loaded stops wiretap from starting, instead of failing each request sent to the path.
+func (u *upstreamTransport) preloadTLSProfiles(config *shared.WiretapConfiguration) error {
+	if config.PathConfigurations == nil {
+		return nil
+	}
+	for x := config.PathConfigurations.First(); x != nil; x = x.Next() {
+		if x.Value() == nil {
+			continue
+		}
+		profile := effectiveTLSProfile(config, x.Value())
+		if profile == nil {
+			continue
+		}
+		if _, err := u.transportFor(*profile); err != nil {
+			return fmt.Errorf("path '%s': %w", x.Key(), err)
+		}
+	}
+	return nil
+}
+
+func (u *upstreamTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	request, err := u.authorize(request)
+	if err != nil {
+		return nil, err
+	}
+	if request, err = sign(request); err != nil {
+		return nil, err
+	}
+	profile, ok := request.Context().Value(tlsProfileKey{}).(shared.WiretapTLSConfig)
+	if !ok {
+		return u.base.RoundTrip(request)
+	}
+	transport, err := u.transportFor(profile)
+	if err != nil {
+		return nil, err
+	}
+	return transport.RoundTrip(request)
+}
+
+func (u *upstreamTransport) transportFor(profile shared.WiretapTLSConfig) (*http.Transport, error) {
+	key := profileCacheKey(profile)
+	if cached, ok := u.transports.Load(key); ok {
+		return cached.(*profileTransport).transport, cached.(*profileTransport).err
+	}
+	built := &profileTransport{}
+	built.transport = u.base.Clone()
+	built.transport.TLSClientConfig, built.err = newUpstreamTLSConfig(&profile)
+	cached, _ := u.transports.LoadOrStore(key, built)
+	return cached.(*profileTransport).transport, cached.(*profileTransport).err
+}
+
+func profileCacheKey(profile shared.WiretapTLSConfig) string {
+	verify := profile.Verify != nil && *profile.Verify
+	return fmt.Sprintf("%s|%s|%s|%s|%s|%t",
+		profile.CA, profile.Certificate, profile.Key, profile.ServerName, profile.MinVersion, verify)
+}
@@ -0,0 +1,139 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/ranch/bus"
+	"github.com/pb33f/ranch/store"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEffectiveTLSProfile(t *testing.T) {
+	verify, skip := true, false
+	config := &shared.WiretapConfiguration{}
+	assert.Nil(t, effectiveTLSProfile(config, nil))
+	assert.Nil(t, effectiveTLSProfile(config, &shared.WiretapPathConfig{}))
+
+	// secure paths verify certificates.
+	profile := effectiveTLSProfile(config, &shared.WiretapPathConfig{Secure: true})
+	require.NotNil(t, profile)
+	assert.True(t, *profile.Verify)
+
+	// unless told not to.
+	profile = effectiveTLSProfile(config, &shared.WiretapPathConfig{Secure: true, TLS: &shared.WiretapTLSConfig{Verify: &skip}})
+	assert.False(t, *profile.Verify)
+
+	// path settings replace global ones.
+	config.UpstreamTLS = &shared.WiretapTLSConfig{CA: "ca.pem", Certificate: "global.pem", Key: "global-key.pem", Verify: &verify}
+	profile = effectiveTLSProfile(config, &shared.WiretapPathConfig{TLS: &shared.WiretapTLSConfig{
+		Certificate: "path.pem",
+		Key:         "path-key.pem",
+		ServerName:  "staging.internal",
+	}})
+	assert.Equal(t, &shared.WiretapTLSConfig{
+		CA:          "ca.pem",
+		Certificate: "path.pem",
+		Key:         "path-key.pem",
+		ServerName:  "staging.internal",
+		Verify:      &verify,
+	}, profile)
+	assert.Equal(t, "global.pem", config.UpstreamTLS.Certificate)
+}
+
+func TestUpstreamTransportPresentsClientCertificates(t *testing.T) {
+	var clientCertificates atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientCertificates.Store(int32(len(r.TLS.PeerCertificates)))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	// the test server's own certificate doubles as the CA bundle and the client certificate.
+	dir := t.TempDir()
+	serverCertificate := server.TLS.Certificates[0]
+	caPath := filepath.Join(dir, "ca.pem")
+	certPath := filepath.Join(dir, "client.pem")
+	keyPath := filepath.Join(dir, "client-key.pem")
+	certificatePEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: serverCertificate.Certificate[0]})
+	keyDER, err := x509.MarshalPKCS8PrivateKey(serverCertificate.PrivateKey)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(caPath, certificatePEM, 0o600))
+	require.NoError(t, os.WriteFile(certPath, certificatePEM, 0o600))
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	transport := newUpstreamTransport(base)
+	send := func(profile *shared.WiretapTLSConfig) (*http.Response, error) {
+		request, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		return transport.RoundTrip(withTLSProfile(request, profile))
+	}
+
+	// without a client certificate the handshake is refused.
+	_, err = send(nil)
+	assert.Error(t, err)
+
+	verify := true
+	response, err := send(&shared.WiretapTLSConfig{CA: caPath, Certificate: certPath, Key: keyPath, Verify: &verify})
+	require.NoError(t, err)
+	_ = response.Body.Close()
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
+	assert.Equal(t, int32(1), clientCertificates.Load())
+
+	// verification fails without the CA bundle.
+	_, err = send(&shared.WiretapTLSConfig{Certificate: certPath, Key: keyPath, Verify: &verify})
+	assert.Error(t, err)
+
+	_, err = send(&shared.WiretapTLSConfig{MinVersion: "1.4"})
+	assert.ErrorContains(t, err, "unsupported upstream minimum TLS version")
+
+	first, _ := transport.transportFor(shared.WiretapTLSConfig{CA: caPath, Verify: &verify})
+	again, _ := transport.transportFor(shared.WiretapTLSConfig{CA: caPath, Verify: &verify})
+	assert.Same(t, first, again)
+}
+
+func TestNewWiretapServiceRefusesInvalidUpstreamTLS(t *testing.T) {
+	config := &shared.WiretapConfiguration{
+		UpstreamTLS: &shared.WiretapTLSConfig{CA: filepath.Join(t.TempDir(), "missing.pem")},
+	}
+	ws, err := NewWiretapService(nil, config, store.NewManager(bus.NewEventBus()))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to configure upstream TLS: unable to read upstream CA bundle")
+	assert.Nil(t, ws)
+}
+
+func TestNewWiretapServiceRefusesInvalidPathTLS(t *testing.T) {
+	config := &shared.WiretapConfiguration{
+		PathConfigurations: orderedmap.New[string, *shared.WiretapPathConfig](),
+	}
+	config.PathConfigurations.Set("/payments/**", &shared.WiretapPathConfig{
+		TLS: &shared.WiretapTLSConfig{
+			Certificate: filepath.Join(t.TempDir(), "missing.pem"),
+			Key:         filepath.Join(t.TempDir(), "missing-key.pem"),
+		},
+	})
+	ws, err := NewWiretapService(nil, config, store.NewManager(bus.NewEventBus()))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(),
+		"unable to configure upstream TLS: path '/payments/**': unable to load upstream client certificate")
+	assert.Nil(t, ws)
+}
@@ -5,6 +5,8 @@ package daemon
 
 import (
 	"net/http"
+	"slices"
+	"time"
 
 	"github.com/pb33f/ranch/model"
 	daemonvalidator "github.com/pb33f/wiretap/daemon/validator"
@@ -60,6 +62,10 @@ func (ws *WiretapService) ValidateResponseForRequest(
 	if ws.validator != nil {
 		validationErrors, cleanedErrors = ws.validator.ValidateResponseForRequest(validationRequest, returnedResponse)
 	}
+	if violations := ws.pluginResponseViolations(validationRequest, returnedResponse, preReadBody...); len(violations) > 0 {
+		validationErrors = append(validationErrors, violations...)
+		cleanedErrors = append(cleanedErrors, violations...)
+	}
 
 	var txn *transaction.HttpTransaction
 	if len(preReadBody) > 0 {
@@ -87,7 +93,7 @@ func (ws *WiretapService) ValidateResponseForRequest(
 // synchronous hard-error path must never deadlock on a consumer.
 func sendToStreamChan(ws *WiretapService, errs []*shared.WiretapValidationError) {
 	select {
-	case ws.streamChan <- errs:
+	case ws.streamChan <- redactViolations(ws.redactor, errs):
 	default:
 		if ws.config != nil && ws.config.Logger != nil {
 			ws.config.Logger.Debug("[wiretap] stream channel full; dropping validation errors from stream report")
@@ -104,6 +110,9 @@ func (ws *WiretapService) ValidateRequest(
 	if ws.validator != nil {
 		cleanedErrors = ws.validator.ValidateRequest(modelRequest, httpRequest)
 	}
+	tokens, tokenViolations := ws.tokens.inspect(httpRequest, time.Now())
+	cleanedErrors = append(cleanedErrors, shared.ConvertValidationErrors("", tokenViolations)...)
+	cleanedErrors = append(cleanedErrors, ws.pluginRequestViolations(httpRequest)...)
 
 	// record results
 	var buildTransConfig HttpTransactionConfig
@@ -123,6 +132,7 @@ func (ws *WiretapService) ValidateRequest(
 	if len(cleanedErrors) > 0 {
 		txn.RequestValidation = cleanedErrors
 	}
+	txn.Tokens = tokens
 	ws.storeRequestTransaction(modelRequest.Id.String(), txn)
 
 	// broadcast what we found.
@@ -147,6 +157,7 @@ func (ws *WiretapService) storeTransactionUpdate(key string, txn *transaction.Ht
 	if ws == nil || ws.transactionStore == nil || key == "" || txn == nil {
 		return
 	}
+	txn = ws.redactTransaction(txn)
 	existingValue, ok := ws.transactionStore.Get(key)
 	if !ok {
 		ws.transactionStore.Put(key, txn, nil)
@@ -174,9 +185,36 @@ func (ws *WiretapService) storeTransactionUpdate(key string, txn *transaction.Ht
 	if txn.ResponseValidation != nil {
 		merged.ResponseValidation = txn.ResponseValidation
 	}
+	if txn.ResponseValidationSkipped != "" {
+		merged.ResponseValidationSkipped = txn.ResponseValidationSkipped
+	}
 	if txn.SpecConflict != nil {
 		merged.SpecConflict = txn.SpecConflict
 	}
+	if txn.Websocket != nil {
+		merged.Websocket = txn.Websocket
+	}
+	if txn.Mirror != nil {
+		merged.Mirror = txn.Mirror
+	}
+	if txn.Resilience != nil {
+		merged.Resilience = txn.Resilience
+	}
+	if txn.MockFallback != "" {
+		merged.MockFallback = txn.MockFallback
+	}
+	if txn.Transforms != nil {
+		merged.Transforms = txn.Transforms
+	}
+	if txn.Tokens != nil {
+		merged.Tokens = txn.Tokens
+	}
+	// the errors of the request stages of a script may be stored after those of its response stages.
+	for _, message := range txn.ScriptErrors {
+		if !slices.Contains(merged.ScriptErrors, message) {
+			merged.ScriptErrors = append(slices.Clone(merged.ScriptErrors), message)
+		}
+	}
 
 	ws.transactionStore.Put(key, &merged, nil)
 }
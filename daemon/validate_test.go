@@ -69,10 +69,11 @@ paths:
 
 	eventBus := bus.NewEventBus()
 	storeManager := store.NewManager(eventBus)
-	ws := NewWiretapService([]shared.ApiDocument{{
+	ws, err := NewWiretapService([]shared.ApiDocument{{
 		DocumentName: "rewritten.yaml",
 		Document:     doc,
 	}}, config, storeManager)
+	require.NoError(t, err)
 	ws.controlsStore.Put(shared.ConfigKey, config, nil)
 
 	broadcaster := &recordingBroadcaster{}
@@ -115,7 +116,8 @@ func TestValidateRequestAttachesSpecConflict(t *testing.T) {
 	}
 	eventBus := bus.NewEventBus()
 	storeManager := store.NewManager(eventBus)
-	ws := NewWiretapService(docs, config, storeManager, report)
+	ws, err := NewWiretapService(docs, config, storeManager, report)
+	require.NoError(t, err)
 	ws.setBroadcastChannel(eventBus.GetChannelManager().CreateChannel(WiretapBroadcastChan))
 	ws.controlsStore.Put(shared.ConfigKey, config, nil)
 
@@ -146,7 +148,8 @@ func TestTransactionStoreMergesRequestAndResponseUpdates(t *testing.T) {
 	}
 	eventBus := bus.NewEventBus()
 	storeManager := store.NewManager(eventBus)
-	ws := NewWiretapService(nil, config, storeManager)
+	ws, err := NewWiretapService(nil, config, storeManager)
+	require.NoError(t, err)
 
 	id := uuid.New()
 	ws.storeResponseTransaction(id.String(), &transaction.HttpTransaction{
@@ -173,6 +176,48 @@ func TestTransactionStoreMergesRequestAndResponseUpdates(t *testing.T) {
 	assert.Equal(t, http.StatusOK, txn.Response.StatusCode)
 }
 
+func TestHandleHttpRequestMarksStreamedResponsesAsSkipped(t *testing.T) {
+	config := &shared.WiretapConfiguration{
+		RedirectProtocol:   "http",
+		RedirectHost:       "upstream.local",
+		ReportFile:         t.TempDir() + "/violations.jsonl",
+		Logger:             slog.New(slog.NewTextHandler(io.Discard, nil)),
+		PathConfigurations: orderedmap.New[string, *shared.WiretapPathConfig](),
+	}
+	config.CompilePaths()
+
+	eventBus := bus.NewEventBus()
+	storeManager := store.NewManager(eventBus)
+	ws, err := NewWiretapService([]shared.ApiDocument{buildDaemonLiteralSpec(t, "events.yaml", "/events")}, config, storeManager)
+	require.NoError(t, err)
+	ws.setBroadcastChannel(eventBus.GetChannelManager().CreateChannel(WiretapBroadcastChan))
+	ws.controlsStore.Put(shared.ConfigKey, config, nil)
+	ws.proxy = proxy.NewHandler(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode:    http.StatusOK,
+			Header:        http.Header{"Content-Type": []string{"text/event-stream"}},
+			ContentLength: -1,
+			Body:          io.NopCloser(strings.NewReader("data: ping\n\n")),
+		}, nil
+	}))
+
+	id := uuid.New()
+	rec := httptest.NewRecorder()
+	ws.handleHttpRequest(&model.Request{
+		Id:                 &id,
+		HttpRequest:        httptest.NewRequest(http.MethodGet, "http://wiretap.local/events", nil),
+		HttpResponseWriter: rec,
+	})
+	assert.Equal(t, "data: ping\n\n", rec.Body.String())
+
+	stored, ok := ws.transactionStore.Get(id.String())
+	require.True(t, ok)
+	txn := stored.(*transaction.HttpTransaction)
+	assert.Equal(t, transaction.ValidationSkippedStreaming, txn.ResponseValidationSkipped)
+	require.NotNil(t, txn.Response)
+	assert.Equal(t, "data: ping\n\n", txn.Response.Body)
+}
+
 func TestValidateRequestFiltersSpecConflictByCurrentPath(t *testing.T) {
 	docs := []shared.ApiDocument{
 		buildDaemonLiteralSpec(t, "profile.yaml", "/users/me"),
@@ -187,7 +232,8 @@ func TestValidateRequestFiltersSpecConflictByCurrentPath(t *testing.T) {
 	}
 	eventBus := bus.NewEventBus()
 	storeManager := store.NewManager(eventBus)
-	ws := NewWiretapService(docs, config, storeManager, report)
+	ws, err := NewWiretapService(docs, config, storeManager, report)
+	require.NoError(t, err)
 	ws.setBroadcastChannel(eventBus.GetChannelManager().CreateChannel(WiretapBroadcastChan))
 	ws.controlsStore.Put(shared.ConfigKey, config, nil)
 
@@ -11,6 +11,7 @@ import (
 	"github.com/pb33f/ranch/model"
 	"github.com/pb33f/wiretap/mock"
 	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/sse"
 	"github.com/pb33f/wiretap/validation"
 )
 
@@ -48,6 +49,29 @@ func New(documentValidators []DocumentValidator) *Validator {
 	}
 }
 
+func (v *Validator) DocumentValidators() []DocumentValidator {
+	if v == nil {
+		return nil
+	}
+	return v.documentValidators
+}
+
+// EventValidator returns a function that validates the server-sent events of a response to a request, against the
+// event schema of the matching specification. Returns nil if no specification declares an event stream.
+func (v *Validator) EventValidator(httpRequest *http.Request, statusCode int) func(*sse.Event) []*shared.WiretapValidationError {
+	docValidator, validationRequest := v.GetValidatorAndRequestForHTTPRequest(httpRequest)
+	if docValidator == nil || docValidator.DocModel == nil {
+		return nil
+	}
+	schema := validation.EventSchema(docValidator.DocModel, validationRequest, statusCode)
+	if schema == nil {
+		return nil
+	}
+	return func(event *sse.Event) []*shared.WiretapValidationError {
+		return shared.ConvertValidationErrors(docValidator.DocumentName, validation.ValidateEvent(schema, event))
+	}
+}
+
 func (v *Validator) GetValidatorForRequest(request *model.Request) *DocumentValidator {
 	if request == nil {
 		return nil
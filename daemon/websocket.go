@@ -6,12 +6,17 @@ package daemon
 import (
 	"crypto/tls"
 	"fmt"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/pb33f/ranch/model"
 	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/transaction"
 )
 
+// websocketCloseWait is how long a close message may take to be sent before the connection is dropped.
+const websocketCloseWait = time.Second
+
 var gorillaDropHeaders = []string{
 	// Gorilla fills in the following headers, and complains if they are already present
 	"Upgrade",
@@ -33,8 +38,9 @@ func (ws *WiretapService) handleWebsocketRequest(request *model.Request) {
 		ws.config.Logger.Error(fmt.Sprintf("Unable to find websocket config for URL: %s", websocketUrl))
 	}
 
-	// There's nothing to do if we're in mock mode
+	// Mock mode serves the scripted websocket mock, there's no upstream to connect to.
 	if config.MockMode {
+		ws.serveWebsocketMock(request, config, websocketUrl, websocketConfig)
 		return
 	}
 
@@ -91,7 +97,7 @@ func (ws *WiretapService) handleWebsocketRequest(request *model.Request) {
 	// Open a new websocket connection with the server
 	dialer := *websocket.DefaultDialer
 	dialer.TLSClientConfig = &tls.Config{InsecureSkipVerify: !*websocketConfig.VerifyCert}
-	serverConn, _, err := dialer.Dial(newRequest.URL.String(), newRequest.Header)
+	serverConn, upgradeResponse, err := dialer.Dial(newRequest.URL.String(), newRequest.Header)
 	if err != nil {
 		ws.config.Logger.Error(fmt.Sprintf("Unable to connect to remote server; websocket connection failed: %s", err))
 		return
@@ -100,6 +106,22 @@ func (ws *WiretapService) handleWebsocketRequest(request *model.Request) {
 		_ = serverConn.Close()
 	}(serverConn)
 
+	// Record the upgrade as the session transaction, frames are captured on it as they are proxied.
+	txn := BuildHttpTransaction(HttpTransactionConfig{
+		OriginalRequest:   request.HttpRequest,
+		NewRequest:        newRequest,
+		ID:                request.Id,
+		TransactionConfig: config,
+		DropHeaders:       dropHeaders,
+		InjectHeaders:     injectHeaders,
+		Auth:              auth,
+	})
+	ws.storeRequestTransaction(request.Id.String(), txn)
+	ws.broadcastRequest(request, txn)
+	ws.broadcastResponse(request, BuildResponse(request, upgradeResponse))
+	frames := ws.newFrameRecorder(request, config, websocketConfig)
+	defer frames.stop()
+
 	clientSentinel := make(chan struct{})
 	serverSentinel := make(chan struct{})
 
@@ -111,9 +133,11 @@ func (ws *WiretapService) handleWebsocketRequest(request *model.Request) {
 			if err != nil {
 				closeCode, isUnexpected := getCloseCode(err)
 				logWebsocketClose(config, closeCode, isUnexpected)
-				_ = clientConn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+				frames.close(closeCode)
+				closeWebsocket(clientConn)
 				return
 			}
+			frames.record(transaction.FrameFromClient, messageType, message)
 
 			err = serverConn.WriteMessage(messageType, message)
 			if err != nil {
@@ -132,9 +156,11 @@ func (ws *WiretapService) handleWebsocketRequest(request *model.Request) {
 			if err != nil {
 				closeCode, isUnexpected := getCloseCode(err)
 				logWebsocketClose(config, closeCode, isUnexpected)
-				_ = clientConn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+				frames.close(closeCode)
+				closeWebsocket(clientConn)
 				return
 			}
+			frames.record(transaction.FrameFromServer, messageType, message)
 
 			err = clientConn.WriteMessage(messageType, message)
 			if err != nil {
@@ -155,6 +181,13 @@ func (ws *WiretapService) handleWebsocketRequest(request *model.Request) {
 	}
 }
 
+// closeWebsocket sends a normal close message. Both proxying goroutines write to the client connection and only one
+// message can be written at a time, close messages are sent as control messages, which may be written concurrently.
+func closeWebsocket(conn *websocket.Conn) {
+	_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+		time.Now().Add(websocketCloseWait))
+}
+
 func getCloseCode(err error) (int, bool) {
 	unexpectedClose := websocket.IsUnexpectedCloseError(err,
 		websocket.CloseNormalClosure,
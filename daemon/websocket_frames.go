@@ -0,0 +1,228 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/wiretap/asyncapi"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/transaction"
+	"github.com/pb33f/wiretap/validation"
+)
+
+const (
+	// maxWebsocketFrames caps the frames kept on a session, the oldest are dropped once it is full.
+	maxWebsocketFrames = 1000
+	// frameQueueSize caps the frames waiting to be recorded, frames arriving while it is full are dropped.
+	frameQueueSize = 256
+)
+
+// frameRecorder captures the frames of a proxied websocket on its session transaction, and broadcasts each one as it
+// is captured. When the websocket carries an AsyncAPI channel, data frames are validated against its messages: frames
+// from the client are reported like request violations, frames from the server like response violations. Frames are
+// queued by the goroutines relaying them, and validated, stored and broadcast one at a time by the recorder, so a slow
+// contract never holds up the websocket.
+type frameRecorder struct {
+	ws       *WiretapService
+	request  *model.Request
+	channel  *asyncapi.Channel
+	specName string
+	maxSize  int64
+	queue    chan *queuedFrame
+
+	mu      sync.Mutex
+	count   map[string]int
+	dropped int
+	stopped bool
+
+	// owned by the recorder goroutine.
+	payloadSize int64
+	closeCode   int
+	evicted     int
+}
+
+// queuedFrame is a frame waiting to be recorded, or the close of the websocket when frame is nil.
+type queuedFrame struct {
+	frame     *transaction.WebsocketFrame
+	number    int
+	payload   []byte
+	closeCode int
+}
+
+func (ws *WiretapService) newFrameRecorder(request *model.Request, config *shared.WiretapConfiguration,
+	websocketConfig *shared.WiretapWebsocketConfig) *frameRecorder {
+	documentPath := websocketAsyncAPI(config, websocketConfig)
+	r := &frameRecorder{
+		ws:       ws,
+		request:  request,
+		channel:  websocketChannel(ws.asyncAPIDocument(documentPath), websocketConfig, request.HttpRequest.URL.Path),
+		specName: filepath.Base(documentPath),
+		maxSize:  config.GetMaxValidatedBodySize(),
+		queue:    make(chan *queuedFrame, frameQueueSize),
+		count:    make(map[string]int),
+	}
+	go r.run()
+	return r
+}
+
+// record queues a frame read from the client or the server.
+func (r *frameRecorder) record(direction string, messageType int, payload []byte) {
+	frame := &transaction.WebsocketFrame{
+		Direction: direction,
+		Opcode:    messageType,
+		Size:      len(payload),
+		Timestamp: time.Now().UnixMilli(),
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count[direction]++
+	r.enqueue(&queuedFrame{frame: frame, number: r.count[direction], payload: payload})
+}
+
+// close queues the close code of the websocket.
+func (r *frameRecorder) close(code int) {
+	if code <= 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.enqueue(&queuedFrame{closeCode: code})
+}
+
+// stop lets the recorder finish the frames already queued, and ignore any that are read after the websocket closed.
+func (r *frameRecorder) stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.stopped {
+		r.stopped = true
+		close(r.queue)
+	}
+}
+
+// enqueue queues a frame without waiting, counting it as dropped when the queue is full. Callers hold the lock.
+func (r *frameRecorder) enqueue(queued *queuedFrame) {
+	if r.stopped {
+		return
+	}
+	select {
+	case r.queue <- queued:
+	default:
+		r.dropped++
+	}
+}
+
+func (r *frameRecorder) run() {
+	for queued := range r.queue {
+		if queued.frame == nil {
+			if r.closeCode == 0 {
+				r.closeCode = queued.closeCode
+				r.update(nil)
+			}
+			continue
+		}
+		r.validate(queued)
+		r.update(queued.frame)
+	}
+}
+
+// validate validates a data frame against the messages of the channel, and keeps its payload while the payloads of
+// the session are within the maximum validated body size.
+func (r *frameRecorder) validate(queued *queuedFrame) {
+	frame, payload := queued.frame, queued.payload
+	if r.channel != nil && (frame.Opcode == websocket.TextMessage || frame.Opcode == websocket.BinaryMessage) {
+		action := asyncapi.ActionReceive
+		if frame.Direction == transaction.FrameFromServer {
+			action = asyncapi.ActionSend
+		}
+		message, errs := validation.ValidateMessage(r.channel.MessagesFor(action), payload)
+		if message != nil {
+			frame.Message = message.Name
+		}
+		if len(errs) > 0 {
+			frame.Validation = shared.ConvertValidationErrors(r.specName, errs)
+			for _, err := range frame.Validation {
+				err.Message = fmt.Sprintf("Websocket %s frame %d: %s", frame.Direction, queued.number, err.Message)
+			}
+		}
+	}
+
+	if frame.Opcode == websocket.TextMessage && r.payloadSize+int64(len(payload)) <= r.maxSize {
+		frame.Payload = string(payload)
+		r.payloadSize += int64(len(payload))
+	}
+}
+
+// update adds the latest frame to the stored session, and broadcasts it on its own.
+func (r *frameRecorder) update(frame *transaction.WebsocketFrame) {
+	id := r.request.Id.String()
+	live := &transaction.HttpTransaction{Id: id, Websocket: &transaction.WebsocketSession{CloseCode: r.closeCode}}
+	if r.channel != nil {
+		live.Websocket.Channel = r.channel.Name
+	}
+	if frame != nil {
+		live.Websocket.Frames = []*transaction.WebsocketFrame{frame}
+	}
+	live.Websocket.DroppedFrames = r.store(r.ws.redactTransaction(live))
+
+	switch {
+	case frame == nil || len(frame.Validation) == 0:
+		r.ws.activeBroadcaster().Response(r.request, live)
+	case frame.Direction == transaction.FrameFromClient:
+		sendToStreamChan(r.ws, frame.Validation)
+		r.ws.activeBroadcaster().RequestValidationErrors(r.request, frame.Validation, live)
+	default:
+		sendToStreamChan(r.ws, frame.Validation)
+		r.ws.activeBroadcaster().ResponseValidationErrors(r.request, live, frame.Validation)
+	}
+}
+
+// store appends the (redacted) latest frame and its violations to the stored session, dropping the oldest frames and
+// violations once the session holds maxWebsocketFrames of them. Stored frames are shared between the versions of the
+// session, and never rewritten; the recorder is their only writer. It returns how many frames have been dropped.
+func (r *frameRecorder) store(update *transaction.HttpTransaction) int {
+	merged := &transaction.HttpTransaction{Id: update.Id}
+	if existing, ok := r.ws.transactionStore.Get(update.Id); ok {
+		if txn, ok := existing.(*transaction.HttpTransaction); ok && txn != nil {
+			*merged = *txn
+		}
+	}
+
+	session := *update.Websocket
+	if merged.Websocket != nil {
+		session.Frames = append(merged.Websocket.Frames, update.Websocket.Frames...)
+	}
+	if len(session.Frames) > maxWebsocketFrames {
+		r.evicted += len(session.Frames) - maxWebsocketFrames
+		session.Frames = session.Frames[len(session.Frames)-maxWebsocketFrames:]
+	}
+	r.mu.Lock()
+	session.DroppedFrames = r.evicted + r.dropped
+	r.mu.Unlock()
+	merged.Websocket = &session
+
+	for _, frame := range update.Websocket.Frames {
+		if frame.Direction == transaction.FrameFromClient {
+			merged.RequestValidation = appendViolations(merged.RequestValidation, frame.Validation)
+		} else {
+			merged.ResponseValidation = appendViolations(merged.ResponseValidation, frame.Validation)
+		}
+	}
+	r.ws.transactionStore.Put(update.Id, merged, nil)
+	return session.DroppedFrames
+}
+
+// appendViolations appends the violations of a frame to those of the session, keeping the latest maxWebsocketFrames.
+func appendViolations(violations, frameViolations []*shared.WiretapValidationError) []*shared.WiretapValidationError {
+	if len(frameViolations) == 0 {
+		return violations
+	}
+	violations = append(violations, frameViolations...)
+	return violations[max(len(violations)-maxWebsocketFrames, 0):]
+}
@@ -0,0 +1,200 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/ranch/bus"
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/ranch/store"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleWebsocketRequestCapturesAndValidatesFrames(t *testing.T) {
+	spec := filepath.Join(t.TempDir(), "chat.yaml")
+	require.NoError(t, os.WriteFile(spec, []byte(`asyncapi: 3.0.0
+info:
+  title: chat
+  version: 1.0.0
+channels:
+  chat:
+    address: /chat
+    messages:
+      say:
+        payload:
+          type: object
+          required: [text]
+          properties:
+            text:
+              type: string
+      said:
+        payload:
+          type: object
+          required: [text, user]
+          properties:
+            text:
+              type: string
+            user:
+              type: string
+operations:
+  receiveSay:
+    action: receive
+    channel:
+      $ref: '#/channels/chat'
+    messages:
+      - $ref: '#/channels/chat/messages/say'
+  sendSaid:
+    action: send
+    channel:
+      $ref: '#/channels/chat'
+    messages:
+      - $ref: '#/channels/chat/messages/said'
+`), 0o644))
+
+	// the upstream answers every message it is sent, without a user.
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			_ = conn.WriteMessage(websocket.TextMessage, message)
+		}
+	}))
+	defer upstream.Close()
+	upstreamURL, _ := url.Parse(upstream.URL)
+
+	verify := false
+	config := &shared.WiretapConfiguration{
+		RedirectProtocol:   "http",
+		RedirectHost:       upstreamURL.Hostname(),
+		RedirectPort:       upstreamURL.Port(),
+		AsyncAPI:           spec,
+		ReportFile:         t.TempDir() + "/violations.jsonl",
+		Logger:             slog.New(slog.NewTextHandler(io.Discard, nil)),
+		PathConfigurations: orderedmap.New[string, *shared.WiretapPathConfig](),
+		WebsocketConfigs: map[string]*shared.WiretapWebsocketConfig{
+			"/chat": {VerifyCert: &verify},
+		},
+	}
+	config.CompilePaths()
+
+	eventBus := bus.NewEventBus()
+	ws, err := NewWiretapService(nil, config, store.NewManager(eventBus))
+	require.NoError(t, err)
+	ws.setBroadcastChannel(eventBus.GetChannelManager().CreateChannel(WiretapBroadcastChan))
+	ws.controlsStore.Put(shared.ConfigKey, config, nil)
+
+	id := uuid.New()
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		ws.HandleWebsocketRequest(&model.Request{Id: &id, HttpRequest: r, HttpResponseWriter: w})
+	}))
+	defer server.Close()
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/chat", nil)
+	require.NoError(t, err)
+	_ = client.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	for _, message := range []string{`{"text":"hello"}`, `{"text":1}`} {
+		require.NoError(t, client.WriteMessage(websocket.TextMessage, []byte(message)))
+		_, _, err = client.ReadMessage()
+		require.NoError(t, err)
+	}
+	require.NoError(t, client.WriteMessage(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseGoingAway, "")))
+	_ = client.Close()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("websocket proxy did not finish")
+	}
+
+	// frames are recorded in the background, the close is the last of them.
+	var txn *transaction.HttpTransaction
+	require.Eventually(t, func() bool {
+		stored, ok := ws.transactionStore.Get(id.String())
+		if ok {
+			txn = stored.(*transaction.HttpTransaction)
+		}
+		return ok && txn.Websocket != nil && txn.Websocket.CloseCode != 0
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NotNil(t, txn.Request)
+	require.NotNil(t, txn.Response)
+	assert.Equal(t, http.StatusSwitchingProtocols, txn.Response.StatusCode)
+
+	require.NotNil(t, txn.Websocket)
+	assert.Equal(t, "chat", txn.Websocket.Channel)
+	assert.Equal(t, websocket.CloseGoingAway, txn.Websocket.CloseCode)
+	require.Len(t, txn.Websocket.Frames, 4)
+
+	first := txn.Websocket.Frames[0]
+	assert.Equal(t, transaction.FrameFromClient, first.Direction)
+	assert.Equal(t, websocket.TextMessage, first.Opcode)
+	assert.Equal(t, len(`{"text":"hello"}`), first.Size)
+	assert.Equal(t, `{"text":"hello"}`, first.Payload)
+	assert.Equal(t, "say", first.Message)
+	assert.Empty(t, first.Validation)
+
+	// the echo is missing the user a server message needs.
+	assert.Equal(t, transaction.FrameFromServer, txn.Websocket.Frames[1].Direction)
+	assert.NotEmpty(t, txn.Websocket.Frames[1].Validation)
+
+	// the client sent a number.
+	assert.NotEmpty(t, txn.Websocket.Frames[2].Validation)
+
+	require.NotEmpty(t, txn.RequestValidation)
+	assert.True(t, strings.HasPrefix(txn.RequestValidation[0].Message, "Websocket client frame 2: "))
+	assert.Equal(t, "chat.yaml", txn.RequestValidation[0].SpecName)
+	require.NotEmpty(t, txn.ResponseValidation)
+	assert.True(t, strings.HasPrefix(txn.ResponseValidation[0].Message, "Websocket server frame 1: "))
+}
+
+func TestFrameRecorderCapsTheFramesOfASession(t *testing.T) {
+	config := &shared.WiretapConfiguration{
+		ReportFile: t.TempDir() + "/violations.jsonl",
+		Logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	eventBus := bus.NewEventBus()
+	ws, err := NewWiretapService(nil, config, store.NewManager(eventBus))
+	require.NoError(t, err)
+	ws.setBroadcastChannel(eventBus.GetChannelManager().CreateChannel(WiretapBroadcastChan))
+
+	id := uuid.New()
+	recorder := &frameRecorder{ws: ws, request: &model.Request{Id: &id}, maxSize: 1 << 20, count: make(map[string]int)}
+	for i := range maxWebsocketFrames + 5 {
+		recorder.update(&transaction.WebsocketFrame{Direction: transaction.FrameFromClient, Size: i})
+	}
+
+	// only the latest frames are kept, each one stored once.
+	stored, ok := ws.transactionStore.Get(id.String())
+	require.True(t, ok)
+	session := stored.(*transaction.HttpTransaction).Websocket
+	require.Len(t, session.Frames, maxWebsocketFrames)
+	assert.Equal(t, 5, session.Frames[0].Size)
+	assert.Equal(t, maxWebsocketFrames+4, session.Frames[maxWebsocketFrames-1].Size)
+	assert.Equal(t, 5, session.DroppedFrames)
+}
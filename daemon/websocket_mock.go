@@ -0,0 +1,54 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"github.com/gorilla/websocket"
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/wiretap/daemon/wsmock"
+	"github.com/pb33f/wiretap/shared"
+)
+
+// serveWebsocketMock accepts a websocket upgrade in mock mode and serves the mock scripted for the websocket. A
+// websocket without a mock configuration stays open, but silent.
+func (ws *WiretapService) serveWebsocketMock(request *model.Request, config *shared.WiretapConfiguration,
+	websocketUrl string, websocketConfig *shared.WiretapWebsocketConfig) {
+
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+	}
+	clientConn, err := upgrader.Upgrade(request.HttpResponseWriter, request.HttpRequest, nil)
+	if err != nil {
+		ws.config.Logger.Error("Unable to upgrade mock websocket connection")
+		return
+	}
+	defer func(clientConn *websocket.Conn) {
+		_ = clientConn.Close()
+	}(clientConn)
+
+	ws.websocketMock(config, websocketUrl, websocketConfig).Serve(clientConn, ws.config.Logger)
+}
+
+// websocketMock returns the compiled mock script for a websocket. Messages are generated from the mock's AsyncAPI
+// document, or the document used to validate the websocket.
+func (ws *WiretapService) websocketMock(config *shared.WiretapConfiguration, websocketUrl string,
+	websocketConfig *shared.WiretapWebsocketConfig) *wsmock.Script {
+	if script, ok := ws.websocketMocks.Load(websocketUrl); ok {
+		return script.(*wsmock.Script)
+	}
+
+	var mockConfig *shared.WiretapWebsocketMockConfig
+	if websocketConfig != nil {
+		mockConfig = websocketConfig.Mock
+	}
+	documentPath := websocketAsyncAPI(config, websocketConfig)
+	if mockConfig != nil && mockConfig.AsyncAPI != "" {
+		documentPath = mockConfig.AsyncAPI
+	}
+
+	script, _ := ws.websocketMocks.LoadOrStore(websocketUrl,
+		wsmock.NewScript(mockConfig, ws.asyncAPIDocument(documentPath)))
+	return script.(*wsmock.Script)
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package daemon
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/pb33f/ranch/bus"
+	"github.com/pb33f/ranch/model"
+	"github.com/pb33f/ranch/store"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleWebsocketRequestServesMockInMockMode(t *testing.T) {
+	spec := filepath.Join(t.TempDir(), "asyncapi.yaml")
+	require.NoError(t, os.WriteFile(spec, []byte(`asyncapi: 3.0.0
+info:
+  title: ticks
+  version: 1.0.0
+channels:
+  ticks:
+    messages:
+      tick:
+        payload:
+          type: object
+          required: [count]
+          properties:
+            count:
+              type: integer
+`), 0o644))
+
+	config := &shared.WiretapConfiguration{
+		MockMode: true,
+		Logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		WebsocketConfigs: map[string]*shared.WiretapWebsocketConfig{
+			"/ws": {Mock: &shared.WiretapWebsocketMockConfig{
+				AsyncAPI: spec,
+				Rules: []*shared.WiretapWebsocketMockRule{{
+					Match:   "tick",
+					Replies: []*shared.WiretapWebsocketMockMessage{{Channel: "ticks"}},
+				}},
+			}},
+		},
+	}
+
+	eventBus := bus.NewEventBus()
+	ws, err := NewWiretapService(nil, config, store.NewManager(eventBus))
+	require.NoError(t, err)
+	ws.controlsStore.Put(shared.ConfigKey, config, nil)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := uuid.New()
+		ws.HandleWebsocketRequest(&model.Request{Id: &id, HttpRequest: r, HttpResponseWriter: w})
+	}))
+	defer server.Close()
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+	require.NoError(t, err)
+	defer client.Close()
+	_ = client.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	require.NoError(t, client.WriteMessage(websocket.TextMessage, []byte("tick")))
+	_, message, err := client.ReadMessage()
+	require.NoError(t, err)
+	assert.Contains(t, string(message), `"count"`)
+}
@@ -29,6 +29,9 @@ func (ws *WiretapService) activeBroadcaster() daemonbroadcast.Broadcaster {
 	if !ws.broadcaster.Ready() && ws.broadcastChan != nil {
 		ws.broadcaster.Set(daemonbroadcast.NewBroadcaster(ws.broadcastChan, WiretapBroadcastChan))
 	}
+	if ws.redactor != nil {
+		return &redactingBroadcaster{Broadcaster: ws.broadcaster, ws: ws}
+	}
 	return ws.broadcaster
 }
 
@@ -71,6 +74,13 @@ func (ws *WiretapService) broadcastResponseError(request *model.Request, respons
 	ws.activeBroadcaster().ResponseError(request, resp, err)
 }
 
+// broadcastSkippedResponseValidation records a response that was streamed to the client without being validated.
+func (ws *WiretapService) broadcastSkippedResponseValidation(request *model.Request, response *http.Response, body []byte, reason string) {
+	txn := BuildResponseFromBytes(request, response, body)
+	txn.ResponseValidationSkipped = reason
+	ws.broadcastResponse(request, txn)
+}
+
 func (ws *WiretapService) broadcastResponseValidationErrors(request *model.Request, txn *transaction.HttpTransaction, errors []*shared.WiretapValidationError) {
 	if request != nil && request.Id != nil {
 		ws.storeResponseTransaction(request.Id.String(), txn)
@@ -4,8 +4,9 @@
 package daemon
 
 import (
-	"crypto/tls"
+	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/pb33f/ranch/bus"
@@ -18,6 +19,9 @@ import (
 	"github.com/pb33f/wiretap/daemon/proxy"
 	daemonvalidator "github.com/pb33f/wiretap/daemon/validator"
 	"github.com/pb33f/wiretap/mock"
+	"github.com/pb33f/wiretap/plugin"
+	"github.com/pb33f/wiretap/redact"
+	"github.com/pb33f/wiretap/script"
 	"github.com/pb33f/wiretap/shared"
 	"github.com/pb33f/wiretap/specs"
 	"github.com/pb33f/wiretap/validation"
@@ -31,33 +35,64 @@ const (
 )
 
 type WiretapService struct {
-	transport        *http.Transport
-	serviceCore      service.FabricServiceCore
-	broadcastChan    *bus.Channel
-	bus              bus.EventBus
-	controlsStore    store.BusStore
-	transactionStore store.BusStore
-	config           *shared.WiretapConfiguration
-	fs               http.Handler
-	broadcaster      *broadcast.LazyBroadcaster
-	validator        *daemonvalidator.Validator
-	proxy            *proxy.Handler
-	mock             *mockproxy.Handler
-	stream           bool
-	streamChan       chan []*shared.WiretapValidationError
-	reportFile       string
-	StaticMockDir    string
-	routeConflicts   *specs.RouteConflictIndex
+	transport         *http.Transport
+	upstream          *upstreamTransport
+	serviceCore       service.FabricServiceCore
+	broadcastChan     *bus.Channel
+	bus               bus.EventBus
+	controlsStore     store.BusStore
+	transactionStore  store.BusStore
+	config            *shared.WiretapConfiguration
+	fs                http.Handler
+	broadcaster       *broadcast.LazyBroadcaster
+	validator         *daemonvalidator.Validator
+	proxy             *proxy.Handler
+	mock              *mockproxy.Handler
+	stream            bool
+	streamChan        chan []*shared.WiretapValidationError
+	reportFile        string
+	StaticMockDir     string
+	routeConflicts    *specs.RouteConflictIndex
+	websocketMocks    sync.Map
+	asyncAPIDocuments sync.Map
+	mirrorReport      *mirrorReport
+	tokens            *tokenInspector
+	redactor          *redact.Redactor
+	scripts           map[*shared.WiretapPathConfig]*script.Script
+	plugins           []*plugin.Plugin
 }
 
-func NewWiretapService(documents []shared.ApiDocument, config *shared.WiretapConfiguration, storeManager store.Manager, conflictReports ...*specs.ConflictReport) *WiretapService {
+func NewWiretapService(documents []shared.ApiDocument, config *shared.WiretapConfiguration, storeManager store.Manager, conflictReports ...*specs.ConflictReport) (*WiretapService, error) {
 	controlsStore := storeManager.CreateStore(controls.ControlServiceChan)
 	transactionStore := storeManager.CreateStore(WiretapServiceChan)
 
 	tr := http.DefaultTransport.(*http.Transport).Clone()
 	tr.MaxIdleConns = 20
 	tr.IdleConnTimeout = 30 * time.Second
-	tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	tlsConfig, err := newUpstreamTLSConfig(config.UpstreamTLS)
+	if err != nil {
+		return nil, fmt.Errorf("unable to configure upstream TLS: %w", err)
+	}
+	tr.TLSClientConfig = tlsConfig
+	upstream := newUpstreamTransport(tr)
+	if err = upstream.preloadTLSProfiles(config); err != nil {
+		return nil, fmt.Errorf("unable to configure upstream TLS: %w", err)
+	}
+
+	tokens, err := newTokenInspector(config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load JWT keys: %w", err)
+	}
+
+	scripts, err := loadScripts(config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load scripts: %w", err)
+	}
+
+	plugins, err := loadPlugins(config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load plugins: %w", err)
+	}
 
 	wts := &WiretapService{
 		stream:     config.StreamReport,
@@ -67,12 +102,17 @@ func NewWiretapService(documents []shared.ApiDocument, config *shared.WiretapCon
 		// once the buffer fills — report streaming is best-effort, proxying is not.
 		streamChan:       make(chan []*shared.WiretapValidationError, 256),
 		transport:        tr,
+		upstream:         upstream,
 		controlsStore:    controlsStore,
 		transactionStore: transactionStore,
 		broadcaster:      broadcast.NewLazyBroadcaster(),
-		proxy:            proxy.NewHandler(tr),
+		proxy:            proxy.NewHandler(upstream),
 		mock:             mockproxy.NewHandler(),
 		StaticMockDir:    config.StaticMockDir,
+		mirrorReport:     newMirrorReport(config.MirrorReportFile),
+		tokens:           tokens,
+		scripts:          scripts,
+		plugins:          plugins,
 	}
 	if len(conflictReports) > 0 && conflictReports[0] != nil {
 		wts.routeConflicts = conflictReports[0].RouteIndex
@@ -88,30 +128,47 @@ func NewWiretapService(documents []shared.ApiDocument, config *shared.WiretapCon
 			continue
 		}
 
+		mockEngine := mock.NewMockEngineWithConfig(
+			&docModel.Model,
+			config.MockModePretty,
+			config.UseAllMockResponseFields,
+			config.StrictMode,
+			config.HardErrors,
+			config.MockBypassValidation)
+		if config.MockSeed != nil {
+			mockEngine.SetSeed(*config.MockSeed)
+		}
+		if config.MockPagination {
+			mockEngine.SetPagination(true)
+		}
+		if config.MockPaginationTotal > 0 {
+			mockEngine.SetPaginationTotal(config.MockPaginationTotal)
+		}
+		if config.MockEventCount > 0 {
+			mockEngine.SetEventCount(config.MockEventCount)
+		}
+
 		documentValidators = append(documentValidators, daemonvalidator.DocumentValidator{
 			DocumentName: document.DocumentName,
 			Document:     document.Document,
 			DocModel:     &docModel.Model,
-			Validator:    validation.NewHttpValidatorWithConfig(&docModel.Model, config.StrictMode),
-			MockEngine: mock.NewMockEngineWithConfig(
-				&docModel.Model,
-				config.MockModePretty,
-				config.UseAllMockResponseFields,
-				config.StrictMode,
-				config.HardErrors,
-				config.MockBypassValidation),
+			Validator:    validation.NewHttpValidatorWithConfig(&docModel.Model, config.StrictMode, tokenCookies(config)...),
+			MockEngine:   mockEngine,
 		})
 	}
 	wts.validator = daemonvalidator.New(documentValidators)
 
+	if wts.redactor, err = newRedactor(config, documents); err != nil {
+		return nil, fmt.Errorf("unable to configure redaction: %w", err)
+	}
+
 	// hard-wire the config, change this later if needed.
 	wts.config = config
 
 	// listen for violations
 	wts.listenForValidationErrors()
 
-	return wts
-
+	return wts, nil
 }
 
 func (ws *WiretapService) HandleServiceRequest(request *model.Request, core service.FabricServiceCore) {
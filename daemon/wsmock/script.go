@@ -0,0 +1,264 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+// Package wsmock serves mocked websockets, answering client messages and pushing server messages as scripted by a
+// websocket mock configuration.
+package wsmock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pb33f/libopenapi/renderer"
+	"github.com/pb33f/wiretap/asyncapi"
+	"github.com/pb33f/wiretap/shared"
+)
+
+// CloseGracePeriod is how long a mock waits for the client to acknowledge a scripted close before dropping the
+// connection.
+const CloseGracePeriod = time.Second
+
+// Script is a compiled websocket mock configuration, ready to serve connections.
+type Script struct {
+	config    *shared.WiretapWebsocketMockConfig
+	document  *asyncapi.Document
+	generator *renderer.MockGenerator
+	generate  sync.Mutex
+	matchers  []any
+}
+
+// NewScript compiles a websocket mock configuration. The AsyncAPI document is optional, it is only needed by
+// messages that are generated from a channel. A nil configuration serves a connection that stays open and silent.
+func NewScript(config *shared.WiretapWebsocketMockConfig, document *asyncapi.Document) *Script {
+	if config == nil {
+		config = &shared.WiretapWebsocketMockConfig{}
+	}
+	script := &Script{
+		config:    config,
+		document:  document,
+		generator: renderer.NewMockGenerator(renderer.JSON),
+	}
+	for _, rule := range config.Rules {
+		script.matchers = append(script.matchers, normalizeMatch(rule.Match))
+	}
+	return script
+}
+
+// connection is a single mocked websocket connection. gorilla connections support one concurrent writer, so all
+// writes are serialized.
+type connection struct {
+	script *Script
+	conn   *websocket.Conn
+	logger *slog.Logger
+	mu     sync.Mutex
+	done   chan struct{}
+	closed bool
+}
+
+// Serve runs the script against a connection until either side closes it.
+func (s *Script) Serve(conn *websocket.Conn, logger *slog.Logger) {
+	c := &connection{script: s, conn: conn, logger: logger, done: make(chan struct{})}
+	defer close(c.done)
+
+	go func() {
+		for _, message := range s.config.OnConnect {
+			if !c.send(message, nil) {
+				return
+			}
+		}
+	}()
+	for _, push := range s.config.Push {
+		go c.push(push)
+	}
+	if s.config.Close != nil {
+		go func() {
+			if c.sleep(s.config.Close.After) {
+				c.close(s.config.Close)
+			}
+		}()
+	}
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			var closeErr *websocket.CloseError
+			if errors.As(err, &closeErr) {
+				logger.Info(fmt.Sprintf("Mock websocket closed with code: %d", closeErr.Code))
+			}
+			return
+		}
+		rule := s.match(message)
+		if rule == nil {
+			continue
+		}
+		go c.reply(rule, message)
+	}
+}
+
+// match returns the first rule that matches a client message.
+func (s *Script) match(message []byte) *shared.WiretapWebsocketMockRule {
+	var decoded any
+	isJSON := json.Unmarshal(message, &decoded) == nil
+
+	for i, rule := range s.config.Rules {
+		switch match := s.matchers[i].(type) {
+		case nil:
+			return rule
+		case string:
+			if shared.StringCompare(match, string(message)) {
+				return rule
+			}
+		default:
+			if isJSON && shared.IsSubset(match, decoded) {
+				return rule
+			}
+		}
+	}
+	return nil
+}
+
+func (c *connection) reply(rule *shared.WiretapWebsocketMockRule, message []byte) {
+	var decoded any
+	if json.Unmarshal(message, &decoded) != nil {
+		decoded = string(message)
+	}
+	vars := map[string]any{"message": decoded}
+
+	for _, reply := range rule.Replies {
+		if !c.send(reply, vars) {
+			return
+		}
+	}
+	if rule.Close != nil && c.sleep(rule.Close.After) {
+		c.close(rule.Close)
+	}
+}
+
+func (c *connection) push(push *shared.WiretapWebsocketMockPush) {
+	for sent := 0; push.Count == 0 || sent < push.Count; sent++ {
+		if sent > 0 && !c.sleep(push.Interval) {
+			return
+		}
+		if !c.send(&push.WiretapWebsocketMockMessage, nil) {
+			return
+		}
+		if push.Interval <= 0 && push.Count == 0 {
+			// without an interval a push is only sent once.
+			return
+		}
+	}
+}
+
+// send waits for the delay of a message, then renders and writes it. Returns false once the connection is done.
+func (c *connection) send(message *shared.WiretapWebsocketMockMessage, vars map[string]any) bool {
+	if !c.sleep(message.Delay) {
+		return false
+	}
+	body, err := c.script.render(message, vars)
+	if err != nil {
+		c.logger.Error("[wiretap] unable to render mock websocket message", "error", err)
+		return true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return false
+	}
+	if err = c.conn.WriteMessage(websocket.TextMessage, body); err != nil {
+		c.closed = true
+		return false
+	}
+	return true
+}
+
+// close sends a scripted close frame, and gives the client a grace period to acknowledge it.
+func (c *connection) close(config *shared.WiretapWebsocketMockClose) {
+	code := config.Code
+	if code == 0 {
+		code = websocket.CloseNormalClosure
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	c.closed = true
+	_ = c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, config.Reason),
+		time.Now().Add(CloseGracePeriod))
+	_ = c.conn.SetReadDeadline(time.Now().Add(CloseGracePeriod))
+}
+
+// sleep waits for a number of milliseconds, returning false if the connection is done first.
+func (c *connection) sleep(ms int) bool {
+	if ms <= 0 {
+		select {
+		case <-c.done:
+			return false
+		default:
+			return true
+		}
+	}
+	timer := time.NewTimer(time.Duration(ms) * time.Millisecond)
+	defer timer.Stop()
+	select {
+	case <-c.done:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// render builds the body of a message, generating it from an AsyncAPI channel, or replacing the template variables
+// of a static body.
+func (s *Script) render(message *shared.WiretapWebsocketMockMessage, vars map[string]any) ([]byte, error) {
+	if message.Channel == "" {
+		body, err := shared.ReplaceTemplateVars(message.Body, vars)
+		return []byte(body), err
+	}
+	if s.document == nil {
+		return nil, fmt.Errorf("channel '%s' needs an AsyncAPI document", message.Channel)
+	}
+	channel := s.document.Channel(message.Channel)
+	if channel == nil {
+		return nil, fmt.Errorf("channel '%s' is not defined by the AsyncAPI document", message.Channel)
+	}
+
+	var candidate *asyncapi.Message
+	if message.Message != "" {
+		candidate = channel.Message(message.Message)
+	} else if sent := channel.MessagesFor(asyncapi.ActionSend); len(sent) > 0 {
+		candidate = sent[0]
+	}
+	if candidate == nil || candidate.Schema == nil {
+		return nil, fmt.Errorf("channel '%s' has no message '%s' with a JSON schema payload",
+			message.Channel, message.Message)
+	}
+	// the generator is shared by every connection, and is not safe for concurrent use.
+	s.generate.Lock()
+	defer s.generate.Unlock()
+	return s.generator.GenerateMock(candidate.Schema, "")
+}
+
+// normalizeMatch round trips a match through JSON, so numbers decoded from YAML configuration compare with numbers
+// decoded from JSON messages.
+func normalizeMatch(match any) any {
+	if _, ok := match.(string); ok || match == nil {
+		return match
+	}
+	encoded, err := json.Marshal(match)
+	if err != nil {
+		return match
+	}
+	var normalized any
+	if json.Unmarshal(encoded, &normalized) != nil {
+		return match
+	}
+	return normalized
+}
@@ -0,0 +1,179 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package wsmock
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pb33f/wiretap/asyncapi"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.yaml.in/yaml/v4"
+)
+
+func serve(t *testing.T, script *Script) *websocket.Conn {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		script.Serve(conn, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	}))
+	t.Cleanup(server.Close)
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+	_ = client.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return client
+}
+
+func read(t *testing.T, client *websocket.Conn) string {
+	t.Helper()
+	_, message, err := client.ReadMessage()
+	require.NoError(t, err)
+	return string(message)
+}
+
+func scriptConfig(t *testing.T, config string) *shared.WiretapWebsocketMockConfig {
+	t.Helper()
+	var mock shared.WiretapWebsocketMockConfig
+	require.NoError(t, yaml.Unmarshal([]byte(config), &mock))
+	return &mock
+}
+
+func TestScript_Replies(t *testing.T) {
+	client := serve(t, NewScript(scriptConfig(t, `
+onConnect:
+  - body: hello
+rules:
+  - match:
+      action: subscribe
+      id: 7
+    replies:
+      - body: '{"subscribed":"${message.symbol}"}'
+      - body: '{"price":1}'
+        delay: 10
+  - match: ping.*
+    replies:
+      - body: pong
+`), nil))
+
+	assert.Equal(t, "hello", read(t, client))
+
+	require.NoError(t, client.WriteMessage(websocket.TextMessage, []byte(`{"action":"subscribe","id":7,"symbol":"PB33F"}`)))
+	assert.JSONEq(t, `{"subscribed":"PB33F"}`, read(t, client))
+	assert.JSONEq(t, `{"price":1}`, read(t, client))
+
+	// unmatched messages are not answered.
+	require.NoError(t, client.WriteMessage(websocket.TextMessage, []byte(`{"action":"unsubscribe"}`)))
+	require.NoError(t, client.WriteMessage(websocket.TextMessage, []byte(`ping!`)))
+	assert.Equal(t, "pong", read(t, client))
+}
+
+func TestScript_Push(t *testing.T) {
+	client := serve(t, NewScript(scriptConfig(t, `
+push:
+  - body: tick
+    interval: 10
+    count: 3
+`), nil))
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, "tick", read(t, client))
+	}
+}
+
+func TestScript_Close(t *testing.T) {
+	client := serve(t, NewScript(scriptConfig(t, `
+rules:
+  - match: bye
+    replies:
+      - body: later
+    close:
+      code: 4001
+      reason: scripted
+`), nil))
+
+	require.NoError(t, client.WriteMessage(websocket.TextMessage, []byte("bye")))
+	assert.Equal(t, "later", read(t, client))
+
+	_, _, err := client.ReadMessage()
+	var closeErr *websocket.CloseError
+	require.ErrorAs(t, err, &closeErr)
+	assert.Equal(t, 4001, closeErr.Code)
+	assert.Equal(t, "scripted", closeErr.Text)
+}
+
+func TestScript_CloseAfter(t *testing.T) {
+	client := serve(t, NewScript(scriptConfig(t, `
+close:
+  code: 1012
+  after: 10
+`), nil))
+
+	_, _, err := client.ReadMessage()
+	var closeErr *websocket.CloseError
+	require.ErrorAs(t, err, &closeErr)
+	assert.Equal(t, websocket.CloseServiceRestart, closeErr.Code)
+}
+
+func TestScript_AsyncAPI(t *testing.T) {
+	document, err := asyncapi.Parse([]byte(`asyncapi: 3.0.0
+info:
+  title: prices
+  version: 1.0.0
+channels:
+  prices:
+    messages:
+      price:
+        payload:
+          type: object
+          required: [symbol, price]
+          properties:
+            symbol:
+              type: string
+            price:
+              type: number
+operations:
+  sendPrices:
+    action: send
+    channel:
+      $ref: '#/channels/prices'
+`))
+	require.NoError(t, err)
+
+	client := serve(t, NewScript(scriptConfig(t, `
+push:
+  - channel: prices
+  - channel: prices
+    message: missing
+  - channel: prices
+    message: price
+    delay: 10
+`), document))
+
+	for i := 0; i < 2; i++ {
+		var price map[string]any
+		require.NoError(t, json.Unmarshal([]byte(read(t, client)), &price))
+		assert.Contains(t, price, "symbol")
+		assert.Contains(t, price, "price")
+	}
+}
+
+func TestScript_NilConfig(t *testing.T) {
+	script := NewScript(nil, nil)
+	assert.Nil(t, script.match([]byte("anything")))
+}
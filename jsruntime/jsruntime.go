@@ -0,0 +1,76 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+// Package jsruntime runs JavaScript programs, like scripts and plugins, in the embedded pure-Go JavaScript runtime.
+// Runtimes are pooled, so a program can be run concurrently, and everything run on them is interrupted when it runs
+// for too long.
+package jsruntime
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+// Pool holds runtimes that have run a program. Pools are safe to use concurrently: each run takes a runtime of its own.
+type Pool struct {
+	kind     string
+	program  *goja.Program
+	runtimes sync.Pool
+}
+
+// NewPool creates a pool of runtimes for a program. The kind of program, like script or plugin, is used in errors.
+func NewPool(kind string, program *goja.Program) *Pool {
+	return &Pool{kind: kind, program: program}
+}
+
+// Get returns a runtime that has run the program, running it on a new runtime when none is free. The program is
+// interrupted after the timeout.
+func (p *Pool) Get(timeout time.Duration) (*goja.Runtime, error) {
+	if vm, ok := p.runtimes.Get().(*goja.Runtime); ok {
+		return vm, nil
+	}
+	vm := goja.New()
+	if err := p.Run(vm, timeout, func() error {
+		_, err := vm.RunProgram(p.program)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return vm, nil
+}
+
+// Put returns a runtime to the pool.
+func (p *Pool) Put(vm *goja.Runtime) {
+	p.runtimes.Put(vm)
+}
+
+// Run runs a function on a runtime, interrupting it after the timeout. The runtime is left ready to run again.
+// Exceptions thrown by the program are returned as errors holding the thrown value.
+func (p *Pool) Run(vm *goja.Runtime, timeout time.Duration, run func() error) error {
+	fired := make(chan struct{})
+	interrupt := time.AfterFunc(timeout, func() {
+		vm.Interrupt(p.kind + " timed out")
+		close(fired)
+	})
+	err := run()
+	if !interrupt.Stop() {
+		<-fired
+	}
+	vm.ClearInterrupt()
+	if err == nil {
+		return nil
+	}
+	var exception *goja.Exception
+	if errors.As(err, &exception) {
+		return errors.New(exception.Value().String())
+	}
+	var interrupted *goja.InterruptedError
+	if errors.As(err, &interrupted) {
+		return fmt.Errorf("%v after %s", interrupted.Value(), timeout)
+	}
+	return err
+}
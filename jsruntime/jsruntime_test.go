@@ -0,0 +1,60 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package jsruntime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool_Run(t *testing.T) {
+	program, err := goja.Compile("test.js", `
+var calls = 0;
+function count() { return ++calls; }
+function fail() { throw new Error("nope"); }
+function spin() { for (;;) {} }`, true)
+	require.NoError(t, err)
+	pool := NewPool("test", program)
+
+	vm, err := pool.Get(time.Second)
+	require.NoError(t, err)
+	call := func(name string) (goja.Value, error) {
+		fn, ok := goja.AssertFunction(vm.Get(name))
+		require.True(t, ok)
+		var result goja.Value
+		err := pool.Run(vm, 50*time.Millisecond, func() error {
+			var callErr error
+			result, callErr = fn(goja.Undefined())
+			return callErr
+		})
+		return result, err
+	}
+
+	result, err := call("count")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result.ToInteger())
+
+	_, err = call("fail")
+	assert.EqualError(t, err, "Error: nope")
+
+	_, err = call("spin")
+	assert.EqualError(t, err, "test timed out after 50ms")
+
+	// the runtime is still usable, and keeps its state.
+	result, err = call("count")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), result.ToInteger())
+	pool.Put(vm)
+}
+
+func TestPool_GetRefusesProgramsThatDoNotRun(t *testing.T) {
+	program, err := goja.Compile("test.js", `throw new Error("broken")`, true)
+	require.NoError(t, err)
+	_, err = NewPool("test", program).Get(time.Second)
+	assert.EqualError(t, err, "Error: broken")
+}
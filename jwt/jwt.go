@@ -0,0 +1,93 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+// Package jwt decodes JSON Web Tokens (RFC 7519) sent by clients, so their claims and scopes can be checked, and
+// verifies their signatures against JSON Web Keys, PEM public keys or HMAC secrets.
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Token is a decoded JSON Web Token. Decoding a token does not verify its signature.
+type Token struct {
+	Raw       string
+	Header    map[string]any
+	Claims    map[string]any
+	Signature []byte
+}
+
+// IsJWT returns true if a token has the shape of a compact JWT, three segments separated by dots.
+func IsJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// Parse decodes a compact JWT, without verifying its signature.
+func Parse(raw string) (*Token, error) {
+	segments := strings.Split(raw, ".")
+	if len(segments) != 3 {
+		return nil, errors.New("a JWT has three segments separated by dots")
+	}
+	token := &Token{Raw: raw}
+	if err := decodeSegment(segments[0], &token.Header); err != nil {
+		return nil, fmt.Errorf("invalid header: %w", err)
+	}
+	if err := decodeSegment(segments[1], &token.Claims); err != nil {
+		return nil, fmt.Errorf("invalid claims: %w", err)
+	}
+	signature, err := decode(segments[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+	token.Signature = signature
+	return token, nil
+}
+
+func decodeSegment(segment string, value *map[string]any) error {
+	decoded, err := decode(segment)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(decoded, value); err != nil {
+		return err
+	}
+	if *value == nil {
+		return errors.New("not a JSON object")
+	}
+	return nil
+}
+
+// Scopes returns the scopes granted to a token, from its space separated 'scope' claim (RFC 8693), or its 'scp'
+// claim, which may be a string or an array of strings.
+func (t *Token) Scopes() []string {
+	var scopes []string
+	for _, claim := range []string{"scope", "scp"} {
+		switch value := t.Claims[claim].(type) {
+		case string:
+			scopes = append(scopes, strings.Fields(value)...)
+		case []any:
+			for _, scope := range value {
+				if s, ok := scope.(string); ok {
+					scopes = append(scopes, s)
+				}
+			}
+		}
+	}
+	return scopes
+}
+
+// MissingScopes returns the required scopes not granted to a token.
+func (t *Token) MissingScopes(required []string) []string {
+	granted := t.Scopes()
+	var missing []string
+	for _, scope := range required {
+		if !slices.Contains(granted, scope) {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,46 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package jwt
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encode(segment string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(segment))
+}
+
+func TestParse(t *testing.T) {
+	raw := encode(`{"alg":"HS256","typ":"JWT"}`) + "." + encode(`{"sub":"pb33f","scope":"read write","scp":["admin"]}`) +
+		"." + encode("signature")
+	require.True(t, IsJWT(raw))
+
+	token, err := Parse(raw)
+	require.NoError(t, err)
+	assert.Equal(t, "HS256", token.Header["alg"])
+	assert.Equal(t, "pb33f", token.Claims["sub"])
+	assert.Equal(t, []byte("signature"), token.Signature)
+	assert.Equal(t, []string{"read", "write", "admin"}, token.Scopes())
+	assert.Equal(t, []string{"delete"}, token.MissingScopes([]string{"read", "admin", "delete"}))
+	assert.Empty(t, token.MissingScopes(nil))
+}
+
+func TestParseMalformedTokens(t *testing.T) {
+	header := encode(`{"alg":"none"}`)
+	for token, message := range map[string]string{
+		"opaque":                                 "a JWT has three segments separated by dots",
+		"!!!." + encode(`{}`) + ".":              "invalid header: illegal base64 data at input byte 0",
+		header + "." + encode(`[1]`) + ".":       "invalid claims: json: cannot unmarshal array into Go value of type map[string]interface {}",
+		header + "." + encode(`null`) + ".":      "invalid claims: not a JSON object",
+		header + "." + encode(`{}`) + ".not*b64": "invalid signature: illegal base64 data at input byte 3",
+	} {
+		_, err := Parse(token)
+		assert.EqualError(t, err, message, token)
+	}
+	assert.False(t, IsJWT("opaque"))
+}
@@ -0,0 +1,285 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Key is a key that verifies token signatures: an *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey, or the []byte
+// secret of an HMAC.
+type Key struct {
+	ID     string
+	Public any
+}
+
+// KeySet holds the keys tokens are verified against.
+type KeySet struct {
+	Keys []*Key
+}
+
+// errKeyType is returned when a key cannot verify a signature made with an algorithm.
+var errKeyType = errors.New("key does not match the algorithm")
+
+// AddJWKS adds the keys of a JSON Web Key Set (RFC 7517). RSA, EC, OKP (Ed25519) and oct keys are supported, other
+// keys are ignored.
+func (ks *KeySet) AddJWKS(data []byte) error {
+	var set struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			Crv string `json:"crv"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+			K   string `json:"k"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return fmt.Errorf("invalid JWKS: %w", err)
+	}
+	for i, jwk := range set.Keys {
+		var public any
+		var err error
+		switch jwk.Kty {
+		case "RSA":
+			public, err = rsaKey(jwk.N, jwk.E)
+		case "EC":
+			public, err = ecKey(jwk.Crv, jwk.X, jwk.Y)
+		case "OKP":
+			if jwk.Crv != "Ed25519" {
+				continue
+			}
+			var x []byte
+			x, err = decode(jwk.X)
+			public = ed25519.PublicKey(x)
+		case "oct":
+			public, err = decode(jwk.K)
+		default:
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("invalid JWKS key %d: %w", i+1, err)
+		}
+		ks.Keys = append(ks.Keys, &Key{ID: jwk.Kid, Public: public})
+	}
+	return nil
+}
+
+// AddPEM adds the public keys and certificates of a PEM file.
+func (ks *KeySet) AddPEM(data []byte) error {
+	added := 0
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		var public any
+		var err error
+		switch block.Type {
+		case "PUBLIC KEY":
+			public, err = x509.ParsePKIXPublicKey(block.Bytes)
+		case "RSA PUBLIC KEY":
+			public, err = x509.ParsePKCS1PublicKey(block.Bytes)
+		case "CERTIFICATE":
+			var certificate *x509.Certificate
+			if certificate, err = x509.ParseCertificate(block.Bytes); err == nil {
+				public = certificate.PublicKey
+			}
+		default:
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		ks.Keys = append(ks.Keys, &Key{Public: public})
+		added++
+	}
+	if added == 0 {
+		return errors.New("no public keys or certificates found")
+	}
+	return nil
+}
+
+// AddSecret adds the secret of HMAC signatures.
+func (ks *KeySet) AddSecret(secret []byte) {
+	ks.Keys = append(ks.Keys, &Key{Public: secret})
+}
+
+// Verify verifies the signature of a token against the keys of a set. When the token names its key with a 'kid'
+// header, only keys with that id, or without an id, are tried.
+func (t *Token) Verify(keys *KeySet) error {
+	alg, _ := t.Header["alg"].(string)
+	if alg == "" || strings.EqualFold(alg, "none") {
+		return errors.New("the token is not signed")
+	}
+	if _, ok := algorithmHash(alg); !ok && alg != "EdDSA" {
+		return fmt.Errorf("unsupported algorithm '%s'", alg)
+	}
+	kid, _ := t.Header["kid"].(string)
+	signingInput := t.Raw[:strings.LastIndex(t.Raw, ".")]
+
+	tried := 0
+	for _, key := range keys.Keys {
+		if kid != "" && key.ID != "" && key.ID != kid {
+			continue
+		}
+		err := verify(alg, key.Public, []byte(signingInput), t.Signature)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, errKeyType) {
+			tried++
+		}
+	}
+	if tried == 0 {
+		if kid != "" {
+			return fmt.Errorf("no '%s' key with the id '%s' to verify the signature", alg, kid)
+		}
+		return fmt.Errorf("no '%s' key to verify the signature", alg)
+	}
+	return errors.New("the signature does not match")
+}
+
+func verify(alg string, key any, input, signature []byte) error {
+	if alg == "EdDSA" {
+		public, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return errKeyType
+		}
+		if !ed25519.Verify(public, input, signature) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	}
+
+	hash, _ := algorithmHash(alg)
+	switch alg[:2] {
+	case "HS":
+		secret, ok := key.([]byte)
+		if !ok {
+			return errKeyType
+		}
+		mac := hmac.New(hash.New, secret)
+		mac.Write(input)
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	}
+
+	digest := hash.New()
+	digest.Write(input)
+	sum := digest.Sum(nil)
+
+	switch alg[:2] {
+	case "RS", "PS":
+		public, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errKeyType
+		}
+		if alg[0] == 'P' {
+			return rsa.VerifyPSS(public, hash, sum, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+		return rsa.VerifyPKCS1v15(public, hash, sum, signature)
+	case "ES":
+		public, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return errKeyType
+		}
+		size := (public.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errors.New("invalid signature")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(public, sum, r, s) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	}
+	return errKeyType
+}
+
+// algorithmHash returns the hash of an HMAC, RSA or ECDSA signature algorithm, HS256 to ES512.
+func algorithmHash(alg string) (crypto.Hash, bool) {
+	if len(alg) != 5 {
+		return 0, false
+	}
+	switch alg[:2] {
+	case "HS", "RS", "PS", "ES":
+	default:
+		return 0, false
+	}
+	switch alg[2:] {
+	case "256":
+		return crypto.SHA256, true
+	case "384":
+		return crypto.SHA384, true
+	case "512":
+		return crypto.SHA512, true
+	}
+	return 0, false
+}
+
+// CheckTime returns an error if a token has expired, or is not valid yet, according to its 'exp' and 'nbf' claims.
+func (t *Token) CheckTime(now time.Time) error {
+	if exp, ok := t.Claims["exp"].(float64); ok && !now.Before(time.Unix(int64(exp), 0)) {
+		return fmt.Errorf("the token expired at %s", time.Unix(int64(exp), 0).UTC().Format(time.RFC3339))
+	}
+	if nbf, ok := t.Claims["nbf"].(float64); ok && now.Before(time.Unix(int64(nbf), 0)) {
+		return fmt.Errorf("the token is not valid before %s", time.Unix(int64(nbf), 0).UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+func decode(value string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+}
+
+func rsaKey(n, e string) (*rsa.PublicKey, error) {
+	modulus, err := decode(n)
+	if err != nil {
+		return nil, err
+	}
+	exponent, err := decode(e)
+	if err != nil {
+		return nil, err
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(modulus), E: int(new(big.Int).SetBytes(exponent).Int64())}, nil
+}
+
+func ecKey(crv, x, y string) (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve '%s'", crv)
+	}
+	xBytes, err := decode(x)
+	if err != nil {
+		return nil, err
+	}
+	yBytes, err := decode(y)
+	if err != nil {
+		return nil, err
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(xBytes), Y: new(big.Int).SetBytes(yBytes)}, nil
+}
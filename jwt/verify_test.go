@@ -0,0 +1,160 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sign returns a token with a header and claims, signed by a signing function.
+func sign(t *testing.T, header, claims string, signer func(input []byte) []byte) *Token {
+	input := encode(header) + "." + encode(claims)
+	token, err := Parse(input + "." + base64.RawURLEncoding.EncodeToString(signer([]byte(input))))
+	require.NoError(t, err)
+	return token
+}
+
+func sha256Sum(input []byte) []byte {
+	sum := sha256.Sum256(input)
+	return sum[:]
+}
+
+func TestVerifyHMAC(t *testing.T) {
+	token := sign(t, `{"alg":"HS256"}`, `{"sub":"pb33f"}`, func(input []byte) []byte {
+		mac := hmac.New(sha256.New, []byte("s3cret"))
+		mac.Write(input)
+		return mac.Sum(nil)
+	})
+
+	keys := &KeySet{}
+	keys.AddSecret([]byte("s3cret"))
+	assert.NoError(t, token.Verify(keys))
+
+	wrong := &KeySet{}
+	wrong.AddSecret([]byte("guess"))
+	assert.EqualError(t, token.Verify(wrong), "the signature does not match")
+}
+
+func TestVerifyJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	b64 := base64.RawURLEncoding.EncodeToString
+	jwks := fmt.Sprintf(`{"keys":[
+		{"kid":"rsa","kty":"RSA","n":"%s","e":"%s"},
+		{"kid":"ec","kty":"EC","crv":"P-256","x":"%s","y":"%s"},
+		{"kid":"ed","kty":"OKP","crv":"Ed25519","x":"%s"},
+		{"kid":"other","kty":"unknown"}]}`,
+		b64(rsaKey.N.Bytes()), b64(big.NewInt(int64(rsaKey.E)).Bytes()),
+		b64(ecKey.X.FillBytes(make([]byte, 32))), b64(ecKey.Y.FillBytes(make([]byte, 32))), b64(edPublic))
+	keys := &KeySet{}
+	require.NoError(t, keys.AddJWKS([]byte(jwks)))
+	require.Len(t, keys.Keys, 3)
+
+	rs256 := sign(t, `{"alg":"RS256","kid":"rsa"}`, `{}`, func(input []byte) []byte {
+		signature, signErr := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, sha256Sum(input))
+		require.NoError(t, signErr)
+		return signature
+	})
+	assert.NoError(t, rs256.Verify(keys))
+
+	ps256 := sign(t, `{"alg":"PS256"}`, `{}`, func(input []byte) []byte {
+		signature, signErr := rsa.SignPSS(rand.Reader, rsaKey, crypto.SHA256, sha256Sum(input),
+			&rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		require.NoError(t, signErr)
+		return signature
+	})
+	assert.NoError(t, ps256.Verify(keys))
+
+	es256 := sign(t, `{"alg":"ES256","kid":"ec"}`, `{}`, func(input []byte) []byte {
+		r, s, signErr := ecdsa.Sign(rand.Reader, ecKey, sha256Sum(input))
+		require.NoError(t, signErr)
+		return append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	})
+	assert.NoError(t, es256.Verify(keys))
+
+	edDSA := sign(t, `{"alg":"EdDSA","kid":"ed"}`, `{}`, func(input []byte) []byte {
+		return ed25519.Sign(edPrivate, input)
+	})
+	assert.NoError(t, edDSA.Verify(keys))
+
+	// a token naming a key that is not in the set cannot be verified.
+	missing := sign(t, `{"alg":"RS256","kid":"rotated"}`, `{}`, func(input []byte) []byte {
+		signature, signErr := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, sha256Sum(input))
+		require.NoError(t, signErr)
+		return signature
+	})
+	assert.EqualError(t, missing.Verify(keys), "no 'RS256' key with the id 'rotated' to verify the signature")
+
+	assert.EqualError(t, keys.AddJWKS([]byte(`{"keys":[{"kty":"EC","crv":"P-192"}]}`)),
+		"invalid JWKS key 1: unsupported curve 'P-192'")
+}
+
+func TestVerifyPEM(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+
+	keys := &KeySet{}
+	require.NoError(t, keys.AddPEM(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})))
+	token := sign(t, `{"alg":"RS256"}`, `{}`, func(input []byte) []byte {
+		signature, signErr := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, sha256Sum(input))
+		require.NoError(t, signErr)
+		return signature
+	})
+	assert.NoError(t, token.Verify(keys))
+
+	// the HMAC secret of another set cannot verify an RSA signature.
+	secrets := &KeySet{}
+	secrets.AddSecret([]byte("s3cret"))
+	assert.EqualError(t, token.Verify(secrets), "no 'RS256' key to verify the signature")
+
+	assert.EqualError(t, keys.AddPEM([]byte("not a key")), "no public keys or certificates found")
+}
+
+func TestVerifyUnsignedTokens(t *testing.T) {
+	keys := &KeySet{}
+	keys.AddSecret([]byte("s3cret"))
+
+	unsigned, err := Parse(encode(`{"alg":"none"}`) + "." + encode(`{}`) + ".")
+	require.NoError(t, err)
+	assert.EqualError(t, unsigned.Verify(keys), "the token is not signed")
+
+	unsupported, err := Parse(encode(`{"alg":"HS1"}`) + "." + encode(`{}`) + ".")
+	require.NoError(t, err)
+	assert.EqualError(t, unsupported.Verify(keys), "unsupported algorithm 'HS1'")
+}
+
+func TestCheckTime(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	token, err := Parse(encode(`{"alg":"none"}`) + "." +
+		encode(fmt.Sprintf(`{"nbf":%d,"exp":%d}`, now.Add(-time.Hour).Unix(), now.Add(time.Hour).Unix())) + ".")
+	require.NoError(t, err)
+
+	assert.NoError(t, token.CheckTime(now))
+	assert.EqualError(t, token.CheckTime(now.Add(time.Hour)), "the token expired at 2026-03-01T13:00:00Z")
+	assert.EqualError(t, token.CheckTime(now.Add(-2*time.Hour)), "the token is not valid before 2026-03-01T11:00:00Z")
+}
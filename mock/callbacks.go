@@ -0,0 +1,280 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	libopenapierrs "github.com/pb33f/libopenapi-validator/errors"
+	"github.com/pb33f/libopenapi-validator/helpers"
+	"github.com/pb33f/libopenapi-validator/responses"
+	"github.com/pb33f/libopenapi/arazzo/expression"
+	"github.com/pb33f/libopenapi/datamodel/high/v3"
+	"go.yaml.in/yaml/v4"
+)
+
+// Callback is an outbound request, built from a callback or webhook declared in a specification.
+type Callback struct {
+	Name       string        // the name of the callback or webhook.
+	Expression string        // the callback URL expression, or the webhook name.
+	Request    *http.Request // the request to send to the subscriber.
+	PathItem   *v3.PathItem  // the path item that describes the request and the expected responses.
+}
+
+// CallbackExchange holds the request that was mocked and the mock response, callback URL expressions
+// are resolved against it.
+type CallbackExchange struct {
+	Request         *http.Request
+	RequestBody     []byte
+	StatusCode      int
+	ResponseHeaders http.Header
+	ResponseBody    []byte
+}
+
+// BuildCallbacks builds the requests for the callbacks declared by the operation of a mocked request. Callbacks
+// that can't be built (the URL expression can't be resolved, or is not an absolute URL) are returned as errors.
+func (rme *ResponseMockEngine) BuildCallbacks(exchange *CallbackExchange) ([]*Callback, []error) {
+	path, pathKey, err := rme.findPathAndKey(exchange.Request)
+	if err != nil {
+		return nil, nil
+	}
+	operation := rme.findOperation(exchange.Request, path)
+	if operation == nil || operation.Callbacks == nil || operation.Callbacks.Len() == 0 {
+		return nil, nil
+	}
+
+	ctx := buildExpressionContext(exchange, pathKey)
+
+	var callbacks []*Callback
+	var errs []error
+	for name, callback := range operation.Callbacks.FromOldest() {
+		if callback == nil || callback.Expression == nil {
+			continue
+		}
+		for expr, pathItem := range callback.Expression.FromOldest() {
+			target, resolveErr := resolveCallbackExpression(expr, ctx)
+			if resolveErr != nil {
+				errs = append(errs, fmt.Errorf("callback '%s': unable to resolve '%s': %w", name, expr, resolveErr))
+				continue
+			}
+			built, buildErr := rme.buildCallbackRequests(name, expr, target, pathItem)
+			if buildErr != nil {
+				errs = append(errs, fmt.Errorf("callback '%s': %w", name, buildErr))
+				continue
+			}
+			callbacks = append(callbacks, built...)
+		}
+	}
+	return callbacks, errs
+}
+
+// BuildWebhook builds the requests for a webhook declared by the specification, to be sent to target.
+// Returns nil if the specification does not declare the webhook.
+func (rme *ResponseMockEngine) BuildWebhook(name, target string) ([]*Callback, error) {
+	if rme.doc.Webhooks == nil {
+		return nil, nil
+	}
+	pathItem, ok := rme.doc.Webhooks.Get(name)
+	if !ok {
+		return nil, nil
+	}
+	return rme.buildCallbackRequests(name, name, target, pathItem)
+}
+
+// Webhooks returns the names of the webhooks declared by the specification.
+func (rme *ResponseMockEngine) Webhooks() []string {
+	var names []string
+	if rme.doc.Webhooks != nil {
+		for name := range rme.doc.Webhooks.KeysFromOldest() {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ValidateCallbackResponse validates the response from a subscriber against the callback or webhook operation.
+func (rme *ResponseMockEngine) ValidateCallbackResponse(callback *Callback, response *http.Response) []*libopenapierrs.ValidationError {
+	validator := responses.NewResponseBodyValidator(rme.doc)
+	_, errs := validator.ValidateResponseBodyWithPathItem(callback.Request, response, callback.PathItem, callback.Expression)
+	return errs
+}
+
+// buildCallbackRequests builds a request for each operation of a callback path item, with a mocked body.
+func (rme *ResponseMockEngine) buildCallbackRequests(name, expr, target string, pathItem *v3.PathItem) ([]*Callback, error) {
+	targetURL, err := url.Parse(target)
+	if err != nil || !targetURL.IsAbs() || targetURL.Host == "" {
+		return nil, fmt.Errorf("'%s' is not an absolute URL", target)
+	}
+	if pathItem == nil {
+		return nil, nil
+	}
+
+	var callbacks []*Callback
+	for method, operation := range pathItem.GetOperations().FromOldest() {
+		body, contentType, bodyErr := rme.generateRequestBody(operation, strings.Join([]string{name, expr, method}, " "))
+		if bodyErr != nil {
+			return nil, bodyErr
+		}
+		request, reqErr := http.NewRequest(strings.ToUpper(method), targetURL.String(), bytes.NewReader(body))
+		if reqErr != nil {
+			return nil, reqErr
+		}
+		if contentType != "" {
+			request.Header.Set(helpers.ContentTypeHeader, contentType)
+		}
+		callbacks = append(callbacks, &Callback{
+			Name:       name,
+			Expression: expr,
+			Request:    request,
+			PathItem:   pathItem,
+		})
+	}
+	return callbacks, nil
+}
+
+// generateRequestBody mocks the request body of an operation, preferring JSON when more than one media type is defined.
+func (rme *ResponseMockEngine) generateRequestBody(operation *v3.Operation, seedKey string) ([]byte, string, error) {
+	if operation == nil || operation.RequestBody == nil || operation.RequestBody.Content == nil ||
+		operation.RequestBody.Content.Len() == 0 {
+		return nil, "", nil
+	}
+	content := operation.RequestBody.Content
+	contentType := "application/json"
+	mt := content.GetOrZero(contentType)
+	if mt == nil {
+		first := content.First()
+		contentType, mt = first.Key(), first.Value()
+	}
+
+	var body []byte
+	var err error
+	if seed, seeded := rme.seed, rme.seed != nil; seeded && !hasMediaTypeExamples(mt) && mt.Schema != nil && mt.Schema.Schema() != nil {
+		body, err = rme.generateSeededMock(mt.Schema.Schema(), *seed, seedKey)
+	} else {
+		body, err = rme.mockEngine.GenerateMock(mt, "")
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if !strings.Contains(contentType, "json") {
+		var raw string
+		if json.Unmarshal(body, &raw) == nil {
+			body = []byte(raw)
+		}
+	}
+	return body, contentType, nil
+}
+
+// resolveCallbackExpression resolves a callback URL expression, which is either a runtime expression, or a
+// URL with runtime expressions embedded in braces, like `https://example.com/{$request.body#/id}`.
+func resolveCallbackExpression(expr string, ctx *expression.Context) (string, error) {
+	if strings.HasPrefix(expr, "$") {
+		parsed, err := expression.Parse(expr)
+		if err != nil {
+			return "", err
+		}
+		return evaluateCallbackExpression(parsed, ctx)
+	}
+
+	tokens, err := expression.ParseEmbedded(expr)
+	if err != nil {
+		return "", err
+	}
+	var resolved strings.Builder
+	for _, token := range tokens {
+		if !token.IsExpression {
+			resolved.WriteString(token.Literal)
+			continue
+		}
+		value, evalErr := evaluateCallbackExpression(token.Expression, ctx)
+		if evalErr != nil {
+			return "", evalErr
+		}
+		resolved.WriteString(value)
+	}
+	return resolved.String(), nil
+}
+
+func evaluateCallbackExpression(expr expression.Expression, ctx *expression.Context) (string, error) {
+	// header names are case-insensitive, the context holds them in canonical form.
+	if expr.Type == expression.RequestHeader || expr.Type == expression.ResponseHeader {
+		expr.Property = http.CanonicalHeaderKey(expr.Property)
+	}
+	value, err := expression.Evaluate(expr, ctx)
+	if err != nil {
+		return "", err
+	}
+	if node, ok := value.(*yaml.Node); ok {
+		var decoded any
+		if decodeErr := node.Decode(&decoded); decodeErr != nil {
+			return "", decodeErr
+		}
+		value = decoded
+	}
+	return fmt.Sprint(value), nil
+}
+
+// buildExpressionContext builds the runtime expression context for a mocked request and response.
+func buildExpressionContext(exchange *CallbackExchange, pathKey string) *expression.Context {
+	request := exchange.Request
+	ctx := &expression.Context{
+		URL:             request.URL.String(),
+		Method:          request.Method,
+		StatusCode:      exchange.StatusCode,
+		RequestHeaders:  flattenHeaders(request.Header),
+		RequestQuery:    make(map[string]string),
+		RequestPath:     extractPathParams(pathKey, request.URL.Path),
+		ResponseHeaders: flattenHeaders(exchange.ResponseHeaders),
+		RequestBody:     parseBodyNode(exchange.RequestBody),
+		ResponseBody:    parseBodyNode(exchange.ResponseBody),
+	}
+	for key, values := range request.URL.Query() {
+		if len(values) > 0 {
+			ctx.RequestQuery[key] = values[0]
+		}
+	}
+	return ctx
+}
+
+func flattenHeaders(header http.Header) map[string]string {
+	flat := make(map[string]string, len(header))
+	for key := range header {
+		flat[http.CanonicalHeaderKey(key)] = header.Get(key)
+	}
+	return flat
+}
+
+// extractPathParams matches a request path to a path template, from the end, so server base paths are ignored.
+func extractPathParams(pathKey, path string) map[string]string {
+	params := make(map[string]string)
+	templateSegments := strings.Split(strings.Trim(pathKey, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+	offset := len(pathSegments) - len(templateSegments)
+	if offset < 0 {
+		return params
+	}
+	for i, segment := range templateSegments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			params[strings.Trim(segment, "{}")] = pathSegments[offset+i]
+		}
+	}
+	return params
+}
+
+// parseBodyNode parses a JSON (or YAML) body so JSON pointers can be resolved against it.
+func parseBodyNode(body []byte) *yaml.Node {
+	if len(body) == 0 {
+		return nil
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(body, &node); err != nil {
+		return nil
+	}
+	return &node
+}
@@ -0,0 +1,190 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var callbackSpec = `openapi: 3.1.0
+paths:
+  /accounts/{accountId}/subscriptions:
+    post:
+      parameters:
+        - name: accountId
+          in: path
+          required: true
+          schema:
+            type: string
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                callbackUrl:
+                  type: string
+      responses:
+        '201':
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  id:
+                    type: string
+      callbacks:
+        onEvent:
+          '{$request.body#/callbackUrl}/events/{$request.path.accountId}?sub={$response.body#/id}':
+            post:
+              requestBody:
+                content:
+                  application/json:
+                    schema:
+                      $ref: '#/components/schemas/Event'
+              responses:
+                '200':
+                  content:
+                    application/json:
+                      schema:
+                        type: object
+                        required: [received]
+                        properties:
+                          received:
+                            type: boolean
+        onHeader:
+          '{$request.header.x-callback}':
+            post:
+              responses:
+                '204':
+                  description: ok
+webhooks:
+  newPet:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Event'
+      responses:
+        '200':
+          description: ok
+components:
+  schemas:
+    Event:
+      type: object
+      required: [type]
+      properties:
+        type:
+          type: string
+          enum: [created]
+`
+
+func callbackTestEngine(t *testing.T) *ResponseMockEngine {
+	d, err := libopenapi.NewDocument([]byte(callbackSpec))
+	require.NoError(t, err)
+	doc, err := d.BuildV3Model()
+	require.NoError(t, err)
+	return NewMockEngine(&doc.Model, false, true)
+}
+
+func callbackExchange(header string) *CallbackExchange {
+	requestBody := []byte(`{"callbackUrl":"https://subscriber.pb33f.io/hooks"}`)
+	request, _ := http.NewRequest(http.MethodPost, "https://api.pb33f.io/accounts/acc-1/subscriptions",
+		bytes.NewReader(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+	if header != "" {
+		request.Header.Set("X-Callback", header)
+	}
+	return &CallbackExchange{
+		Request:      request,
+		RequestBody:  requestBody,
+		StatusCode:   201,
+		ResponseBody: []byte(`{"id":"sub-1"}`),
+	}
+}
+
+func TestMockEngine_BuildCallbacks(t *testing.T) {
+	me := callbackTestEngine(t)
+	callbacks, errs := me.BuildCallbacks(callbackExchange("https://other.pb33f.io/notify"))
+	require.Empty(t, errs)
+	require.Len(t, callbacks, 2)
+
+	event := callbacks[0]
+	assert.Equal(t, "onEvent", event.Name)
+	assert.Equal(t, http.MethodPost, event.Request.Method)
+	assert.Equal(t, "https://subscriber.pb33f.io/hooks/events/acc-1?sub=sub-1", event.Request.URL.String())
+	assert.Equal(t, "application/json", event.Request.Header.Get("Content-Type"))
+
+	body, _ := io.ReadAll(event.Request.Body)
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(body, &decoded))
+	assert.Equal(t, "created", decoded["type"])
+
+	assert.Equal(t, "https://other.pb33f.io/notify", callbacks[1].Request.URL.String())
+}
+
+func TestMockEngine_BuildCallbacks_Unresolvable(t *testing.T) {
+	me := callbackTestEngine(t)
+	callbacks, errs := me.BuildCallbacks(callbackExchange(""))
+	assert.Len(t, callbacks, 1)
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "onHeader")
+}
+
+func TestMockEngine_BuildCallbacks_NoCallbacks(t *testing.T) {
+	me := callbackTestEngine(t)
+	request, _ := http.NewRequest(http.MethodGet, "https://api.pb33f.io/nowhere", nil)
+	callbacks, errs := me.BuildCallbacks(&CallbackExchange{Request: request})
+	assert.Empty(t, callbacks)
+	assert.Empty(t, errs)
+}
+
+func TestMockEngine_BuildWebhook(t *testing.T) {
+	me := callbackTestEngine(t)
+	assert.Equal(t, []string{"newPet"}, me.Webhooks())
+
+	callbacks, err := me.BuildWebhook("newPet", "http://localhost:9999/pets")
+	require.NoError(t, err)
+	require.Len(t, callbacks, 1)
+	assert.Equal(t, "http://localhost:9999/pets", callbacks[0].Request.URL.String())
+
+	callbacks, err = me.BuildWebhook("missing", "http://localhost:9999/pets")
+	assert.NoError(t, err)
+	assert.Empty(t, callbacks)
+
+	_, err = me.BuildWebhook("newPet", "not a url")
+	assert.Error(t, err)
+}
+
+func TestMockEngine_ValidateCallbackResponse(t *testing.T) {
+	me := callbackTestEngine(t)
+	callbacks, _ := me.BuildCallbacks(callbackExchange(""))
+	require.NotEmpty(t, callbacks)
+
+	response := func(body string) *http.Response {
+		return &http.Response{
+			StatusCode: 200,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}
+	}
+	assert.Empty(t, me.ValidateCallbackResponse(callbacks[0], response(`{"received":true}`)))
+	assert.NotEmpty(t, me.ValidateCallbackResponse(callbacks[0], response(`{"received":"nope"}`)))
+}
+
+func TestExtractPathParams(t *testing.T) {
+	assert.Equal(t, map[string]string{"id": "1", "sub": "x"},
+		extractPathParams("/users/{id}/subs/{sub}", "/api/v1/users/1/subs/x"))
+	assert.Empty(t, extractPathParams("/users/{id}/subs/{sub}", "/users"))
+}
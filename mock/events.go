@@ -0,0 +1,134 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/wiretap/sse"
+	"github.com/pb33f/wiretap/validation"
+)
+
+// DefaultEventCount is the number of events a mocked server-sent event stream holds.
+const DefaultEventCount = 5
+
+// SetEventCount sets the number of events a mocked server-sent event stream holds.
+func (rme *ResponseMockEngine) SetEventCount(count int) {
+	rme.eventCount = count
+}
+
+// eventSchema returns the schema of each event in a `text/event-stream` media type, preferring the `itemSchema`.
+func eventSchema(mt *v3.MediaType) *base.Schema {
+	if mt == nil {
+		return nil
+	}
+	if mt.ItemSchema != nil {
+		return mt.ItemSchema.Schema()
+	}
+	if mt.Schema != nil {
+		return mt.Schema.Schema()
+	}
+	return nil
+}
+
+// generateEvents renders a stream of server-sent events, each one generated from the event schema.
+func (rme *ResponseMockEngine) generateEvents(schema *base.Schema, seed int64, seeded bool, seedKey string) ([]byte, error) {
+	count := rme.eventCount
+	if count <= 0 {
+		count = DefaultEventCount
+	}
+
+	var stream bytes.Buffer
+	for i := 0; i < count; i++ {
+		var value []byte
+		var err error
+		if seeded {
+			value, err = rme.generateSeededMock(schema, seed, fmt.Sprintf("%s event %d", seedKey, i))
+		} else {
+			value, err = rme.mockEngine.GenerateMock(schema, "")
+		}
+		if err != nil {
+			return nil, err
+		}
+		value = compactJSON(value)
+		event, err := rme.buildEvent(schema, value, seed, seeded, fmt.Sprintf("%s data %d", seedKey, i))
+		if err != nil {
+			return nil, err
+		}
+		if event.ID == "" {
+			event.ID = strconv.Itoa(i + 1)
+		}
+		stream.Write(event.Format())
+	}
+	return stream.Bytes(), nil
+}
+
+// buildEvent turns a mocked value into an event. When the schema describes the whole event, the fields of the value
+// become the fields of the event, and the data is generated from the `contentSchema` of the data property if declared.
+func (rme *ResponseMockEngine) buildEvent(schema *base.Schema, value []byte, seed int64, seeded bool, seedKey string) (*sse.Event, error) {
+	if !validation.IsEventShaped(schema) {
+		var raw string
+		if json.Unmarshal(value, &raw) == nil {
+			return &sse.Event{Data: raw}, nil
+		}
+		return &sse.Event{Data: string(value)}, nil
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(value, &fields); err != nil {
+		return nil, err
+	}
+	event := &sse.Event{}
+	if v, ok := fields["event"].(string); ok {
+		event.Event = v
+	}
+	if v, ok := fields["id"]; ok && v != nil {
+		event.ID = fmt.Sprint(v)
+	}
+	if v, ok := fields["retry"].(float64); ok && v > 0 {
+		event.Retry = int(v)
+	}
+
+	dataSchema := schema.Properties.GetOrZero("data")
+	if dataSchema != nil && dataSchema.Schema() != nil && dataSchema.Schema().ContentSchema != nil {
+		if contentSchema := dataSchema.Schema().ContentSchema.Schema(); contentSchema != nil {
+			var data []byte
+			var err error
+			if seeded {
+				data, err = rme.generateSeededMock(contentSchema, seed, seedKey)
+			} else {
+				data, err = rme.mockEngine.GenerateMock(contentSchema, "")
+			}
+			if err != nil {
+				return nil, err
+			}
+			event.Data = string(compactJSON(data))
+			return event, nil
+		}
+	}
+
+	switch data := fields["data"].(type) {
+	case nil:
+	case string:
+		event.Data = data
+	default:
+		encoded, _ := json.Marshal(data)
+		event.Data = string(encoded)
+	}
+	return event, nil
+}
+
+// compactJSON removes the indentation the mock generator adds in pretty mode, event data is a single line.
+func compactJSON(value []byte) []byte {
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, value); err != nil {
+		return value
+	}
+	return compacted.Bytes()
+}
@@ -0,0 +1,135 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package mock
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/wiretap/sse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var eventsSpec = `openapi: 3.2.0
+paths:
+  /prices:
+    get:
+      responses:
+        '200':
+          content:
+            text/event-stream:
+              schema:
+                type: object
+                required: [symbol, price]
+                properties:
+                  symbol:
+                    type: string
+                  price:
+                    type: number
+  /orders:
+    get:
+      responses:
+        '200':
+          content:
+            text/event-stream:
+              itemSchema:
+                type: object
+                required: [event, data]
+                properties:
+                  event:
+                    type: string
+                    enum: [created]
+                  data:
+                    type: string
+                    contentMediaType: application/json
+                    contentSchema:
+                      type: object
+                      required: [orderId]
+                      properties:
+                        orderId:
+                          type: integer
+  /recorded:
+    get:
+      responses:
+        '200':
+          content:
+            text/event-stream:
+              schema:
+                type: string
+              example: "data: recorded\n\n"
+`
+
+func eventsTestEngine(t *testing.T) *ResponseMockEngine {
+	d, err := libopenapi.NewDocument([]byte(eventsSpec))
+	require.NoError(t, err)
+	doc, err := d.BuildV3Model()
+	require.NoError(t, err)
+	return NewMockEngine(&doc.Model, true, true)
+}
+
+func mockEvents(t *testing.T, me *ResponseMockEngine, path string) ([]*sse.Event, http.Header) {
+	request, _ := http.NewRequest(http.MethodGet, "https://api.pb33f.io"+path, nil)
+	request.Header.Set("Accept", sse.ContentType)
+	b, status, headers, err := me.GenerateResponseWithHeaders(request)
+	require.NoError(t, err)
+	require.Equal(t, 200, status)
+	return sse.NewParser().Feed(b), headers
+}
+
+func TestMockEngine_Events(t *testing.T) {
+	me := eventsTestEngine(t)
+	me.SetEventCount(3)
+
+	events, headers := mockEvents(t, me, "/prices")
+	assert.Equal(t, sse.ContentType, headers.Get("Content-Type"))
+	require.Len(t, events, 3)
+	for i, event := range events {
+		assert.Equal(t, []string{"1", "2", "3"}[i], event.ID)
+
+		// pretty mocks are compacted, event data is one line.
+		var price map[string]any
+		require.NoError(t, json.Unmarshal([]byte(event.Data), &price))
+		assert.NotContains(t, event.Data, "\n")
+		assert.Contains(t, price, "symbol")
+		assert.Contains(t, price, "price")
+	}
+}
+
+func TestMockEngine_Events_DefaultCount(t *testing.T) {
+	events, _ := mockEvents(t, eventsTestEngine(t), "/prices")
+	assert.Len(t, events, DefaultEventCount)
+}
+
+func TestMockEngine_Events_ItemSchema(t *testing.T) {
+	me := eventsTestEngine(t)
+	me.SetEventCount(2)
+
+	events, _ := mockEvents(t, me, "/orders")
+	require.Len(t, events, 2)
+	for _, event := range events {
+		assert.Equal(t, "created", event.Event)
+		var order map[string]any
+		require.NoError(t, json.Unmarshal([]byte(event.Data), &order))
+		assert.IsType(t, float64(0), order["orderId"])
+	}
+}
+
+func TestMockEngine_Events_Seeded(t *testing.T) {
+	me := eventsTestEngine(t)
+	me.SetSeed(42)
+
+	first, _ := mockEvents(t, me, "/prices")
+	again, _ := mockEvents(t, me, "/prices")
+	assert.Equal(t, first, again)
+	assert.NotEqual(t, first[0].Data, first[1].Data)
+}
+
+func TestMockEngine_Events_ExampleWins(t *testing.T) {
+	events, _ := mockEvents(t, eventsTestEngine(t), "/recorded")
+	require.Len(t, events, 1)
+	assert.Equal(t, "recorded", events[0].Data)
+}
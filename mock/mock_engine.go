@@ -16,9 +16,11 @@ import (
 	libopenapierrs "github.com/pb33f/libopenapi-validator/errors"
 	"github.com/pb33f/libopenapi-validator/helpers"
 	"github.com/pb33f/libopenapi-validator/paths"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
 	"github.com/pb33f/libopenapi/datamodel/high/v3"
 	"github.com/pb33f/libopenapi/renderer"
 	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/sse"
 	"github.com/pb33f/wiretap/validation"
 )
 
@@ -30,6 +32,11 @@ type ResponseMockEngine struct {
 	validationOpts   *config.ValidationOptions
 	hardValidation   bool // when true, reject requests with validation errors
 	bypassValidation bool // when true, skip the hardValidation short-circuit so Preferred examples still fire
+	useAllExamples   bool
+	seed             *int64 // when set, schema generated mocks are deterministic
+	pagination       bool   // when true, paginated operations return the page asked for, without a preference
+	paginationTotal  int
+	eventCount       int
 }
 
 func NewMockEngine(document *v3.Document, pretty, useAllPropertyExamples bool) *ResponseMockEngine {
@@ -49,6 +56,7 @@ func NewMockEngine(document *v3.Document, pretty, useAllPropertyExamples bool) *
 		pretty:         pretty,
 		validationOpts: config.NewValidationOptions(config.WithRegexCache(&sync.Map{})),
 		hardValidation: true, // default to rejecting on validation errors for backward compatibility
+		useAllExamples: useAllPropertyExamples,
 	}
 }
 
@@ -71,6 +79,7 @@ func NewStrictMockEngine(document *v3.Document, pretty, useAllPropertyExamples b
 		pretty:         pretty,
 		validationOpts: config.NewValidationOptions(config.WithRegexCache(&sync.Map{})),
 		hardValidation: true, // default to rejecting on validation errors for backward compatibility
+		useAllExamples: useAllPropertyExamples,
 	}
 }
 
@@ -92,6 +101,12 @@ func NewMockEngineWithConfig(document *v3.Document, pretty, useAllPropertyExampl
 	return engine
 }
 
+// SetSeed makes mocks generated from schemas deterministic. The same seed renders the same values for an
+// operation, until the schema changes. A request can override the seed with the `wiretap-mock-seed` header.
+func (rme *ResponseMockEngine) SetSeed(seed int64) {
+	rme.seed = &seed
+}
+
 // shouldBypassValidation returns true when MockBypassValidation is set on the
 // engine or the request carries "wiretap-bypass-validation: true". Only
 // consulted to guard the hard-validation short-circuit in runWorkflow.
@@ -103,6 +118,13 @@ func (rme *ResponseMockEngine) shouldBypassValidation(r *http.Request) bool {
 }
 
 func (rme *ResponseMockEngine) GenerateResponse(request *http.Request) ([]byte, int, error) {
+	mock, code, _, err := rme.runWorkflow(request)
+	return mock, code, err
+}
+
+// GenerateResponseWithHeaders generates a mock response, along with the headers that describe it: the
+// `Content-Type` of the selected media type and `Preference-Applied` when `Prefer` preferences were honoured.
+func (rme *ResponseMockEngine) GenerateResponseWithHeaders(request *http.Request) ([]byte, int, http.Header, error) {
 	return rme.runWorkflow(request)
 }
 
@@ -233,8 +255,14 @@ func (rme *ResponseMockEngine) extractMediaTypeHeader(request *http.Request) str
 }
 
 func (rme *ResponseMockEngine) findPath(request *http.Request) (*v3.PathItem, error) {
-	path, errs, _ := paths.FindPath(request, rme.doc, rme.validationOpts)
-	return path, rme.packErrors(errs)
+	path, _, err := rme.findPathAndKey(request)
+	return path, err
+}
+
+// findPathAndKey finds the path item for a request, along with the path template it was defined with.
+func (rme *ResponseMockEngine) findPathAndKey(request *http.Request) (*v3.PathItem, string, error) {
+	path, errs, pathKey := paths.FindPath(request, rme.doc, rme.validationOpts)
+	return path, pathKey, rme.packErrors(errs)
 }
 
 func (rme *ResponseMockEngine) findOperation(request *http.Request, pathItem *v3.PathItem) *v3.Operation {
@@ -293,14 +321,54 @@ func (rme *ResponseMockEngine) buildErrorWithPayload(status int, title, msg, has
 	return rme.render(wte)
 }
 
+// GenerateUnauthorizedResponse generates the 401 response of the operation of a request, for a request that failed
+// its security requirements. When the operation has no 401 response, a 401 error is rendered instead.
+func (rme *ResponseMockEngine) GenerateUnauthorizedResponse(request *http.Request) ([]byte, int, http.Header, error) {
+	path, _, err := rme.findPathAndKey(request)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	operation := rme.findOperation(request, path)
+	if operation == nil {
+		return nil, 0, nil, fmt.Errorf("no '%s' operation found for '%s'", request.Method, request.URL.Path)
+	}
+	return rme.unauthorized(request, operation, nil)
+}
+
+// unauthorized renders the 401 response of an operation, or a 401 error when it has none. The security error is
+// passed through.
+func (rme *ResponseMockEngine) unauthorized(request *http.Request, operation *v3.Operation, err error) ([]byte, int, http.Header, error) {
+	mt, _, _ := rme.findBestMediaTypeMatch(operation, request, []string{"401"})
+	if mt != nil {
+		mock, mockErr := rme.mockEngine.GenerateMock(mt, rme.extractPreferred(request))
+		if mockErr != nil {
+			return rme.buildError(
+				500,
+				"Unable to build mock (401)",
+				fmt.Sprintf("Errors occurred while generating an error 401 mock response: %s",
+					errors.Join(err, mockErr)),
+				"build_mock_error",
+			), 500, nil, mockErr
+		}
+		return mock, 401, nil, err
+	}
+	return rme.buildError(
+		401,
+		"Unauthorized (401)",
+		fmt.Sprintf("Unable to call '%s' on '%s', you are not authorized to access this resource",
+			request.Method, request.URL.Path),
+		"build_mock_error",
+	), 401, nil, err
+}
+
 func (rme *ResponseMockEngine) extractPreferred(request *http.Request) string {
 	return request.Header.Get(helpers.Preferred)
 }
 
-func (rme *ResponseMockEngine) runWorkflow(request *http.Request) ([]byte, int, error) {
+func (rme *ResponseMockEngine) runWorkflow(request *http.Request) ([]byte, int, http.Header, error) {
 
 	// get path, not valid? return 404
-	path, err := rme.findPath(request)
+	path, pathKey, err := rme.findPathAndKey(request)
 	if err != nil {
 		return rme.buildError(
 			404,
@@ -308,7 +376,7 @@ func (rme *ResponseMockEngine) runWorkflow(request *http.Request) ([]byte, int,
 			fmt.Sprintf("Unable to locate the path '%s' with the method '%s'. %s",
 				request.URL.Path, request.Method, err.Error()),
 			"not_found",
-		), 404, err
+		), 404, nil, err
 
 	}
 
@@ -318,28 +386,7 @@ func (rme *ResponseMockEngine) runWorkflow(request *http.Request) ([]byte, int,
 	// check the request is valid against security requirements.
 	err = rme.ValidateSecurity(request, operation)
 	if err != nil {
-		mt, _ := rme.findBestMediaTypeMatch(operation, request, []string{"401"})
-		if mt != nil {
-			mock, mockErr := rme.mockEngine.GenerateMock(mt, rme.extractPreferred(request))
-			if mockErr != nil {
-				return rme.buildError(
-					500,
-					"Unable to build mock (401)",
-					fmt.Sprintf("Errors occurred while generating an error 401 mock response: %s",
-						errors.Join(err, mockErr)),
-					"build_mock_error",
-				), 500, mockErr
-			}
-			return mock, 401, err
-		} else {
-			return rme.buildError(
-				401,
-				"Unauthorized (401)",
-				fmt.Sprintf("Unable to call '%s' on '%s', you are not authorized to access this resource",
-					request.Method, request.URL.Path),
-				"build_mock_error",
-			), 401, err
-		}
+		return rme.unauthorized(request, operation, err)
 	}
 
 	// validate the request against the document.
@@ -348,7 +395,7 @@ func (rme *ResponseMockEngine) runWorkflow(request *http.Request) ([]byte, int,
 	// but the mock is still served to allow development/testing workflows.
 	_, validationErrors := rme.validator.ValidateHttpRequest(request)
 	if rme.hardValidation && len(validationErrors) > 0 && !rme.shouldBypassValidation(request) {
-		mt, _ := rme.findBestMediaTypeMatch(operation, request, []string{"422", "400"})
+		mt, _, _ := rme.findBestMediaTypeMatch(operation, request, []string{"422", "400"})
 		if mt == nil {
 			// no default, no valid response, inform use with a 500
 			return rme.buildErrorWithPayload(
@@ -358,7 +405,7 @@ func (rme *ResponseMockEngine) runWorkflow(request *http.Request) ([]byte, int,
 					"'422' or '400' response for this operation. Check payload for validation errors.",
 				"validation_failed_and_spec_insufficient_error",
 				validationErrors,
-			), 500, rme.packErrors(validationErrors)
+			), 500, nil, rme.packErrors(validationErrors)
 		}
 		return rme.buildErrorWithPayload(
 			422,
@@ -366,32 +413,63 @@ func (rme *ResponseMockEngine) runWorkflow(request *http.Request) ([]byte, int,
 			"The request failed validation, Check payload for validation errors.",
 			"validation_failed_error",
 			validationErrors,
-		), 422, rme.packErrors(validationErrors)
+		), 422, nil, rme.packErrors(validationErrors)
 
 	}
 
+	prefs := ParsePreferences(request)
 	preferred := rme.extractPreferred(request)
+	if prefs.Example != "" {
+		preferred = prefs.Example
+	}
 
-	var lo string
+	var lo, mtKey string
 	var mt *v3.MediaType
 	var noMT bool = true
 
-	if preferred != "" {
+	if prefs.Code != "" && rme.hasResponseCode(operation, prefs.Code) {
+		// An explicit `Prefer: code=` selects the response, and the media type from that response.
+		lo = prefs.Code
+		mt, mtKey, noMT = rme.findBestMediaTypeMatch(operation, request, []string{lo})
+		prefs.apply(PreferCode, lo)
+	} else if preferred != "" {
 		// If an explicit preferred header is present, let it have a chance to take precedence
 		// This allows a developer to cause a 3xx, 4xx, or 5xx mocked response by passing
 		// the appropriate example header value.
-		mt, lo, noMT = rme.findMediaTypeContainingNamedExample(operation, request, preferred)
+		mt, mtKey, lo, noMT = rme.findMediaTypeContainingNamedExample(operation, request, preferred)
 	}
 
 	if noMT {
 		// When no preferred header is passed, or preferred header did not match a named example
 		lo = rme.findLowestSuccessCode(operation)
-		mt, noMT = rme.findBestMediaTypeMatch(operation, request, []string{lo})
+		mt, mtKey, noMT = rme.findBestMediaTypeMatch(operation, request, []string{lo})
+	}
+
+	if prefs.Example != "" && mt != nil && mt.Examples != nil {
+		if _, ok := mt.Examples.Get(prefs.Example); ok {
+			prefs.apply(PreferExample, prefs.Example)
+		}
+	}
+
+	headers := http.Header{}
+	if mtKey != "" {
+		headers.Set(helpers.ContentTypeHeader, mtKey)
 	}
 
 	c, _ := strconv.Atoi(lo)
 	if c == http.StatusNoContent {
-		return nil, c, nil
+		rme.setPreferenceApplied(headers, prefs)
+		return nil, c, headers, nil
+	}
+
+	if mt == nil && rme.notAcceptable(operation, lo, request) {
+		return rme.buildError(
+			406,
+			"Not acceptable",
+			fmt.Sprintf("None of the media types of the '%s' response are acceptable, the request only accepts '%s'",
+				lo, request.Header.Get("Accept")),
+			"not_acceptable",
+		), 406, nil, nil
 	}
 
 	if mt == nil && noMT {
@@ -401,10 +479,56 @@ func (rme *ResponseMockEngine) runWorkflow(request *http.Request) ([]byte, int,
 			"Media type not supported",
 			fmt.Sprintf("The media type requested '%s' is not supported by this operation", mtString),
 			"build_mock_error",
-		), 415, nil
+		), 415, nil, nil
 	}
 
-	mock, mockErr := rme.mockEngine.GenerateMock(mt, preferred)
+	// check for wiretap-status-code in header and override the code, regardless of what was found in the spec.
+	if statusCode := request.Header.Get("wiretap-status-code"); statusCode != "" {
+		c, _ = strconv.Atoi(statusCode)
+	}
+
+	// `Prefer: return=minimal` asks for no body at all, so there is nothing to generate.
+	if prefs.Return == PreferReturnMinimal {
+		prefs.apply(PreferReturn, PreferReturnMinimal)
+		rme.setPreferenceApplied(headers, prefs)
+		return nil, c, headers, nil
+	}
+	if prefs.Return == PreferReturnRepr {
+		prefs.apply(PreferReturn, PreferReturnRepr)
+	}
+
+	var mock []byte
+	var mockErr error
+	seed, seeded := rme.extractSeed(request)
+	seedKey := strings.Join([]string{request.Method, pathKey, lo, mtKey}, " ")
+	if schema := eventSchema(mt); sse.IsEventStream(mtKey) && schema != nil && preferred == "" &&
+		(prefs.Dynamic || !hasMediaTypeExamples(mt)) {
+		// server-sent event streams are a series of generated events, unless an example provides the stream.
+		mock, mockErr = rme.generateEvents(schema, seed, seeded, seedKey)
+		if prefs.Dynamic {
+			prefs.apply(PreferDynamic, "true")
+		}
+		if mockErr == nil {
+			rme.setPreferenceApplied(headers, prefs)
+			return mock, c, headers, nil
+		}
+	} else if prefs.Dynamic && mt != nil && mt.Schema != nil && mt.Schema.Schema() != nil {
+		// `Prefer: dynamic=true` skips the media type and schema examples, and generates from the schema.
+		dynamic := *mt.Schema.Schema()
+		dynamic.Example = nil
+		dynamic.Examples = nil
+		if seeded {
+			mock, mockErr = rme.generateSeededMock(&dynamic, seed, seedKey)
+		} else {
+			mock, mockErr = rme.mockEngine.GenerateMock(&dynamic, "")
+		}
+		prefs.apply(PreferDynamic, "true")
+	} else if seeded && preferred == "" && mt != nil && !hasMediaTypeExamples(mt) && mt.Schema != nil && mt.Schema.Schema() != nil {
+		// media type examples still win, only values generated from the schema are seeded.
+		mock, mockErr = rme.generateSeededMock(mt.Schema.Schema(), seed, seedKey)
+	} else {
+		mock, mockErr = rme.mockEngine.GenerateMock(mt, preferred)
+	}
 	if mockErr != nil {
 		return rme.buildError(
 			422,
@@ -412,13 +536,34 @@ func (rme *ResponseMockEngine) runWorkflow(request *http.Request) ([]byte, int,
 			fmt.Sprintf("Errors occurred while generating an error 422 mock response: %s",
 				errors.Join(err, mockErr)),
 			"build_mock_error",
-		), 422, mockErr
+		), 422, nil, mockErr
+	}
+
+	// list operations return the page of the collection that was asked for, when pagination is turned on.
+	if rme.paginationEnabled(prefs) && len(mock) > 0 && mt != nil && strings.Contains(mtKey, "json") &&
+		c >= 200 && c < 300 {
+		var schema *base.Schema
+		if mt.Schema != nil {
+			schema = mt.Schema.Schema()
+		}
+		if p := rme.detectPagination(request, path, operation, findResponse(operation, lo), schema); p != nil {
+			fromExample := !prefs.Dynamic && (hasMediaTypeExamples(mt) ||
+				(schema != nil && (schema.Example != nil || len(schema.Examples) > 0)))
+			var paged bool
+			mock, paged = rme.paginate(mock, p, schema, fromExample, request, headers, seedKey)
+			if paged && prefs.Paginate != nil {
+				prefs.apply(PreferPaginate, "true")
+			}
+		}
 	}
 
 	// The mock generator always JSON-encodes values (including scalars), which wraps
 	// plain strings in quotes and escapes HTML characters. For non-JSON content types
 	// (e.g. text/html, text/plain), unwrap the JSON string encoding to return raw content.
-	mediaTypeString := rme.extractMediaTypeHeader(request)
+	mediaTypeString := mtKey
+	if mediaTypeString == "" {
+		mediaTypeString = rme.extractMediaTypeHeader(request)
+	}
 	if !strings.Contains(mediaTypeString, "json") && len(mock) > 0 {
 		var raw string
 		if err := json.Unmarshal(mock, &raw); err == nil {
@@ -433,32 +578,44 @@ func (rme *ResponseMockEngine) runWorkflow(request *http.Request) ([]byte, int,
 			fmt.Sprintf("Nothing was generated for the request '%s' with the method '%s'. Response is empty",
 				request.URL.Path, request.Method),
 			"empty",
-		), 200, err
+		), 200, nil, err
 	}
 
-	// check for wiretap-status-code in header and override the code, regardless of what was found in the spec.
-	if statusCode := request.Header.Get("wiretap-status-code"); statusCode != "" {
-		c, _ = strconv.Atoi(statusCode)
+	rme.setPreferenceApplied(headers, prefs)
+	return mock, c, headers, nil
+}
+
+// setPreferenceApplied adds the `Preference-Applied` header if any preferences were honoured.
+func (rme *ResponseMockEngine) setPreferenceApplied(headers http.Header, prefs *MockPreferences) {
+	if applied := prefs.Applied(); applied != "" {
+		headers.Set(PreferenceAppliedHeader, applied)
 	}
+}
+
+// hasMediaTypeExamples checks if a media type defines an inline or named example.
+func hasMediaTypeExamples(mt *v3.MediaType) bool {
+	return mt.Example != nil || (mt.Examples != nil && mt.Examples.Len() > 0)
+}
 
-	return mock, c, nil
+// hasResponseCode checks if an operation defines a response for a status code.
+func (rme *ResponseMockEngine) hasResponseCode(operation *v3.Operation, code string) bool {
+	if operation == nil || operation.Responses == nil || operation.Responses.Codes == nil {
+		return false
+	}
+	_, ok := operation.Responses.Codes.Get(code)
+	return ok
 }
 
 func (rme *ResponseMockEngine) findMediaTypeContainingNamedExample(
 	operation *v3.Operation,
 	request *http.Request,
-	preferredExample string) (*v3.MediaType, string, bool) {
-
-	mediaTypeString := rme.extractMediaTypeHeader(request)
+	preferredExample string) (*v3.MediaType, string, string, bool) {
 
 	for codePairs := operation.Responses.Codes.First(); codePairs != nil; codePairs = codePairs.Next() {
 		resp := codePairs.Value()
 
 		if resp.Content != nil {
-			responseBody := resp.Content.GetOrZero(mediaTypeString)
-			if responseBody == nil {
-				responseBody = resp.Content.GetOrZero("application/json")
-			}
+			responseBody, mtKey := rme.selectMediaType(resp.Content, request)
 
 			if responseBody == nil {
 				continue
@@ -467,12 +624,12 @@ func (rme *ResponseMockEngine) findMediaTypeContainingNamedExample(
 			_, present := responseBody.Examples.Get(preferredExample)
 
 			if present {
-				return responseBody, codePairs.Key(), false
+				return responseBody, mtKey, codePairs.Key(), false
 			}
 		}
 	}
 
-	return nil, "", true
+	return nil, "", "", true
 }
 
 func (rme *ResponseMockEngine) findLowestSuccessCode(operation *v3.Operation) string {
@@ -495,14 +652,12 @@ func (rme *ResponseMockEngine) findLowestSuccessCode(operation *v3.Operation) st
 func (rme *ResponseMockEngine) findBestMediaTypeMatch(
 	op *v3.Operation,
 	request *http.Request,
-	resultCodes []string) (*v3.MediaType, bool) {
+	resultCodes []string) (*v3.MediaType, string, bool) {
 
 	if op.Responses == nil {
-		return nil, false
+		return nil, "", false
 	}
 
-	mediaTypeString := rme.extractMediaTypeHeader(request)
-
 	// Try to find a matching media type in responses matching
 	// parameterized result codes
 	for _, code := range resultCodes {
@@ -512,27 +667,21 @@ func (rme *ResponseMockEngine) findBestMediaTypeMatch(
 			continue
 		}
 		if resp.Content != nil {
-			responseBody := resp.Content.GetOrZero(mediaTypeString)
-			if responseBody != nil {
-				// try and extract a default JSON response
-				return responseBody, false
-			} else {
-				responseBody = resp.Content.GetOrZero("application/json")
-				return responseBody, false
-			}
+			responseBody, mtKey := rme.selectMediaType(resp.Content, request)
+			return responseBody, mtKey, false
 		} else {
 			// no content, so try and extract a default JSON response
-			return nil, false
+			return nil, "", false
 		}
 	}
 
 	// As a last resort, check if a default response is specified and attempt
 	// to use that
 	if op.Responses.Default != nil && op.Responses.Default.Content != nil {
-		if op.Responses.Default.Content.GetOrZero(mediaTypeString) != nil {
-			return op.Responses.Default.Content.GetOrZero(mediaTypeString), false
+		if responseBody, mtKey := rme.selectMediaType(op.Responses.Default.Content, request); responseBody != nil {
+			return responseBody, mtKey, false
 		}
 	}
 
-	return nil, true
+	return nil, "", true
 }
@@ -0,0 +1,493 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package mock
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+const (
+	// DefaultPaginationTotal is the number of items a paginated mock collection holds, unless configured.
+	DefaultPaginationTotal = 100
+	paginationDefaultLimit = 10
+	paginationMaxLimit     = 1000
+	paginationCursorPrefix = "offset:"
+)
+
+// query parameter names that are recognized as pagination controls.
+var (
+	paginationLimitParams  = []string{"limit", "per_page", "perpage", "page_size", "pagesize", "size", "max_results", "maxresults", "$top", "top"}
+	paginationPageParams   = []string{"page", "page_number", "pagenumber"}
+	paginationOffsetParams = []string{"offset", "skip", "$skip", "start"}
+	paginationCursorParams = []string{"cursor", "after", "starting_after", "page_token", "pagetoken", "next_token", "nexttoken", "continuation"}
+)
+
+// response field and header names that are filled in with pagination values, compared without case, `_` or `-`.
+var (
+	paginationItemFields     = []string{"items", "data", "results", "records", "content", "entries", "values", "list"}
+	paginationNestedFields   = []string{"meta", "links", "pagination", "paging", "pageinfo"}
+	paginationTotalFields    = []string{"total", "totalcount", "totalitems", "totalresults", "totalelements", "totalsize"}
+	paginationPagesFields    = []string{"totalpages", "pagecount", "pages"}
+	paginationHasMoreFields  = []string{"hasmore", "hasnext", "hasnextpage", "more"}
+	paginationNextFields     = []string{"next", "nextcursor", "nextpagetoken", "nexttoken", "nextpage", "nexturl", "nextlink", "nextpageurl", "nexthref"}
+	paginationPrevFields     = []string{"prev", "previous", "prevcursor", "previouscursor", "prevpagetoken", "previouspagetoken", "prevpage", "previouspage", "prevurl", "previousurl", "prevlink", "prevhref"}
+	paginationTotalHeaders   = []string{"xtotalcount", "totalcount", "xtotal"}
+	paginationLinkHeader     = "Link"
+	paginationFieldSeparator = strings.NewReplacer("_", "", "-", "")
+)
+
+// mockPagination describes the page of a collection that a request has asked for.
+type mockPagination struct {
+	limitParam  string
+	pageParam   string
+	offsetParam string
+	cursorParam string
+	limit       int
+	offset      int
+	total       int
+	linkHeader  bool
+	totalHeader string
+	count       int
+}
+
+// SetPagination turns on mock pagination for every paginated operation. Without it, requests opt in with
+// `Prefer: paginate=true`.
+func (rme *ResponseMockEngine) SetPagination(enabled bool) {
+	rme.pagination = enabled
+}
+
+// SetPaginationTotal sets the number of items a paginated mock collection holds.
+func (rme *ResponseMockEngine) SetPaginationTotal(total int) {
+	rme.paginationTotal = total
+}
+
+// paginationEnabled checks if a request should get a page of a collection, a `paginate` preference wins over
+// the engine setting.
+func (rme *ResponseMockEngine) paginationEnabled(prefs *MockPreferences) bool {
+	if prefs.Paginate != nil {
+		return *prefs.Paginate
+	}
+	return rme.pagination
+}
+
+// detectPagination checks if an operation is paginated, by looking for a paging shaped response: an array with a
+// `Link` or total count response header, or an object holding an array next to a total, has-more or next / previous
+// field. Query parameters only pick the page, they never make an operation paginated. Returns nil if it's not paginated.
+func (rme *ResponseMockEngine) detectPagination(
+	request *http.Request,
+	pathItem *v3.PathItem,
+	operation *v3.Operation,
+	response *v3.Response,
+	schema *base.Schema) *mockPagination {
+
+	p := &mockPagination{limit: paginationDefaultLimit, total: rme.paginationTotal}
+	if p.total <= 0 {
+		p.total = DefaultPaginationTotal
+	}
+
+	var params []*v3.Parameter
+	if pathItem != nil {
+		params = append(params, pathItem.Parameters...)
+	}
+	if operation != nil {
+		params = append(params, operation.Parameters...)
+	}
+
+	var limitSchema *base.Schema
+	for _, param := range params {
+		if param == nil || param.In != "query" {
+			continue
+		}
+		name := strings.ToLower(param.Name)
+		switch {
+		case p.limitParam == "" && slices.Contains(paginationLimitParams, name):
+			p.limitParam = param.Name
+			if param.Schema != nil {
+				limitSchema = param.Schema.Schema()
+			}
+		case p.pageParam == "" && slices.Contains(paginationPageParams, name):
+			p.pageParam = param.Name
+		case p.offsetParam == "" && slices.Contains(paginationOffsetParams, name):
+			p.offsetParam = param.Name
+		case p.cursorParam == "" && slices.Contains(paginationCursorParams, name):
+			p.cursorParam = param.Name
+		}
+	}
+
+	if response != nil && response.Headers != nil {
+		for name := range response.Headers.KeysFromOldest() {
+			if strings.EqualFold(name, paginationLinkHeader) {
+				p.linkHeader = true
+			}
+			if slices.Contains(paginationTotalHeaders, normalizePaginationField(name)) {
+				p.totalHeader = name
+			}
+		}
+	}
+
+	if !p.pagingShaped(schema) {
+		return nil
+	}
+
+	// the page size comes from the request, then the default of the limit parameter.
+	if limitSchema != nil && limitSchema.Default != nil {
+		if limit, err := strconv.Atoi(limitSchema.Default.Value); err == nil && limit > 0 {
+			p.limit = limit
+		}
+	}
+	query := request.URL.Query()
+	if limit, err := strconv.Atoi(query.Get(p.limitParam)); p.limitParam != "" && err == nil && limit > 0 {
+		p.limit = limit
+	}
+	if limitSchema != nil && limitSchema.Maximum != nil && p.limit > int(*limitSchema.Maximum) {
+		p.limit = int(*limitSchema.Maximum)
+	}
+	p.limit = max(1, min(p.limit, paginationMaxLimit))
+
+	// the position comes from an offset, then a cursor, then a page number.
+	if offset, err := strconv.Atoi(query.Get(p.offsetParam)); p.offsetParam != "" && err == nil && offset > 0 {
+		p.offset = offset
+	} else if cursor := query.Get(p.cursorParam); p.cursorParam != "" && cursor != "" {
+		p.offset = decodePaginationCursor(cursor)
+	} else if page, err := strconv.Atoi(query.Get(p.pageParam)); p.pageParam != "" && err == nil && page > 1 {
+		// pages past the last one are all empty, keeping them there stops the offset from overflowing.
+		p.offset = (min(page, p.pages()+1) - 1) * p.limit
+	}
+
+	p.count = max(0, min(p.limit, p.total-p.offset))
+	return p
+}
+
+// paginate reshapes a JSON mock into the requested page, returning false when the mock is left as it is. The
+// collection is either the body itself, or the first array field in it. When the mock came from an example, its items
+// are used for the start of the collection, the rest are rendered from the items schema. Rendering is always seeded,
+// so items stay the same across pages and requests.
+func (rme *ResponseMockEngine) paginate(
+	mock []byte,
+	p *mockPagination,
+	schema *base.Schema,
+	fromExample bool,
+	request *http.Request,
+	headers http.Header,
+	seedKey string) ([]byte, bool) {
+
+	var body any
+	decoder := json.NewDecoder(bytes.NewReader(mock))
+	decoder.UseNumber()
+	if err := decoder.Decode(&body); err != nil {
+		return mock, false
+	}
+
+	seed, _ := rme.extractSeed(request)
+	page := func(existing []any, itemSchema *base.Schema) []any {
+		if len(existing) == 0 && itemSchema == nil {
+			return existing
+		}
+		if !fromExample && itemSchema != nil {
+			existing = nil
+		}
+		items := make([]any, 0, p.count)
+		for i := 0; i < p.count; i++ {
+			index := p.offset + i
+			switch {
+			case index < len(existing):
+				items = append(items, existing[index])
+			case itemSchema != nil:
+				item, err := rme.renderSeeded(itemSchema, seed, fmt.Sprintf("%s item %d", seedKey, index))
+				if err != nil {
+					return existing
+				}
+				items = append(items, item)
+			default:
+				items = append(items, existing[index%len(existing)])
+			}
+		}
+		return items
+	}
+
+	// the mock itself has to be paging shaped as well, examples that aren't are returned as they are.
+	switch value := body.(type) {
+	case []any:
+		if !p.linkHeader && p.totalHeader == "" {
+			return mock, false
+		}
+		body = page(value, paginationItemsSchema(schema))
+	case map[string]any:
+		field := paginationItemsField(value, schema)
+		if field == "" || !hasBodyPaginationFields(value, 0) {
+			return mock, false
+		}
+		var itemSchema *base.Schema
+		if schema != nil && schema.Properties != nil {
+			if proxy := schema.Properties.GetOrZero(field); proxy != nil {
+				itemSchema = paginationItemsSchema(proxy.Schema())
+			}
+		}
+		value[field] = page(value[field].([]any), itemSchema)
+		p.fillFields(value, field, request)
+	default:
+		return mock, false
+	}
+
+	if p.linkHeader {
+		if link := p.links(request); link != "" {
+			headers.Set(paginationLinkHeader, link)
+		}
+	}
+	if p.totalHeader != "" {
+		headers.Set(p.totalHeader, strconv.Itoa(p.total))
+	}
+
+	var paged []byte
+	var err error
+	if rme.pretty {
+		paged, err = json.MarshalIndent(body, "", "  ")
+	} else {
+		paged, err = json.Marshal(body)
+	}
+	if err != nil {
+		return mock, false
+	}
+	return paged, true
+}
+
+// fillFields sets the total, next / previous and has-more fields in a paginated response, including
+// those nested in objects like `meta` or `links`.
+func (p *mockPagination) fillFields(body map[string]any, itemsField string, request *http.Request) {
+	for key, value := range body {
+		if key == itemsField {
+			continue
+		}
+		field := normalizePaginationField(key)
+		switch {
+		case slices.Contains(paginationTotalFields, field):
+			body[key] = p.total
+		case slices.Contains(paginationPagesFields, field):
+			body[key] = p.pages()
+		case slices.Contains(paginationHasMoreFields, field):
+			body[key] = p.hasNext()
+		case slices.Contains(paginationNextFields, field):
+			body[key] = p.fieldValue(field, value, request, p.offset+p.limit, p.hasNext())
+		case slices.Contains(paginationPrevFields, field):
+			body[key] = p.fieldValue(field, value, request, max(0, p.offset-p.limit), p.offset > 0)
+		case p.limitParam != "" && strings.EqualFold(key, p.limitParam):
+			body[key] = p.limit
+		case p.offsetParam != "" && strings.EqualFold(key, p.offsetParam):
+			body[key] = p.offset
+		case p.pageParam != "" && strings.EqualFold(key, p.pageParam):
+			body[key] = p.page(p.offset)
+		case slices.Contains(paginationNestedFields, field):
+			if nested, ok := value.(map[string]any); ok {
+				p.fillFields(nested, itemsField, request)
+			}
+		}
+	}
+}
+
+// fieldValue renders a next or previous field, keeping the shape the mock already has: page numbers stay
+// numbers, link objects keep their `href`, cursor and token fields get a cursor, anything else gets a URL.
+func (p *mockPagination) fieldValue(field string, existing any, request *http.Request, offset int, exists bool) any {
+	if !exists {
+		return nil
+	}
+	switch value := existing.(type) {
+	case json.Number, float64, int:
+		return p.page(offset)
+	case map[string]any:
+		if _, ok := value["href"]; ok {
+			value["href"] = p.url(request, offset)
+		}
+		return value
+	}
+	if strings.Contains(field, "cursor") || strings.Contains(field, "token") {
+		return encodePaginationCursor(offset)
+	}
+	return p.url(request, offset)
+}
+
+// links renders an RFC 8288 `Link` header, with first, prev, next and last relations.
+func (p *mockPagination) links(request *http.Request) string {
+	var links []string
+	add := func(offset int, rel string) {
+		links = append(links, fmt.Sprintf("<%s>; rel=\"%s\"", p.url(request, offset), rel))
+	}
+	add(0, "first")
+	if p.offset > 0 {
+		add(max(0, p.offset-p.limit), "prev")
+	}
+	if p.hasNext() {
+		add(p.offset+p.limit, "next")
+	}
+	if p.cursorParam == "" {
+		add((p.pages()-1)*p.limit, "last")
+	}
+	return strings.Join(links, ", ")
+}
+
+// url renders the request URL for the page starting at offset, using the pagination parameters of the operation.
+func (p *mockPagination) url(request *http.Request, offset int) string {
+	u := *request.URL
+	query := u.Query()
+	switch {
+	case p.cursorParam != "":
+		query.Set(p.cursorParam, encodePaginationCursor(offset))
+	case p.offsetParam != "":
+		query.Set(p.offsetParam, strconv.Itoa(offset))
+	case p.pageParam != "":
+		query.Set(p.pageParam, strconv.Itoa(p.page(offset)))
+	default:
+		query.Set("page", strconv.Itoa(p.page(offset)))
+	}
+	if p.limitParam != "" {
+		query.Set(p.limitParam, strconv.Itoa(p.limit))
+	}
+	u.RawQuery = query.Encode()
+	return u.RequestURI()
+}
+
+func (p *mockPagination) hasNext() bool {
+	return p.offset+p.limit < p.total
+}
+
+func (p *mockPagination) page(offset int) int {
+	return offset/p.limit + 1
+}
+
+func (p *mockPagination) pages() int {
+	return max(1, int(math.Ceil(float64(p.total)/float64(p.limit))))
+}
+
+// paginationItemsField finds the collection in a response object, a well known field name first, then the first
+// array defined by the schema.
+func paginationItemsField(body map[string]any, schema *base.Schema) string {
+	for _, name := range paginationItemFields {
+		for key, value := range body {
+			if _, ok := value.([]any); ok && strings.EqualFold(key, name) {
+				return key
+			}
+		}
+	}
+	if schema != nil && schema.Properties != nil {
+		for key := range schema.Properties.KeysFromOldest() {
+			if _, ok := body[key].([]any); ok {
+				return key
+			}
+		}
+	}
+	return ""
+}
+
+func paginationItemsSchema(schema *base.Schema) *base.Schema {
+	if schema == nil || schema.Items == nil || !schema.Items.IsA() || schema.Items.A == nil {
+		return nil
+	}
+	return schema.Items.A.Schema()
+}
+
+// pagingShaped checks if a response schema describes a page of a collection. Arrays need a `Link` or total count
+// header to say where they sit in the collection, objects need an array and a total, has-more or next / previous field.
+func (p *mockPagination) pagingShaped(schema *base.Schema) bool {
+	if schema == nil {
+		return false
+	}
+	if paginationItemsSchema(schema) != nil {
+		return p.linkHeader || p.totalHeader != ""
+	}
+	if schema.Properties == nil {
+		return false
+	}
+	hasItems := false
+	for _, proxy := range schema.Properties.FromOldest() {
+		if property := proxy.Schema(); property != nil && paginationItemsSchema(property) != nil {
+			hasItems = true
+			break
+		}
+	}
+	return hasItems && hasPaginationFields(schema, 0)
+}
+
+// hasPaginationFields checks if a response schema has total, has-more or next / previous fields.
+func hasPaginationFields(schema *base.Schema, depth int) bool {
+	if schema == nil || schema.Properties == nil || depth > 1 {
+		return false
+	}
+	for key, proxy := range schema.Properties.FromOldest() {
+		field := normalizePaginationField(key)
+		if isPaginationField(field) {
+			return true
+		}
+		// pagination links are often nested, in `links` or `meta`.
+		if slices.Contains(paginationNestedFields, field) && hasPaginationFields(proxy.Schema(), depth+1) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasBodyPaginationFields checks if a response body has total, has-more or next / previous fields.
+func hasBodyPaginationFields(body map[string]any, depth int) bool {
+	if depth > 1 {
+		return false
+	}
+	for key, value := range body {
+		field := normalizePaginationField(key)
+		if isPaginationField(field) {
+			return true
+		}
+		if nested, ok := value.(map[string]any); ok && slices.Contains(paginationNestedFields, field) &&
+			hasBodyPaginationFields(nested, depth+1) {
+			return true
+		}
+	}
+	return false
+}
+
+func isPaginationField(field string) bool {
+	return slices.Contains(paginationTotalFields, field) || slices.Contains(paginationPagesFields, field) ||
+		slices.Contains(paginationHasMoreFields, field) || slices.Contains(paginationNextFields, field) ||
+		slices.Contains(paginationPrevFields, field)
+}
+
+func normalizePaginationField(name string) string {
+	return strings.ToLower(paginationFieldSeparator.Replace(name))
+}
+
+func encodePaginationCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(paginationCursorPrefix + strconv.Itoa(offset)))
+}
+
+// decodePaginationCursor returns the offset in a cursor, or zero if the cursor was not created by the mock engine.
+func decodePaginationCursor(cursor string) int {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(decoded), paginationCursorPrefix))
+	if err != nil || offset < 0 {
+		return 0
+	}
+	return offset
+}
+
+// findResponse returns the response of an operation for a status code, or the default response.
+func findResponse(operation *v3.Operation, code string) *v3.Response {
+	if operation == nil || operation.Responses == nil {
+		return nil
+	}
+	if response := operation.Responses.Codes.GetOrZero(code); response != nil {
+		return response
+	}
+	return operation.Responses.Default
+}
@@ -0,0 +1,348 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package mock
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var paginationSpec = `openapi: 3.1.0
+paths:
+  /pets:
+    get:
+      parameters:
+        - name: page
+          in: query
+          schema:
+            type: integer
+        - name: per_page
+          in: query
+          schema:
+            type: integer
+            default: 5
+            maximum: 50
+      responses:
+        '200':
+          headers:
+            Link:
+              schema:
+                type: string
+            X-Total-Count:
+              schema:
+                type: integer
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Pet'
+  /orders:
+    get:
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+        - name: cursor
+          in: query
+          schema:
+            type: string
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  data:
+                    type: array
+                    items:
+                      $ref: '#/components/schemas/Pet'
+                  totalCount:
+                    type: integer
+                  nextCursor:
+                    type: string
+                  prevCursor:
+                    type: string
+                  meta:
+                    type: object
+                    properties:
+                      hasMore:
+                        type: boolean
+  /tags:
+    get:
+      parameters:
+        - name: size
+          in: query
+          schema:
+            type: integer
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  type: string
+              example: [red, green, blue]
+  /owners:
+    get:
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  items:
+                    type: array
+                    items:
+                      type: string
+                  owner:
+                    type: string
+  /invoices:
+    get:
+      parameters:
+        - name: page
+          in: query
+          schema:
+            type: integer
+        - name: limit
+          in: query
+          schema:
+            type: integer
+            maximum: 0
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  items:
+                    type: array
+                    items:
+                      type: string
+                  page:
+                    type: integer
+                  total:
+                    type: integer
+  /pets/{id}:
+    get:
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: string
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  tags:
+                    type: array
+                    items:
+                      type: string
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [id, name]
+      properties:
+        id:
+          type: string
+          format: uuid
+        name:
+          type: string
+`
+
+func paginationTestEngine(t *testing.T) *ResponseMockEngine {
+	d, err := libopenapi.NewDocument([]byte(paginationSpec))
+	require.NoError(t, err)
+	doc, err := d.BuildV3Model()
+	require.NoError(t, err)
+	me := NewMockEngine(&doc.Model, false, true)
+	me.SetPagination(true)
+	me.SetPaginationTotal(12)
+	return me
+}
+
+func generatePage(t *testing.T, me *ResponseMockEngine, uri string, prefer ...string) ([]byte, http.Header) {
+	request, _ := http.NewRequest(http.MethodGet, "https://api.pb33f.io"+uri, nil)
+	for _, preference := range prefer {
+		request.Header.Add(PreferHeader, preference)
+	}
+	b, status, headers, err := me.GenerateResponseWithHeaders(request)
+	require.NoError(t, err)
+	require.Equal(t, 200, status)
+	return b, headers
+}
+
+func TestMockEngine_Pagination_PageNumbers(t *testing.T) {
+	me := paginationTestEngine(t)
+
+	// the default page size comes from the parameter schema.
+	b, headers := generatePage(t, me, "/pets")
+	var pets []map[string]any
+	require.NoError(t, json.Unmarshal(b, &pets))
+	assert.Len(t, pets, 5)
+	assert.Equal(t, "12", headers.Get("X-Total-Count"))
+	assert.Equal(t, `</pets?page=1&per_page=5>; rel="first", </pets?page=2&per_page=5>; rel="next", `+
+		`</pets?page=3&per_page=5>; rel="last"`, headers.Get("Link"))
+
+	// the last page only holds what is left of the collection.
+	b, headers = generatePage(t, me, "/pets?page=3&per_page=5")
+	require.NoError(t, json.Unmarshal(b, &pets))
+	assert.Len(t, pets, 2)
+	assert.NotContains(t, headers.Get("Link"), `rel="next"`)
+	assert.Contains(t, headers.Get("Link"), `</pets?page=2&per_page=5>; rel="prev"`)
+
+	// pages past the end are empty.
+	b, _ = generatePage(t, me, "/pets?page=9&per_page=5")
+	assert.JSONEq(t, `[]`, string(b))
+}
+
+func TestMockEngine_Pagination_HugePageNumbers(t *testing.T) {
+	me := paginationTestEngine(t)
+
+	// (page - 1) * per_page overflows to -1.
+
+	b, headers := generatePage(t, me, "/pets?page=3689348814741910324&per_page=5")
+	assert.JSONEq(t, `[]`, string(b))
+	assert.Equal(t, "12", headers.Get("X-Total-Count"))
+	assert.NotContains(t, headers.Get("Link"), `rel="next"`)
+}
+
+func TestMockEngine_Pagination_LimitMaximumBelowOne(t *testing.T) {
+	me := paginationTestEngine(t)
+
+	var invoices struct {
+		Items []string `json:"items"`
+		Page  int      `json:"page"`
+	}
+	b, _ := generatePage(t, me, "/invoices?page=2")
+	require.NoError(t, json.Unmarshal(b, &invoices))
+	assert.Len(t, invoices.Items, 1)
+	assert.Equal(t, 2, invoices.Page)
+}
+
+func TestMockEngine_Pagination_ItemsAreConsistentAcrossPages(t *testing.T) {
+	me := paginationTestEngine(t)
+
+	var all, second []map[string]any
+	b, _ := generatePage(t, me, "/pets?per_page=10")
+	require.NoError(t, json.Unmarshal(b, &all))
+	b, _ = generatePage(t, me, "/pets?page=2&per_page=5")
+	require.NoError(t, json.Unmarshal(b, &second))
+
+	require.Len(t, all, 10)
+	require.Len(t, second, 5)
+	assert.Equal(t, all[5:], second)
+	assert.NotEqual(t, all[0]["id"], all[1]["id"])
+}
+
+func TestMockEngine_Pagination_Cursors(t *testing.T) {
+	me := paginationTestEngine(t)
+
+	b, _ := generatePage(t, me, "/orders?limit=5")
+	var first map[string]any
+	require.NoError(t, json.Unmarshal(b, &first))
+	assert.Len(t, first["data"], 5)
+	assert.Equal(t, float64(12), first["totalCount"])
+	assert.Nil(t, first["prevCursor"])
+	assert.Equal(t, true, first["meta"].(map[string]any)["hasMore"])
+
+	next := first["nextCursor"].(string)
+	require.NotEmpty(t, next)
+
+	b, _ = generatePage(t, me, "/orders?limit=5&cursor="+url.QueryEscape(next))
+	var second map[string]any
+	require.NoError(t, json.Unmarshal(b, &second))
+	assert.Len(t, second["data"], 5)
+	assert.NotNil(t, second["prevCursor"])
+
+	b, _ = generatePage(t, me, "/orders?limit=5&cursor="+url.QueryEscape(second["nextCursor"].(string)))
+	var last map[string]any
+	require.NoError(t, json.Unmarshal(b, &last))
+	assert.Len(t, last["data"], 2)
+	assert.Nil(t, last["nextCursor"])
+	assert.Equal(t, false, last["meta"].(map[string]any)["hasMore"])
+}
+
+func TestMockEngine_Pagination_NotPaginated(t *testing.T) {
+	me := paginationTestEngine(t)
+	b, headers := generatePage(t, me, "/pets/1")
+
+	var pet map[string]any
+	require.NoError(t, json.Unmarshal(b, &pet))
+	assert.Len(t, pet["tags"], 1)
+	assert.Empty(t, headers.Get("Link"))
+}
+
+func TestDecodePaginationCursor(t *testing.T) {
+	assert.Equal(t, 25, decodePaginationCursor(encodePaginationCursor(25)))
+	assert.Equal(t, 0, decodePaginationCursor("not-a-cursor"))
+}
+
+func TestMockEngine_Pagination_FirstPageIsStable(t *testing.T) {
+	me := paginationTestEngine(t)
+	first, _ := generatePage(t, me, "/pets")
+	again, _ := generatePage(t, me, "/pets")
+	assert.Equal(t, first, again)
+}
+
+func TestMockEngine_Pagination_IsOptIn(t *testing.T) {
+	me := paginationTestEngine(t)
+	me.SetPagination(false)
+
+	var pets []map[string]any
+	b, headers := generatePage(t, me, "/pets?per_page=2")
+	require.NoError(t, json.Unmarshal(b, &pets))
+	assert.Len(t, pets, 1)
+	assert.Empty(t, headers.Get("Link"))
+	assert.Empty(t, headers.Get(PreferenceAppliedHeader))
+
+	b, headers = generatePage(t, me, "/pets?per_page=2", "paginate=true")
+	require.NoError(t, json.Unmarshal(b, &pets))
+	assert.Len(t, pets, 2)
+	assert.Equal(t, "12", headers.Get("X-Total-Count"))
+	assert.Equal(t, "paginate=true", headers.Get(PreferenceAppliedHeader))
+
+	// a preference also turns pagination off, when it's on for the engine.
+	me.SetPagination(true)
+	b, headers = generatePage(t, me, "/pets?per_page=2", "paginate=false")
+	require.NoError(t, json.Unmarshal(b, &pets))
+	assert.Len(t, pets, 1)
+	assert.Empty(t, headers.Get("Link"))
+}
+
+func TestMockEngine_Pagination_NeedsPagingShapedResponses(t *testing.T) {
+	me := paginationTestEngine(t)
+
+	// a generic `size` parameter on a plain array doesn't make it a page, the example is returned as it is.
+	b, headers := generatePage(t, me, "/tags?size=1")
+	assert.JSONEq(t, `["red","green","blue"]`, string(b))
+	assert.Empty(t, headers.Get("Link"))
+
+	// neither does an array in an object without a total or cursor field.
+	b, _ = generatePage(t, me, "/owners?limit=5")
+	var owners map[string]any
+	require.NoError(t, json.Unmarshal(b, &owners))
+	assert.Len(t, owners["items"], 1)
+}
@@ -0,0 +1,204 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package mock
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+)
+
+const (
+	PreferHeader            = "Prefer"
+	PreferenceAppliedHeader = "Preference-Applied"
+	PreferCode              = "code"
+	PreferExample           = "example"
+	PreferDynamic           = "dynamic"
+	PreferReturn            = "return"
+	PreferPaginate          = "paginate"
+	PreferReturnMinimal     = "minimal"
+	PreferReturnRepr        = "representation"
+)
+
+// MockPreferences holds the RFC 7240 preferences a client can send in the `Prefer` header to drive
+// the mock engine, e.g. `Prefer: code=404, example=notFound` or `Prefer: dynamic=true, return=minimal`.
+// Paginate is nil unless the request asked for pagination to be turned on or off.
+type MockPreferences struct {
+	Code     string
+	Example  string
+	Dynamic  bool
+	Return   string
+	Paginate *bool
+	applied  []string
+}
+
+// apply records a preference as honoured, so it can be returned in the `Preference-Applied` header.
+func (mp *MockPreferences) apply(preference, value string) {
+	mp.applied = append(mp.applied, preference+"="+value)
+}
+
+// Applied returns the value of the `Preference-Applied` header, or an empty string if nothing was applied.
+func (mp *MockPreferences) Applied() string {
+	return strings.Join(mp.applied, ", ")
+}
+
+// ParsePreferences extracts mock preferences from all `Prefer` headers on a request. Unknown preferences
+// and parameters are ignored, as RFC 7240 requires.
+func ParsePreferences(request *http.Request) *MockPreferences {
+	prefs := &MockPreferences{}
+	for _, header := range request.Header.Values(PreferHeader) {
+		for _, preference := range strings.Split(header, ",") {
+			// drop any preference parameters, they are not used by any supported preference.
+			token, _, _ := strings.Cut(preference, ";")
+			name, value, _ := strings.Cut(strings.TrimSpace(token), "=")
+			value = strings.Trim(strings.TrimSpace(value), `"`)
+
+			switch strings.ToLower(strings.TrimSpace(name)) {
+			case PreferCode:
+				if _, err := strconv.Atoi(value); err == nil && prefs.Code == "" {
+					prefs.Code = value
+				}
+			case PreferExample:
+				if prefs.Example == "" {
+					prefs.Example = value
+				}
+			case PreferDynamic:
+				prefs.Dynamic = strings.EqualFold(value, "true")
+			case PreferPaginate:
+				paginate := strings.EqualFold(value, "true")
+				prefs.Paginate = &paginate
+			case PreferReturn:
+				if v := strings.ToLower(value); v == PreferReturnMinimal || v == PreferReturnRepr {
+					prefs.Return = v
+				}
+			}
+		}
+	}
+	return prefs
+}
+
+type acceptedMediaType struct {
+	mediaType string
+	q         float64
+	order     int
+}
+
+// specificity ranks how closely the accepted media type describes a response media type: `*/*` is the least
+// specific, then `type/*`, then the media type itself.
+func (amt acceptedMediaType) specificity() int {
+	switch {
+	case amt.mediaType == "*/*":
+		return 0
+	case strings.HasSuffix(amt.mediaType, "/*"):
+		return 1
+	}
+	return 2
+}
+
+// matches checks if a response media type is in the accepted range.
+func (amt acceptedMediaType) matches(mediaType string) bool {
+	switch amt.specificity() {
+	case 0:
+		return true
+	case 1:
+		return strings.HasPrefix(mediaType, strings.TrimSuffix(amt.mediaType, "*"))
+	}
+	return amt.mediaType == mediaType
+}
+
+// parseAcceptHeader parses an `Accept` header into the media ranges it lists, in the order they were sent.
+// Media ranges with q=0 are kept, they rule out the media types they match.
+func parseAcceptHeader(accept string) []acceptedMediaType {
+	var accepted []acceptedMediaType
+	for i, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if qv, ok := params["q"]; ok {
+			if parsed, pErr := strconv.ParseFloat(qv, 64); pErr == nil {
+				q = min(max(parsed, 0), 1)
+			}
+		}
+		accepted = append(accepted, acceptedMediaType{mediaType: mediaType, q: q, order: i})
+	}
+	return accepted
+}
+
+// acceptance returns the q-value the `Accept` header gives a response media type, from the most specific media
+// range that matches it, and the specificity of that range. A media type no range matches is not acceptable.
+func acceptance(accepted []acceptedMediaType, mediaType string) (float64, int) {
+	if parsed, _, err := mime.ParseMediaType(mediaType); err == nil {
+		mediaType = parsed
+	}
+	q, specificity := 0.0, -1
+	for _, amt := range accepted {
+		if amt.specificity() > specificity && amt.matches(mediaType) {
+			q, specificity = amt.q, amt.specificity()
+		}
+	}
+	return q, specificity
+}
+
+// selectMediaType picks the response media type to mock from a response content map. Each media type is given the
+// q-value of the most specific `Accept` media range matching it, media types with a q-value of zero, or that no
+// range matches, are not acceptable. The highest q-value wins, then media types listed explicitly over those matched
+// by a wildcard, then the media type from `Content-Type` / `Accept` as before, then `application/json`, then the
+// order of the specification. Without an `Accept` header, everything is acceptable. Returns nil when nothing is.
+func (rme *ResponseMockEngine) selectMediaType(
+	content *orderedmap.Map[string, *v3.MediaType],
+	request *http.Request) (*v3.MediaType, string) {
+
+	if content == nil {
+		return nil, ""
+	}
+
+	accepted := parseAcceptHeader(request.Header.Get("Accept"))
+	if len(accepted) == 0 {
+		accepted = []acceptedMediaType{{mediaType: "*/*", q: 1}}
+	}
+	mediaTypeString := rme.extractMediaTypeHeader(request)
+	rank := func(mediaType string) int {
+		switch mediaType {
+		case mediaTypeString:
+			return 2
+		case "application/json":
+			return 1
+		}
+		return 0
+	}
+
+	var selected *v3.MediaType
+	var selectedKey string
+	var selectedQ float64
+	var selectedSpecificity int
+	for pair := content.First(); pair != nil; pair = pair.Next() {
+		q, specificity := acceptance(accepted, pair.Key())
+		if q <= 0 {
+			continue
+		}
+		if selected == nil || q > selectedQ ||
+			(q == selectedQ && (specificity > selectedSpecificity ||
+				(specificity == selectedSpecificity && rank(pair.Key()) > rank(selectedKey)))) {
+			selected, selectedKey, selectedQ, selectedSpecificity = pair.Value(), pair.Key(), q, specificity
+		}
+	}
+	return selected, selectedKey
+}
+
+// notAcceptable checks if the response of an operation for a status code has content, but the `Accept` header
+// of a request rules all of it out.
+func (rme *ResponseMockEngine) notAcceptable(operation *v3.Operation, code string, request *http.Request) bool {
+	response := findResponse(operation, code)
+	if response == nil || response.Content == nil || response.Content.Len() == 0 {
+		return false
+	}
+	mt, _ := rme.selectMediaType(response.Content, request)
+	return mt == nil
+}
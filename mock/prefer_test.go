@@ -0,0 +1,211 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package mock
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var preferSpec = `openapi: 3.1.0
+paths:
+  /things:
+    get:
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Thing'
+              examples:
+                happyDays:
+                  value:
+                    name: happy days
+                robocop:
+                  value:
+                    name: robocop
+            application/xml:
+              schema:
+                $ref: '#/components/schemas/Thing'
+              example: <thing><name>xml</name></thing>
+        '404':
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  message:
+                    type: string
+              examples:
+                missing:
+                  value:
+                    message: thing is missing
+                gone:
+                  value:
+                    message: thing is gone
+components:
+  schemas:
+    Thing:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+          example: nameExample
+`
+
+func preferTestEngine(t *testing.T) *ResponseMockEngine {
+	d, err := libopenapi.NewDocument([]byte(preferSpec))
+	require.NoError(t, err)
+	doc, err := d.BuildV3Model()
+	require.NoError(t, err)
+	return NewMockEngine(&doc.Model, false, false)
+}
+
+func TestParsePreferences(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodGet, "https://api.pb33f.io/things", nil)
+	request.Header.Add(PreferHeader, `code=404; foo=bar, example="gone"`)
+	request.Header.Add(PreferHeader, "dynamic=true, return=Minimal, respond-async, wait=10")
+
+	prefs := ParsePreferences(request)
+	assert.Equal(t, "404", prefs.Code)
+	assert.Equal(t, "gone", prefs.Example)
+	assert.True(t, prefs.Dynamic)
+	assert.Equal(t, PreferReturnMinimal, prefs.Return)
+	assert.Empty(t, prefs.Applied())
+}
+
+func TestMockEngine_Prefer_CodeAndExample(t *testing.T) {
+	me := preferTestEngine(t)
+	request, _ := http.NewRequest(http.MethodGet, "https://api.pb33f.io/things", nil)
+	request.Header.Set(PreferHeader, "code=404, example=gone")
+
+	b, status, headers, err := me.GenerateResponseWithHeaders(request)
+	assert.NoError(t, err)
+	assert.Equal(t, 404, status)
+	assert.JSONEq(t, `{"message":"thing is gone"}`, string(b))
+	assert.Equal(t, "code=404, example=gone", headers.Get(PreferenceAppliedHeader))
+	assert.Equal(t, "application/json", headers.Get("Content-Type"))
+}
+
+func TestMockEngine_Prefer_UnknownCodeIsIgnored(t *testing.T) {
+	me := preferTestEngine(t)
+	request, _ := http.NewRequest(http.MethodGet, "https://api.pb33f.io/things", nil)
+	request.Header.Set(PreferHeader, "code=503")
+
+	b, status, headers, err := me.GenerateResponseWithHeaders(request)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, status)
+	assert.JSONEq(t, `{"name":"happy days"}`, string(b))
+	assert.Empty(t, headers.Get(PreferenceAppliedHeader))
+}
+
+func TestMockEngine_Prefer_ExampleSelectsResponse(t *testing.T) {
+	me := preferTestEngine(t)
+	request, _ := http.NewRequest(http.MethodGet, "https://api.pb33f.io/things", nil)
+	request.Header.Set(PreferHeader, "example=missing")
+
+	b, status, headers, err := me.GenerateResponseWithHeaders(request)
+	assert.NoError(t, err)
+	assert.Equal(t, 404, status)
+	assert.JSONEq(t, `{"message":"thing is missing"}`, string(b))
+	assert.Equal(t, "example=missing", headers.Get(PreferenceAppliedHeader))
+}
+
+func TestMockEngine_Prefer_Dynamic(t *testing.T) {
+	me := preferTestEngine(t)
+	request, _ := http.NewRequest(http.MethodGet, "https://api.pb33f.io/things", nil)
+	request.Header.Set(PreferHeader, "dynamic=true")
+
+	b, status, headers, err := me.GenerateResponseWithHeaders(request)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, status)
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.NotEqual(t, "happy days", decoded["name"])
+	assert.NotEqual(t, "robocop", decoded["name"])
+	assert.Equal(t, "dynamic=true", headers.Get(PreferenceAppliedHeader))
+}
+
+func TestMockEngine_Prefer_ReturnMinimal(t *testing.T) {
+	me := preferTestEngine(t)
+	request, _ := http.NewRequest(http.MethodGet, "https://api.pb33f.io/things", nil)
+	request.Header.Set(PreferHeader, "return=minimal")
+
+	b, status, headers, err := me.GenerateResponseWithHeaders(request)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, status)
+	assert.Empty(t, b)
+	assert.Equal(t, "return=minimal", headers.Get(PreferenceAppliedHeader))
+}
+
+func TestMockEngine_Accept_QValues(t *testing.T) {
+	me := preferTestEngine(t)
+	request, _ := http.NewRequest(http.MethodGet, "https://api.pb33f.io/things", nil)
+	request.Header.Set("Accept", "application/json;q=0.5, application/xml;q=0.9, */*;q=0.1")
+
+	b, status, headers, err := me.GenerateResponseWithHeaders(request)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "<thing><name>xml</name></thing>", string(b))
+	assert.Equal(t, "application/xml", headers.Get("Content-Type"))
+
+	request.Header.Set("Accept", "application/xml;q=0, application/json")
+	b, _, headers, err = me.GenerateResponseWithHeaders(request)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"name":"happy days"}`, string(b))
+	assert.Equal(t, "application/json", headers.Get("Content-Type"))
+}
+
+func TestMockEngine_Accept_WildcardKeepsJsonDefault(t *testing.T) {
+	me := preferTestEngine(t)
+	request, _ := http.NewRequest(http.MethodGet, "https://api.pb33f.io/things", nil)
+	request.Header.Set("Accept", "*/*")
+
+	_, _, headers, err := me.GenerateResponseWithHeaders(request)
+	assert.NoError(t, err)
+	assert.Equal(t, "application/json", headers.Get("Content-Type"))
+}
+
+func TestMockEngine_Accept_ZeroQRulesOutJson(t *testing.T) {
+	me := preferTestEngine(t)
+	request, _ := http.NewRequest(http.MethodGet, "https://api.pb33f.io/things", nil)
+	request.Header.Set("Accept", "application/json;q=0, */*")
+
+	b, status, headers, err := me.GenerateResponseWithHeaders(request)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "<thing><name>xml</name></thing>", string(b))
+	assert.Equal(t, "application/xml", headers.Get("Content-Type"))
+}
+
+func TestMockEngine_Accept_WildcardQValuesAreHonoured(t *testing.T) {
+	me := preferTestEngine(t)
+	request, _ := http.NewRequest(http.MethodGet, "https://api.pb33f.io/things", nil)
+	request.Header.Set("Accept", "application/json;q=0.2, application/*;q=0.8")
+
+	_, _, headers, err := me.GenerateResponseWithHeaders(request)
+	assert.NoError(t, err)
+	assert.Equal(t, "application/xml", headers.Get("Content-Type"))
+}
+
+func TestMockEngine_Accept_NotAcceptable(t *testing.T) {
+	me := preferTestEngine(t)
+	request, _ := http.NewRequest(http.MethodGet, "https://api.pb33f.io/things", nil)
+	request.Header.Set("Accept", "text/html, application/json;q=0")
+
+	b, status, _, err := me.GenerateResponseWithHeaders(request)
+	assert.NoError(t, err)
+	assert.Equal(t, 406, status)
+
+	var problem map[string]any
+	require.NoError(t, json.Unmarshal(b, &problem))
+	assert.Equal(t, "Not acceptable (406)", problem["title"])
+}
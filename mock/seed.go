@@ -0,0 +1,291 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/lucasjones/reggen"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/pb33f/libopenapi/renderer"
+)
+
+// MockSeedHeader can be sent with a request to render deterministic mock data, it overrides the configured seed.
+const MockSeedHeader = "wiretap-mock-seed"
+
+const (
+	seedMaxDepth     = 50
+	seedPatternLimit = 10
+)
+
+// seedEpoch anchors generated dates and times, using the clock would make them different on every request.
+var seedEpoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// extractSeed returns the seed from the `wiretap-mock-seed` header, or the configured seed if there is one.
+func (rme *ResponseMockEngine) extractSeed(request *http.Request) (int64, bool) {
+	if header := request.Header.Get(MockSeedHeader); header != "" {
+		if seed, err := strconv.ParseInt(header, 10, 64); err == nil {
+			return seed, true
+		}
+	}
+	if rme.seed != nil {
+		return *rme.seed, true
+	}
+	return 0, false
+}
+
+// generateSeededMock renders a deterministic mock from a schema.
+func (rme *ResponseMockEngine) generateSeededMock(schema *base.Schema, seed int64, key string) ([]byte, error) {
+	value, err := rme.renderSeeded(schema, seed, key)
+	if err != nil {
+		return nil, err
+	}
+	if rme.pretty {
+		return json.MarshalIndent(value, "", "  ")
+	}
+	return json.Marshal(value)
+}
+
+// renderSeeded renders a schema with a mock generator seeded from the seed and a key describing what is being
+// rendered (operation, status code and media type), so the same seed always renders the same mock for an operation,
+// across restarts and spec reloads, as long as the schema has not changed. The generator is given no dictionary, so
+// words don't depend on the words installed on the host.
+func (rme *ResponseMockEngine) renderSeeded(schema *base.Schema, seed int64, key string) (any, error) {
+	h := fnv.New64a()
+	_, _ = fmt.Fprintf(h, "%d:%s", seed, key)
+	source := int64(h.Sum64())
+
+	generator := renderer.NewMockGeneratorWithDictionary("", renderer.JSON)
+	if rme.useAllExamples {
+		generator.DisableRequiredCheck()
+	}
+	generator.SetSeed(source)
+	mock, err := generator.GenerateMock(schema, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(mock) == 0 {
+		return nil, fmt.Errorf("unable to render schema for mock, it's empty")
+	}
+
+	var value any
+	decoder := json.NewDecoder(bytes.NewReader(mock))
+	decoder.UseNumber()
+	if err = decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return pinSeededValues(schema, value, rand.New(rand.NewSource(source)), 0), nil
+}
+
+// pinSeededValues re-renders the values the mock generator doesn't draw from its seeded source: dates and times come
+// from the clock, UUIDs and patterns from their own random sources. Numbers the generator rendered outside the bounds
+// of their schema are brought back in. Everything else is left as it was rendered.
+func pinSeededValues(schema *base.Schema, value any, random *rand.Rand, depth int) any {
+	if schema == nil || depth > seedMaxDepth {
+		return value
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		if schema.Properties != nil {
+			for name, proxy := range schema.Properties.FromOldest() {
+				if property, ok := v[name]; ok {
+					v[name] = pinSeededValues(proxy.Schema(), property, random, depth+1)
+				}
+			}
+		}
+	case []any:
+		if schema.Items != nil && schema.Items.IsA() && schema.Items.A != nil {
+			items := schema.Items.A.Schema()
+			for i := range v {
+				v[i] = pinSeededValues(items, v[i], random, depth+1)
+			}
+		}
+	case string:
+		// examples and enums are already fixed, or picked with the seeded source.
+		if schema.Example == nil && len(schema.Examples) == 0 && len(schema.Enum) == 0 {
+			value = pinSeededString(schema, v, random)
+		}
+	case json.Number:
+		if schema.Example == nil && len(schema.Examples) == 0 && len(schema.Enum) == 0 {
+			value = pinSeededNumber(schema, v, random)
+		}
+	}
+
+	// the generator merges composed schemas into the value, or uses the value of the first one that renders.
+	var composed []*base.SchemaProxy
+	composed = append(composed, schema.AllOf...)
+	if len(schema.OneOf) > 0 {
+		composed = append(composed, schema.OneOf[0])
+	}
+	if len(schema.AnyOf) > 0 {
+		composed = append(composed, schema.AnyOf[0])
+	}
+	for _, proxy := range composed {
+		if proxy != nil {
+			value = pinSeededValues(proxy.Schema(), value, random, depth+1)
+		}
+	}
+	return value
+}
+
+func pinSeededString(schema *base.Schema, value string, random *rand.Rand) string {
+	switch schema.Format {
+	case "date-time":
+		return seededTime(random).Format(time.RFC3339)
+	case "date":
+		return seededTime(random).Format(time.DateOnly)
+	case "time":
+		return seededTime(random).Format(time.TimeOnly)
+	case "uuid":
+		return seededUUID(random)
+	case "":
+		if schema.Pattern == "" {
+			return value
+		}
+		generator, err := reggen.NewGenerator(schema.Pattern)
+		if err != nil {
+			return value
+		}
+		generator.SetSeed(random.Int63())
+		limit := seedPatternLimit
+		if schema.MaxLength != nil {
+			limit = int(*schema.MaxLength)
+		}
+		return generator.Generate(limit)
+	}
+	return value
+}
+
+// pinSeededNumber keeps a number inside the bounds of its schema, the generator ignores exclusive bounds and
+// multipleOf. When no multiple fits the bounds, a value within them is used instead.
+func pinSeededNumber(schema *base.Schema, value json.Number, random *rand.Rand) any {
+	b := numberBounds(schema)
+	var step float64
+	if schema.MultipleOf != nil && *schema.MultipleOf > 0 {
+		step = *schema.MultipleOf
+	}
+	if n, err := value.Float64(); err == nil && b.contains(n) && (step == 0 || math.Mod(n, step) == 0) {
+		return value
+	}
+
+	integer := slices.Contains(schema.Type, "integer") || schema.Format == "int32" || schema.Format == "int64"
+	if integer && step != math.Trunc(step) {
+		step = 0
+	}
+	if !integer && step == 0 {
+		n := b.lo + random.Float64()*(b.hi-b.lo)
+		if !b.contains(n) {
+			n = (b.lo + b.hi) / 2
+		}
+		return n
+	}
+	if step == 0 {
+		step = 1
+	}
+
+	// render a multiple of the step, or when none fits, a whole number within the bounds.
+	first, last := math.Ceil(b.lo/step), math.Floor(b.hi/step)
+	if !b.contains(first * step) {
+		first++
+	}
+	if !b.contains(last * step) {
+		last--
+	}
+	if first > last {
+		first, last, step = math.Ceil(b.lo), math.Floor(b.hi), 1
+		if !b.contains(first) {
+			first++
+		}
+		if !b.contains(last) {
+			last--
+		}
+		if first > last {
+			// no whole number fits the bounds either, there is nothing better to render.
+			return value
+		}
+	}
+	// pick how many steps to take. Spans too wide for Int63n are picked in floating point, and kept within bounds.
+	var steps float64
+	if span := last - first; span < math.MaxInt64 {
+		steps = first + float64(random.Int63n(int64(span)+1))
+	} else {
+		r := random.Float64()
+		steps = min(max(math.Floor(first*(1-r)+last*r), first), last)
+	}
+	n := steps * step
+	if integer && n >= math.MinInt64 && n < math.MaxInt64 {
+		return int64(n)
+	}
+	return n
+}
+
+// seedBounds is the range a number must be rendered in. When only one end is declared, the other is 100 away.
+type seedBounds struct {
+	lo, hi                   float64
+	hasLo, hasHi             bool
+	exclusiveLo, exclusiveHi bool
+}
+
+// contains checks a number against the declared ends of the range.
+func (b seedBounds) contains(n float64) bool {
+	return (!b.hasLo || n > b.lo || (!b.exclusiveLo && n == b.lo)) &&
+		(!b.hasHi || n < b.hi || (!b.exclusiveHi && n == b.hi))
+}
+
+// numberBounds returns the bounds of a number schema. Both the OpenAPI 3.0 (boolean) and 3.1 (number) flavours
+// of exclusiveMinimum and exclusiveMaximum are supported.
+func numberBounds(schema *base.Schema) seedBounds {
+	b := seedBounds{lo: 1, hi: 100}
+	if schema.Minimum != nil {
+		b.lo, b.hasLo = *schema.Minimum, true
+	}
+	if schema.Maximum != nil {
+		b.hi, b.hasHi = *schema.Maximum, true
+	}
+	if em := schema.ExclusiveMinimum; em != nil {
+		if em.IsA() {
+			b.exclusiveLo = em.A && b.hasLo
+		} else if !b.hasLo || em.B >= b.lo {
+			b.lo, b.hasLo, b.exclusiveLo = em.B, true, true
+		}
+	}
+	if em := schema.ExclusiveMaximum; em != nil {
+		if em.IsA() {
+			b.exclusiveHi = em.A && b.hasHi
+		} else if !b.hasHi || em.B <= b.hi {
+			b.hi, b.hasHi, b.exclusiveHi = em.B, true, true
+		}
+	}
+	if b.hasLo && !b.hasHi {
+		b.hi = b.lo + 100
+	}
+	if b.hasHi && !b.hasLo {
+		b.lo = b.hi - 100
+	}
+	return b
+}
+
+func seededTime(random *rand.Rand) time.Time {
+	return seedEpoch.Add(time.Duration(random.Int63n(365*24*60*60)) * time.Second)
+}
+
+// seededUUID renders a version 4 UUID from the seeded random source.
+func seededUUID(random *rand.Rand) string {
+	b := make([]byte, 16)
+	for i := range b {
+		b[i] = byte(random.Intn(256))
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,238 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package mock
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var seededSpec = `openapi: 3.1.0
+paths:
+  /users/{id}:
+    get:
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: string
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+  /examples:
+    get:
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+              example:
+                id: from-example
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        id:
+          type: string
+          format: uuid
+        email:
+          type: string
+          format: email
+          minLength: 30
+        created:
+          type: string
+          format: date-time
+        code:
+          type: string
+          pattern: '^[A-Z]{3}-[0-9]{4}$'
+        nickname:
+          type: string
+          minLength: 12
+          maxLength: 14
+        age:
+          type: integer
+          minimum: 18
+          maximum: 21
+        score:
+          type: number
+          minimum: 0
+          exclusiveMaximum: 1
+        even:
+          type: integer
+          minimum: 1
+          maximum: 9
+          multipleOf: 2
+        level:
+          type: integer
+          minimum: 5
+          maximum: 7
+          multipleOf: 10
+        tags:
+          type: array
+          minItems: 2
+          maxItems: 4
+          items:
+            type: string
+`
+
+func seededTestEngine(t *testing.T) *ResponseMockEngine {
+	d, err := libopenapi.NewDocument([]byte(seededSpec))
+	require.NoError(t, err)
+	doc, err := d.BuildV3Model()
+	require.NoError(t, err)
+	return NewMockEngine(&doc.Model, false, true)
+}
+
+func generateSeeded(t *testing.T, me *ResponseMockEngine, path, seed string) []byte {
+	request, _ := http.NewRequest(http.MethodGet, "https://api.pb33f.io"+path, nil)
+	if seed != "" {
+		request.Header.Set(MockSeedHeader, seed)
+	}
+	b, status, err := me.GenerateResponse(request)
+	require.NoError(t, err)
+	require.Equal(t, 200, status)
+	return b
+}
+
+func TestMockEngine_Seed_Deterministic(t *testing.T) {
+	me := seededTestEngine(t)
+	me.SetSeed(42)
+
+	first := generateSeeded(t, me, "/users/1", "")
+	assert.Equal(t, first, generateSeeded(t, me, "/users/1", ""))
+
+	// the same operation renders the same mock, whatever the path parameters are.
+	assert.Equal(t, first, generateSeeded(t, me, "/users/2", ""))
+
+	// a new engine (a restart or spec reload) renders the same mock too.
+	reloaded := seededTestEngine(t)
+	reloaded.SetSeed(42)
+	assert.Equal(t, first, generateSeeded(t, reloaded, "/users/1", ""))
+}
+
+func TestMockEngine_Seed_HeaderOverridesConfig(t *testing.T) {
+	me := seededTestEngine(t)
+	me.SetSeed(42)
+
+	configured := generateSeeded(t, me, "/users/1", "")
+	fromHeader := generateSeeded(t, me, "/users/1", "7")
+	assert.NotEqual(t, configured, fromHeader)
+	assert.Equal(t, fromHeader, generateSeeded(t, seededTestEngine(t), "/users/1", "7"))
+}
+
+func TestMockEngine_Seed_RespectsFormatsAndConstraints(t *testing.T) {
+	me := seededTestEngine(t)
+	for _, seed := range []string{"1", "2", "3", "4", "5"} {
+		var user map[string]any
+		require.NoError(t, json.Unmarshal(generateSeeded(t, me, "/users/1", seed), &user))
+
+		_, err := uuid.Parse(user["id"].(string))
+		assert.NoError(t, err)
+
+		email := user["email"].(string)
+		assert.Regexp(t, `^[A-Za-z]+@[A-Za-z]+\.com$`, email)
+		assert.GreaterOrEqual(t, len(email), 30)
+
+		_, err = time.Parse(time.RFC3339, user["created"].(string))
+		assert.NoError(t, err)
+
+		assert.Regexp(t, regexp.MustCompile(`^[A-Z]{3}-[0-9]{4}$`), user["code"])
+
+		nickname := user["nickname"].(string)
+		assert.GreaterOrEqual(t, len(nickname), 12)
+		assert.LessOrEqual(t, len(nickname), 14)
+
+		age := user["age"].(float64)
+		assert.GreaterOrEqual(t, age, 18.0)
+		assert.LessOrEqual(t, age, 21.0)
+
+		score := user["score"].(float64)
+		assert.GreaterOrEqual(t, score, 0.0)
+		assert.Less(t, score, 1.0)
+
+		even := user["even"].(float64)
+		assert.GreaterOrEqual(t, even, 2.0)
+		assert.LessOrEqual(t, even, 8.0)
+		assert.Zero(t, int(even)%2)
+
+		// no multiple of 10 fits, so a value within the bounds is used.
+		level := user["level"].(float64)
+		assert.GreaterOrEqual(t, level, 5.0)
+		assert.LessOrEqual(t, level, 7.0)
+
+		tags := user["tags"].([]any)
+		assert.GreaterOrEqual(t, len(tags), 2)
+		assert.LessOrEqual(t, len(tags), 4)
+	}
+}
+
+func TestMockEngine_Seed_ExamplesStillWin(t *testing.T) {
+	me := seededTestEngine(t)
+	me.SetSeed(42)
+	assert.JSONEq(t, `{"id":"from-example"}`, string(generateSeeded(t, me, "/examples", "")))
+}
+
+func TestMockEngine_Seed_InvalidHeaderIgnored(t *testing.T) {
+	me := seededTestEngine(t)
+	request, _ := http.NewRequest(http.MethodGet, "https://api.pb33f.io/users/1", nil)
+	request.Header.Set(MockSeedHeader, "not-a-number")
+
+	_, seeded := me.extractSeed(request)
+	assert.False(t, seeded)
+}
+
+func TestPinSeededNumber_HugeRanges(t *testing.T) {
+	bound := func(n float64) *float64 { return &n }
+	for name, test := range map[string]struct {
+		schema  *base.Schema
+		integer bool
+	}{
+		"integers wider than int63": {
+			schema:  &base.Schema{Type: []string{"integer"}, Minimum: bound(-9e18), Maximum: bound(9e18)},
+			integer: true,
+		},
+		"integers wider than int64": {
+			schema: &base.Schema{Type: []string{"integer"}, Minimum: bound(-1e30), Maximum: bound(1e30)},
+		},
+		"whole numbers as wide as float64": {
+			schema: &base.Schema{Type: []string{"number"}, Minimum: bound(-math.MaxFloat64), Maximum: bound(math.MaxFloat64),
+				MultipleOf: bound(1)},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			random := rand.New(rand.NewSource(1))
+			for range 100 {
+				var n float64
+				switch v := pinSeededNumber(test.schema, "-1e309", random).(type) {
+				case int64:
+					require.True(t, test.integer, "%d is not a float", v)
+					n = float64(v)
+				case float64:
+					require.False(t, test.integer, "%v is not an integer", v)
+					n = v
+				}
+				assert.GreaterOrEqual(t, n, *test.schema.Minimum)
+				assert.LessOrEqual(t, n, *test.schema.Maximum)
+				assert.Equal(t, math.Trunc(n), n)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/dop251/goja"
+	"github.com/pb33f/wiretap/jsruntime"
+)
+
+// javaScript runs the entry points of a JavaScript plugin in pooled runtimes.
+type javaScript struct {
+	runtimes *jsruntime.Pool
+}
+
+func loadJavaScript(path string, source []byte) (*javaScript, map[string]bool, error) {
+	program, err := goja.Compile(path, string(source), true)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to compile plugin '%s': %w", path, err)
+	}
+	j := &javaScript{runtimes: jsruntime.NewPool("plugin", program)}
+	vm, err := j.runtimes.Get(Timeout)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to run plugin '%s': %w", path, err)
+	}
+	entries := make(map[string]bool)
+	for _, entry := range EntryPoints {
+		if _, ok := goja.AssertFunction(vm.Get(entry)); ok {
+			entries[entry] = true
+		}
+	}
+	j.runtimes.Put(vm)
+	return j, entries, nil
+}
+
+func (j *javaScript) run(entry string, input []byte) ([]byte, error) {
+	var value any
+	if err := json.Unmarshal(input, &value); err != nil {
+		return nil, err
+	}
+
+	vm, err := j.runtimes.Get(Timeout)
+	if err != nil {
+		return nil, err
+	}
+	fn, _ := goja.AssertFunction(vm.Get(entry))
+	var result goja.Value
+	err = j.runtimes.Run(vm, Timeout, func() error {
+		var callErr error
+		result, callErr = fn(goja.Undefined(), vm.ToValue(value))
+		return callErr
+	})
+	var returned any
+	if err == nil && result != nil && !goja.IsUndefined(result) && !goja.IsNull(result) {
+		returned = result.Export()
+	}
+	j.runtimes.Put(vm)
+	if err != nil || returned == nil {
+		return nil, err
+	}
+
+	encoded, err := json.Marshal(returned)
+	if err != nil {
+		return nil, fmt.Errorf("%s returned an invalid result: %w", entry, err)
+	}
+	return encoded, nil
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+// Package plugin loads custom validators and transformers, for rules that can't be expressed in OpenAPI. Plugins
+// speak a JSON ABI: each entry point is called with an Input, the request (and response) of a transaction and the id
+// of the operation it matched, and returns violations, or the message to use in place of the one it was given.
+//
+// The entry points, all optional, are validateRequest and validateResponse, returning a list of Violation, and
+// transformRequest and transformResponse, returning a Message, or nothing to leave the message unchanged.
+//
+// Plugins are either JavaScript files, run in an embedded pure-Go JavaScript runtime, that define the entry points as
+// functions, or WebAssembly modules, run in the pure-Go interpreter of the wasm package, that export them.
+//
+// WebAssembly plugins are reactors, built as libraries (like with -buildmode=c-shared for Go), exporting their memory
+// as memory and an alloc(size i32) i32 function. Each entry point they export is an (input i32, length i32) i64
+// function: wiretap allocates the input with alloc, writes its JSON there, calls the entry point, and reads back the
+// JSON at the pointer in the high 32 bits of the result, of the length in the low 32 bits, or nothing for 0. When the
+// plugin also exports a free(pointer i32, length i32) function, wiretap frees the input and result with it once it is
+// done with them. Plugins may import the functions of WASI preview 1 that compilers need to set up their runtime, but
+// no files or network: what they write to stdout and stderr goes to the stderr of wiretap, and other calls fail.
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Entry points plugins may define.
+const (
+	ValidateRequest   = "validateRequest"
+	ValidateResponse  = "validateResponse"
+	TransformRequest  = "transformRequest"
+	TransformResponse = "transformResponse"
+)
+
+// EntryPoints are the entry points plugins may define.
+var EntryPoints = []string{ValidateRequest, ValidateResponse, TransformRequest, TransformResponse}
+
+// Timeout is how long an entry point of a plugin may run before it is interrupted.
+var Timeout = time.Second
+
+// Message is a request or response passed to and returned by plugins. Requests have a method, path and query,
+// responses a status. Headers hold every value sent for a name.
+type Message struct {
+	Method  string              `json:"method,omitempty"`
+	Path    string              `json:"path,omitempty"`
+	Query   string              `json:"query,omitempty"`
+	Status  int                 `json:"status,omitempty"`
+	Headers map[string][]string `json:"headers"`
+	Body    string              `json:"body"`
+}
+
+// Input is what an entry point is called with. Response is only set for the response entry points.
+type Input struct {
+	OperationID string   `json:"operationId,omitempty"`
+	Request     *Message `json:"request"`
+	Response    *Message `json:"response,omitempty"`
+}
+
+// Violation is a rule a transaction broke, returned by the validate entry points.
+type Violation struct {
+	Message  string `json:"message"`
+	Reason   string `json:"reason,omitempty"`
+	HowToFix string `json:"howToFix,omitempty"`
+}
+
+// Plugin is a loaded plugin. Plugins are safe to call concurrently.
+type Plugin struct {
+	Name    string
+	entries map[string]bool
+	runner  runner
+}
+
+// runner runs the entry points of a plugin, passing them their input and taking back what they return as JSON. It
+// returns nil when an entry point returns nothing.
+type runner interface {
+	run(entry string, input []byte) ([]byte, error)
+}
+
+// Load loads the plugin at a path, returning an error when it can't be loaded or defines none of the entry points.
+func Load(path string) (*Plugin, error) {
+	extension := strings.ToLower(filepath.Ext(path))
+	if extension != ".js" && extension != ".wasm" {
+		return nil, fmt.Errorf("unable to load plugin '%s': plugins must be JavaScript (.js) or WebAssembly (.wasm) files", path)
+	}
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read plugin '%s': %w", path, err)
+	}
+	p := &Plugin{Name: filepath.Base(path)}
+	if extension == ".js" {
+		p.runner, p.entries, err = loadJavaScript(path, source)
+	} else {
+		p.runner, p.entries, err = loadWebAssembly(path, source)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(p.entries) == 0 {
+		return nil, fmt.Errorf("plugin '%s' defines none of the functions %s", path, strings.Join(EntryPoints, ", "))
+	}
+	return p, nil
+}
+
+// Has returns true when the plugin defines an entry point.
+func (p *Plugin) Has(entry string) bool {
+	return p != nil && p.entries[entry]
+}
+
+// Validate calls a validate entry point, returning the violations of the transaction.
+func (p *Plugin) Validate(entry string, input *Input) ([]*Violation, error) {
+	var violations []*Violation
+	if err := p.call(entry, input, &violations); err != nil {
+		return nil, err
+	}
+	return violations, nil
+}
+
+// Transform calls a transform entry point, returning the message to use in place of the one given, or nil to leave
+// it unchanged.
+func (p *Plugin) Transform(entry string, input *Input) (*Message, error) {
+	var message *Message
+	if err := p.call(entry, input, &message); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// call passes the input to an entry point as JSON, and decodes what it returns into output.
+func (p *Plugin) call(entry string, input *Input, output any) error {
+	if !p.Has(entry) {
+		return nil
+	}
+	for _, message := range []*Message{input.Request, input.Response} {
+		if message != nil && message.Headers == nil {
+			message.Headers = map[string][]string{}
+		}
+	}
+	encoded, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	returned, err := p.runner.run(entry, encoded)
+	if err != nil || returned == nil {
+		return err
+	}
+	if err = json.Unmarshal(returned, output); err != nil {
+		return fmt.Errorf("%s returned an invalid result: %w", entry, err)
+	}
+	return nil
+}
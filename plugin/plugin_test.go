@@ -0,0 +1,258 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package plugin
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePlugin(t *testing.T, name, source string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(source), 0o600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	p, err := Load(writePlugin(t, "rules.js", `function validateRequest(input) { return []; }`))
+	require.NoError(t, err)
+	assert.Equal(t, "rules.js", p.Name)
+	assert.True(t, p.Has(ValidateRequest))
+	assert.False(t, p.Has(TransformResponse))
+
+	for name, message := range map[string]string{
+		"rules.wasm": "unable to compile plugin",
+		"rules.lua":  "plugins must be JavaScript (.js) or WebAssembly (.wasm) files",
+		"broken.js":  "unable to compile plugin",
+		"empty.js":   "defines none of the functions validateRequest, validateResponse",
+	} {
+		source := `function somethingElse() {}`
+		if name == "broken.js" {
+			source = `function validateRequest(input) {`
+		}
+		_, err = Load(writePlugin(t, name, source))
+		require.Error(t, err, name)
+		assert.Contains(t, err.Error(), message)
+	}
+}
+
+func TestPlugin_Validate(t *testing.T) {
+	p, err := Load(writePlugin(t, "correlation.js", `
+function validateRequest(input) {
+	if (!input.request.headers["X-Correlation-Id"]) {
+		return [{message: "missing correlation id", reason: input.operationId + " needs one", howToFix: "send one"}];
+	}
+}
+function validateResponse(input) {
+	if (input.response.body.indexOf("ssn") >= 0) {
+		return [{message: "response contains PII"}];
+	}
+	return [];
+}`))
+	require.NoError(t, err)
+
+	input := &Input{OperationID: "listPets", Request: &Message{Method: "GET", Path: "/pets"}}
+	violations, err := p.Validate(ValidateRequest, input)
+	require.NoError(t, err)
+	assert.Equal(t, []*Violation{{
+		Message:  "missing correlation id",
+		Reason:   "listPets needs one",
+		HowToFix: "send one",
+	}}, violations)
+
+	input.Request.Headers = map[string][]string{"X-Correlation-Id": {"42"}}
+	violations, err = p.Validate(ValidateRequest, input)
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+
+	input.Response = &Message{Status: 200, Body: `{"ssn":"123"}`}
+	violations, err = p.Validate(ValidateResponse, input)
+	require.NoError(t, err)
+	assert.Equal(t, []*Violation{{Message: "response contains PII"}}, violations)
+}
+
+func TestPlugin_Transform(t *testing.T) {
+	p, err := Load(writePlugin(t, "tag.js", `
+function transformResponse(input) {
+	if (input.response.status !== 200) {
+		return;
+	}
+	var response = input.response;
+	response.headers["X-Plugin"] = ["tag"];
+	response.body = response.body.toUpperCase();
+	return response;
+}`))
+	require.NoError(t, err)
+
+	transformed, err := p.Transform(TransformResponse, &Input{
+		Request:  &Message{Method: "GET", Path: "/pets"},
+		Response: &Message{Status: 200, Body: "ok"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &Message{Status: 200, Headers: map[string][]string{"X-Plugin": {"tag"}}, Body: "OK"}, transformed)
+
+	transformed, err = p.Transform(TransformResponse, &Input{
+		Request:  &Message{Method: "GET", Path: "/pets"},
+		Response: &Message{Status: 404},
+	})
+	require.NoError(t, err)
+	assert.Nil(t, transformed)
+}
+
+func TestPlugin_Failures(t *testing.T) {
+	defer func(timeout time.Duration) { Timeout = timeout }(Timeout)
+	Timeout = 50 * time.Millisecond
+
+	p, err := Load(writePlugin(t, "failing.js", `
+function validateRequest(input) {
+	if (input.request.path === "/spin") { for (;;) {} }
+	if (input.request.path === "/throw") { throw new Error("no rules"); }
+	return "not a list";
+}`))
+	require.NoError(t, err)
+
+	_, err = p.Validate(ValidateRequest, &Input{Request: &Message{Path: "/spin"}})
+	assert.EqualError(t, err, "plugin timed out after 50ms")
+	_, err = p.Validate(ValidateRequest, &Input{Request: &Message{Path: "/throw"}})
+	assert.EqualError(t, err, "Error: no rules")
+	_, err = p.Validate(ValidateRequest, &Input{Request: &Message{Path: "/pets"}})
+	assert.ErrorContains(t, err, "validateRequest returned an invalid result")
+}
+
+// leb encodes a signed LEB128 integer, which encodes unsigned ones too when they are small enough.
+func leb(n int64) []byte {
+	var b []byte
+	for {
+		c := byte(n & 0x7f)
+		n >>= 7
+		if (n == 0 && c&0x40 == 0) || (n == -1 && c&0x40 != 0) {
+			return append(b, c)
+		}
+		b = append(b, c|0x80)
+	}
+}
+
+func vec(items ...[]byte) []byte {
+	return append(leb(int64(len(items))), bytes.Join(items, nil)...)
+}
+
+func section(id byte, items ...[]byte) []byte {
+	contents := vec(items...)
+	return append(append([]byte{id}, leb(int64(len(contents)))...), contents...)
+}
+
+func str(s string) []byte {
+	return append(leb(int64(len(s))), s...)
+}
+
+// body encodes a function body without locals.
+func body(code ...byte) []byte {
+	contents := append(append([]byte{0}, code...), 0x0b)
+	return append(leb(int64(len(contents))), contents...)
+}
+
+func export(name string, kind, index byte) []byte {
+	return append(str(name), kind, index)
+}
+
+func wasmModule(sections ...[]byte) string {
+	return "\x00asm\x01\x00\x00\x00" + string(bytes.Join(sections, nil))
+}
+
+var (
+	allocSignature = []byte{0x60, 1, 0x7f, 1, 0x7f}
+	entrySignature = []byte{0x60, 2, 0x7f, 0x7f, 1, 0x7e}
+	violations     = `[{"message":"missing correlation id","reason":"from wasm"}]`
+)
+
+// webAssemblyRules is a plugin with a bump allocator, a validateRequest returning violations it keeps at 16 in its
+// memory, a transformRequest returning the request of its input, a validateResponse that never returns and a
+// transformResponse that traps.
+var webAssemblyRules = wasmModule(
+	section(1, allocSignature, entrySignature),
+	section(3, []byte{0}, []byte{1}, []byte{1}, []byte{1}, []byte{1}),
+	section(5, []byte{0, 1}),
+	section(6, append([]byte{0x7f, 1, 0x41}, append(leb(1024), 0x0b)...)),
+	section(7,
+		export("memory", 2, 0), export("alloc", 0, 0), export("validateRequest", 0, 1),
+		export("transformRequest", 0, 2), export("validateResponse", 0, 3), export("transformResponse", 0, 4)),
+	section(10,
+		// global.get 0, global.get 0, local.get 0, i32.add, global.set 0
+		body(0x23, 0, 0x23, 0, 0x20, 0, 0x6a, 0x24, 0),
+		// i64.const 16<<32 | length
+		body(append([]byte{0x42}, leb(16<<32|int64(len(violations)))...)...),
+		// the request starts 11 bytes in, after {"request":, and ends a byte before the input does:
+		// (input + 11) << 32 | (length - 12)
+		body(0x20, 0, 0xad, 0x42, 11, 0x7c, 0x42, 32, 0x86, 0x20, 1, 0x41, 12, 0x6b, 0xad, 0x84),
+		// loop, br 0, end, unreachable
+		body(0x03, 0x40, 0x0c, 0, 0x0b, 0x00),
+		body(0x00),
+	),
+	section(11, append([]byte{0, 0x41, 16, 0x0b}, str(violations)...)),
+)
+
+func TestLoad_WebAssembly(t *testing.T) {
+	p, err := Load(writePlugin(t, "rules.wasm", webAssemblyRules))
+	require.NoError(t, err)
+	assert.Equal(t, "rules.wasm", p.Name)
+	for _, entry := range EntryPoints {
+		assert.True(t, p.Has(entry), entry)
+	}
+
+	for message, source := range map[string]string{
+		"must export its memory as 'memory'": wasmModule(
+			section(1, allocSignature), section(3, []byte{0}), section(7, export("alloc", 0, 0)),
+			section(10, body(0x41, 0))),
+		"must export an alloc function of type (i32) -> (i32)": wasmModule(
+			section(5, []byte{0, 1}), section(7, export("memory", 2, 0))),
+		"exports validateRequest as (i32) -> (i32), entry points must be (i32, i32) -> (i64)": wasmModule(
+			section(1, allocSignature), section(3, []byte{0}), section(5, []byte{0, 1}),
+			section(7, export("memory", 2, 0), export("alloc", 0, 0), export("validateRequest", 0, 0)),
+			section(10, body(0x41, 0))),
+		"it imports env.log, plugins may only import functions of wasi_snapshot_preview1": wasmModule(
+			section(1, []byte{0x60, 0, 0}), section(2, append(append(str("env"), str("log")...), 0, 0))),
+		"defines none of the functions": wasmModule(
+			section(1, allocSignature), section(3, []byte{0}), section(5, []byte{0, 1}),
+			section(7, export("memory", 2, 0), export("alloc", 0, 0)),
+			section(10, body(0x41, 0))),
+	} {
+		_, err = Load(writePlugin(t, "rules.wasm", source))
+		require.Error(t, err, message)
+		assert.Contains(t, err.Error(), message)
+	}
+}
+
+func TestPlugin_WebAssembly(t *testing.T) {
+	defer func(timeout time.Duration) { Timeout = timeout }(Timeout)
+	Timeout = 50 * time.Millisecond
+
+	p, err := Load(writePlugin(t, "rules.wasm", webAssemblyRules))
+	require.NoError(t, err)
+
+	input := &Input{Request: &Message{Method: "POST", Path: "/pets", Body: `{"name":"fido"}`}}
+	found, err := p.Validate(ValidateRequest, input)
+	require.NoError(t, err)
+	assert.Equal(t, []*Violation{{Message: "missing correlation id", Reason: "from wasm"}}, found)
+
+	transformed, err := p.Transform(TransformRequest, input)
+	require.NoError(t, err)
+	assert.Equal(t, input.Request, transformed)
+
+	input.Response = &Message{Status: 200}
+	_, err = p.Validate(ValidateResponse, input)
+	assert.EqualError(t, err, "plugin timed out after 50ms")
+	_, err = p.Transform(TransformResponse, input)
+	assert.EqualError(t, err, "wasm trap: unreachable")
+
+	// failed calls drop their instance, the next call gets a new one.
+	found, err = p.Validate(ValidateRequest, input)
+	require.NoError(t, err)
+	assert.Len(t, found, 1)
+}
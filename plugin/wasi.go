@@ -0,0 +1,210 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package plugin
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pb33f/wiretap/wasm"
+)
+
+const wasiModule = "wasi_snapshot_preview1"
+
+// WASI error numbers.
+const (
+	errnoSuccess = 0
+	errnoBadF    = 8
+	errnoFault   = 21
+	errnoInval   = 28
+	errnoNoSys   = 52
+)
+
+var started = time.Now()
+
+// wasi are the functions of WASI preview 1 plugins can call. They give plugins the clock, randomness and stdout and
+// stderr, which is what compilers need to set up their runtime, and nothing else: no arguments, environment or files.
+var wasi = map[string]wasm.HostFunction{
+	"args_sizes_get":    wasiFunc(2, noneSizes),
+	"args_get":          wasiFunc(2, success),
+	"environ_sizes_get": wasiFunc(2, noneSizes),
+	"environ_get":       wasiFunc(2, success),
+	"sched_yield":       wasiFunc(0, success),
+	"clock_res_get":     wasiFunc(2, clockResolution),
+	"clock_time_get": {
+		Type: wasm.FuncType{Params: []wasm.ValueType{wasm.I32, wasm.I64, wasm.I32}, Results: []wasm.ValueType{wasm.I32}},
+		Call: clockTime,
+	},
+	"random_get":          wasiFunc(2, random),
+	"fd_write":            wasiFunc(4, write),
+	"fd_fdstat_get":       wasiFunc(2, fdstat),
+	"fd_fdstat_set_flags": wasiFunc(2, standardFd),
+	"fd_prestat_get":      wasiFunc(2, noPreopens),
+	"fd_prestat_dir_name": wasiFunc(3, noPreopens),
+	"proc_exit": {
+		Type: wasm.FuncType{Params: []wasm.ValueType{wasm.I32}},
+		Call: func(_ *wasm.Instance, args []uint64) ([]uint64, error) {
+			return nil, fmt.Errorf("plugin exited with code %d", int32(args[0]))
+		},
+	},
+}
+
+// wasiImports returns the host functions a module imports. Modules may import any function of WASI preview 1, those
+// that aren't implemented fail with ENOSYS, and nothing else.
+func wasiImports(module *wasm.Module) (wasm.Imports, error) {
+	functions := make(map[string]wasm.HostFunction)
+	for _, imported := range module.Imports() {
+		if imported.Module != wasiModule {
+			return nil, fmt.Errorf("it imports %s.%s, plugins may only import functions of %s",
+				imported.Module, imported.Name, wasiModule)
+		}
+		if f, ok := wasi[imported.Name]; ok {
+			functions[imported.Name] = f
+			continue
+		}
+		if len(imported.Type.Results) != 1 || imported.Type.Results[0] != wasm.I32 {
+			return nil, fmt.Errorf("it imports %s.%s of type %s, which is not a function of %s",
+				imported.Module, imported.Name, imported.Type, wasiModule)
+		}
+		functions[imported.Name] = wasm.HostFunction{
+			Type: imported.Type,
+			Call: func(*wasm.Instance, []uint64) ([]uint64, error) {
+				return []uint64{errnoNoSys}, nil
+			},
+		}
+	}
+	return wasm.Imports{wasiModule: functions}, nil
+}
+
+// wasiFunc returns a WASI function taking i32 parameters and returning an error number.
+func wasiFunc(params int, call func(memory []byte, args []uint64) uint64) wasm.HostFunction {
+	typ := wasm.FuncType{Params: make([]wasm.ValueType, params), Results: []wasm.ValueType{wasm.I32}}
+	for i := range typ.Params {
+		typ.Params[i] = wasm.I32
+	}
+	return wasm.HostFunction{
+		Type: typ,
+		Call: func(instance *wasm.Instance, args []uint64) ([]uint64, error) {
+			return []uint64{call(instance.Memory(), args)}, nil
+		},
+	}
+}
+
+// span returns length bytes of memory at a pointer, or false when they are outside of it.
+func span(memory []byte, pointer, length uint64) ([]byte, bool) {
+	if pointer+length > uint64(len(memory)) {
+		return nil, false
+	}
+	return memory[pointer : pointer+length], true
+}
+
+func success([]byte, []uint64) uint64 {
+	return errnoSuccess
+}
+
+func noneSizes(memory []byte, args []uint64) uint64 {
+	count, ok := span(memory, args[0], 4)
+	size, ok2 := span(memory, args[1], 4)
+	if !ok || !ok2 {
+		return errnoFault
+	}
+	clear(count)
+	clear(size)
+	return errnoSuccess
+}
+
+func clockResolution(memory []byte, args []uint64) uint64 {
+	if args[0] > 3 {
+		return errnoInval
+	}
+	resolution, ok := span(memory, args[1], 8)
+	if !ok {
+		return errnoFault
+	}
+	binary.LittleEndian.PutUint64(resolution, 1)
+	return errnoSuccess
+}
+
+func clockTime(instance *wasm.Instance, args []uint64) ([]uint64, error) {
+	var now uint64
+	switch args[0] {
+	case 0:
+		now = uint64(time.Now().UnixNano())
+	case 1, 2, 3:
+		// monotonic, and the process and thread CPU time clocks, that plugins get as monotonic time.
+		now = uint64(time.Since(started).Nanoseconds())
+	default:
+		return []uint64{errnoInval}, nil
+	}
+	timestamp, ok := span(instance.Memory(), args[2], 8)
+	if !ok {
+		return []uint64{errnoFault}, nil
+	}
+	binary.LittleEndian.PutUint64(timestamp, now)
+	return []uint64{errnoSuccess}, nil
+}
+
+func random(memory []byte, args []uint64) uint64 {
+	buffer, ok := span(memory, args[0], args[1])
+	if !ok {
+		return errnoFault
+	}
+	_, _ = rand.Read(buffer)
+	return errnoSuccess
+}
+
+// write writes what plugins write to stdout or stderr to the stderr of wiretap.
+func write(memory []byte, args []uint64) uint64 {
+	if args[0] != 1 && args[0] != 2 {
+		return errnoBadF
+	}
+	vectors, ok := span(memory, args[1], 8*args[2])
+	written, ok2 := span(memory, args[3], 4)
+	if !ok || !ok2 {
+		return errnoFault
+	}
+	total := uint32(0)
+	for i := 0; i < len(vectors); i += 8 {
+		data, ok := span(memory, uint64(binary.LittleEndian.Uint32(vectors[i:])),
+			uint64(binary.LittleEndian.Uint32(vectors[i+4:])))
+		if !ok {
+			return errnoFault
+		}
+		n, _ := os.Stderr.Write(data)
+		total += uint32(n)
+	}
+	binary.LittleEndian.PutUint32(written, total)
+	return errnoSuccess
+}
+
+// fdstat describes stdin, stdout and stderr as character devices.
+func fdstat(memory []byte, args []uint64) uint64 {
+	if args[0] > 2 {
+		return errnoBadF
+	}
+	stat, ok := span(memory, args[1], 24)
+	if !ok {
+		return errnoFault
+	}
+	clear(stat)
+	stat[0] = 2
+	binary.LittleEndian.PutUint64(stat[8:], ^uint64(0))
+	return errnoSuccess
+}
+
+// standardFd succeeds for stdin, stdout and stderr, and fails for any other file descriptor.
+func standardFd(_ []byte, args []uint64) uint64 {
+	if args[0] > 2 {
+		return errnoBadF
+	}
+	return errnoSuccess
+}
+
+// noPreopens fails for every file descriptor: plugins have no directories opened for them.
+func noPreopens([]byte, []uint64) uint64 {
+	return errnoBadF
+}
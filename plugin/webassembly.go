@@ -0,0 +1,164 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pb33f/wiretap/wasm"
+)
+
+var (
+	entryType = wasm.FuncType{Params: []wasm.ValueType{wasm.I32, wasm.I32}, Results: []wasm.ValueType{wasm.I64}}
+	allocType = wasm.FuncType{Params: []wasm.ValueType{wasm.I32}, Results: []wasm.ValueType{wasm.I32}}
+	freeType  = wasm.FuncType{Params: []wasm.ValueType{wasm.I32, wasm.I32}}
+)
+
+// webAssembly runs the entry points of a WebAssembly plugin. Instances run one call at a time, they are pooled, and
+// dropped when a call fails: whatever state they were left in can't be trusted.
+type webAssembly struct {
+	module    *wasm.Module
+	imports   wasm.Imports
+	free      bool
+	instances sync.Pool
+}
+
+func loadWebAssembly(path string, source []byte) (*webAssembly, map[string]bool, error) {
+	module, err := wasm.Compile(source)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to compile plugin '%s': %w", path, err)
+	}
+	imports, err := wasiImports(module)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to load plugin '%s': %w", path, err)
+	}
+	if !module.ExportedMemory("memory") {
+		return nil, nil, fmt.Errorf("plugin '%s' must export its memory as 'memory'", path)
+	}
+	if typ, ok := module.ExportedFunction("alloc"); !ok || !typ.Equal(allocType) {
+		return nil, nil, fmt.Errorf("plugin '%s' must export an alloc function of type %s", path, allocType)
+	}
+	w := &webAssembly{module: module, imports: imports}
+	if typ, ok := module.ExportedFunction("free"); ok {
+		if !typ.Equal(freeType) {
+			return nil, nil, fmt.Errorf("plugin '%s' exports free as %s, it must be %s", path, typ, freeType)
+		}
+		w.free = true
+	}
+	entries := make(map[string]bool)
+	for _, entry := range EntryPoints {
+		typ, ok := module.ExportedFunction(entry)
+		if !ok {
+			continue
+		}
+		if !typ.Equal(entryType) {
+			return nil, nil, fmt.Errorf("plugin '%s' exports %s as %s, entry points must be %s", path, entry, typ, entryType)
+		}
+		entries[entry] = true
+	}
+
+	instance, err := w.instantiate()
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to run plugin '%s': %w", path, err)
+	}
+	w.instances.Put(instance)
+	return w, entries, nil
+}
+
+// instantiate creates an instance of the module, initializing the runtime of reactors.
+func (w *webAssembly) instantiate() (*wasm.Instance, error) {
+	instance, err := w.module.Instantiate(w.imports)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := w.module.ExportedFunction("_initialize"); ok {
+		err = guard(instance, func() error {
+			_, err := instance.Call("_initialize")
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return instance, nil
+}
+
+func (w *webAssembly) run(entry string, input []byte) ([]byte, error) {
+	instance, _ := w.instances.Get().(*wasm.Instance)
+	if instance == nil {
+		var err error
+		if instance, err = w.instantiate(); err != nil {
+			return nil, err
+		}
+	}
+	var output []byte
+	err := guard(instance, func() error {
+		var callErr error
+		output, callErr = w.exchange(instance, entry, input)
+		return callErr
+	})
+	if err != nil {
+		return nil, err
+	}
+	w.instances.Put(instance)
+	return output, nil
+}
+
+// exchange writes the input to the memory of the instance, calls an entry point with it, and reads back its output.
+func (w *webAssembly) exchange(instance *wasm.Instance, entry string, input []byte) ([]byte, error) {
+	results, err := instance.Call("alloc", uint64(len(input)))
+	if err != nil {
+		return nil, err
+	}
+	pointer, length := results[0], uint64(len(input))
+	memory := instance.Memory()
+	if pointer+length > uint64(len(memory)) {
+		return nil, errors.New("alloc returned memory outside of the memory of the plugin")
+	}
+	copy(memory[pointer:], input)
+	if results, err = instance.Call(entry, pointer, length); err != nil {
+		return nil, err
+	}
+	if err = w.release(instance, pointer, length); err != nil {
+		return nil, err
+	}
+	if results[0] == 0 {
+		return nil, nil
+	}
+
+	pointer, length = results[0]>>32, results[0]&0xffffffff
+	memory = instance.Memory()
+	if pointer+length > uint64(len(memory)) {
+		return nil, fmt.Errorf("%s returned an invalid result: memory outside of the memory of the plugin", entry)
+	}
+	output := slices.Clone(memory[pointer : pointer+length])
+	if err = w.release(instance, pointer, length); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+// release frees memory of the instance, when the plugin exports free.
+func (w *webAssembly) release(instance *wasm.Instance, pointer, length uint64) error {
+	if !w.free {
+		return nil
+	}
+	_, err := instance.Call("free", pointer, length)
+	return err
+}
+
+// guard runs calls on an instance, interrupting them when they run for longer than Timeout.
+func guard(instance *wasm.Instance, run func() error) error {
+	timer := time.AfterFunc(Timeout, instance.Interrupt)
+	err := run()
+	// when the timer has fired, the interrupt may still land on a later call: the call timed out either way.
+	if !timer.Stop() || errors.Is(err, wasm.ErrInterrupted) {
+		return fmt.Errorf("plugin timed out after %s", Timeout)
+	}
+	return err
+}
@@ -0,0 +1,404 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+// Package redact removes, masks or hashes sensitive values: the values of named headers, cookies and query parameters,
+// the values JSONPaths match in JSON bodies, properties OpenAPI specifications mark sensitive, and any text matching
+// a pattern, like email addresses, card numbers and tokens.
+package redact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/textproto"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/pb33f/jsonpath/pkg/jsonpath"
+	jsonpathconfig "github.com/pb33f/jsonpath/pkg/jsonpath/config"
+	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/transform"
+	"go.yaml.in/yaml/v4"
+)
+
+// Mask replaces masked values.
+const Mask = "[redacted]"
+
+// pattern is a regular expression matching sensitive text. When valid is set, only the matches it accepts are
+// sensitive.
+type pattern struct {
+	regexp *regexp.Regexp
+	valid  func(match string) bool
+}
+
+// builtinPatterns are the patterns configured by name.
+var builtinPatterns = map[string]*pattern{
+	"email": {regexp: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)},
+	"card":  {regexp: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), valid: luhn},
+	"token": {regexp: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*|(?i:\b(?:bearer|basic)\s+[A-Za-z0-9._~+/-]+=*)`)},
+}
+
+// Redactor redacts sensitive values, as configured.
+type Redactor struct {
+	strategy   string
+	salt       string
+	headers    map[string]bool
+	cookies    map[string]bool
+	query      map[string]bool
+	jsonPaths  []*jsonpath.JSONPath
+	properties map[string]bool
+	patterns   []*pattern
+}
+
+// New creates the redactor of a configuration. When the configuration redacts the properties specifications mark
+// sensitive, the root nodes of the specifications are searched for them. A nil configuration redacts nothing, and
+// returns a nil redactor.
+func New(config *shared.WiretapRedactionConfig, specs ...*yaml.Node) (*Redactor, error) {
+	if config == nil {
+		return nil, nil
+	}
+	r := &Redactor{
+		strategy:   config.Strategy,
+		salt:       config.Salt,
+		headers:    make(map[string]bool),
+		cookies:    make(map[string]bool),
+		query:      make(map[string]bool),
+		properties: make(map[string]bool),
+	}
+	switch r.strategy {
+	case "":
+		r.strategy = shared.RedactMask
+	case shared.RedactRemove, shared.RedactMask, shared.RedactHash:
+	default:
+		return nil, fmt.Errorf("unknown redaction strategy '%s', use '%s', '%s' or '%s'", r.strategy,
+			shared.RedactRemove, shared.RedactMask, shared.RedactHash)
+	}
+	for _, header := range config.Headers {
+		r.headers[textproto.CanonicalMIMEHeaderKey(header)] = true
+	}
+	for _, cookie := range config.Cookies {
+		r.cookies[cookie] = true
+	}
+	for _, expression := range config.JSONPaths {
+		jsonPath, err := jsonpath.NewPath(expression, jsonpathconfig.WithPropertyNameExtension())
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction JSONPath '%s': %w", expression, err)
+		}
+		r.jsonPaths = append(r.jsonPaths, jsonPath)
+	}
+	for _, expression := range config.Patterns {
+		if builtin := builtinPatterns[expression]; builtin != nil {
+			r.patterns = append(r.patterns, builtin)
+			continue
+		}
+		compiled, err := regexp.Compile(expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern '%s': %w", expression, err)
+		}
+		r.patterns = append(r.patterns, &pattern{regexp: compiled})
+	}
+	if config.SpecProperties {
+		for _, spec := range specs {
+			r.addSensitive(spec)
+		}
+	}
+	return r, nil
+}
+
+// Removes returns true if redacted values are removed, rather than replaced.
+func (r *Redactor) Removes() bool {
+	return r.strategy == shared.RedactRemove
+}
+
+// Value redacts a whole value: it is masked, hashed, or emptied when redacted values are removed.
+func (r *Redactor) Value(value string) string {
+	switch r.strategy {
+	case shared.RedactRemove:
+		return ""
+	case shared.RedactHash:
+		sum := sha256.Sum256([]byte(r.salt + value))
+		return "sha256:" + hex.EncodeToString(sum[:8])
+	default:
+		return Mask
+	}
+}
+
+// Text redacts the parts of a text that match the patterns.
+func (r *Redactor) Text(text string) string {
+	for _, p := range r.patterns {
+		text = p.regexp.ReplaceAllStringFunc(text, func(match string) string {
+			if p.valid != nil && !p.valid(match) {
+				return match
+			}
+			return r.Value(match)
+		})
+	}
+	return text
+}
+
+// Header redacts the value of a header. The values of sensitive cookies are redacted from Cookie and Set-Cookie
+// headers. When the header is sensitive and redacted values are removed, false is returned, and the header should be
+// removed.
+func (r *Redactor) Header(name, value string) (string, bool) {
+	switch name = textproto.CanonicalMIMEHeaderKey(name); {
+	case r.headers[name]:
+		return r.Value(value), !r.Removes()
+	case name == "Cookie":
+		var cookies []string
+		for _, cookie := range strings.Split(value, ";") {
+			if cookie, keep := r.cookiePair(strings.TrimSpace(cookie)); keep {
+				cookies = append(cookies, cookie)
+			}
+		}
+		return strings.Join(cookies, "; "), len(cookies) > 0
+	case name == "Set-Cookie":
+		cookie, attributes, _ := strings.Cut(value, ";")
+		cookie, keep := r.cookiePair(cookie)
+		if attributes != "" {
+			cookie += ";" + attributes
+		}
+		return cookie, keep
+	}
+	return r.Text(value), true
+}
+
+// cookiePair redacts the value of a 'name=value' cookie pair.
+func (r *Redactor) cookiePair(pair string) (string, bool) {
+	name, value, ok := strings.Cut(pair, "=")
+	if !ok {
+		return r.Text(pair), true
+	}
+	value, keep := r.Cookie(name, value)
+	return name + "=" + value, keep
+}
+
+// Cookie redacts the value of a cookie. When the cookie is sensitive and redacted values are removed, false is
+// returned, and the cookie should be removed.
+func (r *Redactor) Cookie(name, value string) (string, bool) {
+	if r.cookies[name] {
+		return r.Value(value), !r.Removes()
+	}
+	return r.Text(value), true
+}
+
+// Property returns true if object members with a name are sensitive properties.
+func (r *Redactor) Property(name string) bool {
+	return r.properties[name]
+}
+
+// Query redacts the parameters of a raw query string, keeping their order.
+func (r *Redactor) Query(rawQuery string) string {
+	if rawQuery == "" {
+		return rawQuery
+	}
+	var parameters []string
+	for _, parameter := range strings.Split(rawQuery, "&") {
+		escapedName, escapedValue, hasValue := strings.Cut(parameter, "=")
+		name, err := url.QueryUnescape(escapedName)
+		if err != nil {
+			name = escapedName
+		}
+		value, err := url.QueryUnescape(escapedValue)
+		if err != nil {
+			value = escapedValue
+		}
+		redacted := r.Text(value)
+		if r.query[name] {
+			if r.Removes() {
+				continue
+			}
+			redacted = r.Value(value)
+		}
+		if redacted != value {
+			escapedValue = url.QueryEscape(redacted)
+		}
+		if hasValue {
+			parameter = escapedName + "=" + escapedValue
+		}
+		parameters = append(parameters, parameter)
+	}
+	return strings.Join(parameters, "&")
+}
+
+// URL redacts the query parameters of a URL.
+func (r *Redactor) URL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.RawQuery == "" {
+		return r.Text(rawURL)
+	}
+	parsed.RawQuery = r.Query(parsed.RawQuery)
+	return parsed.String()
+}
+
+// Body redacts a body. The values of JSON bodies matching the JSONPaths are redacted, along with sensitive properties,
+// and the patterns are matched against their strings. The patterns are matched against the whole of other bodies.
+// A body with nothing to redact is returned unchanged.
+func (r *Redactor) Body(body string) string {
+	root, err := transform.Parse([]byte(body))
+	if err != nil {
+		return r.Text(body)
+	}
+
+	var sensitive []*yaml.Node
+	for _, jsonPath := range r.jsonPaths {
+		sensitive = append(sensitive, jsonPath.Query(root)...)
+	}
+	sensitive = append(sensitive, r.sensitiveProperties(root)...)
+
+	changed := len(sensitive) > 0
+	if r.Removes() {
+		transform.Remove(root, sensitive)
+	} else {
+		redacted := make(map[*yaml.Node]bool)
+		for _, node := range sensitive {
+			if !redacted[node] {
+				redacted[node] = true
+				r.redactNode(node)
+			}
+		}
+	}
+	if len(r.patterns) > 0 && r.redactStrings(root) {
+		changed = true
+	}
+	if !changed {
+		return body
+	}
+	redacted, err := transform.Marshal(root)
+	if err != nil {
+		return Mask
+	}
+	return string(redacted)
+}
+
+// sensitiveProperties returns the values of the object members named as sensitive properties.
+func (r *Redactor) sensitiveProperties(node *yaml.Node) []*yaml.Node {
+	if len(r.properties) == 0 {
+		return nil
+	}
+	var values []*yaml.Node
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if r.properties[node.Content[i].Value] {
+				values = append(values, node.Content[i+1])
+				continue
+			}
+			values = append(values, r.sensitiveProperties(node.Content[i+1])...)
+		}
+		return values
+	}
+	for _, c := range node.Content {
+		values = append(values, r.sensitiveProperties(c)...)
+	}
+	return values
+}
+
+// redactNode replaces a node with its redacted value, a string.
+func (r *Redactor) redactNode(node *yaml.Node) {
+	value := node.Value
+	if node.Kind != yaml.ScalarNode || node.ShortTag() != "!!str" {
+		encoded, _ := transform.Marshal(node)
+		value = string(encoded)
+	}
+	*node = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: r.Value(value)}
+}
+
+// redactStrings matches the patterns against the strings of a node, and returns true if any were redacted.
+func (r *Redactor) redactStrings(node *yaml.Node) bool {
+	if node.Kind == yaml.ScalarNode {
+		if node.ShortTag() != "!!str" {
+			return false
+		}
+		redacted := r.Text(node.Value)
+		if redacted == node.Value {
+			return false
+		}
+		node.Value = redacted
+		return true
+	}
+	changed := false
+	for i, c := range node.Content {
+		// object keys are not redacted.
+		if node.Kind == yaml.MappingNode && i%2 == 0 {
+			continue
+		}
+		if r.redactStrings(c) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// addSensitive adds the properties and parameters a specification marks sensitive, with 'format: password' or
+// 'x-sensitive: true', wherever they are defined. Properties are matched by name in any JSON body.
+func (r *Redactor) addSensitive(node *yaml.Node) {
+	if node == nil {
+		return
+	}
+	if node.Kind == yaml.MappingNode {
+		if properties := member(node, "properties"); properties != nil && properties.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(properties.Content); i += 2 {
+				if sensitive(properties.Content[i+1]) {
+					r.properties[properties.Content[i].Value] = true
+				}
+			}
+		}
+		in, name := member(node, "in"), member(node, "name")
+		if in != nil && name != nil && (sensitive(node) || sensitive(member(node, "schema"))) {
+			switch in.Value {
+			case "header":
+				r.headers[textproto.CanonicalMIMEHeaderKey(name.Value)] = true
+			case "cookie":
+				r.cookies[name.Value] = true
+			case "query":
+				r.query[name.Value] = true
+			}
+		}
+	}
+	for _, c := range node.Content {
+		r.addSensitive(c)
+	}
+}
+
+// member returns the value of a member of a mapping node, or nil if it has none.
+func member(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// sensitive returns true if a schema or parameter is marked sensitive.
+func sensitive(node *yaml.Node) bool {
+	if format := member(node, "format"); format != nil && format.Value == "password" {
+		return true
+	}
+	marked := member(node, "x-sensitive")
+	return marked != nil && marked.Value == "true"
+}
+
+// luhn returns true if the digits of a number pass the Luhn check, like card numbers do.
+func luhn(number string) bool {
+	sum, digits := 0, 0
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if digits%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+	}
+	return digits > 0 && sum%10 == 0
+}
@@ -0,0 +1,177 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package redact
+
+import (
+	"testing"
+
+	"github.com/pb33f/wiretap/shared"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.yaml.in/yaml/v4"
+)
+
+func TestNew(t *testing.T) {
+	r, err := New(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, r)
+
+	for config, message := range map[*shared.WiretapRedactionConfig]string{
+		{Strategy: "shred"}:          "unknown redaction strategy 'shred', use 'remove', 'mask' or 'hash'",
+		{JSONPaths: []string{"$[?"}}: "invalid redaction JSONPath '$[?'",
+		{Patterns: []string{"("}}:    "invalid redaction pattern '('",
+	} {
+		_, err = New(config)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), message)
+	}
+}
+
+func TestRedactor_Strategies(t *testing.T) {
+	masked, _ := New(&shared.WiretapRedactionConfig{})
+	assert.Equal(t, Mask, masked.Value("secret"))
+
+	removed, _ := New(&shared.WiretapRedactionConfig{Strategy: shared.RedactRemove})
+	assert.Equal(t, "", removed.Value("secret"))
+	assert.True(t, removed.Removes())
+
+	hashed, _ := New(&shared.WiretapRedactionConfig{Strategy: shared.RedactHash, Salt: "pepper"})
+	assert.Equal(t, hashed.Value("secret"), hashed.Value("secret"))
+	assert.NotEqual(t, hashed.Value("secret"), hashed.Value("other"))
+	assert.Regexp(t, `^sha256:[0-9a-f]{16}$`, hashed.Value("secret"))
+
+	unsalted, _ := New(&shared.WiretapRedactionConfig{Strategy: shared.RedactHash})
+	assert.NotEqual(t, hashed.Value("secret"), unsalted.Value("secret"))
+}
+
+func TestRedactor_Patterns(t *testing.T) {
+	r, err := New(&shared.WiretapRedactionConfig{Patterns: []string{"email", "card", "token", `order-\d+`}})
+	require.NoError(t, err)
+
+	assert.Equal(t, "contact [redacted] or [redacted]", r.Text("contact dave@pb33f.io or quobix@pb33f.io"))
+	assert.Equal(t, "card [redacted], not 4111 1111 1111 1112", r.Text("card 4111 1111 1111 1111, not 4111 1111 1111 1112"))
+	assert.Equal(t, "[redacted]", r.Text("Bearer abc.def-ghi"))
+	assert.Equal(t, "jwt [redacted]", r.Text("jwt eyJhbGciOiJub25lIn0.eyJzdWIiOiJwYjMzZiJ9."))
+	assert.Equal(t, "[redacted] shipped", r.Text("order-1234 shipped"))
+	assert.Equal(t, "nothing to see", r.Text("nothing to see"))
+}
+
+func TestRedactor_HeadersAndCookies(t *testing.T) {
+	r, _ := New(&shared.WiretapRedactionConfig{Headers: []string{"x-api-key"}, Cookies: []string{"session"}})
+
+	value, keep := r.Header("X-Api-Key", "abc")
+	assert.Equal(t, Mask, value)
+	assert.True(t, keep)
+
+	value, keep = r.Header("Cookie", "theme=dark; session=abc")
+	assert.Equal(t, "theme=dark; session="+Mask, value)
+	assert.True(t, keep)
+
+	value, _ = r.Header("Set-Cookie", "session=abc; Path=/; HttpOnly")
+	assert.Equal(t, "session="+Mask+"; Path=/; HttpOnly", value)
+
+	removed, _ := New(&shared.WiretapRedactionConfig{Strategy: shared.RedactRemove, Headers: []string{"x-api-key"},
+		Cookies: []string{"session"}})
+	_, keep = removed.Header("x-api-key", "abc")
+	assert.False(t, keep)
+	value, keep = removed.Header("Cookie", "theme=dark; session=abc")
+	assert.Equal(t, "theme=dark", value)
+	assert.True(t, keep)
+	_, keep = removed.Header("Cookie", "session=abc")
+	assert.False(t, keep)
+	_, keep = removed.Cookie("session", "abc")
+	assert.False(t, keep)
+}
+
+func TestRedactor_Query(t *testing.T) {
+	r, _ := New(&shared.WiretapRedactionConfig{Patterns: []string{"email"}})
+	assert.Equal(t, "page=1&email=%5Bredacted%5D&flag", r.Query("page=1&email=dave%40pb33f.io&flag"))
+	assert.Equal(t, "https://api.pb33f.io/users?email=%5Bredacted%5D",
+		r.URL("https://api.pb33f.io/users?email=dave@pb33f.io"))
+}
+
+func TestRedactor_Body(t *testing.T) {
+	r, err := New(&shared.WiretapRedactionConfig{
+		JSONPaths: []string{"$.card.number", "$.addresses[*].street"},
+		Patterns:  []string{"email"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, `{"name":"dave","contact":"[redacted]","card":{"number":"[redacted]","expiry":"01/30"},`+
+		`"addresses":[{"street":"[redacted]","city":"Atlanta"}],"count":2}`,
+		r.Body(`{"name":"dave","contact":"dave@pb33f.io","card":{"number":4111111111111111,"expiry":"01/30"},`+
+			`"addresses":[{"street":"1 Main St","city":"Atlanta"}],"count":2}`))
+
+	// bodies with nothing to redact keep their formatting, other bodies are matched as text.
+	assert.Equal(t, "{\n  \"name\": \"dave\"\n}", r.Body("{\n  \"name\": \"dave\"\n}"))
+	assert.Equal(t, "email=[redacted]", r.Body("email=dave@pb33f.io"))
+	assert.Equal(t, "", r.Body(""))
+
+	removed, _ := New(&shared.WiretapRedactionConfig{Strategy: shared.RedactRemove, JSONPaths: []string{"$..password"}})
+	assert.Equal(t, `{"user":{"name":"dave"}}`, removed.Body(`{"user":{"name":"dave","password":"hunter2"}}`))
+
+	hashed, _ := New(&shared.WiretapRedactionConfig{Strategy: shared.RedactHash, JSONPaths: []string{"$.card"}})
+	assert.Equal(t, `{"card":"`+hashed.Value(`{"number":4111}`)+`"}`, hashed.Body(`{"card":{"number":4111}}`))
+}
+
+var sensitiveSpec = `openapi: 3.1.0
+info:
+  title: sensitive
+  version: 1.0.0
+paths:
+  /login:
+    post:
+      parameters:
+        - name: X-Session
+          in: header
+          x-sensitive: true
+          schema:
+            type: string
+        - name: otp
+          in: query
+          schema:
+            type: string
+            format: password
+        - name: page
+          in: query
+          schema:
+            type: integer
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                username:
+                  type: string
+                password:
+                  type: string
+                  format: password
+                recovery:
+                  type: object
+                  x-sensitive: true
+      responses:
+        "200":
+          description: ok
+`
+
+func TestRedactor_SpecProperties(t *testing.T) {
+	var spec yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(sensitiveSpec), &spec))
+
+	r, err := New(&shared.WiretapRedactionConfig{SpecProperties: true}, &spec)
+	require.NoError(t, err)
+	assert.True(t, r.Property("password"))
+	assert.False(t, r.Property("username"))
+
+	assert.Equal(t, `{"username":"dave","password":"[redacted]","recovery":"[redacted]"}`,
+		r.Body(`{"username":"dave","password":"hunter2","recovery":{"email":"dave@pb33f.io"}}`))
+	value, _ := r.Header("x-session", "abc")
+	assert.Equal(t, Mask, value)
+	assert.Equal(t, "otp=%5Bredacted%5D&page=1", r.Query("otp=123456&page=1"))
+
+	// properties are only redacted from specifications when configured to.
+	r, _ = New(&shared.WiretapRedactionConfig{}, &spec)
+	assert.False(t, r.Property("password"))
+}
@@ -0,0 +1,333 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+// Package script runs JavaScript middleware on the requests and responses wiretap proxies, in an embedded pure-Go
+// JavaScript runtime. A script defines a function for each stage it hooks into: beforeRouting, beforeUpstream,
+// afterResponse and beforeClientWrite. Each function is called with a context holding the request, the response once
+// there is one, the matched operation and a key-value store shared by every script; it changes the request or response
+// in place, or answers the request itself with ctx.respond(status, body, headers).
+package script
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/pb33f/wiretap/jsruntime"
+)
+
+// Stages scripts hook into, in the order they run.
+const (
+	// StageBeforeRouting runs before a request is matched to its upstream, rewrites and validation.
+	StageBeforeRouting = "beforeRouting"
+	// StageBeforeUpstream runs on the request sent upstream, just before it is sent.
+	StageBeforeUpstream = "beforeUpstream"
+	// StageAfterResponse runs on the upstream response, before it is validated.
+	StageAfterResponse = "afterResponse"
+	// StageBeforeClientWrite runs on the response after it has been validated, just before it is written to the client.
+	StageBeforeClientWrite = "beforeClientWrite"
+)
+
+// Stages are the stages scripts hook into, in the order they run.
+var Stages = []string{StageBeforeRouting, StageBeforeUpstream, StageAfterResponse, StageBeforeClientWrite}
+
+// Timeout is how long a stage of a script may run before it is interrupted.
+var Timeout = time.Second
+
+// Message is a request or response exchanged with a script. Requests have a method, path and query, responses a
+// status.
+type Message struct {
+	Method  string
+	Path    string
+	Query   string
+	Status  int
+	Headers http.Header
+	Body    []byte
+}
+
+// Operation is the operation of a specification a request matched.
+type Operation struct {
+	ID     string
+	Method string
+	Path   string
+}
+
+// Exchange is what a stage of a script runs on. Request and Response are changed in place; Respond is set when the
+// script answered the request itself, during a request stage.
+type Exchange struct {
+	Request   *Message
+	Response  *Message
+	Operation Operation
+	Respond   *Message
+}
+
+// Store is the key-value store shared by scripts, and by every request they run on.
+type Store struct {
+	mu     sync.Mutex
+	values map[string]any
+}
+
+// NewStore returns an empty store.
+func NewStore() *Store {
+	return &Store{values: make(map[string]any)}
+}
+
+// Get returns the value of a key, or nil.
+func (s *Store) Get(key string) any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.values[key]
+}
+
+// Set sets the value of a key, deleting it when the value is nil.
+func (s *Store) Set(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if value == nil {
+		delete(s.values, key)
+		return
+	}
+	s.values[key] = value
+}
+
+// Script is a compiled script, and the stages it hooks into. Scripts are safe to run concurrently: each run takes a
+// runtime of its own from a pool.
+type Script struct {
+	Name     string
+	stages   map[string]bool
+	store    *Store
+	runtimes *jsruntime.Pool
+}
+
+// Load reads and compiles a script, returning an error when it does not compile, fails to run, or defines none of the
+// stages.
+func Load(path string, store *Store) (*Script, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read script '%s': %w", path, err)
+	}
+	program, err := goja.Compile(path, string(source), true)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compile script '%s': %w", path, err)
+	}
+	s := &Script{Name: path, stages: make(map[string]bool), store: store, runtimes: jsruntime.NewPool("script", program)}
+
+	vm, err := s.runtimes.Get(Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("unable to run script '%s': %w", path, err)
+	}
+	for _, stage := range Stages {
+		if _, ok := goja.AssertFunction(vm.Get(stage)); ok {
+			s.stages[stage] = true
+		}
+	}
+	if len(s.stages) == 0 {
+		return nil, fmt.Errorf("script '%s' defines none of the functions %s", path, strings.Join(Stages, ", "))
+	}
+	s.runtimes.Put(vm)
+	return s, nil
+}
+
+// Has returns true when the script hooks into a stage.
+func (s *Script) Has(stage string) bool {
+	return s != nil && s.stages[stage]
+}
+
+// Run runs a stage of the script on an exchange. When the script throws, or runs longer than Timeout, its error is
+// returned and the exchange is left unchanged.
+func (s *Script) Run(stage string, exchange *Exchange) error {
+	if !s.Has(stage) {
+		return nil
+	}
+	vm, err := s.runtimes.Get(Timeout)
+	if err != nil {
+		return err
+	}
+	fn, _ := goja.AssertFunction(vm.Get(stage))
+
+	ctx := newContext(exchange, s.store)
+	err = s.runtimes.Run(vm, Timeout, func() error {
+		_, err := fn(goja.Undefined(), vm.ToValue(ctx.values))
+		return err
+	})
+	s.runtimes.Put(vm)
+	if err != nil {
+		return err
+	}
+	return ctx.apply(exchange)
+}
+
+// context is the context a stage is called with. Its messages are Go maps, that scripts change in place.
+type context struct {
+	values   map[string]any
+	request  map[string]any
+	response map[string]any
+	respond  map[string]any
+}
+
+func newContext(exchange *Exchange, store *Store) *context {
+	ctx := &context{}
+	ctx.values = map[string]any{
+		"operation": map[string]any{
+			"id":     exchange.Operation.ID,
+			"method": exchange.Operation.Method,
+			"path":   exchange.Operation.Path,
+		},
+		"store": map[string]any{
+			"get": func(key string) any { return store.Get(key) },
+			"set": func(key string, value goja.Value) {
+				if value == nil || goja.IsUndefined(value) || goja.IsNull(value) {
+					store.Set(key, nil)
+					return
+				}
+				store.Set(key, value.Export())
+			},
+			"delete": func(key string) { store.Set(key, nil) },
+		},
+	}
+	if exchange.Request != nil {
+		ctx.request = map[string]any{
+			"method":  exchange.Request.Method,
+			"path":    exchange.Request.Path,
+			"query":   exchange.Request.Query,
+			"headers": headerValues(exchange.Request.Headers),
+			"body":    string(exchange.Request.Body),
+		}
+		ctx.values["request"] = ctx.request
+	}
+	if exchange.Response != nil {
+		ctx.response = map[string]any{
+			"status":  exchange.Response.Status,
+			"headers": headerValues(exchange.Response.Headers),
+			"body":    string(exchange.Response.Body),
+		}
+		ctx.values["response"] = ctx.response
+	} else {
+		// only requests that have not been answered can be answered by a script.
+		ctx.values["respond"] = func(call goja.FunctionCall) goja.Value {
+			ctx.respond = map[string]any{
+				"status":  call.Argument(0).Export(),
+				"body":    call.Argument(1).Export(),
+				"headers": call.Argument(2).Export(),
+			}
+			return goja.Undefined()
+		}
+	}
+	return ctx
+}
+
+// apply copies the messages the script changed back to the exchange.
+func (ctx *context) apply(exchange *Exchange) error {
+	if ctx.request != nil {
+		exchange.Request.Method = fmt.Sprint(ctx.request["method"])
+		exchange.Request.Path = fmt.Sprint(ctx.request["path"])
+		exchange.Request.Query = fmt.Sprint(ctx.request["query"])
+		exchange.Request.Headers = headersFrom(ctx.request["headers"])
+		body, err := bodyFrom(ctx.request["body"])
+		if err != nil {
+			return err
+		}
+		exchange.Request.Body = body
+	}
+	if ctx.response != nil {
+		status, err := statusFrom(ctx.response["status"])
+		if err != nil {
+			return err
+		}
+		body, err := bodyFrom(ctx.response["body"])
+		if err != nil {
+			return err
+		}
+		exchange.Response.Status = status
+		exchange.Response.Headers = headersFrom(ctx.response["headers"])
+		exchange.Response.Body = body
+	}
+	if ctx.respond != nil {
+		status, err := statusFrom(ctx.respond["status"])
+		if err != nil {
+			return err
+		}
+		body, err := bodyFrom(ctx.respond["body"])
+		if err != nil {
+			return err
+		}
+		exchange.Respond = &Message{
+			Status:  status,
+			Headers: headersFrom(ctx.respond["headers"]),
+			Body:    body,
+		}
+	}
+	return nil
+}
+
+// headerValues returns headers as an object of names and values, values sent more than once joined with commas.
+func headerValues(headers http.Header) map[string]any {
+	values := make(map[string]any, len(headers))
+	for name, value := range headers {
+		values[name] = strings.Join(value, ", ")
+	}
+	return values
+}
+
+// headersFrom returns the headers of an object of names and values, or of names and arrays of values.
+func headersFrom(value any) http.Header {
+	headers := http.Header{}
+	values, _ := value.(map[string]any)
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		switch v := values[name].(type) {
+		case nil:
+		case []any:
+			for _, item := range v {
+				headers.Add(name, fmt.Sprint(item))
+			}
+		default:
+			headers.Set(name, fmt.Sprint(v))
+		}
+	}
+	return headers
+}
+
+func statusFrom(value any) (int, error) {
+	switch v := value.(type) {
+	case int64:
+		if v >= 100 && v <= 999 {
+			return int(v), nil
+		}
+	case int:
+		if v >= 100 && v <= 999 {
+			return v, nil
+		}
+	case float64:
+		if v >= 100 && v <= 999 && v == float64(int(v)) {
+			return int(v), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid status '%v'", value)
+}
+
+// bodyFrom returns a body set by a script: strings as they are, anything else encoded as JSON.
+func bodyFrom(value any) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []byte(v), nil
+	default:
+		body, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode body: %w", err)
+		}
+		return body, nil
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package script
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeScript(t *testing.T, source string) string {
+	path := filepath.Join(t.TempDir(), "script.js")
+	require.NoError(t, os.WriteFile(path, []byte(source), 0o600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	s, err := Load(writeScript(t, `function beforeUpstream(ctx) {} function afterResponse(ctx) {}`), NewStore())
+	require.NoError(t, err)
+	assert.False(t, s.Has(StageBeforeRouting))
+	assert.True(t, s.Has(StageBeforeUpstream))
+	assert.True(t, s.Has(StageAfterResponse))
+	assert.False(t, s.Has(StageBeforeClientWrite))
+
+	for source, message := range map[string]string{
+		`function beforeUpstream(ctx) {`: "unable to compile script",
+		`throw new Error("nope")`:        "unable to run script",
+		`function somethingElse() {}`:    "defines none of the functions beforeRouting, beforeUpstream",
+	} {
+		_, err = Load(writeScript(t, source), NewStore())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), message)
+	}
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.js"), NewStore())
+	assert.ErrorContains(t, err, "unable to read script")
+}
+
+func TestScript_RunChangesTheRequest(t *testing.T) {
+	s, err := Load(writeScript(t, `
+function beforeUpstream(ctx) {
+	var body = JSON.parse(ctx.request.body);
+	body.operation = ctx.operation.id;
+	ctx.request.body = body;
+	ctx.request.path = "/v2" + ctx.request.path;
+	ctx.request.headers["X-Tenant"] = ctx.request.headers["Authorization"].split(" ")[1];
+	delete ctx.request.headers["Authorization"];
+}`), NewStore())
+	require.NoError(t, err)
+
+	exchange := &Exchange{
+		Request: &Message{
+			Method:  http.MethodPost,
+			Path:    "/pets",
+			Headers: http.Header{"Authorization": {"Bearer acme"}},
+			Body:    []byte(`{"name":"fido"}`),
+		},
+		Operation: Operation{ID: "createPet"},
+	}
+	require.NoError(t, s.Run(StageBeforeUpstream, exchange))
+	assert.Equal(t, "/v2/pets", exchange.Request.Path)
+	assert.Equal(t, http.Header{"X-Tenant": {"acme"}}, exchange.Request.Headers)
+	assert.JSONEq(t, `{"name":"fido","operation":"createPet"}`, string(exchange.Request.Body))
+	assert.Nil(t, exchange.Respond)
+}
+
+func TestScript_RunChangesTheResponse(t *testing.T) {
+	s, err := Load(writeScript(t, `
+function afterResponse(ctx) {
+	ctx.response.status = 201;
+	ctx.response.headers["X-Seen"] = ["one", "two"];
+	ctx.response.body = ctx.response.body.toUpperCase();
+}`), NewStore())
+	require.NoError(t, err)
+
+	exchange := &Exchange{
+		Request:  &Message{Method: http.MethodGet, Path: "/pets"},
+		Response: &Message{Status: 200, Headers: http.Header{}, Body: []byte("ok")},
+	}
+	require.NoError(t, s.Run(StageAfterResponse, exchange))
+	assert.Equal(t, 201, exchange.Response.Status)
+	assert.Equal(t, []string{"one", "two"}, exchange.Response.Headers.Values("X-Seen"))
+	assert.Equal(t, "OK", string(exchange.Response.Body))
+}
+
+func TestScript_RunResponds(t *testing.T) {
+	s, err := Load(writeScript(t, `
+function beforeRouting(ctx) {
+	if (ctx.request.path === "/health") {
+		ctx.respond(200, {status: "up"}, {"Content-Type": "application/json"});
+	}
+}`), NewStore())
+	require.NoError(t, err)
+
+	exchange := &Exchange{Request: &Message{Method: http.MethodGet, Path: "/health"}}
+	require.NoError(t, s.Run(StageBeforeRouting, exchange))
+	require.NotNil(t, exchange.Respond)
+	assert.Equal(t, 200, exchange.Respond.Status)
+	assert.Equal(t, "application/json", exchange.Respond.Headers.Get("Content-Type"))
+	assert.JSONEq(t, `{"status":"up"}`, string(exchange.Respond.Body))
+
+	exchange = &Exchange{Request: &Message{Method: http.MethodGet, Path: "/pets"}}
+	require.NoError(t, s.Run(StageBeforeRouting, exchange))
+	assert.Nil(t, exchange.Respond)
+}
+
+func TestScript_RunLeavesTheExchangeWhenTheScriptFails(t *testing.T) {
+	s, err := Load(writeScript(t, `
+function beforeUpstream(ctx) {
+	ctx.request.path = "/changed";
+	throw new Error("no tenant");
+}
+function afterResponse(ctx) {
+	ctx.response.status = "teapot";
+}`), NewStore())
+	require.NoError(t, err)
+
+	exchange := &Exchange{Request: &Message{Method: http.MethodGet, Path: "/pets"}}
+	assert.EqualError(t, s.Run(StageBeforeUpstream, exchange), "Error: no tenant")
+	assert.Equal(t, "/pets", exchange.Request.Path)
+
+	exchange.Response = &Message{Status: 200}
+	assert.EqualError(t, s.Run(StageAfterResponse, exchange), "invalid status 'teapot'")
+	assert.Equal(t, 200, exchange.Response.Status)
+}
+
+func TestScript_RunInterruptsScriptsThatTakeTooLong(t *testing.T) {
+	defer func(timeout time.Duration) { Timeout = timeout }(Timeout)
+	Timeout = 50 * time.Millisecond
+
+	s, err := Load(writeScript(t, `
+function beforeUpstream(ctx) {
+	if (ctx.request.path === "/spin") { for (;;) {} }
+	ctx.request.path = "/done";
+}`), NewStore())
+	require.NoError(t, err)
+
+	exchange := &Exchange{Request: &Message{Path: "/spin"}}
+	assert.ErrorContains(t, s.Run(StageBeforeUpstream, exchange), "script timed out after 50ms")
+
+	// the interrupted runtime is used again.
+	exchange = &Exchange{Request: &Message{Path: "/pets"}}
+	require.NoError(t, s.Run(StageBeforeUpstream, exchange))
+	assert.Equal(t, "/done", exchange.Request.Path)
+}
+
+func TestScript_StoreIsSharedByScriptsAndRequests(t *testing.T) {
+	store := NewStore()
+	counter, err := Load(writeScript(t, `
+function beforeUpstream(ctx) {
+	ctx.store.set("count", (ctx.store.get("count") || 0) + 1);
+}`), store)
+	require.NoError(t, err)
+	reader, err := Load(writeScript(t, `
+function afterResponse(ctx) {
+	ctx.response.headers["X-Count"] = String(ctx.store.get("count"));
+	ctx.store.delete("count");
+}`), store)
+	require.NoError(t, err)
+
+	for range 20 {
+		require.NoError(t, counter.Run(StageBeforeUpstream, &Exchange{Request: &Message{}}))
+	}
+
+	exchange := &Exchange{Request: &Message{}, Response: &Message{Status: 200}}
+	require.NoError(t, reader.Run(StageAfterResponse, exchange))
+	assert.Equal(t, "20", exchange.Response.Headers.Get("X-Count"))
+	assert.Nil(t, store.Get("count"))
+}
@@ -0,0 +1,52 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package shared
+
+import (
+	"net/http"
+)
+
+// ClientIdentity is the identity of the certificate a client presented to wiretap: its subject, common name and
+// subject alternative names (DNS names, email addresses, IP addresses and URIs).
+type ClientIdentity struct {
+	Subject    string   `json:"subject,omitempty"`
+	CommonName string   `json:"commonName,omitempty"`
+	SAN        []string `json:"san,omitempty"`
+}
+
+// RequestClientIdentity returns the identity of the client certificate a request was sent with, or nil when the
+// client presented no certificate.
+func RequestClientIdentity(request *http.Request) *ClientIdentity {
+	if request == nil || request.TLS == nil || len(request.TLS.PeerCertificates) == 0 {
+		return nil
+	}
+	certificate := request.TLS.PeerCertificates[0]
+	identity := &ClientIdentity{
+		Subject:    certificate.Subject.String(),
+		CommonName: certificate.Subject.CommonName,
+	}
+	identity.SAN = append(identity.SAN, certificate.DNSNames...)
+	identity.SAN = append(identity.SAN, certificate.EmailAddresses...)
+	for _, ip := range certificate.IPAddresses {
+		identity.SAN = append(identity.SAN, ip.String())
+	}
+	for _, uri := range certificate.URIs {
+		identity.SAN = append(identity.SAN, uri.String())
+	}
+	return identity
+}
+
+// Names returns every name the client is known by, so identities can be matched by common name, subject or any
+// subject alternative name.
+func (ci *ClientIdentity) Names() []string {
+	if ci == nil {
+		return nil
+	}
+	names := make([]string, 0, len(ci.SAN)+2)
+	if ci.CommonName != "" {
+		names = append(names, ci.CommonName)
+	}
+	names = append(names, ci.Subject)
+	return append(names, ci.SAN...)
+}
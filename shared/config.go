@@ -4,6 +4,7 @@
 package shared
 
 import (
+	"crypto/tls"
 	"embed"
 	"encoding/json"
 	"fmt"
@@ -17,6 +18,7 @@ import (
 	"go.yaml.in/yaml/v4"
 
 	"github.com/gobwas/glob"
+	"github.com/pb33f/wiretap/certs"
 )
 
 type ApiDocument struct {
@@ -36,6 +38,8 @@ type WiretapConfiguration struct {
 	RedirectBasePath            string                                      `json:"redirectBasePath,omitempty" yaml:"redirectBasePath,omitempty"`
 	RedirectProtocol            string                                      `json:"redirectProtocol,omitempty" yaml:"redirectProtocol,omitempty"`
 	RedirectURL                 string                                      `json:"redirectURL,omitempty" yaml:"redirectURL,omitempty"`
+	RouteBySpecServers          bool                                        `json:"routeBySpecServers,omitempty" yaml:"routeBySpecServers,omitempty"`
+	SpecUpstreams               map[string]string                           `json:"specUpstreams,omitempty" yaml:"specUpstreams,omitempty"`
 	Port                        string                                      `json:"port,omitempty" yaml:"port,omitempty"`
 	MonitorPort                 string                                      `json:"monitorPort,omitempty" yaml:"monitorPort,omitempty"`
 	WebSocketHost               string                                      `json:"webSocketHost,omitempty" yaml:"webSocketHost,omitempty"`
@@ -55,18 +59,45 @@ type WiretapConfiguration struct {
 	IgnoreClashingOperationID   bool                                        `json:"ignoreClashingOperationId,omitempty" yaml:"ignoreClashingOperationId,omitempty"`
 	Certificate                 string                                      `json:"certificate,omitempty" yaml:"certificate,omitempty"`
 	CertificateKey              string                                      `json:"certificateKey,omitempty" yaml:"certificateKey,omitempty"`
+	TLSAuto                     bool                                        `json:"tlsAuto,omitempty" yaml:"tlsAuto,omitempty"`
+	TLSAutoHosts                []string                                    `json:"tlsAutoHosts,omitempty" yaml:"tlsAutoHosts,omitempty"`
+	ClientCA                    string                                      `json:"clientCA,omitempty" yaml:"clientCA,omitempty"`
+	ClientAuth                  string                                      `json:"clientAuth,omitempty" yaml:"clientAuth,omitempty"`
+	UpstreamTLS                 *WiretapTLSConfig                           `json:"upstreamTLS,omitempty" yaml:"upstreamTLS,omitempty"`
+	AuthProvider                *WiretapAuthProvider                        `json:"authProvider,omitempty" yaml:"authProvider,omitempty"`
+	Mirror                      *WiretapMirrorConfig                        `json:"mirror,omitempty" yaml:"mirror,omitempty"`
+	MirrorReportFile            string                                      `json:"mirrorReportFilename,omitempty" yaml:"mirrorReportFilename,omitempty"`
+	Resilience                  *WiretapResilienceConfig                    `json:"resilience,omitempty" yaml:"resilience,omitempty"`
+	JWT                         *WiretapJWTConfig                           `json:"jwt,omitempty" yaml:"jwt,omitempty"`
+	Redaction                   *WiretapRedactionConfig                     `json:"redaction,omitempty" yaml:"redaction,omitempty"`
+	Plugins                     []string                                    `json:"plugins,omitempty" yaml:"plugins,omitempty"`
+	ForwardProxy                bool                                        `json:"forwardProxy,omitempty" yaml:"forwardProxy,omitempty"`
+	ForwardProxyCA              string                                      `json:"forwardProxyCA,omitempty" yaml:"forwardProxyCA,omitempty"`
+	ForwardProxyCAKey           string                                      `json:"forwardProxyCAKey,omitempty" yaml:"forwardProxyCAKey,omitempty"`
 	HardErrors                  bool                                        `json:"hardValidation,omitempty" yaml:"hardValidation,omitempty"`
 	HardErrorCode               int                                         `json:"hardValidationCode,omitempty" yaml:"hardValidationCode,omitempty"`
 	HardErrorReturnCode         int                                         `json:"hardValidationReturnCode,omitempty" yaml:"hardValidationReturnCode,omitempty"`
 	HardErrorsList              []string                                    `json:"hardValidationList,omitempty" yaml:"hardValidationList,omitempty"`
 	HardErrorReturnProblem      bool                                        `json:"hardErrorReturnProblem,omitempty" yaml:"hardErrorReturnProblem,omitempty"`
+	HardErrorUnauthorized       bool                                        `json:"hardValidationUnauthorized,omitempty" yaml:"hardValidationUnauthorized,omitempty"`
 	PathDelays                  map[string]int                              `json:"pathDelays,omitempty" yaml:"pathDelays,omitempty"`
 	MockMode                    bool                                        `json:"mockMode,omitempty" yaml:"mockMode,omitempty"`
 	MockModeList                []string                                    `json:"mockModeList,omitempty" yaml:"mockModeList,omitempty"`
+	MockFallback                bool                                        `json:"mockFallback,omitempty" yaml:"mockFallback,omitempty"`
+	MockFallbackList            []string                                    `json:"mockFallbackList,omitempty" yaml:"mockFallbackList,omitempty"`
+	MockFallbackStatusCodes     []int                                       `json:"mockFallbackStatusCodes,omitempty" yaml:"mockFallbackStatusCodes,omitempty"`
 	StaticMockDir               string                                      `json:"staticMockDir,omitempty" yaml:"staticMockDir,omitempty"`
 	UseAllMockResponseFields    bool                                        `json:"useAllMockResponseFields,omitempty" yaml:"useAllMockResponseFields,omitempty"`
 	MockModePretty              bool                                        `json:"mockModePretty,omitempty" yaml:"mockModePretty,omitempty"`
 	MockBypassValidation        bool                                        `json:"mockBypassValidation,omitempty" yaml:"mockBypassValidation,omitempty"`
+	MockSeed                    *int64                                      `json:"mockSeed,omitempty" yaml:"mockSeed,omitempty"`
+	MockPagination              bool                                        `json:"mockPagination,omitempty" yaml:"mockPagination,omitempty"`
+	MockPaginationTotal         int                                         `json:"mockPaginationTotal,omitempty" yaml:"mockPaginationTotal,omitempty"`
+	MockCallbacks               bool                                        `json:"mockCallbacks,omitempty" yaml:"mockCallbacks,omitempty"`
+	MockCallbackDelay           int                                         `json:"mockCallbackDelay,omitempty" yaml:"mockCallbackDelay,omitempty"`
+	MockEventCount              int                                         `json:"mockEventCount,omitempty" yaml:"mockEventCount,omitempty"`
+	MockEventInterval           int                                         `json:"mockEventInterval,omitempty" yaml:"mockEventInterval,omitempty"`
+	MaxValidatedBodySize        int64                                       `json:"maxValidatedBodySize,omitempty" yaml:"maxValidatedBodySize,omitempty"`
 	Base                        string                                      `json:"base,omitempty" yaml:"base,omitempty"`
 	HAR                         string                                      `json:"har,omitempty" yaml:"har,omitempty"`
 	HARValidate                 bool                                        `json:"harValidate,omitempty" yaml:"harValidate,omitempty"`
@@ -77,12 +108,14 @@ type WiretapConfiguration struct {
 	IgnoreRedirects             []string                                    `json:"ignoreRedirects,omitempty" yaml:"ignoreRedirects,omitempty"`
 	RedirectAllowList           []string                                    `json:"redirectAllowList,omitempty" yaml:"redirectAllowList,omitempty"`
 	WebsocketConfigs            map[string]*WiretapWebsocketConfig          `json:"websockets" yaml:"websockets"`
+	AsyncAPI                    string                                      `json:"asyncapi,omitempty" yaml:"asyncapi,omitempty"`
 	IgnoreValidation            []string                                    `json:"ignoreValidation,omitempty" yaml:"ignoreValidation,omitempty"`
 	ValidationAllowList         []string                                    `json:"validationAllowList,omitempty" yaml:"validationAllowList,omitempty"`
 	StrictRedirectLocation      bool                                        `json:"strictRedirectLocation,omitempty" yaml:"strictRedirectLocation,omitempty"`
 	StrictMode                  bool                                        `json:"strictMode,omitempty" yaml:"strictMode,omitempty"`
 	IgnorePathRewrite           []*IgnoreRewriteConfig                      `json:"ignorePathRewrite,omitempty" yaml:"ignorePathRewrite,omitempty"`
 	CompiledMockModeList        []glob.Glob                                 `json:"-" yaml:"-"`
+	CompiledMockFallbackList    []glob.Glob                                 `json:"-" yaml:"-"`
 	CompiledPathDelays          map[string]*CompiledPathDelay               `json:"-" yaml:"-"`
 	CompiledVariables           map[string]*CompiledVariable                `json:"-" yaml:"-"`
 	Version                     string                                      `json:"-" yaml:"-"`
@@ -95,6 +128,8 @@ type WiretapConfiguration struct {
 	CompiledValidationAllowList []*CompiledRedirect                         `json:"-" yaml:"-"`
 	CompiledIgnorePathRewrite   []*CompiledIgnoreRewrite                    `json:"-" yaml:"-"`
 	FS                          embed.FS                                    `json:"-"`
+	ClientTLSConfig             *tls.Config                                 `json:"-" yaml:"-"`
+	ForwardProxyAuthority       *certs.Authority                            `json:"-" yaml:"-"`
 	Logger                      *slog.Logger
 }
 
@@ -179,6 +214,13 @@ func (wtc *WiretapConfiguration) CompileMockModeList() {
 	}
 }
 
+func (wtc *WiretapConfiguration) CompileMockFallbackList() {
+	wtc.CompiledMockFallbackList = make([]glob.Glob, 0)
+	for _, x := range wtc.MockFallbackList {
+		wtc.CompiledMockFallbackList = append(wtc.CompiledMockFallbackList, glob.MustCompile(wtc.ReplaceWithVariables(x)))
+	}
+}
+
 func (wtc *WiretapConfiguration) CompileHardErrorList() {
 	wtc.CompiledHardErrorList = make([]glob.Glob, 0)
 	for _, x := range wtc.HardErrorsList {
@@ -246,6 +288,14 @@ func (wtc *WiretapConfiguration) GetHttpProtocol() string {
 	return protocol
 }
 
+// GetWebSocketProtocol returns the scheme of the ranch websocket, wss when wiretap is served over TLS.
+func (wtc *WiretapConfiguration) GetWebSocketProtocol() string {
+	if wtc.GetHttpProtocol() == "https" {
+		return "wss"
+	}
+	return "ws"
+}
+
 func (wtc *WiretapConfiguration) GetApiGateway() string {
 	return fmt.Sprintf("%s://%s", wtc.GetHttpProtocol(), wtc.GetApiGatewayHost())
 }
@@ -258,26 +308,255 @@ func (wtc *WiretapConfiguration) GetMonitorUI() string {
 	return fmt.Sprintf("%s://localhost:%s", wtc.GetHttpProtocol(), wtc.MonitorPort)
 }
 
+// GetMaxValidatedBodySize returns the largest response body (in bytes) that is held in memory and validated,
+// larger bodies are streamed to the client without validation.
+func (wtc *WiretapConfiguration) GetMaxValidatedBodySize() int64 {
+	if wtc.MaxValidatedBodySize > 0 {
+		return wtc.MaxValidatedBodySize
+	}
+	return DefaultMaxValidatedBodySize
+}
+
 func (wtc *WiretapConfiguration) GetContractList() string {
 	return strings.Join(wtc.Contracts, ", ")
 }
 
+// WiretapWebsocketConfig configures a websocket path. Frames are validated against the messages of an AsyncAPI
+// channel, either the one named by the configuration, or the channel with the address of the websocket path. The
+// AsyncAPI document of the websocket replaces the global one.
 type WiretapWebsocketConfig struct {
-	VerifyCert  *bool    `json:"verifyCert" yaml:"verifyCert"`
-	DropHeaders []string `json:"dropHeaders" yaml:"dropHeaders"`
+	VerifyCert  *bool                       `json:"verifyCert" yaml:"verifyCert"`
+	DropHeaders []string                    `json:"dropHeaders" yaml:"dropHeaders"`
+	AsyncAPI    string                      `json:"asyncapi,omitempty" yaml:"asyncapi,omitempty"`
+	Channel     string                      `json:"channel,omitempty" yaml:"channel,omitempty"`
+	Mock        *WiretapWebsocketMockConfig `json:"mock,omitempty" yaml:"mock,omitempty"`
+}
+
+// WiretapWebsocketMockConfig scripts a websocket in mock mode. Client messages are answered by the first matching
+// rule, pushes are sent by the server on a timer, and the connection can be closed with a scripted close code.
+// Messages can be generated from the channels of an AsyncAPI document.
+type WiretapWebsocketMockConfig struct {
+	AsyncAPI  string                         `json:"asyncapi,omitempty" yaml:"asyncapi,omitempty"`
+	OnConnect []*WiretapWebsocketMockMessage `json:"onConnect,omitempty" yaml:"onConnect,omitempty"`
+	Rules     []*WiretapWebsocketMockRule    `json:"rules,omitempty" yaml:"rules,omitempty"`
+	Push      []*WiretapWebsocketMockPush    `json:"push,omitempty" yaml:"push,omitempty"`
+	Close     *WiretapWebsocketMockClose     `json:"close,omitempty" yaml:"close,omitempty"`
+}
+
+// WiretapWebsocketMockRule replies to client messages that match. A string match is compared with the whole message
+// (and may be a regex), an object or array match is compared as a subset of a JSON message, like static mocks. A rule
+// without a match answers every message.
+type WiretapWebsocketMockRule struct {
+	Match   any                            `json:"match,omitempty" yaml:"match,omitempty"`
+	Replies []*WiretapWebsocketMockMessage `json:"replies,omitempty" yaml:"replies,omitempty"`
+	Close   *WiretapWebsocketMockClose     `json:"close,omitempty" yaml:"close,omitempty"`
+}
+
+// WiretapWebsocketMockMessage is a message sent by a websocket mock. The body can use ${message.x} template variables
+// to copy values from the client message being replied to. When a channel is set the message is generated from the
+// AsyncAPI document instead. Delay is in milliseconds.
+type WiretapWebsocketMockMessage struct {
+	Body    string `json:"body,omitempty" yaml:"body,omitempty"`
+	Channel string `json:"channel,omitempty" yaml:"channel,omitempty"`
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+	Delay   int    `json:"delay,omitempty" yaml:"delay,omitempty"`
+}
+
+// WiretapWebsocketMockPush is a message the server pushes every interval (in milliseconds), after the delay of the
+// message. A count of zero pushes until the connection closes.
+type WiretapWebsocketMockPush struct {
+	WiretapWebsocketMockMessage `yaml:",inline"`
+	Interval                    int `json:"interval,omitempty" yaml:"interval,omitempty"`
+	Count                       int `json:"count,omitempty" yaml:"count,omitempty"`
+}
+
+// WiretapWebsocketMockClose closes a mocked websocket with a code and reason, after a delay in milliseconds.
+type WiretapWebsocketMockClose struct {
+	Code   int    `json:"code,omitempty" yaml:"code,omitempty"`
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+	After  int    `json:"after,omitempty" yaml:"after,omitempty"`
 }
 
 type WiretapPathConfig struct {
-	Target                string                   `json:"target,omitempty" yaml:"target,omitempty"`
-	PathRewrite           map[string]string        `json:"pathRewrite,omitempty" yaml:"pathRewrite,omitempty"`
-	ChangeOrigin          bool                     `json:"changeOrigin,omitempty" yaml:"changeOrigin,omitempty"`
-	Headers               *WiretapHeaderConfig     `json:"headers,omitempty" yaml:"headers,omitempty"`
-	Secure                bool                     `json:"secure,omitempty" yaml:"secure,omitempty"`
-	Auth                  string                   `json:"auth,omitempty" yaml:"auth,omitempty"`
-	RewriteId             string                   `json:"rewriteId,omitempty" yaml:"rewriteId,omitempty"`
-	IgnoreRewrite         []*IgnoreRewriteConfig   `json:"ignoreRewrite,omitempty" yaml:"ignoreRewrite,omitempty"`
-	CompiledPath          *CompiledPath            `json:"-"`
-	CompiledIgnoreRewrite []*CompiledIgnoreRewrite `json:"-"`
+	Target                 string                   `json:"target,omitempty" yaml:"target,omitempty"`
+	PathRewrite            map[string]string        `json:"pathRewrite,omitempty" yaml:"pathRewrite,omitempty"`
+	ChangeOrigin           bool                     `json:"changeOrigin,omitempty" yaml:"changeOrigin,omitempty"`
+	Headers                *WiretapHeaderConfig     `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Secure                 bool                     `json:"secure,omitempty" yaml:"secure,omitempty"`
+	Auth                   string                   `json:"auth,omitempty" yaml:"auth,omitempty"`
+	RewriteId              string                   `json:"rewriteId,omitempty" yaml:"rewriteId,omitempty"`
+	IgnoreRewrite          []*IgnoreRewriteConfig   `json:"ignoreRewrite,omitempty" yaml:"ignoreRewrite,omitempty"`
+	TLS                    *WiretapTLSConfig        `json:"tls,omitempty" yaml:"tls,omitempty"`
+	AuthProvider           *WiretapAuthProvider     `json:"authProvider,omitempty" yaml:"authProvider,omitempty"`
+	Signing                *WiretapSigningConfig    `json:"signing,omitempty" yaml:"signing,omitempty"`
+	Mirror                 *WiretapMirrorConfig     `json:"mirror,omitempty" yaml:"mirror,omitempty"`
+	Resilience             *WiretapResilienceConfig `json:"resilience,omitempty" yaml:"resilience,omitempty"`
+	Transforms             *WiretapTransformsConfig `json:"transforms,omitempty" yaml:"transforms,omitempty"`
+	Script                 string                   `json:"script,omitempty" yaml:"script,omitempty"`
+	ClientIdentity         string                   `json:"clientIdentity,omitempty" yaml:"clientIdentity,omitempty"`
+	CompiledPath           *CompiledPath            `json:"-"`
+	CompiledIgnoreRewrite  []*CompiledIgnoreRewrite `json:"-"`
+	CompiledClientIdentity glob.Glob                `json:"-"`
+}
+
+const (
+	// AuthProviderClientCredentials fetches tokens with the OAuth2 client credentials grant.
+	AuthProviderClientCredentials = "client_credentials"
+	// AuthProviderRefreshToken fetches tokens with the OAuth2 refresh token grant.
+	AuthProviderRefreshToken = "refresh_token"
+	// AuthProviderTokenFile reads tokens from a file, re-reading it when it changes.
+	AuthProviderTokenFile = "token_file"
+)
+
+// WiretapAuthProvider fetches the bearer tokens sent to upstreams in the Authorization header. Tokens are cached and
+// fetched again before they expire. The client id, secret and refresh token may use variables.
+type WiretapAuthProvider struct {
+	Type         string   `json:"type,omitempty" yaml:"type,omitempty"`
+	TokenURL     string   `json:"tokenUrl,omitempty" yaml:"tokenUrl,omitempty"`
+	ClientID     string   `json:"clientId,omitempty" yaml:"clientId,omitempty"`
+	ClientSecret string   `json:"clientSecret,omitempty" yaml:"clientSecret,omitempty"`
+	Scopes       []string `json:"scopes,omitempty" yaml:"scopes,omitempty"`
+	Audience     string   `json:"audience,omitempty" yaml:"audience,omitempty"`
+	RefreshToken string   `json:"refreshToken,omitempty" yaml:"refreshToken,omitempty"`
+	TokenFile    string   `json:"tokenFile,omitempty" yaml:"tokenFile,omitempty"`
+}
+
+// WiretapMirrorConfig sends a copy of each proxied request to a shadow upstream, after the client has its response,
+// and compares the shadow response with the primary one. Headers named in IgnoreHeaders are not compared, nor are
+// the parts of JSON bodies matching IgnorePaths: dot separated globs like 'meta.requestId' or 'items.*.updatedAt'.
+type WiretapMirrorConfig struct {
+	Target        string   `json:"target,omitempty" yaml:"target,omitempty"`
+	IgnoreHeaders []string `json:"ignoreHeaders,omitempty" yaml:"ignoreHeaders,omitempty"`
+	IgnorePaths   []string `json:"ignorePaths,omitempty" yaml:"ignorePaths,omitempty"`
+}
+
+// WiretapJWTConfig configures how JSON Web Tokens sent by clients are inspected. Bearer tokens in the Authorization
+// header are always decoded, tokens in Cookies as well. Signatures are verified when keys are configured: a JWKS file,
+// PEM files of public keys or certificates, or an HMAC secret. Claims are recorded on transactions, except the values
+// of RedactClaims.
+type WiretapJWTConfig struct {
+	Cookies      []string `json:"cookies,omitempty" yaml:"cookies,omitempty"`
+	JWKS         string   `json:"jwks,omitempty" yaml:"jwks,omitempty"`
+	Keys         []string `json:"keys,omitempty" yaml:"keys,omitempty"`
+	Secret       string   `json:"secret,omitempty" yaml:"secret,omitempty"`
+	RedactClaims []string `json:"redactClaims,omitempty" yaml:"redactClaims,omitempty"`
+}
+
+// WiretapRedactionConfig redacts sensitive values from transactions before they are stored, shown in the monitor,
+// exported in reports, and streamed with violations. Values are redacted from the headers and cookies named, the query
+// parameters, headers and cookies the specifications mark sensitive, the JSON body values JSONPaths match, and body
+// properties the specifications mark sensitive with 'format: password' or 'x-sensitive: true' (SpecProperties).
+// Patterns are regular expressions, or the built-in 'email', 'card' and 'token' patterns, that redact the text they
+// match anywhere in a transaction. Strategy is how values are redacted: removed, masked (the default), or replaced with
+// a hash, salted with Salt, so equal values can still be correlated.
+type WiretapRedactionConfig struct {
+	Strategy       string   `json:"strategy,omitempty" yaml:"strategy,omitempty"`
+	Headers        []string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Cookies        []string `json:"cookies,omitempty" yaml:"cookies,omitempty"`
+	JSONPaths      []string `json:"jsonPaths,omitempty" yaml:"jsonPaths,omitempty"`
+	Patterns       []string `json:"patterns,omitempty" yaml:"patterns,omitempty"`
+	SpecProperties bool     `json:"specProperties,omitempty" yaml:"specProperties,omitempty"`
+	Salt           string   `json:"salt,omitempty" yaml:"salt,omitempty"`
+}
+
+const (
+	// RedactRemove removes redacted values, with their headers, cookies, or object members.
+	RedactRemove = "remove"
+	// RedactMask replaces redacted values with a mask.
+	RedactMask = "mask"
+	// RedactHash replaces redacted values with a salted hash of the value.
+	RedactHash = "hash"
+)
+
+// WiretapTransformsConfig rewrites JSON bodies of requests to a path before they are sent upstream, and of responses
+// before they are validated and returned to the client.
+type WiretapTransformsConfig struct {
+	Request  []*WiretapTransform `json:"request,omitempty" yaml:"request,omitempty"`
+	Response []*WiretapTransform `json:"response,omitempty" yaml:"response,omitempty"`
+}
+
+// WiretapTransform is one operation on a JSON body. With a Path (a JSON Pointer), it is a JSON Patch operation: add,
+// remove, replace, move, copy or test. With a JSONPath, it sets or removes every value the JSONPath matches.
+type WiretapTransform struct {
+	Op       string `json:"op" yaml:"op"`
+	Path     string `json:"path,omitempty" yaml:"path,omitempty"`
+	From     string `json:"from,omitempty" yaml:"from,omitempty"`
+	JSONPath string `json:"jsonPath,omitempty" yaml:"jsonPath,omitempty"`
+	Value    any    `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+const (
+	// ResilienceFallbackProblem answers requests to an open circuit with a problem+json 503.
+	ResilienceFallbackProblem = "problem"
+	// ResilienceFallbackMock answers requests to an open circuit with a response mocked from the specification.
+	ResilienceFallbackMock = "mock"
+)
+
+// WiretapResilienceConfig protects clients from failing upstreams. Timeouts are in milliseconds: ConnectTimeout limits
+// getting a connection, ResponseTimeout limits waiting for the response headers (not reading the body, which may
+// stream for as long as the upstream keeps sending it). Idempotent requests that fail, or
+// are answered with one of RetryStatusCodes (502, 503 and 504 by default), are sent again up to Retries times,
+// waiting RetryBackoff (100ms by default) before the first retry and twice as long before each one after it.
+type WiretapResilienceConfig struct {
+	ConnectTimeout   int                          `json:"connectTimeout,omitempty" yaml:"connectTimeout,omitempty"`
+	ResponseTimeout  int                          `json:"responseTimeout,omitempty" yaml:"responseTimeout,omitempty"`
+	Retries          int                          `json:"retries,omitempty" yaml:"retries,omitempty"`
+	RetryBackoff     int                          `json:"retryBackoff,omitempty" yaml:"retryBackoff,omitempty"`
+	RetryStatusCodes []int                        `json:"retryStatusCodes,omitempty" yaml:"retryStatusCodes,omitempty"`
+	CircuitBreaker   *WiretapCircuitBreakerConfig `json:"circuitBreaker,omitempty" yaml:"circuitBreaker,omitempty"`
+}
+
+// WiretapCircuitBreakerConfig opens the circuit to an upstream host after FailureThreshold (5 by default) requests in
+// a row fail or are answered with a 5xx. While open, requests are not sent upstream but answered with the Fallback,
+// a problem+json 503 ("problem", the default) or a response mocked from the specification ("mock"). After
+// OpenDuration milliseconds (30000 by default) one request is let through, and closes the circuit if it succeeds.
+type WiretapCircuitBreakerConfig struct {
+	FailureThreshold int    `json:"failureThreshold,omitempty" yaml:"failureThreshold,omitempty"`
+	OpenDuration     int    `json:"openDuration,omitempty" yaml:"openDuration,omitempty"`
+	Fallback         string `json:"fallback,omitempty" yaml:"fallback,omitempty"`
+}
+
+// WiretapSigningConfig signs requests sent upstream, after all headers and paths have been rewritten.
+type WiretapSigningConfig struct {
+	SigV4 *WiretapSigV4Config `json:"sigv4,omitempty" yaml:"sigv4,omitempty"`
+	HMAC  *WiretapHMACConfig  `json:"hmac,omitempty" yaml:"hmac,omitempty"`
+}
+
+// WiretapSigV4Config signs requests with AWS Signature Version 4. Credentials that are not configured are read from
+// the AWS environment variables, then from the profile of a shared credentials file (~/.aws/credentials by default).
+type WiretapSigV4Config struct {
+	Service         string `json:"service,omitempty" yaml:"service,omitempty"`
+	Region          string `json:"region,omitempty" yaml:"region,omitempty"`
+	AccessKeyID     string `json:"accessKeyId,omitempty" yaml:"accessKeyId,omitempty"`
+	SecretAccessKey string `json:"secretAccessKey,omitempty" yaml:"secretAccessKey,omitempty"`
+	SessionToken    string `json:"sessionToken,omitempty" yaml:"sessionToken,omitempty"`
+	CredentialsFile string `json:"credentialsFile,omitempty" yaml:"credentialsFile,omitempty"`
+	Profile         string `json:"profile,omitempty" yaml:"profile,omitempty"`
+}
+
+// WiretapHMACConfig signs requests with an HMAC of a canonical string, sent in a header. The canonical string may use
+// ${method}, ${path}, ${query}, ${host}, ${body}, ${bodySha256}, ${timestamp} and ${header.<name>}. The algorithm is
+// sha1, sha256 or sha512 and the encoding hex or base64, sha256 and hex are used when not set.
+type WiretapHMACConfig struct {
+	Secret          string `json:"secret,omitempty" yaml:"secret,omitempty"`
+	Algorithm       string `json:"algorithm,omitempty" yaml:"algorithm,omitempty"`
+	Encoding        string `json:"encoding,omitempty" yaml:"encoding,omitempty"`
+	Header          string `json:"header,omitempty" yaml:"header,omitempty"`
+	Prefix          string `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+	CanonicalString string `json:"canonicalString,omitempty" yaml:"canonicalString,omitempty"`
+	TimestampHeader string `json:"timestampHeader,omitempty" yaml:"timestampHeader,omitempty"`
+}
+
+// WiretapTLSConfig configures TLS connections to upstreams: the CA bundle used to verify them, the client certificate
+// and key presented for mutual TLS, the server name to verify, and the lowest TLS version allowed (1.0 to 1.3).
+// Certificates are only verified when Verify is set; a path config that is secure verifies unless Verify is false.
+type WiretapTLSConfig struct {
+	CA          string `json:"ca,omitempty" yaml:"ca,omitempty"`
+	Certificate string `json:"cert,omitempty" yaml:"cert,omitempty"`
+	Key         string `json:"key,omitempty" yaml:"key,omitempty"`
+	ServerName  string `json:"serverName,omitempty" yaml:"serverName,omitempty"`
+	MinVersion  string `json:"minVersion,omitempty" yaml:"minVersion,omitempty"`
+	Verify      *bool  `json:"verify,omitempty" yaml:"verify,omitempty"`
 }
 
 type IgnoreRewriteConfig struct {
@@ -336,6 +615,10 @@ func (wpc *WiretapPathConfig) Compile(key string) *CompiledPath {
 		cp.CompiledPathRewrite[x] = regexp.MustCompile(x)
 	}
 
+	if wpc.ClientIdentity != "" {
+		wpc.CompiledClientIdentity = glob.MustCompile(wpc.ClientIdentity)
+	}
+
 	wpc.CompiledIgnoreRewrite = make([]*CompiledIgnoreRewrite, len(wpc.IgnoreRewrite))
 	for i, ignoreRewrite := range wpc.IgnoreRewrite {
 		wpc.CompiledIgnoreRewrite[i] = &CompiledIgnoreRewrite{
@@ -347,6 +630,7 @@ func (wpc *WiretapPathConfig) Compile(key string) *CompiledPath {
 }
 
 const ConfigKey = "config"
+const DefaultMaxValidatedBodySize int64 = 10 << 20
 const HARKey = "har"
 const WiretapHostPlaceholder = "%WIRETAP_HOST%"
 const WiretapPortPlaceholder = "%WIRETAP_PORT%"
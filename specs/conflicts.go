@@ -371,6 +371,31 @@ func ServerBasePaths(doc *v3.Document) []string {
 	return serverBasePathsFromServers(doc.Servers)
 }
 
+// ServerHosts returns the lower-cased host names of the document's servers, servers with relative URLs have no host.
+func ServerHosts(doc *v3.Document) []string {
+	if doc == nil {
+		return nil
+	}
+	seen := make(map[string]struct{})
+	var hosts []string
+	for _, server := range doc.Servers {
+		if server == nil {
+			continue
+		}
+		u, err := url.Parse(resolveServerURL(server))
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		host := strings.ToLower(u.Hostname())
+		if _, ok := seen[host]; ok {
+			continue
+		}
+		seen[host] = struct{}{}
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
 func EffectiveOperationServerBasePaths(doc *v3.Document, pathItem *v3.PathItem, operation *v3.Operation) []string {
 	if operation != nil && len(operation.Servers) > 0 {
 		return serverBasePathsFromServers(operation.Servers)
@@ -381,6 +406,34 @@ func EffectiveOperationServerBasePaths(doc *v3.Document, pathItem *v3.PathItem,
 	return ServerBasePaths(doc)
 }
 
+// EffectiveOperationServerURLs returns the server URLs of an operation, with variables replaced by their defaults.
+// Operation servers replace path servers, which replace document servers.
+func EffectiveOperationServerURLs(doc *v3.Document, pathItem *v3.PathItem, operation *v3.Operation) []string {
+	if doc == nil {
+		return nil
+	}
+	servers := doc.Servers
+	if pathItem != nil && len(pathItem.Servers) > 0 {
+		servers = pathItem.Servers
+	}
+	if operation != nil && len(operation.Servers) > 0 {
+		servers = operation.Servers
+	}
+	var urls []string
+	for _, server := range servers {
+		if server == nil || strings.TrimSpace(server.URL) == "" {
+			continue
+		}
+		urls = append(urls, resolveServerURL(server))
+	}
+	return urls
+}
+
+// ServerBasePath returns the base path of a server URL, as it is used to match routes.
+func ServerBasePath(serverURL string) string {
+	return serverPath(serverURL)
+}
+
 func serverBasePathsFromServers(servers []*v3.Server) []string {
 	if len(servers) == 0 {
 		return []string{""}
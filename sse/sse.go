@@ -0,0 +1,139 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+// Package sse reads and writes server-sent event streams (text/event-stream).
+package sse
+
+import (
+	"bytes"
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the media type of a server-sent event stream.
+const ContentType = "text/event-stream"
+
+// Event is a single server-sent event.
+type Event struct {
+	ID    string `json:"id,omitempty"`
+	Event string `json:"event,omitempty"`
+	Data  string `json:"data"`
+	Retry int    `json:"retry,omitempty"`
+}
+
+// IsEventStream checks if a content type is a server-sent event stream.
+func IsEventStream(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == ContentType
+}
+
+// Format renders an event in the wire format, multi-line data is split across data fields.
+func (e *Event) Format() []byte {
+	var b bytes.Buffer
+	if e.ID != "" {
+		b.WriteString("id: " + e.ID + "\n")
+	}
+	if e.Event != "" {
+		b.WriteString("event: " + e.Event + "\n")
+	}
+	if e.Retry > 0 {
+		b.WriteString("retry: " + strconv.Itoa(e.Retry) + "\n")
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(e.Data, "\r\n", "\n"), "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+	return b.Bytes()
+}
+
+// Parser reads events from a stream that arrives in chunks, following the event stream interpretation rules of
+// the HTML living standard. Lines end with CRLF, LF or CR, and a line (or an event) can span chunks.
+type Parser struct {
+	line    bytes.Buffer
+	skipLF  bool // the previous chunk ended with a CR, a LF at the start of the next chunk ends the same line.
+	event   Event
+	data    strings.Builder
+	hasData bool
+	// lastEventID is the id of the events that follow, until an id field changes it.
+	lastEventID string
+}
+
+// NewParser creates a parser for a new stream.
+func NewParser() *Parser {
+	return &Parser{}
+}
+
+// Feed parses the next chunk of the stream, and returns the events it completed.
+func (p *Parser) Feed(chunk []byte) []*Event {
+	var events []*Event
+	for _, c := range chunk {
+		if p.skipLF {
+			p.skipLF = false
+			if c == '\n' {
+				continue
+			}
+		}
+		switch c {
+		case '\r':
+			p.skipLF = true
+			fallthrough
+		case '\n':
+			if event := p.processLine(p.line.String()); event != nil {
+				events = append(events, event)
+			}
+			p.line.Reset()
+		default:
+			p.line.WriteByte(c)
+		}
+	}
+	return events
+}
+
+func (p *Parser) processLine(line string) *Event {
+	if line == "" {
+		return p.dispatch()
+	}
+	if strings.HasPrefix(line, ":") {
+		return nil // a comment, often sent to keep the connection alive.
+	}
+	field, value, found := strings.Cut(line, ":")
+	if found {
+		value = strings.TrimPrefix(value, " ")
+	}
+	switch field {
+	case "data":
+		if p.hasData {
+			p.data.WriteByte('\n')
+		}
+		p.data.WriteString(value)
+		p.hasData = true
+	case "event":
+		p.event.Event = value
+	case "id":
+		if !strings.ContainsRune(value, 0) {
+			p.lastEventID = value
+		}
+	case "retry":
+		if retry, err := strconv.Atoi(value); err == nil && retry >= 0 {
+			p.event.Retry = retry
+		}
+	}
+	return nil
+}
+
+// dispatch completes the current event, with the last event id, events without data are dropped.
+func (p *Parser) dispatch() *Event {
+	defer func() {
+		p.event = Event{}
+		p.data.Reset()
+		p.hasData = false
+	}()
+	if !p.hasData {
+		return nil
+	}
+	event := p.event
+	event.ID = p.lastEventID
+	event.Data = p.data.String()
+	return &event
+}
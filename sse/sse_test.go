@@ -0,0 +1,68 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package sse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParser(t *testing.T) {
+	p := NewParser()
+	events := p.Feed([]byte(": keep-alive\n\nid: 1\nevent: update\ndata: {\"a\":1}\n\ndata: line one\ndata: line two\nretry: 500\n\n"))
+	require.Len(t, events, 2)
+
+	assert.Equal(t, &Event{ID: "1", Event: "update", Data: `{"a":1}`}, events[0])
+	assert.Equal(t, &Event{ID: "1", Data: "line one\nline two", Retry: 500}, events[1])
+}
+
+func TestParser_LastEventIDPersists(t *testing.T) {
+	p := NewParser()
+	events := p.Feed([]byte("id: 42\ndata: first\n\ndata: second\n\n"))
+	require.Len(t, events, 2)
+	assert.Equal(t, "42", events[0].ID)
+	assert.Equal(t, "42", events[1].ID)
+
+	// an empty id clears it, and ids of dropped events still count.
+	events = p.Feed([]byte("id\ndata: third\n\nid: 43\n\ndata: fourth\n\n"))
+	require.Len(t, events, 2)
+	assert.Equal(t, "", events[0].ID)
+	assert.Equal(t, "43", events[1].ID)
+}
+
+func TestParser_EventsSpanChunks(t *testing.T) {
+	p := NewParser()
+	assert.Empty(t, p.Feed([]byte("data: hel")))
+	assert.Empty(t, p.Feed([]byte("lo\r")))
+	events := p.Feed([]byte("\n\r\n"))
+	require.Len(t, events, 1)
+	assert.Equal(t, "hello", events[0].Data)
+}
+
+func TestParser_CRLineEndings(t *testing.T) {
+	events := NewParser().Feed([]byte("data:no space\r\rdata\r\r"))
+	require.Len(t, events, 2)
+	assert.Equal(t, "no space", events[0].Data)
+	assert.Equal(t, "", events[1].Data)
+}
+
+func TestParser_EventsWithoutDataAreDropped(t *testing.T) {
+	assert.Empty(t, NewParser().Feed([]byte("event: ping\nid: 3\n\n")))
+}
+
+func TestEvent_Format(t *testing.T) {
+	event := &Event{ID: "7", Event: "update", Data: "one\ntwo", Retry: 100}
+	assert.Equal(t, "id: 7\nevent: update\nretry: 100\ndata: one\ndata: two\n\n", string(event.Format()))
+
+	parsed := NewParser().Feed(event.Format())
+	require.Len(t, parsed, 1)
+	assert.Equal(t, event, parsed[0])
+}
+
+func TestIsEventStream(t *testing.T) {
+	assert.True(t, IsEventStream("text/event-stream; charset=utf-8"))
+	assert.False(t, IsEventStream("application/json"))
+}
@@ -5,8 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/pb33f/wiretap/shared"
 )
@@ -32,15 +36,29 @@ func (sms *StaticMockService) getBodyFromMockDefinition(matchedMockDefinition St
 		UrlPath: request.URL.Path,
 		Host:    request.Host,
 	}
+	if identity := shared.RequestClientIdentity(request); identity != nil {
+		requestObjectWithIncomingRequestValues.ClientIdentity = identity.CommonName
+	}
 	if (request.Body != nil) && (request.Body != http.NoBody) {
-		contentType := request.Header.Get("Content-Type")
-		switch contentType {
-		case ContentTypeFormUrlEncoded:
+		mediaType := getMediaTypeFromHttpRequest(request)
+		switch {
+		case mediaType == ContentTypeFormUrlEncoded:
 			requestObjectWithIncomingRequestValues.Body = sms.getFormBodyFromHttpRequest(request)
-		case ContentTypeJson:
+		case mediaType == ContentTypeMultipartForm:
+			body, files := sms.getMultipartBodyFromHttpRequest(request)
+			requestObjectWithIncomingRequestValues.Body = body
+			if len(files) > 0 {
+				incomingFiles := valuesToInterfaceMap(files)
+				requestObjectWithIncomingRequestValues.Files = &incomingFiles
+			}
+		case isXmlMediaType(mediaType):
+			requestObjectWithIncomingRequestValues.Body = sms.getXmlBodyFromHttpRequest(request)
+		case mediaType == ContentTypeJson || strings.HasSuffix(mediaType, "+json"):
 			requestObjectWithIncomingRequestValues.Body = sms.getJsonBodyFromHttpRequest(request)
+		case strings.HasPrefix(mediaType, "text/"):
+			requestObjectWithIncomingRequestValues.Body = string(sms.readBodyFromHttpRequest(request))
 		default:
-			sms.logger.Error("Unsupported Content-Type", "contentType", contentType)
+			sms.logger.Error("Unsupported Content-Type", "contentType", request.Header.Get("Content-Type"))
 		}
 	}
 	queryParams := make(map[string]any)
@@ -77,22 +95,58 @@ func (sms *StaticMockService) getBodyFromMockDefinition(matchedMockDefinition St
 	return templateReplacedBodyStr
 }
 
+// getFileFromMockDefinition reads the file referenced by bodyFilename in the matched static mock. Files are
+// returned byte for byte, no template replacement is performed, so any content type (images, PDFs, protobuf,
+// gzip archives) can be served. The returned content type is detected from the file extension, falling back to
+// sniffing the content.
+func (sms *StaticMockService) getFileFromMockDefinition(matchedMockDefinition StaticMockDefinition) ([]byte, string) {
+	bodyFilesDir := filepath.Join(sms.wiretapService.StaticMockDir, MockBodyFilesPath)
+	bodyFilePath := filepath.Join(bodyFilesDir, matchedMockDefinition.Response.BodyFilename)
+
+	// don't allow definitions to escape the body-files directory.
+	if rel, err := filepath.Rel(bodyFilesDir, bodyFilePath); err != nil || strings.HasPrefix(rel, "..") {
+		panic(fmt.Errorf("bodyFilename '%s' is outside of the '%s' directory",
+			matchedMockDefinition.Response.BodyFilename, MockBodyFilesPath))
+	}
+
+	file, err := os.ReadFile(bodyFilePath)
+	if err != nil {
+		panic(err)
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(bodyFilePath))
+	if contentType == "" {
+		contentType = http.DetectContentType(file)
+	}
+	return file, contentType
+}
+
 // getHeadersFromMockDefinition returns headers from the matched static mock
-func (sms *StaticMockService) getHeadersFromMockDefinition(matchedMockDefinition StaticMockDefinition) http.Header {
+func (sms *StaticMockService) getHeadersFromMockDefinition(matchedMockDefinition StaticMockDefinition, contentType string) http.Header {
 	header := http.Header{}
 	// wiretap needs to work from anywhere, so allow everything.
 	headers := make(map[string][]string)
 	shared.SetCORSHeaders(headers)
-	headers["Content-Type"] = []string{"application/json"}
+	headers["Content-Type"] = []string{contentType}
 
 	// Add cors and content-type headers
 	for k, v := range headers {
-		header.Add(k, fmt.Sprint(v))
+		for _, j := range v {
+			header.Add(k, j)
+		}
 	}
 
-	// Add headers from mock definition JSON
+	// Add headers from mock definition JSON, these take precedence over the defaults.
 	for k, v := range matchedMockDefinition.Response.Header {
-		header.Add(k, fmt.Sprint(v))
+		switch hv := v.(type) {
+		case []interface{}:
+			header.Del(k)
+			for _, j := range hv {
+				header.Add(k, fmt.Sprint(j))
+			}
+		default:
+			header.Set(k, fmt.Sprint(hv))
+		}
 	}
 
 	return header
@@ -100,15 +154,24 @@ func (sms *StaticMockService) getHeadersFromMockDefinition(matchedMockDefinition
 
 // getStaticMockResponse returns response from the matched static mock
 func (sms *StaticMockService) getStaticMockResponse(matchedMockDefinition StaticMockDefinition, request *http.Request) *http.Response {
-	body := sms.getBodyFromMockDefinition(matchedMockDefinition, request)
+	var body []byte
+	contentType := ContentTypeJson
+
+	if matchedMockDefinition.Response.BodyFilename != "" {
+		body, contentType = sms.getFileFromMockDefinition(matchedMockDefinition)
+	} else {
+		body = []byte(sms.getBodyFromMockDefinition(matchedMockDefinition, request))
+	}
 
-	buff := bytes.NewBuffer([]byte(body))
+	buff := bytes.NewBuffer(body)
 
 	response := &http.Response{
-		StatusCode: matchedMockDefinition.Response.StatusCode,
-		Body:       io.NopCloser(buff),
+		StatusCode:    matchedMockDefinition.Response.StatusCode,
+		Body:          io.NopCloser(buff),
+		ContentLength: int64(len(body)),
 	}
-	response.Header = sms.getHeadersFromMockDefinition(matchedMockDefinition)
+	response.Header = sms.getHeadersFromMockDefinition(matchedMockDefinition, contentType)
+	response.Header.Set("Content-Length", strconv.Itoa(len(body)))
 
 	return response
 }
@@ -9,15 +9,22 @@ import (
 	"bytes"
 	"encoding/json"
 	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
 	"net/url"
+	"strings"
 
+	xj "github.com/basgys/goxml2json"
 	"github.com/pb33f/ranch/model"
 	"github.com/pb33f/wiretap/shared"
 )
 
-// getJsonBodyFromHttpRequest reads the body of the incoming request and returns it as a JSON interface{}
-func (sms *StaticMockService) getJsonBodyFromHttpRequest(request *http.Request) interface{} {
+// readBodyFromHttpRequest reads the body of the incoming request and restores it, so it can be read again
+func (sms *StaticMockService) readBodyFromHttpRequest(request *http.Request) []byte {
+	if request.Body == nil || request.Body == http.NoBody {
+		return nil
+	}
 	bodyBytes, err := io.ReadAll(request.Body)
 	if err != nil {
 		panic(err)
@@ -26,13 +33,35 @@ func (sms *StaticMockService) getJsonBodyFromHttpRequest(request *http.Request)
 	// Restore request.Body so it can be read again
 	request.Body = io.NopCloser(bytes.NewReader(bodyBytes))
 
+	return bodyBytes
+}
+
+// getMediaTypeFromHttpRequest returns the media type of the incoming request, without any parameters
+func getMediaTypeFromHttpRequest(request *http.Request) string {
+	contentType := request.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+	return mediaType
+}
+
+// isXmlMediaType checks if a media type carries an XML document
+func isXmlMediaType(mediaType string) bool {
+	return mediaType == ContentTypeXml || mediaType == ContentTypeTextXml || strings.HasSuffix(mediaType, "+xml")
+}
+
+// getJsonBodyFromHttpRequest reads the body of the incoming request and returns it as a JSON interface{}
+func (sms *StaticMockService) getJsonBodyFromHttpRequest(request *http.Request) interface{} {
+	bodyBytes := sms.readBodyFromHttpRequest(request)
+
 	var bodyJsonObj interface{}
 
 	if len(bodyBytes) == 0 {
 		return bodyJsonObj
 	}
 
-	err = json.Unmarshal(bodyBytes, &bodyJsonObj)
+	err := json.Unmarshal(bodyBytes, &bodyJsonObj)
 	if err != nil {
 		sms.logger.Error("Error decoding JSON of incoming request. JSON => \n%s", string(bodyBytes), err)
 		panic(err)
@@ -43,31 +72,90 @@ func (sms *StaticMockService) getJsonBodyFromHttpRequest(request *http.Request)
 
 // getFormBodyFromHttpRequest reads the body of the incoming request and returns it as a parsed form map
 func (sms *StaticMockService) getFormBodyFromHttpRequest(request *http.Request) interface{} {
-	bodyBytes, err := io.ReadAll(request.Body)
+	bodyBytes := sms.readBodyFromHttpRequest(request)
+
+	if len(bodyBytes) == 0 {
+		return nil
+	}
+
+	parsedForm, err := url.ParseQuery(string(bodyBytes))
 	if err != nil {
+		sms.logger.Error("Error parsing form-urlencoded body: %s", string(bodyBytes), err)
 		panic(err)
 	}
 
-	// Restore request.Body so it can be read again
-	request.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+	return valuesToInterfaceMap(parsedForm)
+}
+
+// getMultipartBodyFromHttpRequest reads the body of the incoming multipart request and returns the form
+// fields as a map, and the names of any uploaded files keyed by their form field.
+func (sms *StaticMockService) getMultipartBodyFromHttpRequest(request *http.Request) (interface{}, map[string][]string) {
+	bodyBytes := sms.readBodyFromHttpRequest(request)
 
 	if len(bodyBytes) == 0 {
-		return nil
+		return nil, nil
 	}
 
-	parsedForm, err := url.ParseQuery(string(bodyBytes))
+	_, params, err := mime.ParseMediaType(request.Header.Get("Content-Type"))
+	if err != nil || params["boundary"] == "" {
+		sms.logger.Error("Unable to read multipart boundary from Content-Type", "contentType", request.Header.Get("Content-Type"))
+		return nil, nil
+	}
+
+	reader := multipart.NewReader(bytes.NewReader(bodyBytes), params["boundary"])
+	form, err := reader.ReadForm(32 << 20)
 	if err != nil {
-		sms.logger.Error("Error parsing form-urlencoded body: %s", string(bodyBytes), err)
+		sms.logger.Error("Error parsing multipart body", "error", err)
 		panic(err)
 	}
+	defer func() {
+		_ = form.RemoveAll()
+	}()
+
+	files := make(map[string][]string)
+	for field, fileHeaders := range form.File {
+		for _, fh := range fileHeaders {
+			files[field] = append(files[field], fh.Filename)
+		}
+	}
+
+	return valuesToInterfaceMap(form.Value), files
+}
+
+// getXmlBodyFromHttpRequest reads the XML body of the incoming request and returns it as a JSON interface{},
+// with the root element as the top level key.
+func (sms *StaticMockService) getXmlBodyFromHttpRequest(request *http.Request) interface{} {
+	bodyBytes := sms.readBodyFromHttpRequest(request)
+
+	var bodyXmlObj interface{}
 
-	// Convert url.Values to map[string]interface{} for consistent handling
+	if len(bodyBytes) == 0 {
+		return bodyXmlObj
+	}
+
+	converted, err := xj.Convert(bytes.NewReader(bodyBytes))
+	if err != nil {
+		sms.logger.Error("Error decoding XML of incoming request", "body", string(bodyBytes), "error", err)
+		panic(err)
+	}
+
+	err = json.Unmarshal(converted.Bytes(), &bodyXmlObj)
+	if err != nil {
+		panic(err)
+	}
+
+	return bodyXmlObj
+}
+
+// valuesToInterfaceMap converts url.Values style maps to map[string]interface{} for consistent handling.
+// Single values are stored as a string, multiple values as a slice.
+func valuesToInterfaceMap(values map[string][]string) map[string]interface{} {
 	formMap := make(map[string]interface{})
-	for key, values := range parsedForm {
-		if len(values) == 1 {
-			formMap[key] = values[0]
+	for key, v := range values {
+		if len(v) == 1 {
+			formMap[key] = v[0]
 		} else {
-			formMap[key] = values
+			formMap[key] = v
 		}
 	}
 	return formMap
@@ -89,6 +177,31 @@ func (sms *StaticMockService) compareFormBody(mock StaticMockDefinitionRequest,
 	return shared.IsSubset(mock.Body, incomingBody)
 }
 
+// compareMultipartBody compares the multipart form fields of the incoming request with the mock definition
+func (sms *StaticMockService) compareMultipartBody(mock StaticMockDefinitionRequest, request *http.Request) bool {
+	incomingBody, _ := sms.getMultipartBodyFromHttpRequest(request)
+
+	// Check if the mock body is a subset of the incoming form fields
+	return shared.IsSubset(mock.Body, incomingBody)
+}
+
+// compareXmlBody compares the XML body of the incoming request with the mock definition
+func (sms *StaticMockService) compareXmlBody(mock StaticMockDefinitionRequest, request *http.Request) bool {
+	incomingBody := sms.getXmlBodyFromHttpRequest(request)
+
+	// Check if the mock body is a subset of the converted XML document
+	return shared.IsSubset(mock.Body, incomingBody)
+}
+
+// compareFiles compares the names of files uploaded in a multipart request with the mock definition
+func (sms *StaticMockService) compareFiles(mockFiles map[string]any, incoming *http.Request) bool {
+	if !strings.HasPrefix(getMediaTypeFromHttpRequest(incoming), ContentTypeMultipartForm) {
+		return false
+	}
+	_, incomingFiles := sms.getMultipartBodyFromHttpRequest(incoming)
+	return sms.compareValues(mockFiles, incomingFiles)
+}
+
 // transStrArrToInterfaceArr transforms a string array to an interface array (helper method)
 func (sms *StaticMockService) transStrArrToInterfaceArr(strArr []string) []interface{} {
 	strArrTransformedValues := make([]interface{}, 0)
@@ -116,14 +229,19 @@ func (sms *StaticMockService) compareHeaders(mockHeaders map[string]any, incomin
 
 // compareQueryParams compares the query parameters of the incoming request with the mock definition
 func (sms *StaticMockService) compareQueryParams(mockQueryParams map[string]any, incomingQueries url.Values) bool {
+	return sms.compareValues(mockQueryParams, incomingQueries)
+}
+
+// compareValues checks that every key in the mock definition has matching values in a multi-value map
+func (sms *StaticMockService) compareValues(mockValues map[string]any, incomingValues map[string][]string) bool {
 	found := true
-	// Check if all headers in mockHeaders are subset of incoming headers
-	for key, value := range mockQueryParams {
+	// Check if all values in mockValues are subset of incoming values
+	for key, value := range mockValues {
 		switch v := value.(type) {
 		case string:
-			found = found && shared.IsSubset([]interface{}{v}, sms.transStrArrToInterfaceArr(incomingQueries[key]))
+			found = found && shared.IsSubset([]interface{}{v}, sms.transStrArrToInterfaceArr(incomingValues[key]))
 		case []interface{}:
-			found = found && shared.IsSubset(value, sms.transStrArrToInterfaceArr(incomingQueries[key]))
+			found = found && shared.IsSubset(value, sms.transStrArrToInterfaceArr(incomingValues[key]))
 		}
 	}
 
@@ -133,28 +251,34 @@ func (sms *StaticMockService) compareQueryParams(mockQueryParams map[string]any,
 // compareBody compares the body of the incoming request with the mock definition
 func (sms *StaticMockService) compareBody(mock StaticMockDefinitionRequest, incoming *http.Request) bool {
 	switch mb := mock.Body.(type) {
-	case string: // Case string body
-		incomingBodyBytes, err := io.ReadAll(incoming.Body)
-		if err != nil {
-			panic(err)
-		}
+	case string: // Case string body (plain text, XML or any other raw body)
+		incomingBodyBytes := sms.readBodyFromHttpRequest(incoming)
 
-		if string(incomingBodyBytes) != string(mb) {
+		// string bodies are raw payloads, compared as they are: they often hold characters regexes treat as special.
+		if string(incomingBodyBytes) != mb {
 			return false
 		}
-	case map[string]interface{}: // Case JSON Object or Form body
-		contentType := incoming.Header.Get("Content-Type")
-		switch contentType {
-		case ContentTypeFormUrlEncoded:
+	case map[string]interface{}: // Case JSON Object, Form, Multipart or XML body
+		mediaType := getMediaTypeFromHttpRequest(incoming)
+		switch {
+		case mediaType == ContentTypeFormUrlEncoded:
 			if !sms.compareFormBody(mock, incoming) {
 				return false
 			}
-		case ContentTypeJson:
+		case mediaType == ContentTypeMultipartForm:
+			if !sms.compareMultipartBody(mock, incoming) {
+				return false
+			}
+		case isXmlMediaType(mediaType):
+			if !sms.compareXmlBody(mock, incoming) {
+				return false
+			}
+		case mediaType == ContentTypeJson || strings.HasSuffix(mediaType, "+json"):
 			if !sms.compareJsonBody(mock, incoming) {
 				return false
 			}
 		default:
-			sms.logger.Error("Unsupported Content-Type", "contentType", contentType)
+			sms.logger.Error("Unsupported Content-Type", "contentType", incoming.Header.Get("Content-Type"))
 			return false
 		}
 	case []interface{}: // Case JSON Array
@@ -169,6 +293,16 @@ func (sms *StaticMockService) compareBody(mock StaticMockDefinitionRequest, inco
 	return true
 }
 
+// compareClientIdentity compares the names of the client certificate of the incoming request with the mock definition
+func (sms *StaticMockService) compareClientIdentity(identity string, incoming *http.Request) bool {
+	for _, name := range shared.RequestClientIdentity(incoming).Names() {
+		if shared.StringCompare(identity, name) {
+			return true
+		}
+	}
+	return false
+}
+
 // isRequestMatch checks if the incoming request matches a mock definition
 func (sms *StaticMockService) isRequestMatch(mock StaticMockDefinitionRequest, incoming *http.Request) bool {
 	// Compare Host if defined
@@ -176,6 +310,11 @@ func (sms *StaticMockService) isRequestMatch(mock StaticMockDefinitionRequest, i
 		return false
 	}
 
+	// Compare the client certificate if defined
+	if mock.ClientIdentity != "" && !sms.compareClientIdentity(mock.ClientIdentity, incoming) {
+		return false
+	}
+
 	// Compare HTTP method
 	if incoming.Method != mock.Method {
 		return false
@@ -200,6 +339,13 @@ func (sms *StaticMockService) isRequestMatch(mock StaticMockDefinitionRequest, i
 		}
 	}
 
+	// Compare uploaded file names
+	if mock.Files != nil {
+		if !sms.compareFiles(*mock.Files, incoming) {
+			return false
+		}
+	}
+
 	// Compare body content
 	if mock.Body != nil {
 		if !sms.compareBody(mock, incoming) {
@@ -18,12 +18,16 @@ import (
 )
 
 const (
-	StaticMockServiceChan      = "static-mock-service"
-	IncomingHttpRequest        = "incoming-http-request"
-	MockDefinitionsPath        = "/mock-definitions"
-	MockBodyJsonsPath          = "/body-jsons/"
-	ContentTypeFormUrlEncoded  = "application/x-www-form-urlencoded"
-	ContentTypeJson            = "application/json"
+	StaticMockServiceChan     = "static-mock-service"
+	IncomingHttpRequest       = "incoming-http-request"
+	MockDefinitionsPath       = "/mock-definitions"
+	MockBodyJsonsPath         = "/body-jsons/"
+	MockBodyFilesPath         = "/body-files/"
+	ContentTypeFormUrlEncoded = "application/x-www-form-urlencoded"
+	ContentTypeJson           = "application/json"
+	ContentTypeMultipartForm  = "multipart/form-data"
+	ContentTypeXml            = "application/xml"
+	ContentTypeTextXml        = "text/xml"
 )
 
 type StaticMockDefinitionRequest struct {
@@ -32,7 +36,10 @@ type StaticMockDefinitionRequest struct {
 	Host        string          `json:"host,omitempty"`
 	Header      *map[string]any `json:"header,omitempty"`
 	Body        interface{}     `json:"body,omitempty"`
+	Files       *map[string]any `json:"files,omitempty"`
 	QueryParams *map[string]any `json:"queryParams,omitempty"`
+	// ClientIdentity matches a name of the client certificate: its common name, subject or a subject alternative name.
+	ClientIdentity string `json:"clientIdentity,omitempty"`
 }
 
 type StaticMockDefinitionResponse struct {
@@ -40,6 +47,7 @@ type StaticMockDefinitionResponse struct {
 	StatusCode       int            `json:"statusCode,omitempty"`
 	Body             string         `json:"body,omitempty"`
 	BodyJsonFilename string         `json:"bodyJsonFilename,omitempty"`
+	BodyFilename     string         `json:"bodyFilename,omitempty"`
 }
 
 type StaticMockDefinition struct {
@@ -0,0 +1,195 @@
+// Copyright 2026-2024 Princess Beef Heavy Industries, LLC / Dave Shanley
+// https://pb33f.io
+//
+// SPDX-License-Identifier: AGPL
+
+package staticMock
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pb33f/wiretap/daemon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStaticMockService(t *testing.T, definitions string) *StaticMockService {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, MockDefinitionsPath), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, MockBodyFilesPath), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, MockDefinitionsPath, "mocks.json"), []byte(definitions), 0o644))
+	return NewStaticMockService(&daemon.WiretapService{StaticMockDir: dir}, slog.Default())
+}
+
+func TestStaticMock_BodyFilename_ServesBinaryWithContentType(t *testing.T) {
+	sms := newTestStaticMockService(t, `[{
+		"request": {"method": "GET", "urlPath": "/logo"},
+		"response": {"statusCode": 200, "bodyFilename": "logo.png"}
+	}]`)
+	png := []byte{0x89, 'P', 'N', 'G', 0x0d, 0x0a, 0x1a, 0x0a, 0x00, 0xff}
+	require.NoError(t, os.WriteFile(filepath.Join(sms.wiretapService.StaticMockDir, MockBodyFilesPath, "logo.png"), png, 0o644))
+
+	req := httptest.NewRequest(http.MethodGet, "http://wiretap.local/logo", nil)
+	def := sms.checkStaticMockExists(req)
+	require.NotNil(t, def)
+
+	resp := sms.getStaticMockResponse(*def, req)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, png, body)
+	assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "10", resp.Header.Get("Content-Length"))
+	assert.Equal(t, int64(10), resp.ContentLength)
+}
+
+func TestStaticMock_BodyFilename_HeaderOverridesContentType(t *testing.T) {
+	sms := newTestStaticMockService(t, `[{
+		"request": {"method": "GET", "urlPath": "/data"},
+		"response": {"statusCode": 200, "bodyFilename": "data.bin", "header": {"Content-Type": "application/x-protobuf"}}
+	}]`)
+	require.NoError(t, os.WriteFile(filepath.Join(sms.wiretapService.StaticMockDir, MockBodyFilesPath, "data.bin"), []byte{0x08, 0x96, 0x01}, 0o644))
+
+	req := httptest.NewRequest(http.MethodGet, "http://wiretap.local/data", nil)
+	resp := sms.getStaticMockResponse(*sms.checkStaticMockExists(req), req)
+	assert.Equal(t, []string{"application/x-protobuf"}, resp.Header.Values("Content-Type"))
+	assert.Equal(t, "3", resp.Header.Get("Content-Length"))
+}
+
+func TestStaticMock_BodyFilename_CannotEscapeDirectory(t *testing.T) {
+	sms := newTestStaticMockService(t, `[{
+		"request": {"method": "GET", "urlPath": "/escape"},
+		"response": {"statusCode": 200, "bodyFilename": "../mock-definitions/mocks.json"}
+	}]`)
+	req := httptest.NewRequest(http.MethodGet, "http://wiretap.local/escape", nil)
+	assert.Panics(t, func() {
+		sms.getStaticMockResponse(*sms.checkStaticMockExists(req), req)
+	})
+}
+
+func TestStaticMock_MatchMultipartFieldsAndFiles(t *testing.T) {
+	sms := newTestStaticMockService(t, `[{
+		"request": {
+			"method": "POST",
+			"urlPath": "/upload",
+			"body": {"title": "quarterly.*"},
+			"files": {"document": ".*\\.pdf"}
+		},
+		"response": {"statusCode": 201, "body": "{\"title\": \"${body.title}\"}"}
+	}]`)
+
+	build := func(filename string) *http.Request {
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		_ = mw.WriteField("title", "quarterly report")
+		fw, _ := mw.CreateFormFile("document", filename)
+		_, _ = fw.Write([]byte("%PDF-1.4"))
+		_ = mw.Close()
+		req := httptest.NewRequest(http.MethodPost, "http://wiretap.local/upload", &buf)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		return req
+	}
+
+	req := build("report.pdf")
+	def := sms.checkStaticMockExists(req)
+	require.NotNil(t, def)
+	resp := sms.getStaticMockResponse(*def, req)
+	body, _ := io.ReadAll(resp.Body)
+	assert.JSONEq(t, `{"title": "quarterly report"}`, string(body))
+
+	assert.Nil(t, sms.checkStaticMockExists(build("report.docx")))
+}
+
+func TestStaticMock_MatchXmlBody(t *testing.T) {
+	sms := newTestStaticMockService(t, `[{
+		"request": {"method": "POST", "urlPath": "/orders", "body": {"order": {"sku": "ABC-.*"}}},
+		"response": {"statusCode": 200, "body": "ok"}
+	}]`)
+
+	req := httptest.NewRequest(http.MethodPost, "http://wiretap.local/orders",
+		strings.NewReader(`<order><sku>ABC-123</sku><qty>2</qty></order>`))
+	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	assert.NotNil(t, sms.checkStaticMockExists(req))
+
+	req = httptest.NewRequest(http.MethodPost, "http://wiretap.local/orders",
+		strings.NewReader(`<order><sku>XYZ-123</sku></order>`))
+	req.Header.Set("Content-Type", "text/xml")
+	assert.Nil(t, sms.checkStaticMockExists(req))
+}
+
+func TestStaticMock_MatchPlainTextBody(t *testing.T) {
+	sms := newTestStaticMockService(t, `[{
+		"request": {"method": "POST", "urlPath": "/echo", "body": "total: 4.50 (USD)?"},
+		"response": {"statusCode": 200, "body": "${body}"}
+	}]`)
+
+	req := httptest.NewRequest(http.MethodPost, "http://wiretap.local/echo", strings.NewReader("total: 4.50 (USD)?"))
+	req.Header.Set("Content-Type", "text/plain")
+	def := sms.checkStaticMockExists(req)
+	require.NotNil(t, def)
+
+	// body must still be readable after matching.
+	resp := sms.getStaticMockResponse(*def, req)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "total: 4.50 (USD)?", string(body))
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	// string bodies are not regexes.
+	for _, other := range []string{"total: 4x50 (USD)?", "total: 4.50 USD", "total: 4.50 (USD)? and more"} {
+		req = httptest.NewRequest(http.MethodPost, "http://wiretap.local/echo", strings.NewReader(other))
+		req.Header.Set("Content-Type", "text/plain")
+		assert.Nil(t, sms.checkStaticMockExists(req), other)
+	}
+}
+
+func TestStaticMock_MatchJsonBodyWithCharset(t *testing.T) {
+	sms := newTestStaticMockService(t, `[{
+		"request": {"method": "POST", "urlPath": "/json", "body": {"name": "wire.*"}},
+		"response": {"statusCode": 200, "body": "{}"}
+	}]`)
+
+	req := httptest.NewRequest(http.MethodPost, "http://wiretap.local/json", strings.NewReader(`{"name": "wiretap"}`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	assert.NotNil(t, sms.checkStaticMockExists(req))
+}
+
+func TestStaticMock_MatchClientIdentity(t *testing.T) {
+	sms := newTestStaticMockService(t, `[{
+		"request": {"method": "GET", "urlPath": "/invoices", "clientIdentity": "billing-.*"},
+		"response": {"statusCode": 200, "body": "${clientIdentity}"}
+	}]`)
+
+	build := func(cert *x509.Certificate) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "https://wiretap.local/invoices", nil)
+		if cert != nil {
+			req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+		}
+		return req
+	}
+
+	assert.Nil(t, sms.checkStaticMockExists(build(nil)))
+	assert.Nil(t, sms.checkStaticMockExists(build(&x509.Certificate{Subject: pkix.Name{CommonName: "orders-api"}})))
+
+	req := build(&x509.Certificate{Subject: pkix.Name{CommonName: "billing-api"}})
+	def := sms.checkStaticMockExists(req)
+	require.NotNil(t, def)
+	resp := sms.getStaticMockResponse(*def, req)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "billing-api", string(body))
+
+	// subject alternative names match too.
+	assert.NotNil(t, sms.checkStaticMockExists(build(&x509.Certificate{
+		Subject:  pkix.Name{CommonName: "svc"},
+		DNSNames: []string{"billing-worker.internal"},
+	})))
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/pb33f/wiretap/shared"
+	"github.com/pb33f/wiretap/sse"
 )
 
 type HttpCookie struct {
@@ -24,27 +25,132 @@ type HttpCookie struct {
 }
 
 type HttpRequest struct {
-	Timestamp       int64                  `json:"timestamp,omitempty"`
-	URL             string                 `json:"url,omitempty"`
-	Method          string                 `json:"method,omitempty"`
-	Host            string                 `json:"host,omitempty"`
-	Path            string                 `json:"path,omitempty"`
-	OriginalPath    string                 `json:"originalPath,omitempty"`
-	DroppedHeaders  []string               `json:"droppedHeaders,omitempty"`
-	InjectedHeaders map[string]string      `json:"injectedHeaders,omitempty"`
-	Query           string                 `json:"query,omitempty"`
-	Headers         map[string]any         `json:"headers,omitempty"`
-	Body            string                 `json:"requestBody,omitempty"`
-	Cookies         map[string]*HttpCookie `json:"cookies,omitempty"`
+	Timestamp         int64                  `json:"timestamp,omitempty"`
+	URL               string                 `json:"url,omitempty"`
+	Method            string                 `json:"method,omitempty"`
+	Host              string                 `json:"host,omitempty"`
+	Path              string                 `json:"path,omitempty"`
+	OriginalPath      string                 `json:"originalPath,omitempty"`
+	DroppedHeaders    []string               `json:"droppedHeaders,omitempty"`
+	InjectedHeaders   map[string]string      `json:"injectedHeaders,omitempty"`
+	Query             string                 `json:"query,omitempty"`
+	Headers           map[string]any         `json:"headers,omitempty"`
+	Body              string                 `json:"requestBody,omitempty"`
+	Cookies           map[string]*HttpCookie `json:"cookies,omitempty"`
+	ClientCertificate *shared.ClientIdentity `json:"clientCertificate,omitempty"`
 }
 
+// HttpResponse is a response captured by wiretap. The server-sent events of a streamed response are broadcast as they
+// arrive, each one with AppendEvents set, to append to the events and body of the response. DroppedEvents counts the
+// events that were validated, but not kept.
 type HttpResponse struct {
-	Timestamp  int64                  `json:"timestamp,omitempty"`
-	Headers    map[string]any         `json:"headers,omitempty"`
-	StatusCode int                    `json:"statusCode,omitempty"`
-	Body       string                 `json:"responseBody,omitempty"`
-	Cookies    map[string]*HttpCookie `json:"cookies,omitempty"`
-	Time       time.Time              `json:"-"`
+	Timestamp     int64                  `json:"timestamp,omitempty"`
+	Headers       map[string]any         `json:"headers,omitempty"`
+	StatusCode    int                    `json:"statusCode,omitempty"`
+	Body          string                 `json:"responseBody,omitempty"`
+	Cookies       map[string]*HttpCookie `json:"cookies,omitempty"`
+	Events        []*ResponseEvent       `json:"events,omitempty"`
+	AppendEvents  bool                   `json:"appendEvents,omitempty"`
+	DroppedEvents int                    `json:"droppedEvents,omitempty"`
+	Time          time.Time              `json:"-"`
+}
+
+// ResponseEvent is a server-sent event received in a response, and the result of validating it.
+type ResponseEvent struct {
+	sse.Event
+	Timestamp  int64                            `json:"timestamp,omitempty"`
+	Validation []*shared.WiretapValidationError `json:"validation,omitempty"`
+}
+
+// WebsocketSession is the websocket a request was upgraded to, and the frames captured on it. DroppedFrames counts
+// the frames that are not kept, the oldest of long sessions and any arriving faster than they could be recorded.
+type WebsocketSession struct {
+	Channel       string            `json:"channel,omitempty"`
+	Frames        []*WebsocketFrame `json:"frames,omitempty"`
+	CloseCode     int               `json:"closeCode,omitempty"`
+	DroppedFrames int               `json:"droppedFrames,omitempty"`
+}
+
+// WebsocketFrame is a websocket frame captured by the proxy, and the result of validating it. The payload of data
+// frames is kept up to the maximum validated body size of the session.
+type WebsocketFrame struct {
+	Direction  string                           `json:"direction"`
+	Opcode     int                              `json:"opcode"`
+	Size       int                              `json:"size"`
+	Timestamp  int64                            `json:"timestamp"`
+	Payload    string                           `json:"payload,omitempty"`
+	Message    string                           `json:"message,omitempty"`
+	Validation []*shared.WiretapValidationError `json:"validation,omitempty"`
+}
+
+// WebsocketFrame directions.
+const (
+	// FrameFromClient is a frame sent by the client to the upstream server.
+	FrameFromClient = "client"
+	// FrameFromServer is a frame sent by the upstream server to the client.
+	FrameFromServer = "server"
+)
+
+// MirrorResult is the response of the shadow upstream a request was mirrored to, how it differs from the primary
+// response, and the result of validating it. Skipped holds why a request was not mirrored, like a primary response
+// streamed to the client with no whole body to compare.
+type MirrorResult struct {
+	URL                string                           `json:"url"`
+	StatusCode         int                              `json:"statusCode,omitempty"`
+	Error              string                           `json:"error,omitempty"`
+	Skipped            string                           `json:"skipped,omitempty"`
+	Differences        []*MirrorDifference              `json:"differences,omitempty"`
+	ResponseValidation []*shared.WiretapValidationError `json:"responseValidation,omitempty"`
+}
+
+// MirrorDifference is one difference between a primary and a shadow response. Location is the header name or the
+// path in the JSON body that differs.
+type MirrorDifference struct {
+	Kind     string `json:"kind"`
+	Location string `json:"location,omitempty"`
+	Primary  string `json:"primary,omitempty"`
+	Shadow   string `json:"shadow,omitempty"`
+}
+
+// MirrorDifference kinds.
+const (
+	MirrorDifferenceStatus = "status"
+	MirrorDifferenceHeader = "header"
+	MirrorDifferenceBody   = "body"
+)
+
+// ResilienceRecord is how a request was sent upstream under a resilience policy: the attempts made, why each failed
+// attempt failed, the state of the circuit breaker of the upstream, and the fallback used when the circuit was open.
+type ResilienceRecord struct {
+	Attempts int      `json:"attempts"`
+	Failures []string `json:"failures,omitempty"`
+	Breaker  string   `json:"breaker,omitempty"`
+	Fallback string   `json:"fallback,omitempty"`
+}
+
+// ResilienceRecord circuit breaker states.
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half-open"
+)
+
+// TransformRecord is what the transforms of a path did to the bodies of a request and its response. The transaction
+// keeps the bodies the client sent and received, the record keeps the bodies the upstream received and responded with.
+type TransformRecord struct {
+	UpstreamRequestBody  string   `json:"upstreamRequestBody,omitempty"`
+	UpstreamResponseBody string   `json:"upstreamResponseBody,omitempty"`
+	Errors               []string `json:"errors,omitempty"`
+}
+
+// TokenRecord is a JSON Web Token sent with a request, decoded, and whether its signature was verified. Errors holds
+// why the token could not be decoded, verified, or is not valid at the time of the request.
+type TokenRecord struct {
+	Source   string         `json:"source"`
+	Header   map[string]any `json:"header,omitempty"`
+	Claims   map[string]any `json:"claims,omitempty"`
+	Verified bool           `json:"verified,omitempty"`
+	Errors   []string       `json:"errors,omitempty"`
 }
 
 type SpecConflict struct {
@@ -57,14 +163,30 @@ type SpecConflict struct {
 }
 
 type HttpTransaction struct {
-	Request            *HttpRequest                     `json:"httpRequest,omitempty"`
-	RequestValidation  []*shared.WiretapValidationError `json:"requestValidation,omitempty"`
-	Response           *HttpResponse                    `json:"httpResponse,omitempty"`
-	ResponseValidation []*shared.WiretapValidationError `json:"responseValidation,omitempty"`
-	SpecConflict       *SpecConflict                    `json:"specConflict,omitempty"`
-	Id                 string                           `json:"id,omitempty"`
+	Request                   *HttpRequest                     `json:"httpRequest,omitempty"`
+	RequestValidation         []*shared.WiretapValidationError `json:"requestValidation,omitempty"`
+	Response                  *HttpResponse                    `json:"httpResponse,omitempty"`
+	ResponseValidation        []*shared.WiretapValidationError `json:"responseValidation,omitempty"`
+	ResponseValidationSkipped string                           `json:"responseValidationSkipped,omitempty"`
+	SpecConflict              *SpecConflict                    `json:"specConflict,omitempty"`
+	Websocket                 *WebsocketSession                `json:"websocket,omitempty"`
+	Mirror                    *MirrorResult                    `json:"mirror,omitempty"`
+	Resilience                *ResilienceRecord                `json:"resilience,omitempty"`
+	MockFallback              string                           `json:"mockFallback,omitempty"`
+	Transforms                *TransformRecord                 `json:"transforms,omitempty"`
+	Tokens                    []*TokenRecord                   `json:"tokens,omitempty"`
+	ScriptErrors              []string                         `json:"scriptErrors,omitempty"`
+	Id                        string                           `json:"id,omitempty"`
 }
 
+// ResponseValidationSkipped markers explain why a response streamed to the client was not validated.
+const (
+	// ValidationSkippedTooLarge marks a response larger than the configured maximum validated body size.
+	ValidationSkippedTooLarge = "skipped: too large"
+	// ValidationSkippedStreaming marks a streaming response (like server-sent events or NDJSON).
+	ValidationSkippedStreaming = "skipped: streaming"
+)
+
 type FormPart struct {
 	Name  string      `json:"name,omitempty"`
 	Value []string    `json:"value,omitempty"`
@@ -0,0 +1,416 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+// Package transform rewrites JSON bodies with JSON Patch (RFC 6902) operations, and with JSONPath set and remove
+// operations. Bodies are parsed into YAML nodes, so the order of object keys and the text of numbers survive a
+// transform unchanged.
+package transform
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/jsonpath/pkg/jsonpath"
+	"github.com/pb33f/jsonpath/pkg/jsonpath/config"
+	"github.com/pb33f/wiretap/shared"
+	"go.yaml.in/yaml/v4"
+)
+
+// Transform operations.
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+	OpMove    = "move"
+	OpCopy    = "copy"
+	OpTest    = "test"
+	// OpSet replaces every value matching a JSONPath.
+	OpSet = "set"
+)
+
+// ErrNotJSON is returned when a body that is not JSON is transformed.
+var ErrNotJSON = errors.New("body is not JSON")
+
+// Apply applies transforms to a JSON body in order, and returns the transformed body. Like a JSON Patch, transforms
+// are applied all or nothing: when one fails, its error is returned and the body is left unchanged. Empty bodies are
+// never transformed.
+func Apply(body []byte, transforms []*shared.WiretapTransform) ([]byte, error) {
+	if len(transforms) == 0 || len(bytes.TrimSpace(body)) == 0 {
+		return body, nil
+	}
+	document, err := parse(body)
+	if err != nil {
+		return body, err
+	}
+
+	for i, transform := range transforms {
+		if err := apply(document, transform); err != nil {
+			return body, fmt.Errorf("transform %d (%s): %w", i+1, transform.Op, err)
+		}
+	}
+
+	var transformed bytes.Buffer
+	if err := writeJSON(&transformed, document.Content[0]); err != nil {
+		return body, err
+	}
+	return transformed.Bytes(), nil
+}
+
+// Parse parses a JSON body into a node, keeping the order of object keys and the text of numbers.
+func Parse(body []byte) (*yaml.Node, error) {
+	document, err := parse(body)
+	if err != nil {
+		return nil, err
+	}
+	return document.Content[0], nil
+}
+
+// Marshal writes a node parsed by Parse as compact JSON.
+func Marshal(node *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, node); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Remove removes values from a node parsed by Parse, along with their keys when they are object members.
+func Remove(root *yaml.Node, values []*yaml.Node) {
+	parents := make(map[*yaml.Node]*yaml.Node)
+	indexParents(root, parents)
+	for _, value := range values {
+		removeChild(parents[value], value)
+	}
+}
+
+func parse(body []byte) (*yaml.Node, error) {
+	if !json.Valid(body) {
+		return nil, ErrNotJSON
+	}
+	var document yaml.Node
+	if err := yaml.Unmarshal(body, &document); err != nil {
+		return nil, fmt.Errorf("unable to parse body: %w", err)
+	}
+	return &document, nil
+}
+
+func apply(document *yaml.Node, transform *shared.WiretapTransform) error {
+	if transform.JSONPath != "" {
+		return applyJSONPath(document.Content[0], transform)
+	}
+
+	switch transform.Op {
+	case OpAdd:
+		value, err := valueNode(transform.Value)
+		if err != nil {
+			return err
+		}
+		return add(document, transform.Path, value)
+	case OpReplace:
+		value, err := valueNode(transform.Value)
+		if err != nil {
+			return err
+		}
+		if _, err = find(document, transform.Path); err != nil {
+			return err
+		}
+		return add(document, transform.Path, value)
+	case OpTest:
+		value, err := valueNode(transform.Value)
+		if err != nil {
+			return err
+		}
+		target, err := find(document, transform.Path)
+		if err != nil {
+			return err
+		}
+		if !equal(target, value) {
+			return fmt.Errorf("value at '%s' is not the tested value", transform.Path)
+		}
+		return nil
+	case OpRemove:
+		_, err := remove(document, transform.Path)
+		return err
+	case OpMove:
+		if strings.HasPrefix(transform.Path, transform.From+"/") {
+			return fmt.Errorf("cannot move '%s' into itself", transform.From)
+		}
+		value, err := remove(document, transform.From)
+		if err != nil {
+			return err
+		}
+		return add(document, transform.Path, value)
+	case OpCopy:
+		value, err := find(document, transform.From)
+		if err != nil {
+			return err
+		}
+		return add(document, transform.Path, clone(value))
+	default:
+		return fmt.Errorf("unknown operation '%s'", transform.Op)
+	}
+}
+
+// applyJSONPath sets or removes every value matching the JSONPath of a transform. A JSONPath matching nothing
+// changes nothing.
+func applyJSONPath(root *yaml.Node, transform *shared.WiretapTransform) error {
+	path, err := jsonpath.NewPath(transform.JSONPath, config.WithPropertyNameExtension())
+	if err != nil {
+		return fmt.Errorf("invalid JSONPath '%s': %w", transform.JSONPath, err)
+	}
+	matches := path.Query(root)
+
+	switch transform.Op {
+	case OpSet:
+		value, err := valueNode(transform.Value)
+		if err != nil {
+			return err
+		}
+		for _, match := range matches {
+			*match = *clone(value)
+		}
+		return nil
+	case OpRemove:
+		Remove(root, matches)
+		return nil
+	default:
+		return fmt.Errorf("operation '%s' cannot be used with a JSONPath, only '%s' and '%s'", transform.Op, OpSet, OpRemove)
+	}
+}
+
+func indexParents(node *yaml.Node, parents map[*yaml.Node]*yaml.Node) {
+	for _, c := range node.Content {
+		parents[c] = node
+		indexParents(c, parents)
+	}
+}
+
+// removeChild removes a value from its parent, along with its key when the parent is an object.
+func removeChild(parent, value *yaml.Node) {
+	if parent == nil {
+		return
+	}
+	for i, c := range parent.Content {
+		if c != value {
+			continue
+		}
+		switch {
+		case parent.Kind == yaml.SequenceNode:
+			parent.Content = append(parent.Content[:i], parent.Content[i+1:]...)
+		case i%2 == 1:
+			parent.Content = append(parent.Content[:i-1], parent.Content[i+1:]...)
+		default:
+			parent.Content = append(parent.Content[:i], parent.Content[i+2:]...)
+		}
+		return
+	}
+}
+
+// pointerTokens splits a JSON Pointer (RFC 6901) into its unescaped reference tokens.
+func pointerTokens(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer '%s', it must start with '/'", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// locate returns the container holding the value a JSON Pointer refers to, and the last token of the pointer. The
+// container is the document node for the empty pointer.
+func locate(document *yaml.Node, pointer string) (*yaml.Node, string, error) {
+	tokens, err := pointerTokens(pointer)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(tokens) == 0 {
+		return document, "", nil
+	}
+	container := document.Content[0]
+	for i, token := range tokens[:len(tokens)-1] {
+		container = child(container, token)
+		if container == nil {
+			return nil, "", fmt.Errorf("path '%s' does not exist", "/"+strings.Join(tokens[:i+1], "/"))
+		}
+	}
+	return container, tokens[len(tokens)-1], nil
+}
+
+// child returns the value of an object member or array element, or nil if there is none.
+func child(container *yaml.Node, token string) *yaml.Node {
+	switch container.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(container.Content); i += 2 {
+			if container.Content[i].Value == token {
+				return container.Content[i+1]
+			}
+		}
+	case yaml.SequenceNode:
+		if index, err := arrayIndex(token, len(container.Content)-1); err == nil {
+			return container.Content[index]
+		}
+	}
+	return nil
+}
+
+// arrayIndex parses an array index of a JSON Pointer, that is at most limit.
+func arrayIndex(token string, limit int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index '%s'", token)
+	}
+	if index > limit {
+		return 0, fmt.Errorf("array index %d is out of bounds", index)
+	}
+	return index, nil
+}
+
+func find(document *yaml.Node, pointer string) (*yaml.Node, error) {
+	container, token, err := locate(document, pointer)
+	if err != nil {
+		return nil, err
+	}
+	if container == document {
+		return document.Content[0], nil
+	}
+	value := child(container, token)
+	if value == nil {
+		return nil, fmt.Errorf("path '%s' does not exist", pointer)
+	}
+	return value, nil
+}
+
+// add sets an object member, inserts an array element ('-' appends), or replaces the whole document.
+func add(document *yaml.Node, pointer string, value *yaml.Node) error {
+	container, token, err := locate(document, pointer)
+	if err != nil {
+		return err
+	}
+	switch {
+	case container == document:
+		document.Content[0] = value
+	case container.Kind == yaml.MappingNode:
+		if existing := child(container, token); existing != nil {
+			*existing = *value
+			return nil
+		}
+		container.Content = append(container.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: token}, value)
+	case container.Kind == yaml.SequenceNode:
+		index := len(container.Content)
+		if token != "-" {
+			if index, err = arrayIndex(token, len(container.Content)); err != nil {
+				return err
+			}
+		}
+		container.Content = append(container.Content[:index], append([]*yaml.Node{value}, container.Content[index:]...)...)
+	default:
+		return fmt.Errorf("path '%s' is not inside an object or array", pointer)
+	}
+	return nil
+}
+
+// remove removes and returns the value a JSON Pointer refers to.
+func remove(document *yaml.Node, pointer string) (*yaml.Node, error) {
+	container, _, err := locate(document, pointer)
+	if err != nil {
+		return nil, err
+	}
+	if container == document {
+		return nil, errors.New("cannot remove the whole document")
+	}
+	value, err := find(document, pointer)
+	if err != nil {
+		return nil, err
+	}
+	removeChild(container, value)
+	return value, nil
+}
+
+// valueNode converts the value of a transform to a node, through JSON, so it is tagged the way a parsed body is.
+func valueNode(value any) (*yaml.Node, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value: %w", err)
+	}
+	var document yaml.Node
+	if err = yaml.Unmarshal(encoded, &document); err != nil {
+		return nil, fmt.Errorf("invalid value: %w", err)
+	}
+	return document.Content[0], nil
+}
+
+func clone(node *yaml.Node) *yaml.Node {
+	cloned := *node
+	cloned.Content = make([]*yaml.Node, len(node.Content))
+	for i, c := range node.Content {
+		cloned.Content[i] = clone(c)
+	}
+	return &cloned
+}
+
+// equal compares two nodes as JSON values, so 1 and 1.0 are equal.
+func equal(a, b *yaml.Node) bool {
+	var encodedA, encodedB bytes.Buffer
+	if writeJSON(&encodedA, a) != nil || writeJSON(&encodedB, b) != nil {
+		return false
+	}
+	var valueA, valueB any
+	if json.Unmarshal(encodedA.Bytes(), &valueA) != nil || json.Unmarshal(encodedB.Bytes(), &valueB) != nil {
+		return false
+	}
+	return reflect.DeepEqual(valueA, valueB)
+}
+
+// writeJSON writes a node as compact JSON.
+func writeJSON(buf *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, _ := json.Marshal(node.Content[i].Value)
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err := writeJSON(buf, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, element := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSON(buf, element); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case yaml.ScalarNode:
+		switch node.ShortTag() {
+		case "!!null":
+			buf.WriteString("null")
+		case "!!bool", "!!int", "!!float":
+			buf.WriteString(node.Value)
+		default:
+			value, _ := json.Marshal(node.Value)
+			buf.Write(value)
+		}
+	default:
+		return fmt.Errorf("unable to write a YAML node of kind %d as JSON", node.Kind)
+	}
+	return nil
+}
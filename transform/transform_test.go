@@ -0,0 +1,81 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package transform
+
+import (
+	"testing"
+
+	"github.com/pb33f/wiretap/shared"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyJSONPatch(t *testing.T) {
+	body := []byte(`{"id": 1, "price": 10.50, "tags": ["a", "b"], "legacy": {"code": "x"}, "a/b": true}`)
+
+	transformed, err := Apply(body, []*shared.WiretapTransform{
+		{Op: OpTest, Path: "/id", Value: 1.0},
+		{Op: OpRemove, Path: "/a~1b"},
+		{Op: OpAdd, Path: "/tags/-", Value: "c"},
+		{Op: OpAdd, Path: "/tags/0", Value: "z"},
+		{Op: OpReplace, Path: "/id", Value: "one"},
+		{Op: OpMove, From: "/legacy/code", Path: "/code"},
+		{Op: OpCopy, From: "/tags", Path: "/legacy/tags"},
+		{Op: OpAdd, Path: "/currency", Value: map[string]any{"code": "USD"}},
+	})
+	require.NoError(t, err)
+	// key order and number text are kept.
+	assert.Equal(t, `{"id":"one","price":10.50,"tags":["z","a","b","c"],"legacy":{"tags":["z","a","b","c"]},`+
+		`"code":"x","currency":{"code":"USD"}}`, string(transformed))
+
+	// the whole document can be replaced.
+	transformed, err = Apply(body, []*shared.WiretapTransform{{Op: OpReplace, Path: "", Value: []int{1, 2}}})
+	require.NoError(t, err)
+	assert.Equal(t, `[1,2]`, string(transformed))
+}
+
+func TestApplyJSONPatchErrorsLeaveTheBodyUnchanged(t *testing.T) {
+	body := []byte(`{"id":1,"tags":["a"]}`)
+
+	for name, transform := range map[string]*shared.WiretapTransform{
+		"transform 2 (test): value at '/id' is not the tested value":           {Op: OpTest, Path: "/id", Value: 2},
+		"transform 2 (remove): path '/missing' does not exist":                 {Op: OpRemove, Path: "/missing"},
+		"transform 2 (replace): path '/missing' does not exist":                {Op: OpReplace, Path: "/missing", Value: 1},
+		"transform 2 (add): array index 5 is out of bounds":                    {Op: OpAdd, Path: "/tags/5", Value: 1},
+		"transform 2 (add): path '/nope' does not exist":                       {Op: OpAdd, Path: "/nope/deeper", Value: 1},
+		"transform 2 (add): invalid JSON pointer 'id', it must start with '/'": {Op: OpAdd, Path: "id"},
+		"transform 2 (move): cannot move '/tags' into itself":                  {Op: OpMove, From: "/tags", Path: "/tags/0"},
+		"transform 2 (patch): unknown operation 'patch'":                       {Op: "patch", Path: "/id"},
+	} {
+		transformed, err := Apply(body, []*shared.WiretapTransform{{Op: OpTest, Path: "/id", Value: 1}, transform})
+		assert.EqualError(t, err, name)
+		assert.Equal(t, body, transformed)
+	}
+
+	transformed, err := Apply([]byte(`<xml/>`), []*shared.WiretapTransform{{Op: OpRemove, Path: "/id"}})
+	assert.ErrorIs(t, err, ErrNotJSON)
+	assert.Equal(t, `<xml/>`, string(transformed))
+
+	// empty bodies are left alone.
+	transformed, err = Apply(nil, []*shared.WiretapTransform{{Op: OpRemove, Path: "/id"}})
+	assert.NoError(t, err)
+	assert.Nil(t, transformed)
+}
+
+func TestApplyJSONPath(t *testing.T) {
+	body := []byte(`{"items":[{"id":1,"secret":"a","status":null},{"id":2,"secret":"b","status":"sold"}],"total":2}`)
+
+	transformed, err := Apply(body, []*shared.WiretapTransform{
+		{Op: OpRemove, JSONPath: "$.items[*].secret"},
+		{Op: OpSet, JSONPath: "$.items[?(@.status == null)].status", Value: "available"},
+		{Op: OpSet, JSONPath: "$.missing", Value: 1},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `{"items":[{"id":1,"status":"available"},{"id":2,"status":"sold"}],"total":2}`, string(transformed))
+
+	_, err = Apply(body, []*shared.WiretapTransform{{Op: OpCopy, JSONPath: "$.total"}})
+	assert.EqualError(t, err, "transform 1 (copy): operation 'copy' cannot be used with a JSONPath, only 'set' and 'remove'")
+	_, err = Apply(body, []*shared.WiretapTransform{{Op: OpRemove, JSONPath: "$.items[?"}})
+	assert.ErrorContains(t, err, "invalid JSONPath '$.items[?'")
+}
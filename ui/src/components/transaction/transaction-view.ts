@@ -46,6 +46,7 @@ export class HttpTransactionViewComponent extends LitElement {
     private readonly _responseCookiesView: KVViewComponent;
     private readonly _requestQueryView: KVViewComponent;
     private readonly _injectedHeadersView: KVViewComponent;
+    private readonly _clientCertificateView: KVViewComponent;
     private readonly _originalDetailsView: KVViewComponent;
 
     private _linkCache: TransactionLinkCache;
@@ -71,6 +72,9 @@ export class HttpTransactionViewComponent extends LitElement {
         this._injectedHeadersView = new KVViewComponent();
         this._injectedHeadersView.keyLabel = 'Injected Header';
         this._injectedHeadersView.valueLabel = 'Injected Value';
+        this._clientCertificateView = new KVViewComponent();
+        this._clientCertificateView.keyLabel = 'Certificate';
+        this._clientCertificateView.valueLabel = 'Identity';
         this._originalDetailsView = new KVViewComponent();
         this._originalDetailsView.keyLabel = 'Detail';
         this._originalDetailsView.valueLabel = 'Mutated Value';
@@ -101,6 +105,13 @@ export class HttpTransactionViewComponent extends LitElement {
                     this._injectedHeadersView.data = new Map(Object.entries(value.httpRequest?.injectedHeaders));
                 }
 
+                const cert = value.httpRequest.clientCertificate;
+                this._clientCertificateView.data = cert ? new Map([
+                    ['Subject', cert.subject],
+                    ['Common Name', cert.commonName],
+                    ['Alternative Names', cert.san?.join(', ')],
+                ]) : null;
+
                 // if there are original details.
                 if (value.httpRequest.originalPath != value.httpRequest.path) {
                     this._originalDetailsView.data = new Map([
@@ -156,7 +167,11 @@ export class HttpTransactionViewComponent extends LitElement {
                     return html`
                         <wiretap-violation-view .violation="${i}"></wiretap-violation-view>
                     `
-                })}`;
+                })}
+                ${this._httpTransaction?.responseValidationSkipped ?
+                        html`<p>Response validation ${this._httpTransaction.responseValidationSkipped}</p>` : html``}
+                ${this._httpTransaction?.mockFallback ?
+                        html`<p>Response mocked from the specification, the upstream failed: ${this._httpTransaction.mockFallback}</p>` : html``}`;
 
 
             let total = 0;
@@ -244,8 +259,26 @@ export class HttpTransactionViewComponent extends LitElement {
                 <sl-tab-group id="tabs" @sl-tab-show=${this.tabSelected}>
                     <sl-tab slot="nav" panel="violations" id="violation-tab" class="tab">${violations}</sl-tab>
                     <sl-tab slot="nav" panel="request" class="tab">Request</sl-tab>
-                    <sl-tab slot="nav" panel="response" class="tab">Response ${(resp?.statusCode>=400) ? html`
+                    <sl-tab slot="nav" panel="response" class="tab">Response ${this._httpTransaction.mockFallback ? html`
+                        <sl-badge variant="neutral" class="violation-badge">mock</sl-badge>` : null}${(resp?.statusCode>=400) ? html`
                         <sl-badge variant="${(resp?.statusCode>=400 && resp?.statusCode < 500) ? 'warning' : 'danger'}" class="violation-badge">&nbsp;</sl-badge>` : null}</sl-tab>
+                    ${this._httpTransaction.websocket ? html`
+                        <sl-tab slot="nav" panel="frames" class="tab">Frames</sl-tab>` : null}
+                    ${this._httpTransaction.mirror ? html`
+                        <sl-tab slot="nav" panel="mirror" class="tab">Mirror ${this._httpTransaction.mirror.differences?.length > 0 ? html`
+                            <sl-badge variant="warning" class="violation-badge">${this._httpTransaction.mirror.differences.length}</sl-badge>` : null}</sl-tab>` : null}
+                    ${this._httpTransaction.resilience ? html`
+                        <sl-tab slot="nav" panel="resilience" class="tab">Resilience ${this._httpTransaction.resilience.failures?.length > 0 ? html`
+                            <sl-badge variant="warning" class="violation-badge">${this._httpTransaction.resilience.failures.length}</sl-badge>` : null}</sl-tab>` : null}
+                    ${this._httpTransaction.transforms ? html`
+                        <sl-tab slot="nav" panel="transforms" class="tab">Transforms ${this._httpTransaction.transforms.errors?.length > 0 ? html`
+                            <sl-badge variant="warning" class="violation-badge">${this._httpTransaction.transforms.errors.length}</sl-badge>` : null}</sl-tab>` : null}
+                    ${this._httpTransaction.scriptErrors?.length > 0 ? html`
+                        <sl-tab slot="nav" panel="script" class="tab">Script
+                            <sl-badge variant="warning" class="violation-badge">${this._httpTransaction.scriptErrors.length}</sl-badge></sl-tab>` : null}
+                    ${this._httpTransaction.tokens?.length > 0 ? html`
+                        <sl-tab slot="nav" panel="tokens" class="tab">Tokens ${this.tokenErrorCount() > 0 ? html`
+                            <sl-badge variant="warning" class="violation-badge">${this.tokenErrorCount()}</sl-badge>` : null}</sl-tab>` : null}
                     ${this._currentLinks?.length > 0 ? html`
                         <sl-tab slot="nav" panel="chain" class="tab">Chain</sl-tab>` : null}
                     <sl-tab-panel name="violations" class="tab-panel">
@@ -264,6 +297,11 @@ export class HttpTransactionViewComponent extends LitElement {
                             <sl-tab slot="nav" panel="request-headers" class="tab-secondary">Headers</sl-tab>
                             <sl-tab slot="nav" panel="request-cookies" class="tab-secondary">Cookies</sl-tab>
                             ${originalTab}
+                            ${req.clientCertificate ? html`
+                                <sl-tab slot="nav" panel="request-client" class="tab-secondary">Client</sl-tab>` : null}
+                            <sl-tab-panel name="request-client">
+                                ${this._clientCertificateView}
+                            </sl-tab-panel>
                             <sl-tab-panel name="request-headers">
                                 ${this._requestHeadersView}
                             </sl-tab-panel>
@@ -313,6 +351,12 @@ export class HttpTransactionViewComponent extends LitElement {
                             </sl-tab-panel>
                         </sl-tab-group>
                     </sl-tab-panel>
+                    ${this._httpTransaction.websocket ? this.renderFramesTabPanel() : null}
+                    ${this._httpTransaction.mirror ? this.renderMirrorTabPanel() : null}
+                    ${this._httpTransaction.resilience ? this.renderResilienceTabPanel() : null}
+                    ${this._httpTransaction.transforms ? this.renderTransformsTabPanel() : null}
+                    ${this._httpTransaction.scriptErrors?.length > 0 ? this.renderScriptTabPanel() : null}
+                    ${this._httpTransaction.tokens?.length > 0 ? this.renderTokensTabPanel() : null}
                     ${this._currentLinks?.length > 0 ? this.renderChainTabPanel() : null}
                 </sl-tab-group>`
 
@@ -441,6 +485,131 @@ export class HttpTransactionViewComponent extends LitElement {
             </div>`
     }
 
+    renderFramesTabPanel(): TemplateResult {
+        const session = this._httpTransaction.websocket;
+        const opcodes: Record<number, string> = {1: 'text', 2: 'binary', 8: 'close', 9: 'ping', 10: 'pong'};
+        return html`
+            <sl-tab-panel name="frames">
+                ${session.channel ? html`<p>Channel <strong>${session.channel}</strong></p>` : null}
+                ${session.frames?.length > 0 ? html`
+                    <ul class="websocket-frames">
+                        ${session.frames.map((frame) => html`
+                            <li class="websocket-frame ${frame.direction}">
+                                <span class="frame-direction">${frame.direction == 'client' ? '→' : '←'}</span>
+                                <span class="frame-time">${new Date(frame.timestamp).toLocaleTimeString()}</span>
+                                <span class="frame-opcode">${opcodes[frame.opcode] ?? frame.opcode}</span>
+                                <span class="frame-size">${frame.size} bytes</span>
+                                ${frame.message ? html`<span class="frame-message">${frame.message}</span>` : null}
+                                ${frame.validation?.length > 0 ? html`
+                                    <sl-badge variant="danger" class="violation-badge">${frame.validation.length}</sl-badge>` : null}
+                                ${frame.payload ? html`<pre class="frame-payload">${frame.payload}</pre>` : null}
+                            </li>`)}
+                    </ul>` : html`<p>No frames have been captured yet.</p>`}
+                ${session.droppedFrames ? html`<p>${session.droppedFrames} frames were not kept</p>` : null}
+                ${session.closeCode ? html`<p>Closed with code ${session.closeCode}</p>` : null}
+            </sl-tab-panel>`
+    }
+
+    renderMirrorTabPanel(): TemplateResult {
+        const mirror = this._httpTransaction.mirror;
+        return html`
+            <sl-tab-panel name="mirror">
+                ${mirror.skipped ? html`<p>Not mirrored to <strong>${mirror.url}</strong>, the response was
+                    streamed (${mirror.skipped})</p>` : html`<p>Mirrored to <strong>${mirror.url}</strong>${mirror.statusCode ? html`,
+                    responded with <strong>${mirror.statusCode}</strong>` : null}</p>`}
+                ${mirror.error ? html`<p class="mirror-error">${mirror.error}</p>` : null}
+                ${mirror.differences?.length > 0 ? html`
+                    <table class="mirror-differences">
+                        <tr><th>Difference</th><th>Primary</th><th>Shadow</th></tr>
+                        ${mirror.differences.map((difference) => html`
+                            <tr>
+                                <td>${difference.kind}${difference.location ? html` <code>${difference.location}</code>` : null}</td>
+                                <td><code>${difference.primary}</code></td>
+                                <td><code>${difference.shadow}</code></td>
+                            </tr>`)}
+                    </table>` : (!mirror.error ? html`<p>The shadow response matches the primary response.</p>` : null)}
+                ${mirror.responseValidation?.length > 0 ? html`
+                    <p>The shadow response has ${mirror.responseValidation.length} contract violations:</p>
+                    <ul>
+                        ${mirror.responseValidation.map((violation) => html`<li>${violation.message}</li>`)}
+                    </ul>` : null}
+            </sl-tab-panel>`
+    }
+
+    renderResilienceTabPanel(): TemplateResult {
+        const resilience = this._httpTransaction.resilience;
+        return html`
+            <sl-tab-panel name="resilience">
+                ${resilience.fallback ? html`
+                    <p>The upstream circuit was <strong>open</strong>, the request was answered with a
+                        <strong>${resilience.fallback}</strong> fallback and not sent upstream.</p>` : html`
+                    <p>Sent upstream in <strong>${resilience.attempts}</strong> ${resilience.attempts === 1 ? 'attempt' : 'attempts'}${resilience.breaker ? html`,
+                        the circuit was <strong>${resilience.breaker}</strong>` : null}.</p>`}
+                ${resilience.failures?.length > 0 ? html`
+                    <ol class="resilience-failures">
+                        ${resilience.failures.map((failure) => html`<li>${failure}</li>`)}
+                    </ol>` : null}
+            </sl-tab-panel>`
+    }
+
+    renderTransformsTabPanel(): TemplateResult {
+        const transforms = this._httpTransaction.transforms;
+        return html`
+            <sl-tab-panel name="transforms">
+                ${transforms.upstreamRequestBody ? html`
+                    <h3>Request body</h3>
+                    <p>Sent by the client</p>
+                    <pre><code>${this._httpTransaction.httpRequest?.requestBody}</code></pre>
+                    <p>Sent upstream</p>
+                    <pre><code>${transforms.upstreamRequestBody}</code></pre>` : null}
+                ${transforms.upstreamResponseBody ? html`
+                    <h3>Response body</h3>
+                    <p>Responded by the upstream</p>
+                    <pre><code>${transforms.upstreamResponseBody}</code></pre>
+                    <p>Sent to the client</p>
+                    <pre><code>${this._httpTransaction.httpResponse?.responseBody}</code></pre>` : null}
+                ${transforms.errors?.length > 0 ? html`
+                    <p>These transforms failed, their bodies were passed on unchanged:</p>
+                    <ol class="resilience-failures">
+                        ${transforms.errors.map((error) => html`<li>${error}</li>`)}
+                    </ol>` : null}
+            </sl-tab-panel>`
+    }
+
+    renderScriptTabPanel(): TemplateResult {
+        return html`
+            <sl-tab-panel name="script">
+                <p>These stages of the script failed, the request or response was passed on unchanged:</p>
+                <ol class="resilience-failures">
+                    ${this._httpTransaction.scriptErrors.map((error) => html`<li>${error}</li>`)}
+                </ol>
+            </sl-tab-panel>`
+    }
+
+    tokenErrorCount(): number {
+        return this._httpTransaction.tokens.reduce((count, token) => count + (token.errors?.length ?? 0), 0);
+    }
+
+    renderTokensTabPanel(): TemplateResult {
+        return html`
+            <sl-tab-panel name="tokens">
+                ${this._httpTransaction.tokens.map((token) => html`
+                    <h3>${token.source}</h3>
+                    <p>${token.verified ? 'The signature was verified.' : 'The signature was not verified.'}</p>
+                    ${token.errors?.length > 0 ? html`
+                        <ol class="resilience-failures">
+                            ${token.errors.map((error) => html`<li>${error}</li>`)}
+                        </ol>` : null}
+                    ${token.header ? html`
+                        <p>Header</p>
+                        <pre><code>${JSON.stringify(token.header, null, 2)}</code></pre>` : null}
+                    ${token.claims ? html`
+                        <p>Claims</p>
+                        <pre><code>${JSON.stringify(token.claims, null, 2)}</code></pre>` : null}
+                `)}
+            </sl-tab-panel>`
+    }
+
     renderChainTabPanel(): TemplateResult {
 
         const selectChain = () => {
@@ -34,6 +34,9 @@ export const WiretapLocalStorage = "wiretap-transactions";
 
 export const NoSpec = "no-spec";
 
+// the frames kept on a websocket session, like the daemon keeps.
+export const MaxWebsocketFrames = 1000;
+
 
 export const TopicPrefix = "/topic/";
 export const QueuePrefix = "/queue/";
@@ -37,6 +37,12 @@ export interface ValidationError {
     context?: any;
 }
 
+export interface ClientIdentity {
+    subject?: string;
+    commonName?: string;
+    san?: string[];
+}
+
 export class HttpRequest {
     url?: string;
     method?: string;
@@ -50,6 +56,7 @@ export class HttpRequest {
     originalPath?: string;
     droppedHeaders?: string[];
     injectedHeaders?: any
+    clientCertificate?: ClientIdentity;
 
     constructor() {
         this.headers = {};
@@ -79,12 +86,24 @@ export class HttpRequest {
     }
 }
 
+export interface ResponseEvent {
+    id?: string;
+    event?: string;
+    data: string;
+    retry?: number;
+    timestamp?: number;
+    validation?: ValidationError[];
+}
+
 export class HttpResponse {
     headers?: any;
     cookies?: any;
     statusCode?: number;
     responseBody?: string;
     timestamp?: number;
+    events?: ResponseEvent[];
+    appendEvents?: boolean;
+    droppedEvents?: number;
 
     constructor() {
         this.headers = {}
@@ -118,14 +137,76 @@ export interface SpecConflict {
     kind?: string;
 }
 
+export interface WebsocketFrame {
+    direction: string;
+    opcode: number;
+    size: number;
+    timestamp: number;
+    payload?: string;
+    message?: string;
+    validation?: ValidationError[];
+}
+
+export interface WebsocketSession {
+    channel?: string;
+    frames?: WebsocketFrame[];
+    closeCode?: number;
+    droppedFrames?: number;
+}
+
+export interface MirrorDifference {
+    kind: string;
+    location?: string;
+    primary?: string;
+    shadow?: string;
+}
+
+export interface MirrorResult {
+    url: string;
+    statusCode?: number;
+    error?: string;
+    skipped?: string;
+    differences?: MirrorDifference[];
+    responseValidation?: ValidationError[];
+}
+
+export interface ResilienceRecord {
+    attempts: number;
+    failures?: string[];
+    breaker?: string;
+    fallback?: string;
+}
+
+export interface TransformRecord {
+    upstreamRequestBody?: string;
+    upstreamResponseBody?: string;
+    errors?: string[];
+}
+
+export interface TokenRecord {
+    source: string;
+    header?: Record<string, any>;
+    claims?: Record<string, any>;
+    verified?: boolean;
+    errors?: string[];
+}
+
 export class HttpTransaction extends HttpTransactionBase {
     delay?: number;
     requestValidation?: ValidationError[];
     httpResponse?: HttpResponse;
     responseValidation?: ValidationError[];
+    responseValidationSkipped?: string;
     containsChainLink?: boolean;
     httpRequest?: HttpRequest;
     specConflict?: SpecConflict;
+    websocket?: WebsocketSession;
+    mirror?: MirrorResult;
+    resilience?: ResilienceRecord;
+    mockFallback?: string;
+    transforms?: TransformRecord;
+    tokens?: TokenRecord[];
+    scriptErrors?: string[];
 
     constructor(timestamp?: number,
                 delay?: number,
@@ -218,7 +299,7 @@ export function BuildLiveTransactionFromState(httpTransaction: HttpTransaction):
         ? Object.assign(new HttpResponse(), httpTransaction.httpResponse)
         : undefined;
 
-    return new HttpTransaction(
+    const liveTransaction = new HttpTransaction(
         httpTransaction.timestamp,
         httpTransaction.delay,
         httpRequest,
@@ -228,4 +309,12 @@ export function BuildLiveTransactionFromState(httpTransaction: HttpTransaction):
         httpTransaction.responseValidation ?? [],
         httpTransaction.containsChainLink,
         httpTransaction.specConflict)
+    liveTransaction.websocket = httpTransaction.websocket;
+    liveTransaction.mirror = httpTransaction.mirror;
+    liveTransaction.resilience = httpTransaction.resilience;
+    liveTransaction.mockFallback = httpTransaction.mockFallback;
+    liveTransaction.transforms = httpTransaction.transforms;
+    liveTransaction.tokens = httpTransaction.tokens;
+    liveTransaction.scriptErrors = httpTransaction.scriptErrors;
+    return liveTransaction;
 }
@@ -8,7 +8,7 @@ import * as localforage from "localforage";
 import {HeaderComponent} from "@/components/wiretap-header/header";
 import {ReportResponse, WiretapControls, WiretapFilters} from "@/model/controls";
 import {
-    GetCurrentSpecCommand, NoSpec, QueuePrefix,
+    GetCurrentSpecCommand, MaxWebsocketFrames, NoSpec, QueuePrefix,
     RequestReportCommand, ResetStateCommand, SpecChannel, StartTheHARCommand, TopicPrefix,
     WiretapChannel, WiretapConfigurationChannel,
     WiretapControlsChannel, WiretapControlsKey, WiretapControlsStore,
@@ -316,6 +316,7 @@ export class WiretapComponent extends LitElement {
                 constructedTransaction.id = wiretapMessage.id;
                 constructedTransaction.requestValidation = wiretapMessage.requestValidation;
                 constructedTransaction.specConflict = wiretapMessage.specConflict;
+                constructedTransaction.scriptErrors = wiretapMessage.scriptErrors;
 
                 // get global delay
                 const controls = this._controlsStore.get(WiretapControlsKey)
@@ -341,16 +342,95 @@ export class WiretapComponent extends LitElement {
                 return constructedTransaction
             }
 
+            // websocket frames are broadcast one at a time, add them to the session, keeping the latest like the daemon.
+            if (existingTransaction && wiretapMessage.websocket) {
+                const session = existingTransaction.websocket ?? {frames: []};
+                session.channel = wiretapMessage.websocket.channel;
+                session.closeCode = wiretapMessage.websocket.closeCode;
+                session.droppedFrames = wiretapMessage.websocket.droppedFrames;
+                session.frames = (session.frames ?? []).concat(wiretapMessage.websocket.frames ?? [])
+                    .slice(-MaxWebsocketFrames);
+                existingTransaction.websocket = session;
+                if (wiretapMessage.requestValidation?.length || wiretapMessage.responseValidation?.length) {
+                    if (!existingTransaction.requestValidation?.length && !existingTransaction.responseValidation?.length) {
+                        this.violatedTransactions++
+                    }
+                    existingTransaction.requestValidation = (existingTransaction.requestValidation ?? [])
+                        .concat(wiretapMessage.requestValidation ?? []);
+                    existingTransaction.responseValidation = (existingTransaction.responseValidation ?? [])
+                        .concat(wiretapMessage.responseValidation ?? []);
+                }
+                this._httpTransactionStore.set(existingTransaction.id, existingTransaction)
+                this.calcComplianceLevel();
+                return
+            }
+
+            // shadow responses arrive after the primary response, on their own.
+            if (existingTransaction && wiretapMessage.mirror) {
+                existingTransaction.mirror = wiretapMessage.mirror;
+                this._httpTransactionStore.set(existingTransaction.id, existingTransaction)
+                return
+            }
+
+            // the attempts made upstream are recorded on their own, unless a fallback answered the request.
+            if (existingTransaction && wiretapMessage.resilience && !wiretapMessage.httpResponse) {
+                existingTransaction.resilience = wiretapMessage.resilience;
+                this._httpTransactionStore.set(existingTransaction.id, existingTransaction)
+                return
+            }
+
+            // the body the upstream responded with before it was transformed arrives on its own.
+            if (existingTransaction && wiretapMessage.transforms && !wiretapMessage.httpResponse && !wiretapMessage.httpRequest) {
+                existingTransaction.transforms = wiretapMessage.transforms;
+                this._httpTransactionStore.set(existingTransaction.id, existingTransaction)
+                return
+            }
+
+            // the errors of the response stages of a script arrive on their own, with those of the request stages.
+            if (existingTransaction && wiretapMessage.scriptErrors && !wiretapMessage.httpResponse && !wiretapMessage.httpRequest) {
+                existingTransaction.scriptErrors = [...new Set((existingTransaction.scriptErrors ?? [])
+                    .concat(wiretapMessage.scriptErrors))];
+                this._httpTransactionStore.set(existingTransaction.id, existingTransaction)
+                return
+            }
+
+            // server-sent events are broadcast one at a time, add them (and their body) to the response.
+            if (existingTransaction?.httpResponse && wiretapMessage.httpResponse?.appendEvents) {
+                const response = existingTransaction.httpResponse;
+                response.events = (response.events ?? []).concat(wiretapMessage.httpResponse.events ?? []);
+                response.responseBody = (response.responseBody ?? '') + (wiretapMessage.httpResponse.responseBody ?? '');
+                response.droppedEvents = wiretapMessage.httpResponse.droppedEvents;
+                if (wiretapMessage.responseValidation?.length) {
+                    if (!existingTransaction.requestValidation?.length && !existingTransaction.responseValidation?.length) {
+                        this.violatedTransactions++
+                    }
+                    existingTransaction.responseValidation = (existingTransaction.responseValidation ?? [])
+                        .concat(wiretapMessage.responseValidation);
+                }
+                this._httpTransactionStore.set(existingTransaction.id, existingTransaction)
+                this.calcComplianceLevel();
+                return
+            }
+
             if (existingTransaction && wiretapMessage.httpResponse) {
-                this.responseCount++;
-                if (wiretapMessage.responseValidation && wiretapMessage.responseValidation.length > 0) {
+                // event streams update the same response as each event arrives, only count it once.
+                if (!existingTransaction.httpResponse) {
+                    this.responseCount++;
+                }
+                if (wiretapMessage.responseValidation && wiretapMessage.responseValidation.length > 0 &&
+                    !existingTransaction.responseValidation?.length) {
                     this.violatedTransactions++
                 }
                 existingTransaction.httpResponse = Object.assign(new HttpResponse(), wiretapMessage?.httpResponse);
                 existingTransaction.responseValidation = wiretapMessage.responseValidation;
+                existingTransaction.responseValidationSkipped = wiretapMessage.responseValidationSkipped;
+                existingTransaction.mockFallback = wiretapMessage.mockFallback;
                 if (wiretapMessage.specConflict) {
                     existingTransaction.specConflict = wiretapMessage.specConflict;
                 }
+                if (wiretapMessage.resilience) {
+                    existingTransaction.resilience = wiretapMessage.resilience;
+                }
                 this._httpTransactionStore.set(existingTransaction.id, existingTransaction)
 
             } else if (existingTransaction && wiretapMessage.httpRequest) {
@@ -0,0 +1,112 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package validation
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi-validator/errors"
+	"github.com/pb33f/libopenapi-validator/paths"
+	"github.com/pb33f/libopenapi-validator/schema_validation"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/wiretap/sse"
+)
+
+// EventSchema returns the schema that describes each event of a server-sent event stream returned for a request.
+// An `itemSchema` declared by the `text/event-stream` media type is preferred over the `schema`. Returns nil if the
+// operation does not declare an event stream for the status code.
+func EventSchema(doc *v3.Document, request *http.Request, statusCode int) *base.Schema {
+	pathItem, _, _ := paths.FindPath(request, doc, nil)
+	if pathItem == nil {
+		return nil
+	}
+	operation := pathItem.GetOperations().GetOrZero(strings.ToLower(request.Method))
+	if operation == nil || operation.Responses == nil {
+		return nil
+	}
+
+	code := strconv.Itoa(statusCode)
+	response := operation.Responses.Codes.GetOrZero(code)
+	if response == nil && len(code) == 3 {
+		response = operation.Responses.Codes.GetOrZero(code[:1] + "XX")
+	}
+	if response == nil {
+		response = operation.Responses.Default
+	}
+	if response == nil || response.Content == nil {
+		return nil
+	}
+
+	for mediaType, mt := range response.Content.FromOldest() {
+		if !sse.IsEventStream(mediaType) || mt == nil {
+			continue
+		}
+		if mt.ItemSchema != nil {
+			return mt.ItemSchema.Schema()
+		}
+		if mt.Schema != nil {
+			return mt.Schema.Schema()
+		}
+	}
+	return nil
+}
+
+// IsEventShaped checks if an event schema describes the whole event (with `data`, `event`, `id` and `retry`
+// properties, as OpenAPI 3.2 does), rather than just the data of each event.
+func IsEventShaped(schema *base.Schema) bool {
+	if schema == nil || schema.Properties == nil {
+		return false
+	}
+	_, ok := schema.Properties.Get("data")
+	return ok
+}
+
+// ValidateEvent validates a server-sent event against an event schema. When the schema describes the whole event,
+// the event is validated as an object, and the data is validated against the `contentSchema` of the data property
+// if one is declared. Otherwise, the data of the event is validated against the schema.
+func ValidateEvent(schema *base.Schema, event *sse.Event) []*errors.ValidationError {
+	if schema == nil || event == nil {
+		return nil
+	}
+	validator := schema_validation.NewSchemaValidator()
+
+	if !IsEventShaped(schema) {
+		_, errs := validator.ValidateSchemaObject(schema, decodeEventData(event.Data))
+		return errs
+	}
+
+	object := map[string]any{"data": event.Data}
+	if event.Event != "" {
+		object["event"] = event.Event
+	}
+	if event.ID != "" {
+		object["id"] = event.ID
+	}
+	if event.Retry > 0 {
+		object["retry"] = event.Retry
+	}
+	_, errs := validator.ValidateSchemaObject(schema, object)
+
+	if dataSchema := schema.Properties.GetOrZero("data"); dataSchema != nil && dataSchema.Schema() != nil &&
+		dataSchema.Schema().ContentSchema != nil {
+		if contentSchema := dataSchema.Schema().ContentSchema.Schema(); contentSchema != nil {
+			_, contentErrs := validator.ValidateSchemaObject(contentSchema, decodeEventData(event.Data))
+			errs = append(errs, contentErrs...)
+		}
+	}
+	return errs
+}
+
+// decodeEventData decodes JSON event data, data that is not JSON is validated as a string.
+func decodeEventData(data string) any {
+	var decoded any
+	if err := json.Unmarshal([]byte(data), &decoded); err != nil {
+		return data
+	}
+	return decoded
+}
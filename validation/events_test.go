@@ -0,0 +1,119 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package validation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/wiretap/sse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var eventsSpec = `openapi: 3.2.0
+info:
+  title: events
+  version: "1.0"
+paths:
+  /prices:
+    get:
+      responses:
+        "200":
+          description: price updates
+          content:
+            text/event-stream:
+              schema:
+                type: object
+                required: [symbol, price]
+                properties:
+                  symbol:
+                    type: string
+                  price:
+                    type: number
+  /orders:
+    get:
+      responses:
+        2XX:
+          description: order events
+          content:
+            text/event-stream:
+              itemSchema:
+                type: object
+                required: [data]
+                properties:
+                  event:
+                    type: string
+                    enum: [created, shipped]
+                  data:
+                    type: string
+                    contentMediaType: application/json
+                    contentSchema:
+                      type: object
+                      required: [orderId]
+                      properties:
+                        orderId:
+                          type: integer
+  /plain:
+    get:
+      responses:
+        "200":
+          description: not a stream
+          content:
+            application/json:
+              schema:
+                type: object
+`
+
+func eventsDocument(t *testing.T) *v3.Document {
+	d, err := libopenapi.NewDocument([]byte(eventsSpec))
+	require.NoError(t, err)
+	doc, err := d.BuildV3Model()
+	require.NoError(t, err)
+	return &doc.Model
+}
+
+func TestEventSchema(t *testing.T) {
+	doc := eventsDocument(t)
+	get := func(path string) *http.Request {
+		return httptest.NewRequest(http.MethodGet, "http://wiretap.local"+path, nil)
+	}
+
+	prices := EventSchema(doc, get("/prices"), 200)
+	require.NotNil(t, prices)
+	assert.False(t, IsEventShaped(prices))
+
+	orders := EventSchema(doc, get("/orders"), 201)
+	require.NotNil(t, orders)
+	assert.True(t, IsEventShaped(orders))
+
+	assert.Nil(t, EventSchema(doc, get("/plain"), 200))
+	assert.Nil(t, EventSchema(doc, get("/prices"), 500))
+	assert.Nil(t, EventSchema(doc, get("/missing"), 200))
+}
+
+func TestValidateEvent_Data(t *testing.T) {
+	doc := eventsDocument(t)
+	schema := EventSchema(doc, httptest.NewRequest(http.MethodGet, "http://wiretap.local/prices", nil), 200)
+
+	assert.Empty(t, ValidateEvent(schema, &sse.Event{Data: `{"symbol":"PB33F","price":1.5}`}))
+	assert.NotEmpty(t, ValidateEvent(schema, &sse.Event{Data: `{"symbol":"PB33F"}`}))
+	assert.NotEmpty(t, ValidateEvent(schema, &sse.Event{Data: `not json`}))
+}
+
+func TestValidateEvent_EventShaped(t *testing.T) {
+	doc := eventsDocument(t)
+	schema := EventSchema(doc, httptest.NewRequest(http.MethodGet, "http://wiretap.local/orders", nil), 200)
+
+	assert.Empty(t, ValidateEvent(schema, &sse.Event{Event: "created", Data: `{"orderId":1}`}))
+
+	// the event type is not one of the declared values.
+	assert.NotEmpty(t, ValidateEvent(schema, &sse.Event{Event: "deleted", Data: `{"orderId":1}`}))
+
+	// the data does not match the content schema.
+	assert.NotEmpty(t, ValidateEvent(schema, &sse.Event{Event: "created", Data: `{"orderId":"one"}`}))
+}
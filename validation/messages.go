@@ -0,0 +1,39 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package validation
+
+import (
+	"encoding/json"
+
+	"github.com/pb33f/libopenapi-validator/errors"
+	"github.com/pb33f/libopenapi-validator/schema_validation"
+	"github.com/pb33f/wiretap/asyncapi"
+)
+
+// ValidateMessage validates a message payload against the AsyncAPI messages it may be, returning the message it
+// matched. When it matches none of them, the message with the fewest errors is returned with its errors. Messages
+// without a JSON schema payload are ignored, so nil is returned if there is nothing to validate against.
+func ValidateMessage(messages []*asyncapi.Message, payload []byte) (*asyncapi.Message, []*errors.ValidationError) {
+	var decoded any
+	if err := json.Unmarshal(payload, &decoded); err != nil {
+		decoded = string(payload)
+	}
+
+	validator := schema_validation.NewSchemaValidator()
+	var closest *asyncapi.Message
+	var closestErrs []*errors.ValidationError
+	for _, message := range messages {
+		if message.Schema == nil {
+			continue
+		}
+		valid, errs := validator.ValidateSchemaObject(message.Schema, decoded)
+		if valid {
+			return message, nil
+		}
+		if closest == nil || len(errs) < len(closestErrs) {
+			closest, closestErrs = message, errs
+		}
+	}
+	return closest, closestErrs
+}
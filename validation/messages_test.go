@@ -0,0 +1,64 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package validation
+
+import (
+	"testing"
+
+	"github.com/pb33f/wiretap/asyncapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateMessage(t *testing.T) {
+	doc, err := asyncapi.Parse([]byte(`asyncapi: 3.0.0
+info:
+  title: chat
+  version: 1.0.0
+channels:
+  chat:
+    messages:
+      said:
+        payload:
+          type: object
+          required: [text]
+          properties:
+            text:
+              type: string
+      joined:
+        payload:
+          type: object
+          required: [user, room]
+          properties:
+            user:
+              type: string
+            room:
+              type: string
+      binary:
+        schemaFormat: application/vnd.apache.avro;version=1.9.0
+        payload:
+          type: record
+`))
+	require.NoError(t, err)
+	messages := doc.Channel("chat").Messages
+
+	message, errs := ValidateMessage(messages, []byte(`{"user":"dave","room":"pb33f"}`))
+	require.NotNil(t, message)
+	assert.Equal(t, "joined", message.Name)
+	assert.Empty(t, errs)
+
+	// the closest message is reported.
+	message, errs = ValidateMessage(messages, []byte(`{"text":1}`))
+	require.NotNil(t, message)
+	assert.Equal(t, "said", message.Name)
+	assert.NotEmpty(t, errs)
+
+	message, errs = ValidateMessage(messages, []byte(`not json`))
+	assert.NotNil(t, message)
+	assert.NotEmpty(t, errs)
+
+	message, errs = ValidateMessage(messages[2:], []byte(`anything`))
+	assert.Nil(t, message)
+	assert.Empty(t, errs)
+}
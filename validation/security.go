@@ -0,0 +1,149 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package validation
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	"github.com/pb33f/libopenapi-validator/config"
+	"github.com/pb33f/wiretap/jwt"
+	"github.com/pb33f/wiretap/shared"
+)
+
+// Authenticate checks the credentials of a request against one security scheme of its operation. Validators check
+// credentials with it, so a missing or malformed credential is reported as a request violation naming the scheme. Credentials are checked for their presence and shape only: basic credentials must be a base64
+// encoded 'username:password', and bearer tokens that are JWTs must decode. OAuth2 and OpenID Connect schemes need a
+// bearer token, and when it is a JWT, it must grant the scopes the operation requires. Opaque tokens are trusted.
+func Authenticate(_ context.Context, input *config.AuthenticationInput) error {
+	return authenticate(input, nil)
+}
+
+// Authenticator returns an authentication function like Authenticate, that also finds the bearer tokens of OAuth2 and
+// OpenID Connect schemes in cookies, when a request has no Authorization header.
+func Authenticator(tokenCookies []string) config.AuthenticationFunc {
+	return func(_ context.Context, input *config.AuthenticationInput) error {
+		return authenticate(input, tokenCookies)
+	}
+}
+
+func authenticate(input *config.AuthenticationInput, tokenCookies []string) error {
+	scheme := input.SecurityScheme
+	request := input.Request
+
+	switch strings.ToLower(scheme.Type) {
+	case "http":
+		credentials, err := authorization(request, scheme.Scheme)
+		if err != nil {
+			return err
+		}
+		switch strings.ToLower(scheme.Scheme) {
+		case "basic":
+			decoded, decodeErr := base64.StdEncoding.DecodeString(credentials)
+			if decodeErr != nil || !strings.Contains(string(decoded), ":") {
+				return errors.New("the basic credentials are not a base64 encoded 'username:password'")
+			}
+		case "bearer":
+			if strings.EqualFold(scheme.BearerFormat, "JWT") || jwt.IsJWT(credentials) {
+				if _, parseErr := jwt.Parse(credentials); parseErr != nil {
+					return fmt.Errorf("the bearer token is not a valid JWT: %w", parseErr)
+				}
+			}
+		}
+		return nil
+
+	case "oauth2", "openidconnect":
+		credentials, err := authorization(request, "bearer")
+		if request.Header.Get("Authorization") == "" {
+			if cookie := cookieToken(request, tokenCookies); cookie != "" {
+				credentials, err = cookie, nil
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if !jwt.IsJWT(credentials) {
+			return nil
+		}
+		token, err := jwt.Parse(credentials)
+		if err != nil {
+			return fmt.Errorf("the bearer token is not a valid JWT: %w", err)
+		}
+		if missing := token.MissingScopes(input.Scopes); len(missing) > 0 {
+			return fmt.Errorf("the bearer token does not grant the %s '%s' required by the operation",
+				shared.Pluralize(len(missing), "scope", "scopes"), strings.Join(missing, "', '"))
+		}
+		return nil
+
+	case "apikey":
+		return apiKey(request, scheme.In, scheme.Name)
+	}
+	return nil
+}
+
+// authorization returns the credentials of the Authorization header of a request, which must use a scheme.
+func authorization(request *http.Request, scheme string) (string, error) {
+	header := request.Header.Get("Authorization")
+	if header == "" {
+		return "", errors.New("no `Authorization` header found in the request")
+	}
+	prefix, credentials, _ := strings.Cut(header, " ")
+	if !strings.EqualFold(prefix, scheme) {
+		return "", fmt.Errorf("the `Authorization` header does not use the '%s' scheme", strings.ToLower(scheme))
+	}
+	credentials = strings.TrimSpace(credentials)
+	if credentials == "" {
+		return "", fmt.Errorf("the `Authorization` header has no %s credentials", strings.ToLower(scheme))
+	}
+	return credentials, nil
+}
+
+// cookieToken returns the value of the first of the token cookies a request has.
+func cookieToken(request *http.Request, tokenCookies []string) string {
+	for _, name := range tokenCookies {
+		if cookie, err := request.Cookie(name); err == nil && cookie.Value != "" {
+			return cookie.Value
+		}
+	}
+	return ""
+}
+
+// apiKey checks that a request has a non-empty API key, in a header, query parameter or cookie.
+func apiKey(request *http.Request, in, name string) error {
+	var values []string
+	var found bool
+	switch in {
+	case "header":
+		values, found = request.Header[textproto.CanonicalMIMEHeaderKey(name)]
+	case "query":
+		values, found = request.URL.Query()[name]
+	case "cookie":
+		if cookie, err := request.Cookie(name); err == nil {
+			values, found = []string{cookie.Value}, true
+		}
+	default:
+		return nil
+	}
+	if !found {
+		return fmt.Errorf("no `%s` %s found in the request", name, apiKeyLocation(in))
+	}
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return nil
+		}
+	}
+	return fmt.Errorf("the `%s` %s is empty", name, apiKeyLocation(in))
+}
+
+func apiKeyLocation(in string) string {
+	if in == "query" {
+		return "query parameter"
+	}
+	return in
+}
@@ -0,0 +1,140 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package validation
+
+import (
+	"encoding/base64"
+	"net/http"
+	"testing"
+
+	"github.com/pb33f/libopenapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var securitySpec = `openapi: 3.1.0
+info:
+  title: security
+  version: 1.0.0
+paths:
+  /basic:
+    get:
+      security:
+        - basicAuth: []
+      responses:
+        "200":
+          description: ok
+  /bearer:
+    get:
+      security:
+        - bearerAuth: []
+      responses:
+        "200":
+          description: ok
+  /key:
+    get:
+      security:
+        - apiKey: []
+      responses:
+        "200":
+          description: ok
+  /pets:
+    post:
+      security:
+        - oauth: [write:pets, read:pets]
+      responses:
+        "200":
+          description: ok
+components:
+  securitySchemes:
+    basicAuth:
+      type: http
+      scheme: basic
+    bearerAuth:
+      type: http
+      scheme: bearer
+      bearerFormat: JWT
+    apiKey:
+      type: apiKey
+      in: query
+      name: key
+    oauth:
+      type: oauth2
+      flows:
+        clientCredentials:
+          tokenUrl: https://pb33f.io/token
+          scopes:
+            write:pets: write pets
+            read:pets: read pets
+`
+
+func testJWT(claims string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(claims)) + "."
+}
+
+func TestAuthenticate(t *testing.T) {
+	document, err := libopenapi.NewDocument([]byte(securitySpec))
+	require.NoError(t, err)
+	model, err := document.BuildV3Model()
+	require.NoError(t, err)
+	validator := NewHttpValidator(&model.Model)
+	schemes := map[string]string{"/basic": "basicAuth", "/bearer": "bearerAuth", "/key": "apiKey", "/pets": "oauth"}
+
+	for name, tc := range map[string]struct {
+		method, url, authorization string
+		reason                     string
+	}{
+		"basic":                 {http.MethodGet, "/basic", "Basic " + base64.StdEncoding.EncodeToString([]byte("a:b")), ""},
+		"basic missing":         {http.MethodGet, "/basic", "", "no `Authorization` header found in the request"},
+		"basic malformed":       {http.MethodGet, "/basic", "Basic bm9wZQ==", "the basic credentials are not a base64 encoded 'username:password'"},
+		"basic wrong scheme":    {http.MethodGet, "/basic", "Bearer abc", "the `Authorization` header does not use the 'basic' scheme"},
+		"bearer":                {http.MethodGet, "/bearer", "Bearer " + testJWT(`{}`), ""},
+		"bearer empty":          {http.MethodGet, "/bearer", "Bearer ", "the `Authorization` header has no bearer credentials"},
+		"bearer not a JWT":      {http.MethodGet, "/bearer", "Bearer opaque", "the bearer token is not a valid JWT: a JWT has three segments separated by dots"},
+		"api key":               {http.MethodGet, "/key?key=abc", "", ""},
+		"api key missing":       {http.MethodGet, "/key", "", "no `key` query parameter found in the request"},
+		"api key empty":         {http.MethodGet, "/key?key=", "", "the `key` query parameter is empty"},
+		"oauth scopes":          {http.MethodPost, "/pets", "Bearer " + testJWT(`{"scope":"read:pets write:pets"}`), ""},
+		"oauth opaque token":    {http.MethodPost, "/pets", "Bearer opaque", ""},
+		"oauth missing token":   {http.MethodPost, "/pets", "", "no `Authorization` header found in the request"},
+		"oauth missing a scope": {http.MethodPost, "/pets", "Bearer " + testJWT(`{"scp":["read:pets"]}`), "the bearer token does not grant the scope 'write:pets' required by the operation"},
+		"oauth missing scopes":  {http.MethodPost, "/pets", "Bearer " + testJWT(`{}`), "the bearer token does not grant the scopes 'write:pets', 'read:pets' required by the operation"},
+		"oauth malformed token": {http.MethodPost, "/pets", "Bearer a.b.c", "the bearer token is not a valid JWT: invalid header: illegal base64 data at input byte 0"},
+	} {
+		request, _ := http.NewRequest(tc.method, "https://pb33f.io"+tc.url, nil)
+		if tc.authorization != "" {
+			request.Header.Set("Authorization", tc.authorization)
+		}
+		valid, errs := validator.ValidateHttpRequest(request)
+		if tc.reason == "" {
+			assert.True(t, valid, name)
+			assert.Empty(t, errs, name)
+			continue
+		}
+		require.Len(t, errs, 1, name)
+		assert.Equal(t, "Authentication failed for security scheme '"+schemes[request.URL.Path]+"'", errs[0].Message, name)
+		assert.Equal(t, tc.reason, errs[0].Reason, name)
+	}
+}
+
+func TestAuthenticatorTokenCookies(t *testing.T) {
+	document, err := libopenapi.NewDocument([]byte(securitySpec))
+	require.NoError(t, err)
+	model, err := document.BuildV3Model()
+	require.NoError(t, err)
+	validator := NewHttpValidator(&model.Model, "session")
+
+	request, _ := http.NewRequest(http.MethodPost, "https://pb33f.io/pets", nil)
+	request.AddCookie(&http.Cookie{Name: "session", Value: testJWT(`{"scope":"read:pets write:pets"}`)})
+	valid, errs := validator.ValidateHttpRequest(request)
+	assert.True(t, valid)
+	assert.Empty(t, errs)
+
+	request, _ = http.NewRequest(http.MethodPost, "https://pb33f.io/pets", nil)
+	request.AddCookie(&http.Cookie{Name: "session", Value: testJWT(`{"scope":"read:pets"}`)})
+	_, errs = validator.ValidateHttpRequest(request)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "the bearer token does not grant the scope 'write:pets' required by the operation", errs[0].Reason)
+}
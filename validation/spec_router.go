@@ -25,6 +25,7 @@ type DocumentValidator struct {
 
 type SpecRouter struct {
 	validators []DocumentValidator
+	hosts      []map[string]struct{}
 	cache      *lru.Cache[string, RouteMatch]
 }
 
@@ -54,6 +55,15 @@ func NewSpecRouter(validators []DocumentValidator) *SpecRouter {
 	if len(docs) > 1 {
 		cache, _ := lru.New[string, RouteMatch](specRouterCacheSize)
 		router.cache = cache
+		router.hosts = make([]map[string]struct{}, len(docs))
+		for i := range docs {
+			for _, host := range specs.ServerHosts(docs[i].DocModel) {
+				if router.hosts[i] == nil {
+					router.hosts[i] = make(map[string]struct{})
+				}
+				router.hosts[i][host] = struct{}{}
+			}
+		}
 	}
 	return router
 }
@@ -94,7 +104,7 @@ func (r *SpecRouter) ResolveMatch(request *http.Request) *RouteMatch {
 	}
 
 	var fallback *RouteMatch
-	for i := range r.validators {
+	for _, i := range r.routeOrder(request) {
 		match := r.matchValidator(i, request, true)
 		if match == nil || match.MatchedPath == "" {
 			continue
@@ -118,6 +128,22 @@ func (r *SpecRouter) ResolveMatch(request *http.Request) *RouteMatch {
 	return &match
 }
 
+// routeOrder returns the order validators are matched in: documents with a server on the host of the request come
+// first, so specs are selected by host when wiretap proxies traffic for many hosts.
+func (r *SpecRouter) routeOrder(request *http.Request) []int {
+	order := make([]int, 0, len(r.validators))
+	host := strings.ToLower(request.URL.Hostname())
+	var others []int
+	for i := range r.validators {
+		if _, ok := r.hosts[i][host]; ok && host != "" {
+			order = append(order, i)
+		} else {
+			others = append(others, i)
+		}
+	}
+	return append(order, others...)
+}
+
 func (r *SpecRouter) matchValidator(index int, request *http.Request, requirePathMatch bool) *RouteMatch {
 	if index < 0 || index >= len(r.validators) {
 		return nil
@@ -246,7 +272,7 @@ func routeCacheKey(request *http.Request) string {
 	if request == nil || request.URL == nil {
 		return ""
 	}
-	return request.Method + " " + request.URL.EscapedPath()
+	return request.Method + " " + request.URL.Host + request.URL.EscapedPath()
 }
 
 func pathHasMethod(pathItem *v3.PathItem, method string) bool {
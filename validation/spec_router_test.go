@@ -231,6 +231,38 @@ paths:
 	assert.Same(t, req, ValidationRequestForRouteMatch(req, match))
 }
 
+func TestSpecRouterResolvePrefersSpecsServingTheRequestHost(t *testing.T) {
+	hostSpec := func(name, host string) DocumentValidator {
+		return buildRouterSpecFromSource(t, name, fmt.Sprintf(`openapi: 3.1.0
+info:
+  title: %s
+  version: "1.0"
+servers:
+  - url: https://%s
+paths:
+  "/health":
+    get:
+      responses:
+        "200":
+          description: ok
+`, name, host))
+	}
+	router := NewSpecRouter([]DocumentValidator{
+		hostSpec("payments", "payments.example.com"),
+		hostSpec("accounts", "accounts.example.com"),
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "https://accounts.example.com/health", nil)
+	assert.Equal(t, "accounts", router.Resolve(req).DocumentName)
+
+	req, _ = http.NewRequest(http.MethodGet, "https://Payments.example.com/health", nil)
+	assert.Equal(t, "payments", router.Resolve(req).DocumentName)
+
+	// a host no spec serves keeps the document order.
+	req, _ = http.NewRequest(http.MethodGet, "https://wiretap.local/health", nil)
+	assert.Equal(t, "payments", router.Resolve(req).DocumentName)
+}
+
 func TestSpecRouterResolveFallsBackToFirstValidator(t *testing.T) {
 	router := NewSpecRouter([]DocumentValidator{
 		buildRouterSpec(t, "pets", "/pets/{petId}"),
@@ -17,26 +17,29 @@ type HttpValidator interface {
 	ValidateHttpResponse(request *http.Request, response *http.Response) (bool, []*errors.ValidationError)
 }
 
-func NewHttpValidator(doc *v3.Document) HttpValidator {
+func NewHttpValidator(doc *v3.Document, tokenCookies ...string) HttpValidator {
 	return validator.NewValidatorFromV3Model(doc,
 		config.WithXmlBodyValidation(),
-		config.WithURLEncodedBodyValidation())
+		config.WithURLEncodedBodyValidation(),
+		config.WithAuthenticationFunc(Authenticator(tokenCookies)))
 }
 
 // NewStrictHttpValidator creates a validator with strict mode enabled.
 // Strict mode detects undeclared properties, parameters, headers, and cookies.
-func NewStrictHttpValidator(doc *v3.Document) HttpValidator {
+func NewStrictHttpValidator(doc *v3.Document, tokenCookies ...string) HttpValidator {
 	return validator.NewValidatorFromV3Model(doc,
 		config.WithStrictMode(),
 		config.WithXmlBodyValidation(),
-		config.WithURLEncodedBodyValidation())
+		config.WithURLEncodedBodyValidation(),
+		config.WithAuthenticationFunc(Authenticator(tokenCookies)))
 }
 
 // NewHttpValidatorWithConfig creates a validator based on configuration.
 // When strictMode is true, undeclared properties, parameters, headers, and cookies are detected.
-func NewHttpValidatorWithConfig(doc *v3.Document, strictMode bool) HttpValidator {
+// Bearer tokens of OAuth2 and OpenID Connect schemes are also read from the token cookies.
+func NewHttpValidatorWithConfig(doc *v3.Document, strictMode bool, tokenCookies ...string) HttpValidator {
 	if strictMode {
-		return NewStrictHttpValidator(doc)
+		return NewStrictHttpValidator(doc, tokenCookies...)
 	}
-	return NewHttpValidator(doc)
+	return NewHttpValidator(doc, tokenCookies...)
 }
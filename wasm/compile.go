@@ -0,0 +1,480 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package wasm
+
+// instruction is a compiled instruction. Blocks don't compile to anything: branches know where they jump to, the
+// height of the operand stack they unwind to and how many values they keep, all resolved when compiling.
+//
+//   - br and br_if: a is the target, b the height and v the number of values kept.
+//   - br_table: a is the first of its branches in the function, b how many there are, the last one is the default.
+//   - if: a is where the else branch, or the end, starts. Jumps at the end of then branches: a is the end.
+//   - calls: a is the function, or for call_indirect the type, and b the table.
+//   - locals, globals, tables, data and element segments: a is the index, b the second index, if there is one.
+//   - loads and stores: a is the offset.
+//   - constants: v is the value.
+type instruction struct {
+	op uint16
+	a  uint32
+	b  uint32
+	v  uint64
+}
+
+type branch struct {
+	target uint32
+	height uint32
+	arity  uint32
+}
+
+// function is a compiled function. Its frame holds its locals, parameters first, then its operands.
+type function struct {
+	params    int
+	results   int
+	locals    int
+	maxHeight int
+	code      []instruction
+	branches  []branch
+}
+
+// control is a block being compiled, the function body is the outermost one.
+type control struct {
+	opcode      uint16
+	height      int
+	params      int
+	results     int
+	start       int
+	ifPC        int
+	patches     []int
+	branches    []int
+	unreachable bool
+}
+
+type compiler struct {
+	module   *Module
+	function *function
+	reader   *reader
+	controls []*control
+	height   int
+}
+
+func (m *Module) compileFunction(index int, r *reader) *function {
+	typ := m.types[m.funcTypes[index]]
+	f := &function{params: len(typ.Params), results: len(typ.Results), locals: len(typ.Params)}
+	for n := r.u32(); n > 0; n-- {
+		count := r.u32()
+		decodeValueType(r)
+		if uint64(f.locals)+uint64(count) > maxLocals {
+			r.fail("function %d declares more than %d locals", index, maxLocals)
+		}
+		f.locals += int(count)
+	}
+	c := &compiler{module: m, function: f, reader: r}
+	c.controls = []*control{{results: f.results, ifPC: -1}}
+	for len(c.controls) > 0 {
+		c.compile(r.byte())
+	}
+	if !r.done() {
+		r.fail("function %d continues after its end", index)
+	}
+	return f
+}
+
+func (c *compiler) compile(op byte) {
+	m, f, r := c.module, c.function, c.reader
+	switch op {
+	case opUnreachable:
+		c.emit(instruction{op: opUnreachable})
+		c.unreachable()
+	case opNop:
+	case opBlock, opLoop:
+		params, results := c.blockType()
+		c.enter(uint16(op), params, results)
+	case opIf:
+		params, results := c.blockType()
+		c.pop(1)
+		block := c.enter(opIf, params, results)
+		block.ifPC = len(f.code)
+		c.emit(instruction{op: opIf})
+	case opElse:
+		block := c.top()
+		if block.opcode != opIf || block.ifPC < 0 {
+			r.fail("else outside of an if")
+		}
+		c.checkEnd(block)
+		block.patches = append(block.patches, len(f.code))
+		c.emit(instruction{op: opJump})
+		f.code[block.ifPC].a = uint32(len(f.code))
+		block.ifPC = -1
+		c.height = block.height + block.params
+		block.unreachable = false
+	case opEnd:
+		block := c.top()
+		c.checkEnd(block)
+		if block.opcode == opIf && block.ifPC >= 0 {
+			if block.params != block.results {
+				r.fail("if without an else must return its parameters")
+			}
+			block.patches = append(block.patches, block.ifPC)
+		}
+		end := uint32(len(f.code))
+		for _, pc := range block.patches {
+			f.code[pc].a = end
+		}
+		for _, i := range block.branches {
+			f.branches[i].target = end
+		}
+		c.controls = c.controls[:len(c.controls)-1]
+		if len(c.controls) == 0 {
+			// branches to the function body are returns.
+			c.emit(instruction{op: opReturn})
+			return
+		}
+		c.height = block.height
+		c.push(block.results)
+	case opBr, opBrIf:
+		if op == opBrIf {
+			c.pop(1)
+		}
+		br, forward := c.target(r.u32())
+		c.pop(int(br.arity))
+		if forward != nil {
+			forward.patches = append(forward.patches, len(f.code))
+		}
+		c.emit(instruction{op: uint16(op), a: br.target, b: br.height, v: uint64(br.arity)})
+		if op == opBr {
+			c.unreachable()
+		} else {
+			c.push(int(br.arity))
+		}
+	case opBrTable:
+		count := r.u32()
+		first := uint32(len(f.branches))
+		c.pop(1)
+		var arity uint32
+		for i := uint32(0); i <= count; i++ {
+			br, forward := c.target(r.u32())
+			if i == 0 {
+				arity = br.arity
+			} else if br.arity != arity && !c.top().unreachable {
+				r.fail("br_table targets keep different numbers of values")
+			}
+			if forward != nil {
+				forward.branches = append(forward.branches, len(f.branches))
+			}
+			f.branches = append(f.branches, br)
+		}
+		c.pop(int(arity))
+		c.emit(instruction{op: opBrTable, a: first, b: count + 1})
+		c.unreachable()
+	case opReturn:
+		c.pop(f.results)
+		c.emit(instruction{op: opReturn})
+		c.unreachable()
+	case opCall:
+		index := r.u32()
+		if int(index) >= len(m.funcTypes) {
+			r.fail("call to missing function %d", index)
+		}
+		typ := m.types[m.funcTypes[index]]
+		c.pop(len(typ.Params))
+		c.push(len(typ.Results))
+		c.emit(instruction{op: opCall, a: index})
+	case opCallIndirect:
+		index := m.typeIndex(r)
+		table := c.table()
+		typ := m.types[index]
+		c.pop(1)
+		c.pop(len(typ.Params))
+		c.push(len(typ.Results))
+		c.emit(instruction{op: opCallIndirect, a: index, b: table})
+	case opDrop:
+		c.pop(1)
+		c.emit(instruction{op: opDrop})
+	case opSelect, opSelectTyped:
+		if op == opSelectTyped {
+			for n := r.u32(); n > 0; n-- {
+				decodeValueType(r)
+			}
+		}
+		c.pop(3)
+		c.push(1)
+		c.emit(instruction{op: opSelect})
+	case opLocalGet, opLocalSet, opLocalTee:
+		index := r.u32()
+		if int(index) >= f.locals {
+			r.fail("missing local %d", index)
+		}
+		switch op {
+		case opLocalGet:
+			c.push(1)
+		case opLocalSet:
+			c.pop(1)
+		}
+		c.emit(instruction{op: uint16(op), a: index})
+	case opGlobalGet, opGlobalSet:
+		index := r.u32()
+		if int(index) >= len(m.globals) {
+			r.fail("missing global %d", index)
+		}
+		if op == opGlobalGet {
+			c.push(1)
+		} else {
+			if !m.globals[index].mutable {
+				r.fail("global %d is immutable", index)
+			}
+			c.pop(1)
+		}
+		c.emit(instruction{op: uint16(op), a: index})
+	case opTableGet:
+		c.pop(1)
+		c.push(1)
+		c.emit(instruction{op: opTableGet, a: c.table()})
+	case opTableSet:
+		c.pop(2)
+		c.emit(instruction{op: opTableSet, a: c.table()})
+	case opMemorySize, opMemoryGrow:
+		c.memory()
+		c.memoryIndex()
+		if op == opMemoryGrow {
+			c.pop(1)
+		}
+		c.push(1)
+		c.emit(instruction{op: uint16(op)})
+	case opI32Const:
+		c.push(1)
+		c.emit(instruction{op: opI32Const, v: uint64(uint32(r.s32()))})
+	case opI64Const:
+		c.push(1)
+		c.emit(instruction{op: opI64Const, v: uint64(r.s64())})
+	case opF32Const:
+		c.push(1)
+		c.emit(instruction{op: opF32Const, v: uint64(r.u32le())})
+	case opF64Const:
+		c.push(1)
+		c.emit(instruction{op: opF64Const, v: r.u64le()})
+	case opRefNull:
+		decodeValueType(r)
+		c.push(1)
+		c.emit(instruction{op: opRefNull})
+	case opRefIsNull:
+		c.pop(1)
+		c.push(1)
+		c.emit(instruction{op: opRefIsNull})
+	case opRefFunc:
+		index := r.u32()
+		if int(index) >= len(m.funcTypes) {
+			r.fail("reference to missing function %d", index)
+		}
+		c.push(1)
+		c.emit(instruction{op: opRefFunc, a: index})
+	case opI32ReinterpretF32, opI64ReinterpretF64, opF32ReinterpretI32, opF64ReinterpretI64, opI64ExtendI32U:
+		// values are kept as bits, zero extended: these don't change them.
+		c.pop(1)
+		c.push(1)
+	case 0xfc:
+		c.compilePrefixed(r.u32())
+	default:
+		switch {
+		case op >= opI32Load && op <= opI64Load32U:
+			c.memoryAccess(op, 1, 1)
+		case op >= opI32Store && op <= opI64Store32:
+			c.memoryAccess(op, 2, 0)
+		case op == opI32Eqz || op == opI64Eqz || (op >= opI32Clz && op <= opI32Popcnt) ||
+			(op >= opI64Clz && op <= opI64Popcnt) || (op >= opF32Abs && op <= opF32Sqrt) ||
+			(op >= opF64Abs && op <= opF64Sqrt) || (op >= opI32WrapI64 && op <= opI64Extend32S):
+			c.pop(1)
+			c.push(1)
+			c.emit(instruction{op: uint16(op)})
+		case (op >= opI32Eq && op <= opI32GeU) || (op >= opI64Eq && op <= opF64Ge) ||
+			(op >= opI32Add && op <= opI32Rotr) || (op >= opI64Add && op <= opI64Rotr) ||
+			(op >= opF32Add && op <= opF32Copysign) || (op >= opF64Add && op <= opF64Copysign):
+			c.pop(2)
+			c.push(1)
+			c.emit(instruction{op: uint16(op)})
+		default:
+			r.unsupported("opcode 0x%x", op)
+		}
+	}
+}
+
+func (c *compiler) compilePrefixed(sub uint32) {
+	m, r := c.module, c.reader
+	op := uint16(0xfc00 + sub)
+	switch {
+	case op >= opI32TruncSatF32S && op <= opI64TruncSatF64U:
+		c.pop(1)
+		c.push(1)
+		c.emit(instruction{op: op})
+	case op == opMemoryInit || op == opDataDrop:
+		index := r.u32()
+		if int(index) >= len(m.data) {
+			r.fail("missing data segment %d", index)
+		}
+		if op == opMemoryInit {
+			c.memory()
+			c.memoryIndex()
+			c.pop(3)
+		}
+		c.emit(instruction{op: op, a: index})
+	case op == opMemoryCopy || op == opMemoryFill:
+		c.memory()
+		c.memoryIndex()
+		if op == opMemoryCopy {
+			c.memoryIndex()
+		}
+		c.pop(3)
+		c.emit(instruction{op: op})
+	case op == opTableInit || op == opElemDrop:
+		index := r.u32()
+		if int(index) >= len(m.elements) {
+			r.fail("missing element segment %d", index)
+		}
+		var table uint32
+		if op == opTableInit {
+			table = c.table()
+			c.pop(3)
+		}
+		c.emit(instruction{op: op, a: index, b: table})
+	case op == opTableCopy:
+		destination, source := c.table(), c.table()
+		c.pop(3)
+		c.emit(instruction{op: op, a: destination, b: source})
+	case op == opTableGrow || op == opTableSize || op == opTableFill:
+		table := c.table()
+		switch op {
+		case opTableGrow:
+			c.pop(2)
+			c.push(1)
+		case opTableSize:
+			c.push(1)
+		case opTableFill:
+			c.pop(3)
+		}
+		c.emit(instruction{op: op, a: table})
+	default:
+		r.unsupported("opcode 0xfc %d", sub)
+	}
+}
+
+// memoryAccess compiles a load or store, the alignment hint is ignored.
+func (c *compiler) memoryAccess(op byte, pops, pushes int) {
+	c.memory()
+	if align := c.reader.u32(); align&0x40 != 0 {
+		c.memoryIndex()
+	}
+	offset := c.reader.u32()
+	c.pop(pops)
+	c.push(pushes)
+	c.emit(instruction{op: uint16(op), a: offset})
+}
+
+// memory checks the module has the memory an instruction uses.
+func (c *compiler) memory() {
+	if c.module.memory == nil {
+		c.reader.fail("memory instruction without a memory")
+	}
+}
+
+// memoryIndex reads the memory an instruction uses, there is only one.
+func (c *compiler) memoryIndex() {
+	if index := c.reader.u32(); index != 0 {
+		c.reader.unsupported("multiple memories")
+	}
+}
+
+func (c *compiler) table() uint32 {
+	index := c.reader.u32()
+	if int(index) >= len(c.module.tables) {
+		c.reader.fail("missing table %d", index)
+	}
+	return index
+}
+
+// blockType returns the number of parameters and results of a block.
+func (c *compiler) blockType() (int, int) {
+	r := c.reader
+	if r.done() {
+		r.fail("unexpected end")
+	}
+	switch b := r.data[r.pos]; {
+	case b == 0x40:
+		r.pos++
+		return 0, 0
+	case b >= 0x6f && b <= 0x7f:
+		decodeValueType(r)
+		return 0, 1
+	}
+	index := r.signed(33)
+	if index < 0 || index >= int64(len(c.module.types)) {
+		r.fail("missing block type %d", index)
+	}
+	typ := c.module.types[index]
+	return len(typ.Params), len(typ.Results)
+}
+
+func (c *compiler) enter(op uint16, params, results int) *control {
+	c.pop(params)
+	block := &control{opcode: op, height: c.height, params: params, results: results, start: len(c.function.code), ifPC: -1}
+	c.controls = append(c.controls, block)
+	c.push(params)
+	return block
+}
+
+func (c *compiler) top() *control {
+	return c.controls[len(c.controls)-1]
+}
+
+func (c *compiler) label(depth uint32) *control {
+	if int(depth) >= len(c.controls) {
+		c.reader.fail("branch to missing label %d", depth)
+	}
+	return c.controls[len(c.controls)-1-int(depth)]
+}
+
+// target returns where a branch to a label jumps, the height of the operand stack it unwinds to and the number of
+// values it keeps. Branches to the end of a block are patched when the end is reached, the block is returned so they
+// can be recorded on it.
+func (c *compiler) target(depth uint32) (branch, *control) {
+	block := c.label(depth)
+	if block.opcode == opLoop {
+		return branch{target: uint32(block.start), height: uint32(block.height), arity: uint32(block.params)}, nil
+	}
+	return branch{height: uint32(block.height), arity: uint32(block.results)}, block
+}
+
+// checkEnd checks a block leaves exactly its results on the stack.
+func (c *compiler) checkEnd(block *control) {
+	if !block.unreachable && c.height != block.height+block.results {
+		c.reader.fail("block leaves %d values, it must leave %d", c.height-block.height, block.results)
+	}
+}
+
+// unreachable marks the rest of the block as unreachable: anything can be popped from its stack.
+func (c *compiler) unreachable() {
+	block := c.top()
+	block.unreachable = true
+	c.height = block.height
+}
+
+func (c *compiler) pop(n int) {
+	block := c.top()
+	if c.height-n < block.height {
+		if !block.unreachable {
+			c.reader.fail("operand stack underflow")
+		}
+		c.height = block.height
+		return
+	}
+	c.height -= n
+}
+
+func (c *compiler) push(n int) {
+	c.height += n
+	if c.height > c.function.maxHeight {
+		c.function.maxHeight = c.height
+	}
+}
+
+func (c *compiler) emit(i instruction) {
+	c.function.code = append(c.function.code, i)
+}
@@ -0,0 +1,763 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package wasm
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+)
+
+var le = binary.LittleEndian
+
+// execute runs a function with its arguments at the base of the stack, leaving its results there. Values are kept as
+// uint64: i32 zero extended, floats as their bits.
+func (in *Instance) execute(f *function, base int) {
+	in.depth++
+	if in.depth > maxCallDepth {
+		panic(trapStackExhausted)
+	}
+	fp := base + f.locals
+	in.reserve(fp + f.maxHeight)
+	s := in.stack
+	clear(s[base+f.params : fp])
+	sp := fp
+	code := f.code
+
+	for pc := 0; ; {
+		ins := &code[pc]
+		pc++
+		switch ins.op {
+		case opUnreachable:
+			panic(trapUnreachable)
+		case opIf:
+			sp--
+			if uint32(s[sp]) == 0 {
+				pc = int(ins.a)
+			}
+		case opJump:
+			pc = int(ins.a)
+		case opBr:
+			if int(ins.a) < pc {
+				in.checkInterrupt()
+			}
+			sp = unwind(s, sp, fp+int(ins.b), int(ins.v))
+			pc = int(ins.a)
+		case opBrIf:
+			sp--
+			if uint32(s[sp]) != 0 {
+				if int(ins.a) < pc {
+					in.checkInterrupt()
+				}
+				sp = unwind(s, sp, fp+int(ins.b), int(ins.v))
+				pc = int(ins.a)
+			}
+		case opBrTable:
+			sp--
+			branches := f.branches[ins.a : ins.a+ins.b]
+			br := branches[len(branches)-1]
+			if i := uint32(s[sp]); i < ins.b-1 {
+				br = branches[i]
+			}
+			if int(br.target) < pc {
+				in.checkInterrupt()
+			}
+			sp = unwind(s, sp, fp+int(br.height), int(br.arity))
+			pc = int(br.target)
+		case opReturn:
+			copy(s[base:], s[sp-f.results:sp])
+			in.depth--
+			return
+		case opCall:
+			callee := &in.functions[ins.a]
+			in.invoke(callee, sp-callee.params)
+			s = in.stack
+			sp += callee.results - callee.params
+		case opCallIndirect:
+			sp--
+			elements := in.tables[ins.b].elements
+			i := uint32(s[sp])
+			if uint64(i) >= uint64(len(elements)) {
+				panic(trapUndefinedElement)
+			}
+			ref := elements[i]
+			if ref == 0 {
+				panic(trapNullElement)
+			}
+			callee := &in.functions[ref-1]
+			if callee.typeID != in.module.typeIDs[ins.a] {
+				panic(trapTypeMismatch)
+			}
+			in.invoke(callee, sp-callee.params)
+			s = in.stack
+			sp += callee.results - callee.params
+		case opDrop:
+			sp--
+		case opSelect:
+			sp -= 2
+			if uint32(s[sp+1]) == 0 {
+				s[sp-1] = s[sp]
+			}
+		case opLocalGet:
+			s[sp] = s[base+int(ins.a)]
+			sp++
+		case opLocalSet:
+			sp--
+			s[base+int(ins.a)] = s[sp]
+		case opLocalTee:
+			s[base+int(ins.a)] = s[sp-1]
+		case opGlobalGet:
+			s[sp] = in.globals[ins.a]
+			sp++
+		case opGlobalSet:
+			sp--
+			in.globals[ins.a] = s[sp]
+		case opTableGet:
+			elements := in.tables[ins.a].elements
+			i := uint32(s[sp-1])
+			if uint64(i) >= uint64(len(elements)) {
+				panic(trapTableAccess)
+			}
+			s[sp-1] = elements[i]
+		case opTableSet:
+			sp -= 2
+			elements := in.tables[ins.a].elements
+			i := uint32(s[sp])
+			if uint64(i) >= uint64(len(elements)) {
+				panic(trapTableAccess)
+			}
+			elements[i] = s[sp+1]
+
+		case opI32Load:
+			s[sp-1] = uint64(le.Uint32(in.memory[in.address(s[sp-1], ins.a, 4):]))
+		case opI64Load:
+			s[sp-1] = le.Uint64(in.memory[in.address(s[sp-1], ins.a, 8):])
+		case opF32Load:
+			s[sp-1] = uint64(le.Uint32(in.memory[in.address(s[sp-1], ins.a, 4):]))
+		case opF64Load:
+			s[sp-1] = le.Uint64(in.memory[in.address(s[sp-1], ins.a, 8):])
+		case opI32Load8S:
+			s[sp-1] = uint64(uint32(int32(int8(in.memory[in.address(s[sp-1], ins.a, 1)]))))
+		case opI32Load8U:
+			s[sp-1] = uint64(in.memory[in.address(s[sp-1], ins.a, 1)])
+		case opI32Load16S:
+			s[sp-1] = uint64(uint32(int32(int16(le.Uint16(in.memory[in.address(s[sp-1], ins.a, 2):])))))
+		case opI32Load16U:
+			s[sp-1] = uint64(le.Uint16(in.memory[in.address(s[sp-1], ins.a, 2):]))
+		case opI64Load8S:
+			s[sp-1] = uint64(int64(int8(in.memory[in.address(s[sp-1], ins.a, 1)])))
+		case opI64Load8U:
+			s[sp-1] = uint64(in.memory[in.address(s[sp-1], ins.a, 1)])
+		case opI64Load16S:
+			s[sp-1] = uint64(int64(int16(le.Uint16(in.memory[in.address(s[sp-1], ins.a, 2):]))))
+		case opI64Load16U:
+			s[sp-1] = uint64(le.Uint16(in.memory[in.address(s[sp-1], ins.a, 2):]))
+		case opI64Load32S:
+			s[sp-1] = uint64(int64(int32(le.Uint32(in.memory[in.address(s[sp-1], ins.a, 4):]))))
+		case opI64Load32U:
+			s[sp-1] = uint64(le.Uint32(in.memory[in.address(s[sp-1], ins.a, 4):]))
+		case opI32Store, opF32Store, opI64Store32:
+			sp -= 2
+			le.PutUint32(in.memory[in.address(s[sp], ins.a, 4):], uint32(s[sp+1]))
+		case opI64Store, opF64Store:
+			sp -= 2
+			le.PutUint64(in.memory[in.address(s[sp], ins.a, 8):], s[sp+1])
+		case opI32Store8, opI64Store8:
+			sp -= 2
+			in.memory[in.address(s[sp], ins.a, 1)] = byte(s[sp+1])
+		case opI32Store16, opI64Store16:
+			sp -= 2
+			le.PutUint16(in.memory[in.address(s[sp], ins.a, 2):], uint16(s[sp+1]))
+		case opMemorySize:
+			s[sp] = uint64(len(in.memory) / pageSize)
+			sp++
+		case opMemoryGrow:
+			s[sp-1] = in.grow(uint32(s[sp-1]))
+
+		case opI32Const, opI64Const, opF32Const, opF64Const:
+			s[sp] = ins.v
+			sp++
+		case opRefNull:
+			s[sp] = 0
+			sp++
+		case opRefIsNull:
+			s[sp-1] = b2u(s[sp-1] == 0)
+		case opRefFunc:
+			s[sp] = uint64(ins.a) + 1
+			sp++
+
+		case opI32Eqz:
+			s[sp-1] = b2u(uint32(s[sp-1]) == 0)
+		case opI32Eq:
+			sp--
+			s[sp-1] = b2u(uint32(s[sp-1]) == uint32(s[sp]))
+		case opI32Ne:
+			sp--
+			s[sp-1] = b2u(uint32(s[sp-1]) != uint32(s[sp]))
+		case opI32LtS:
+			sp--
+			s[sp-1] = b2u(int32(s[sp-1]) < int32(s[sp]))
+		case opI32LtU:
+			sp--
+			s[sp-1] = b2u(uint32(s[sp-1]) < uint32(s[sp]))
+		case opI32GtS:
+			sp--
+			s[sp-1] = b2u(int32(s[sp-1]) > int32(s[sp]))
+		case opI32GtU:
+			sp--
+			s[sp-1] = b2u(uint32(s[sp-1]) > uint32(s[sp]))
+		case opI32LeS:
+			sp--
+			s[sp-1] = b2u(int32(s[sp-1]) <= int32(s[sp]))
+		case opI32LeU:
+			sp--
+			s[sp-1] = b2u(uint32(s[sp-1]) <= uint32(s[sp]))
+		case opI32GeS:
+			sp--
+			s[sp-1] = b2u(int32(s[sp-1]) >= int32(s[sp]))
+		case opI32GeU:
+			sp--
+			s[sp-1] = b2u(uint32(s[sp-1]) >= uint32(s[sp]))
+		case opI64Eqz:
+			s[sp-1] = b2u(s[sp-1] == 0)
+		case opI64Eq:
+			sp--
+			s[sp-1] = b2u(s[sp-1] == s[sp])
+		case opI64Ne:
+			sp--
+			s[sp-1] = b2u(s[sp-1] != s[sp])
+		case opI64LtS:
+			sp--
+			s[sp-1] = b2u(int64(s[sp-1]) < int64(s[sp]))
+		case opI64LtU:
+			sp--
+			s[sp-1] = b2u(s[sp-1] < s[sp])
+		case opI64GtS:
+			sp--
+			s[sp-1] = b2u(int64(s[sp-1]) > int64(s[sp]))
+		case opI64GtU:
+			sp--
+			s[sp-1] = b2u(s[sp-1] > s[sp])
+		case opI64LeS:
+			sp--
+			s[sp-1] = b2u(int64(s[sp-1]) <= int64(s[sp]))
+		case opI64LeU:
+			sp--
+			s[sp-1] = b2u(s[sp-1] <= s[sp])
+		case opI64GeS:
+			sp--
+			s[sp-1] = b2u(int64(s[sp-1]) >= int64(s[sp]))
+		case opI64GeU:
+			sp--
+			s[sp-1] = b2u(s[sp-1] >= s[sp])
+		case opF32Eq:
+			sp--
+			s[sp-1] = b2u(f32(s[sp-1]) == f32(s[sp]))
+		case opF32Ne:
+			sp--
+			s[sp-1] = b2u(f32(s[sp-1]) != f32(s[sp]))
+		case opF32Lt:
+			sp--
+			s[sp-1] = b2u(f32(s[sp-1]) < f32(s[sp]))
+		case opF32Gt:
+			sp--
+			s[sp-1] = b2u(f32(s[sp-1]) > f32(s[sp]))
+		case opF32Le:
+			sp--
+			s[sp-1] = b2u(f32(s[sp-1]) <= f32(s[sp]))
+		case opF32Ge:
+			sp--
+			s[sp-1] = b2u(f32(s[sp-1]) >= f32(s[sp]))
+		case opF64Eq:
+			sp--
+			s[sp-1] = b2u(f64(s[sp-1]) == f64(s[sp]))
+		case opF64Ne:
+			sp--
+			s[sp-1] = b2u(f64(s[sp-1]) != f64(s[sp]))
+		case opF64Lt:
+			sp--
+			s[sp-1] = b2u(f64(s[sp-1]) < f64(s[sp]))
+		case opF64Gt:
+			sp--
+			s[sp-1] = b2u(f64(s[sp-1]) > f64(s[sp]))
+		case opF64Le:
+			sp--
+			s[sp-1] = b2u(f64(s[sp-1]) <= f64(s[sp]))
+		case opF64Ge:
+			sp--
+			s[sp-1] = b2u(f64(s[sp-1]) >= f64(s[sp]))
+
+		case opI32Clz:
+			s[sp-1] = uint64(bits.LeadingZeros32(uint32(s[sp-1])))
+		case opI32Ctz:
+			s[sp-1] = uint64(bits.TrailingZeros32(uint32(s[sp-1])))
+		case opI32Popcnt:
+			s[sp-1] = uint64(bits.OnesCount32(uint32(s[sp-1])))
+		case opI32Add:
+			sp--
+			s[sp-1] = uint64(uint32(s[sp-1]) + uint32(s[sp]))
+		case opI32Sub:
+			sp--
+			s[sp-1] = uint64(uint32(s[sp-1]) - uint32(s[sp]))
+		case opI32Mul:
+			sp--
+			s[sp-1] = uint64(uint32(s[sp-1]) * uint32(s[sp]))
+		case opI32DivS:
+			sp--
+			x, y := int32(s[sp-1]), int32(s[sp])
+			if y == 0 {
+				panic(trapDivideByZero)
+			}
+			if x == math.MinInt32 && y == -1 {
+				panic(trapIntegerOverflow)
+			}
+			s[sp-1] = uint64(uint32(x / y))
+		case opI32DivU:
+			sp--
+			y := uint32(s[sp])
+			if y == 0 {
+				panic(trapDivideByZero)
+			}
+			s[sp-1] = uint64(uint32(s[sp-1]) / y)
+		case opI32RemS:
+			sp--
+			y := int32(s[sp])
+			if y == 0 {
+				panic(trapDivideByZero)
+			}
+			s[sp-1] = uint64(uint32(int32(s[sp-1]) % y))
+		case opI32RemU:
+			sp--
+			y := uint32(s[sp])
+			if y == 0 {
+				panic(trapDivideByZero)
+			}
+			s[sp-1] = uint64(uint32(s[sp-1]) % y)
+		case opI32And:
+			sp--
+			s[sp-1] = uint64(uint32(s[sp-1]) & uint32(s[sp]))
+		case opI32Or:
+			sp--
+			s[sp-1] = uint64(uint32(s[sp-1]) | uint32(s[sp]))
+		case opI32Xor:
+			sp--
+			s[sp-1] = uint64(uint32(s[sp-1]) ^ uint32(s[sp]))
+		case opI32Shl:
+			sp--
+			s[sp-1] = uint64(uint32(s[sp-1]) << (s[sp] & 31))
+		case opI32ShrS:
+			sp--
+			s[sp-1] = uint64(uint32(int32(s[sp-1]) >> (s[sp] & 31)))
+		case opI32ShrU:
+			sp--
+			s[sp-1] = uint64(uint32(s[sp-1]) >> (s[sp] & 31))
+		case opI32Rotl:
+			sp--
+			s[sp-1] = uint64(bits.RotateLeft32(uint32(s[sp-1]), int(s[sp]&31)))
+		case opI32Rotr:
+			sp--
+			s[sp-1] = uint64(bits.RotateLeft32(uint32(s[sp-1]), -int(s[sp]&31)))
+		case opI64Clz:
+			s[sp-1] = uint64(bits.LeadingZeros64(s[sp-1]))
+		case opI64Ctz:
+			s[sp-1] = uint64(bits.TrailingZeros64(s[sp-1]))
+		case opI64Popcnt:
+			s[sp-1] = uint64(bits.OnesCount64(s[sp-1]))
+		case opI64Add:
+			sp--
+			s[sp-1] += s[sp]
+		case opI64Sub:
+			sp--
+			s[sp-1] -= s[sp]
+		case opI64Mul:
+			sp--
+			s[sp-1] *= s[sp]
+		case opI64DivS:
+			sp--
+			x, y := int64(s[sp-1]), int64(s[sp])
+			if y == 0 {
+				panic(trapDivideByZero)
+			}
+			if x == math.MinInt64 && y == -1 {
+				panic(trapIntegerOverflow)
+			}
+			s[sp-1] = uint64(x / y)
+		case opI64DivU:
+			sp--
+			if s[sp] == 0 {
+				panic(trapDivideByZero)
+			}
+			s[sp-1] /= s[sp]
+		case opI64RemS:
+			sp--
+			y := int64(s[sp])
+			if y == 0 {
+				panic(trapDivideByZero)
+			}
+			s[sp-1] = uint64(int64(s[sp-1]) % y)
+		case opI64RemU:
+			sp--
+			if s[sp] == 0 {
+				panic(trapDivideByZero)
+			}
+			s[sp-1] %= s[sp]
+		case opI64And:
+			sp--
+			s[sp-1] &= s[sp]
+		case opI64Or:
+			sp--
+			s[sp-1] |= s[sp]
+		case opI64Xor:
+			sp--
+			s[sp-1] ^= s[sp]
+		case opI64Shl:
+			sp--
+			s[sp-1] <<= s[sp] & 63
+		case opI64ShrS:
+			sp--
+			s[sp-1] = uint64(int64(s[sp-1]) >> (s[sp] & 63))
+		case opI64ShrU:
+			sp--
+			s[sp-1] >>= s[sp] & 63
+		case opI64Rotl:
+			sp--
+			s[sp-1] = bits.RotateLeft64(s[sp-1], int(s[sp]&63))
+		case opI64Rotr:
+			sp--
+			s[sp-1] = bits.RotateLeft64(s[sp-1], -int(s[sp]&63))
+
+		case opF32Abs:
+			s[sp-1] &= 0x7fffffff
+		case opF32Neg:
+			s[sp-1] ^= 0x80000000
+		case opF32Ceil:
+			s[sp-1] = fromF32(float32(math.Ceil(float64(f32(s[sp-1])))))
+		case opF32Floor:
+			s[sp-1] = fromF32(float32(math.Floor(float64(f32(s[sp-1])))))
+		case opF32Trunc:
+			s[sp-1] = fromF32(float32(math.Trunc(float64(f32(s[sp-1])))))
+		case opF32Nearest:
+			s[sp-1] = fromF32(float32(math.RoundToEven(float64(f32(s[sp-1])))))
+		case opF32Sqrt:
+			s[sp-1] = fromF32(float32(math.Sqrt(float64(f32(s[sp-1])))))
+		case opF32Add:
+			sp--
+			s[sp-1] = fromF32(f32(s[sp-1]) + f32(s[sp]))
+		case opF32Sub:
+			sp--
+			s[sp-1] = fromF32(f32(s[sp-1]) - f32(s[sp]))
+		case opF32Mul:
+			sp--
+			s[sp-1] = fromF32(f32(s[sp-1]) * f32(s[sp]))
+		case opF32Div:
+			sp--
+			s[sp-1] = fromF32(f32(s[sp-1]) / f32(s[sp]))
+		case opF32Min:
+			sp--
+			s[sp-1] = fromF32(float32(math.Min(float64(f32(s[sp-1])), float64(f32(s[sp])))))
+		case opF32Max:
+			sp--
+			s[sp-1] = fromF32(float32(math.Max(float64(f32(s[sp-1])), float64(f32(s[sp])))))
+		case opF32Copysign:
+			sp--
+			s[sp-1] = s[sp-1]&0x7fffffff | s[sp]&0x80000000
+		case opF64Abs:
+			s[sp-1] &^= 1 << 63
+		case opF64Neg:
+			s[sp-1] ^= 1 << 63
+		case opF64Ceil:
+			s[sp-1] = math.Float64bits(math.Ceil(f64(s[sp-1])))
+		case opF64Floor:
+			s[sp-1] = math.Float64bits(math.Floor(f64(s[sp-1])))
+		case opF64Trunc:
+			s[sp-1] = math.Float64bits(math.Trunc(f64(s[sp-1])))
+		case opF64Nearest:
+			s[sp-1] = math.Float64bits(math.RoundToEven(f64(s[sp-1])))
+		case opF64Sqrt:
+			s[sp-1] = math.Float64bits(math.Sqrt(f64(s[sp-1])))
+		case opF64Add:
+			sp--
+			s[sp-1] = math.Float64bits(f64(s[sp-1]) + f64(s[sp]))
+		case opF64Sub:
+			sp--
+			s[sp-1] = math.Float64bits(f64(s[sp-1]) - f64(s[sp]))
+		case opF64Mul:
+			sp--
+			s[sp-1] = math.Float64bits(f64(s[sp-1]) * f64(s[sp]))
+		case opF64Div:
+			sp--
+			s[sp-1] = math.Float64bits(f64(s[sp-1]) / f64(s[sp]))
+		case opF64Min:
+			sp--
+			s[sp-1] = math.Float64bits(math.Min(f64(s[sp-1]), f64(s[sp])))
+		case opF64Max:
+			sp--
+			s[sp-1] = math.Float64bits(math.Max(f64(s[sp-1]), f64(s[sp])))
+		case opF64Copysign:
+			sp--
+			s[sp-1] = s[sp-1]&^(1<<63) | s[sp]&(1<<63)
+
+		case opI32WrapI64:
+			s[sp-1] = uint64(uint32(s[sp-1]))
+		case opI32TruncF32S:
+			s[sp-1] = uint64(uint32(int32(truncate(float64(f32(s[sp-1])), math.MinInt32, 1<<31))))
+		case opI32TruncF32U:
+			s[sp-1] = uint64(uint32(truncate(float64(f32(s[sp-1])), -1, 1<<32)))
+		case opI32TruncF64S:
+			s[sp-1] = uint64(uint32(int32(truncate(f64(s[sp-1]), math.MinInt32, 1<<31))))
+		case opI32TruncF64U:
+			s[sp-1] = uint64(uint32(truncate(f64(s[sp-1]), -1, 1<<32)))
+		case opI64ExtendI32S:
+			s[sp-1] = uint64(int64(int32(s[sp-1])))
+		case opI64TruncF32S:
+			s[sp-1] = uint64(int64(truncate(float64(f32(s[sp-1])), math.MinInt64, 1<<63)))
+		case opI64TruncF32U:
+			s[sp-1] = truncateU64(float64(f32(s[sp-1])))
+		case opI64TruncF64S:
+			s[sp-1] = uint64(int64(truncate(f64(s[sp-1]), math.MinInt64, 1<<63)))
+		case opI64TruncF64U:
+			s[sp-1] = truncateU64(f64(s[sp-1]))
+		case opF32ConvertI32S:
+			s[sp-1] = fromF32(float32(int32(s[sp-1])))
+		case opF32ConvertI32U:
+			s[sp-1] = fromF32(float32(uint32(s[sp-1])))
+		case opF32ConvertI64S:
+			s[sp-1] = fromF32(float32(int64(s[sp-1])))
+		case opF32ConvertI64U:
+			s[sp-1] = fromF32(float32(s[sp-1]))
+		case opF32DemoteF64:
+			s[sp-1] = fromF32(float32(f64(s[sp-1])))
+		case opF64ConvertI32S:
+			s[sp-1] = math.Float64bits(float64(int32(s[sp-1])))
+		case opF64ConvertI32U:
+			s[sp-1] = math.Float64bits(float64(uint32(s[sp-1])))
+		case opF64ConvertI64S:
+			s[sp-1] = math.Float64bits(float64(int64(s[sp-1])))
+		case opF64ConvertI64U:
+			s[sp-1] = math.Float64bits(float64(s[sp-1]))
+		case opF64PromoteF32:
+			s[sp-1] = math.Float64bits(float64(f32(s[sp-1])))
+		case opI32Extend8S:
+			s[sp-1] = uint64(uint32(int32(int8(s[sp-1]))))
+		case opI32Extend16S:
+			s[sp-1] = uint64(uint32(int32(int16(s[sp-1]))))
+		case opI64Extend8S:
+			s[sp-1] = uint64(int64(int8(s[sp-1])))
+		case opI64Extend16S:
+			s[sp-1] = uint64(int64(int16(s[sp-1])))
+		case opI64Extend32S:
+			s[sp-1] = uint64(int64(int32(s[sp-1])))
+
+		case opI32TruncSatF32S:
+			s[sp-1] = uint64(uint32(int32(saturate(float64(f32(s[sp-1])), math.MinInt32, math.MaxInt32))))
+		case opI32TruncSatF32U:
+			s[sp-1] = uint64(uint32(saturate(float64(f32(s[sp-1])), 0, math.MaxUint32)))
+		case opI32TruncSatF64S:
+			s[sp-1] = uint64(uint32(int32(saturate(f64(s[sp-1]), math.MinInt32, math.MaxInt32))))
+		case opI32TruncSatF64U:
+			s[sp-1] = uint64(uint32(saturate(f64(s[sp-1]), 0, math.MaxUint32)))
+		case opI64TruncSatF32S:
+			s[sp-1] = saturateS64(float64(f32(s[sp-1])))
+		case opI64TruncSatF32U:
+			s[sp-1] = saturateU64(float64(f32(s[sp-1])))
+		case opI64TruncSatF64S:
+			s[sp-1] = saturateS64(f64(s[sp-1]))
+		case opI64TruncSatF64U:
+			s[sp-1] = saturateU64(f64(s[sp-1]))
+
+		case opMemoryInit:
+			sp -= 3
+			segment := in.data[ins.a]
+			destination, source, n := uint64(uint32(s[sp])), uint64(uint32(s[sp+1])), uint64(uint32(s[sp+2]))
+			if source+n > uint64(len(segment)) || destination+n > uint64(len(in.memory)) {
+				panic(trapMemoryAccess)
+			}
+			copy(in.memory[destination:destination+n], segment[source:])
+		case opDataDrop:
+			in.data[ins.a] = nil
+		case opMemoryCopy:
+			sp -= 3
+			destination, source, n := uint64(uint32(s[sp])), uint64(uint32(s[sp+1])), uint64(uint32(s[sp+2]))
+			if source+n > uint64(len(in.memory)) || destination+n > uint64(len(in.memory)) {
+				panic(trapMemoryAccess)
+			}
+			copy(in.memory[destination:destination+n], in.memory[source:source+n])
+		case opMemoryFill:
+			sp -= 3
+			destination, value, n := uint64(uint32(s[sp])), byte(s[sp+1]), uint64(uint32(s[sp+2]))
+			if destination+n > uint64(len(in.memory)) {
+				panic(trapMemoryAccess)
+			}
+			fill := in.memory[destination : destination+n]
+			for i := range fill {
+				fill[i] = value
+			}
+		case opTableInit:
+			sp -= 3
+			segment, elements := in.elements[ins.a], in.tables[ins.b].elements
+			destination, source, n := uint64(uint32(s[sp])), uint64(uint32(s[sp+1])), uint64(uint32(s[sp+2]))
+			if source+n > uint64(len(segment)) || destination+n > uint64(len(elements)) {
+				panic(trapTableAccess)
+			}
+			copy(elements[destination:destination+n], segment[source:])
+		case opElemDrop:
+			in.elements[ins.a] = nil
+		case opTableCopy:
+			sp -= 3
+			to, from := in.tables[ins.a].elements, in.tables[ins.b].elements
+			destination, source, n := uint64(uint32(s[sp])), uint64(uint32(s[sp+1])), uint64(uint32(s[sp+2]))
+			if source+n > uint64(len(from)) || destination+n > uint64(len(to)) {
+				panic(trapTableAccess)
+			}
+			copy(to[destination:destination+n], from[source:source+n])
+		case opTableGrow:
+			sp--
+			s[sp-1] = in.tables[ins.a].grow(s[sp-1], uint32(s[sp]))
+		case opTableSize:
+			s[sp] = uint64(len(in.tables[ins.a].elements))
+			sp++
+		case opTableFill:
+			sp -= 3
+			elements := in.tables[ins.a].elements
+			destination, value, n := uint64(uint32(s[sp])), s[sp+1], uint64(uint32(s[sp+2]))
+			if destination+n > uint64(len(elements)) {
+				panic(trapTableAccess)
+			}
+			fill := elements[destination : destination+n]
+			for i := range fill {
+				fill[i] = value
+			}
+		}
+	}
+}
+
+// unwind moves the values a branch keeps to the height it unwinds to, returning the new top of the stack.
+func unwind(s []uint64, sp, height, arity int) int {
+	if height != sp-arity {
+		copy(s[height:height+arity], s[sp-arity:sp])
+	}
+	return height + arity
+}
+
+func (in *Instance) checkInterrupt() {
+	if in.interrupted.Load() {
+		panic(hostError{err: ErrInterrupted})
+	}
+}
+
+// address returns where an access of a number of bytes starts in memory, trapping when it does not fit.
+func (in *Instance) address(index uint64, offset uint32, size uint64) uint64 {
+	address := uint64(uint32(index)) + uint64(offset)
+	if address+size > uint64(len(in.memory)) {
+		panic(trapMemoryAccess)
+	}
+	return address
+}
+
+// grow grows the memory by a number of pages, returning the previous number of pages, or -1 when it can't grow.
+func (in *Instance) grow(pages uint32) uint64 {
+	previous := uint64(len(in.memory)) / pageSize
+	size := uint64(len(in.memory)) + uint64(pages)*pageSize
+	if size > in.maxMemory {
+		return uint64(math.MaxUint32)
+	}
+	if size > uint64(cap(in.memory)) {
+		// like append, but never past the maximum: the capacity past the length is still zero.
+		memory := make([]byte, size, min(max(size, 2*uint64(len(in.memory))), in.maxMemory))
+		copy(memory, in.memory)
+		in.memory = memory
+	} else {
+		in.memory = in.memory[:size]
+	}
+	return previous
+}
+
+// grow grows a table by a number of elements, returning its previous size, or -1 when it can't grow.
+func (t *table) grow(value uint64, n uint32) uint64 {
+	previous := uint64(len(t.elements))
+	if previous+uint64(n) > t.max {
+		return uint64(math.MaxUint32)
+	}
+	for range n {
+		t.elements = append(t.elements, value)
+	}
+	return previous
+}
+
+func b2u(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func f32(v uint64) float32 {
+	return math.Float32frombits(uint32(v))
+}
+
+func fromF32(f float32) uint64 {
+	return uint64(math.Float32bits(f))
+}
+
+func f64(v uint64) float64 {
+	return math.Float64frombits(v)
+}
+
+// truncate truncates a float to an integer, trapping when it is not a number or the integer is outside of [lo, hi).
+func truncate(f, lo, hi float64) float64 {
+	if math.IsNaN(f) {
+		panic(trapInvalidConversion)
+	}
+	f = math.Trunc(f)
+	if f < lo || f >= hi || (lo == -1 && f == -1) {
+		panic(trapIntegerOverflow)
+	}
+	return f
+}
+
+func truncateU64(f float64) uint64 {
+	f = truncate(f, -1, 1<<64)
+	if f >= 1<<63 {
+		return uint64(int64(f-(1<<63))) | 1<<63
+	}
+	return uint64(int64(f))
+}
+
+// saturate truncates a float to an integer within [lo, hi], not a number is zero.
+func saturate(f, lo, hi float64) float64 {
+	switch {
+	case math.IsNaN(f):
+		return 0
+	case f <= lo:
+		return lo
+	case f >= hi:
+		return hi
+	}
+	return math.Trunc(f)
+}
+
+func saturateS64(f float64) uint64 {
+	switch {
+	case math.IsNaN(f):
+		return 0
+	case f <= math.MinInt64:
+		return 1 << 63
+	case f >= 1<<63:
+		return math.MaxInt64
+	}
+	return uint64(int64(f))
+}
+
+func saturateU64(f float64) uint64 {
+	switch {
+	case math.IsNaN(f) || f <= 0:
+		return 0
+	case f >= 1<<64:
+		return math.MaxUint64
+	}
+	if f >= 1<<63 {
+		return uint64(int64(f-(1<<63))) | 1<<63
+	}
+	return uint64(int64(f))
+}
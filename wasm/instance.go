@@ -0,0 +1,280 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package wasm
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"sync/atomic"
+)
+
+const (
+	pageSize = 65536
+	// maxPages is the most memory a module can have, 4 GiB, all that 32-bit addresses reach.
+	maxPages     = 65536
+	maxTableSize = 10_000_000
+	// maxCallDepth and maxStack bound the calls and operands of a call, runaway recursion traps instead of exhausting
+	// the memory of the host.
+	maxCallDepth = 10_000
+	maxStack     = 1 << 22
+)
+
+// HostFunction is a function the host provides to modules. It is called with the instance calling it, so it can
+// read and write the memory of the instance, and its arguments. When it returns an error, the call running on the
+// instance stops and returns the error.
+type HostFunction struct {
+	Type FuncType
+	Call func(instance *Instance, args []uint64) ([]uint64, error)
+}
+
+// Imports are the host functions modules can import, by module and name.
+type Imports map[string]map[string]HostFunction
+
+// Trap is returned by calls that trap, like by reading outside of memory or dividing by zero.
+type Trap struct {
+	Reason string
+}
+
+func (t *Trap) Error() string {
+	return "wasm trap: " + t.Reason
+}
+
+// ErrInterrupted is returned by calls stopped by Interrupt.
+var ErrInterrupted = errors.New("wasm: interrupted")
+
+var (
+	trapUnreachable       = &Trap{Reason: "unreachable"}
+	trapDivideByZero      = &Trap{Reason: "integer divide by zero"}
+	trapIntegerOverflow   = &Trap{Reason: "integer overflow"}
+	trapInvalidConversion = &Trap{Reason: "invalid conversion to integer"}
+	trapMemoryAccess      = &Trap{Reason: "out of bounds memory access"}
+	trapTableAccess       = &Trap{Reason: "out of bounds table access"}
+	trapUndefinedElement  = &Trap{Reason: "undefined element"}
+	trapNullElement       = &Trap{Reason: "uninitialized element"}
+	trapTypeMismatch      = &Trap{Reason: "indirect call type mismatch"}
+	trapStackExhausted    = &Trap{Reason: "call stack exhausted"}
+)
+
+// hostError carries the error of a host function through the calls it stopped.
+type hostError struct {
+	err error
+}
+
+// Instance is an instance of a module: its memory, tables and globals. Instances run one call at a time, and are not
+// safe to use concurrently, except for Interrupt. Once a call has failed, the state of the instance is whatever it was
+// when the call stopped.
+type Instance struct {
+	module      *Module
+	functions   []funcInstance
+	tables      []*table
+	memory      []byte
+	maxMemory   uint64
+	globals     []uint64
+	data        [][]byte
+	elements    [][]uint64
+	stack       []uint64
+	depth       int
+	running     bool
+	interrupted atomic.Bool
+}
+
+type funcInstance struct {
+	typeID  int
+	params  int
+	results int
+	code    *function
+	host    *HostFunction
+}
+
+type table struct {
+	elements []uint64
+	max      uint64
+}
+
+// Instantiate creates an instance of the module, with the host functions it imports, and runs its start function.
+func (m *Module) Instantiate(imports Imports) (*Instance, error) {
+	in := &Instance{module: m}
+	for i, imported := range m.imports {
+		host, ok := imports[imported.Module][imported.Name]
+		if !ok {
+			return nil, fmt.Errorf("wasm: unresolved import %s.%s", imported.Module, imported.Name)
+		}
+		if !host.Type.Equal(imported.Type) {
+			return nil, fmt.Errorf("wasm: import %s.%s must be %s, the host provides %s",
+				imported.Module, imported.Name, imported.Type, host.Type)
+		}
+		in.functions = append(in.functions, funcInstance{
+			typeID:  m.typeIDs[m.funcTypes[i]],
+			params:  len(imported.Type.Params),
+			results: len(imported.Type.Results),
+			host:    &host,
+		})
+	}
+	for i, f := range m.functions {
+		in.functions = append(in.functions, funcInstance{
+			typeID:  m.typeIDs[m.funcTypes[len(m.imports)+i]],
+			params:  f.params,
+			results: f.results,
+			code:    f,
+		})
+	}
+
+	if m.memory != nil {
+		in.memory = make([]byte, int(m.memory.min)*pageSize)
+		in.maxMemory = maxPages * pageSize
+		if m.memory.hasMax {
+			in.maxMemory = uint64(m.memory.max) * pageSize
+		}
+	}
+	for _, t := range m.tables {
+		instance := &table{elements: make([]uint64, t.limits.min), max: maxTableSize}
+		if t.limits.hasMax {
+			instance.max = min(uint64(t.limits.max), maxTableSize)
+		}
+		in.tables = append(in.tables, instance)
+	}
+	in.globals = make([]uint64, len(m.globals))
+	for i, g := range m.globals {
+		in.globals[i] = in.evaluate(g.init)
+	}
+
+	in.elements = make([][]uint64, len(m.elements))
+	for i, e := range m.elements {
+		refs := make([]uint64, len(e.init))
+		for j, expression := range e.init {
+			refs[j] = in.evaluate(expression)
+		}
+		switch e.mode {
+		case segmentActive:
+			t := in.tables[e.table]
+			offset := uint64(uint32(in.evaluate(e.offset)))
+			if offset+uint64(len(refs)) > uint64(len(t.elements)) {
+				return nil, fmt.Errorf("wasm: element segment %d does not fit its table", i)
+			}
+			copy(t.elements[offset:], refs)
+		case segmentPassive:
+			in.elements[i] = refs
+		}
+	}
+	in.data = make([][]byte, len(m.data))
+	for i, d := range m.data {
+		switch d.mode {
+		case segmentActive:
+			offset := uint64(uint32(in.evaluate(d.offset)))
+			if offset+uint64(len(d.init)) > uint64(len(in.memory)) {
+				return nil, fmt.Errorf("wasm: data segment %d does not fit the memory", i)
+			}
+			copy(in.memory[offset:], d.init)
+		case segmentPassive:
+			in.data[i] = d.init
+		}
+	}
+
+	if m.start != nil {
+		if _, err := in.call(int(*m.start), nil); err != nil {
+			return nil, err
+		}
+	}
+	return in, nil
+}
+
+func (in *Instance) evaluate(e constExpr) uint64 {
+	switch e.op {
+	case opGlobalGet:
+		return in.globals[e.value]
+	case opRefNull:
+		return 0
+	case opRefFunc:
+		return e.value + 1
+	}
+	return e.value
+}
+
+// Call calls an exported function, returning its results.
+func (in *Instance) Call(name string, args ...uint64) ([]uint64, error) {
+	e, ok := in.module.exports[name]
+	if !ok || e.kind != exportFunction {
+		return nil, fmt.Errorf("wasm: no function is exported as '%s'", name)
+	}
+	return in.call(int(e.index), args)
+}
+
+// Memory returns the memory of the instance. The slice is only valid until the next call: calls may grow the memory.
+func (in *Instance) Memory() []byte {
+	return in.memory
+}
+
+// Interrupt stops the call running on the instance, which returns ErrInterrupted. It is safe to call from any
+// goroutine, a call started after it returns runs as normal.
+func (in *Instance) Interrupt() {
+	in.interrupted.Store(true)
+}
+
+func (in *Instance) call(index int, args []uint64) (results []uint64, err error) {
+	if in.running {
+		return nil, errors.New("wasm: the instance is already running a call")
+	}
+	f := &in.functions[index]
+	if len(args) != f.params {
+		return nil, fmt.Errorf("wasm: function takes %d arguments, it was called with %d", f.params, len(args))
+	}
+	in.running, in.depth = true, 0
+	in.interrupted.Store(false)
+	defer func() {
+		in.running = false
+		if r := recover(); r != nil {
+			switch failure := r.(type) {
+			case *Trap:
+				err = failure
+			case hostError:
+				err = failure.err
+			case runtime.Error:
+				// operands of the wrong type can index out of range, they are not type checked.
+				err = &Trap{Reason: failure.Error()}
+			default:
+				panic(r)
+			}
+		}
+	}()
+
+	in.reserve(max(f.params, f.results))
+	copy(in.stack, args)
+	in.invoke(f, 0)
+	return slices.Clone(in.stack[:f.results]), nil
+}
+
+// invoke calls a function with its arguments at the base of the stack, leaving its results there.
+func (in *Instance) invoke(f *funcInstance, base int) {
+	if in.interrupted.Load() {
+		panic(hostError{err: ErrInterrupted})
+	}
+	if f.code != nil {
+		in.execute(f.code, base)
+		return
+	}
+	results, err := f.host.Call(in, slices.Clone(in.stack[base:base+f.params]))
+	if err != nil {
+		panic(hostError{err: err})
+	}
+	if len(results) != f.results {
+		panic(&Trap{Reason: fmt.Sprintf("host function returned %d results instead of %d", len(results), f.results)})
+	}
+	in.reserve(base + len(results))
+	copy(in.stack[base:], results)
+}
+
+// reserve grows the stack to hold at least a number of values.
+func (in *Instance) reserve(size int) {
+	if size <= len(in.stack) {
+		return
+	}
+	if size > maxStack {
+		panic(trapStackExhausted)
+	}
+	stack := make([]uint64, min(max(size, 2*len(in.stack), 1024), maxStack))
+	copy(stack, in.stack)
+	in.stack = stack
+}
@@ -0,0 +1,648 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+// Package wasm runs WebAssembly modules in a pure-Go interpreter. It supports WebAssembly 1.0 with the sign extension,
+// non-trapping float to int conversion, bulk memory, multi-value and reference types extensions, which is what
+// current compilers emit by default. Modules may import functions from the host, but not memories, tables or globals.
+//
+// Modules are decoded and compiled once, then instantiated as many times as needed. Compilation checks that indices are
+// in range and that the operand stack is balanced, it does not check the types of operands: a module that mixes them
+// up computes nonsense, but can't reach anything outside of its own instance.
+package wasm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValueType is the type of a parameter, result, local or global.
+type ValueType byte
+
+// Value types. Values are passed to and returned from functions as uint64: integers zero extended, floats as their
+// IEEE 754 bits, references as the index of the function plus one, or zero for null.
+const (
+	I32       ValueType = 0x7f
+	I64       ValueType = 0x7e
+	F32       ValueType = 0x7d
+	F64       ValueType = 0x7c
+	FuncRef   ValueType = 0x70
+	ExternRef ValueType = 0x6f
+)
+
+func (v ValueType) String() string {
+	switch v {
+	case I32:
+		return "i32"
+	case I64:
+		return "i64"
+	case F32:
+		return "f32"
+	case F64:
+		return "f64"
+	case FuncRef:
+		return "funcref"
+	case ExternRef:
+		return "externref"
+	}
+	return fmt.Sprintf("0x%x", byte(v))
+}
+
+// FuncType is the signature of a function.
+type FuncType struct {
+	Params  []ValueType
+	Results []ValueType
+}
+
+// String renders a signature like (i32, i32) -> i64.
+func (f FuncType) String() string {
+	list := func(types []ValueType) string {
+		names := make([]string, len(types))
+		for i, t := range types {
+			names[i] = t.String()
+		}
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("(%s) -> (%s)", list(f.Params), list(f.Results))
+}
+
+// Equal returns true when two signatures are the same.
+func (f FuncType) Equal(other FuncType) bool {
+	return bytes.Equal(valueBytes(f.Params), valueBytes(other.Params)) &&
+		bytes.Equal(valueBytes(f.Results), valueBytes(other.Results))
+}
+
+func valueBytes(types []ValueType) []byte {
+	b := make([]byte, len(types))
+	for i, t := range types {
+		b[i] = byte(t)
+	}
+	return b
+}
+
+// Import is a function a module imports from the host.
+type Import struct {
+	Module string
+	Name   string
+	Type   FuncType
+}
+
+// Module is a decoded and compiled module, ready to be instantiated. Modules are safe to instantiate concurrently.
+type Module struct {
+	types     []FuncType
+	typeIDs   []int
+	imports   []Import
+	funcTypes []uint32
+	functions []*function
+	tables    []tableType
+	memory    *limits
+	globals   []global
+	exports   map[string]export
+	start     *uint32
+	elements  []element
+	data      []dataSegment
+	dataCount *uint32
+}
+
+type limits struct {
+	min    uint32
+	max    uint32
+	hasMax bool
+}
+
+type tableType struct {
+	ref    ValueType
+	limits limits
+}
+
+type global struct {
+	typ     ValueType
+	mutable bool
+	init    constExpr
+}
+
+// constExpr is the initializer of a global or the offset of a segment.
+type constExpr struct {
+	op    byte
+	value uint64
+}
+
+const (
+	exportFunction = 0x00
+	exportTable    = 0x01
+	exportMemory   = 0x02
+	exportGlobal   = 0x03
+)
+
+type export struct {
+	kind  byte
+	index uint32
+}
+
+const (
+	segmentActive = iota
+	segmentPassive
+	segmentDeclarative
+)
+
+type element struct {
+	mode   int
+	table  uint32
+	offset constExpr
+	init   []constExpr
+}
+
+type dataSegment struct {
+	mode   int
+	offset constExpr
+	init   []byte
+}
+
+// maxLocals caps the locals of a function, a module can declare billions of them in a few bytes.
+const maxLocals = 50000
+
+// ErrInvalidModule is returned, wrapped, for binaries that are not valid WebAssembly modules.
+var ErrInvalidModule = errors.New("invalid module")
+
+// ErrUnsupported is returned, wrapped, for modules using features this runtime doesn't support.
+var ErrUnsupported = errors.New("unsupported feature")
+
+type decodeError struct{ err error }
+
+// Compile decodes and compiles a module from its binary format.
+func Compile(binary []byte) (module *Module, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			failure, ok := r.(decodeError)
+			if !ok {
+				panic(r)
+			}
+			module, err = nil, failure.err
+		}
+	}()
+
+	r := &reader{data: binary}
+	if !bytes.HasPrefix(binary, []byte("\x00asm")) {
+		r.fail("not a WebAssembly module")
+	}
+	r.pos = 4
+	if version := r.bytes(4); !bytes.Equal(version, []byte{1, 0, 0, 0}) {
+		r.unsupported("binary format version %d", version[0])
+	}
+
+	m := &Module{exports: make(map[string]export)}
+	var bodies []*reader
+	for !r.done() {
+		id := r.byte()
+		section := &reader{data: r.bytes(int(r.u32()))}
+		switch id {
+		case 0:
+			// custom sections hold names and debug information.
+			continue
+		case 1:
+			m.decodeTypes(section)
+		case 2:
+			m.decodeImports(section)
+		case 3:
+			for n := section.u32(); n > 0; n-- {
+				m.funcTypes = append(m.funcTypes, m.typeIndex(section))
+			}
+		case 4:
+			for n := section.u32(); n > 0; n-- {
+				m.tables = append(m.tables, decodeTableType(section))
+			}
+		case 5:
+			for n := section.u32(); n > 0; n-- {
+				if m.memory != nil {
+					r.unsupported("multiple memories")
+				}
+				memory := decodeLimits(section, true)
+				if memory.min > maxPages || (memory.hasMax && memory.max > maxPages) {
+					r.fail("memory size must be at most %d pages", maxPages)
+				}
+				m.memory = &memory
+			}
+		case 6:
+			for n := section.u32(); n > 0; n-- {
+				g := global{typ: decodeValueType(section), mutable: decodeMutable(section)}
+				g.init = m.decodeConstExpr(section, len(m.globals))
+				m.globals = append(m.globals, g)
+			}
+		case 7:
+			m.decodeExports(section)
+		case 8:
+			start := section.u32()
+			m.start = &start
+		case 9:
+			m.decodeElements(section)
+		case 10:
+			count := section.u32()
+			if int(count) != len(m.funcTypes)-len(m.imports) {
+				r.fail("function and code section have different lengths")
+			}
+			for ; count > 0; count-- {
+				bodies = append(bodies, &reader{data: section.bytes(int(section.u32()))})
+			}
+		case 11:
+			m.decodeData(section)
+		case 12:
+			count := section.u32()
+			m.dataCount = &count
+		default:
+			r.unsupported("section %d", id)
+		}
+		if !section.done() {
+			r.fail("section %d is longer than its contents", id)
+		}
+	}
+
+	if len(bodies) != len(m.funcTypes)-len(m.imports) {
+		r.fail("function and code section have different lengths")
+	}
+	for i, body := range bodies {
+		m.functions = append(m.functions, m.compileFunction(len(m.imports)+i, body))
+	}
+	m.check(r)
+	return m, nil
+}
+
+// Imports returns the functions the module imports.
+func (m *Module) Imports() []Import {
+	return m.imports
+}
+
+// ExportedFunction returns the signature of an exported function.
+func (m *Module) ExportedFunction(name string) (FuncType, bool) {
+	e, ok := m.exports[name]
+	if !ok || e.kind != exportFunction {
+		return FuncType{}, false
+	}
+	return m.types[m.funcTypes[e.index]], true
+}
+
+// ExportedMemory returns true when the module exports its memory under a name.
+func (m *Module) ExportedMemory(name string) bool {
+	e, ok := m.exports[name]
+	return ok && e.kind == exportMemory
+}
+
+func (m *Module) decodeTypes(r *reader) {
+	for n := r.u32(); n > 0; n-- {
+		if form := r.byte(); form != 0x60 {
+			r.unsupported("type form 0x%x", form)
+		}
+		var f FuncType
+		for p := r.u32(); p > 0; p-- {
+			f.Params = append(f.Params, decodeValueType(r))
+		}
+		for p := r.u32(); p > 0; p-- {
+			f.Results = append(f.Results, decodeValueType(r))
+		}
+		// indirect calls compare signatures, equal ones share an id so they are compared as numbers.
+		id := len(m.types)
+		for i, other := range m.types {
+			if other.Equal(f) {
+				id = m.typeIDs[i]
+				break
+			}
+		}
+		m.types = append(m.types, f)
+		m.typeIDs = append(m.typeIDs, id)
+	}
+}
+
+func (m *Module) decodeImports(r *reader) {
+	for n := r.u32(); n > 0; n-- {
+		module, name := r.name(), r.name()
+		switch kind := r.byte(); kind {
+		case exportFunction:
+			if len(m.funcTypes) != len(m.imports) {
+				r.fail("imports must come before functions")
+			}
+			index := m.typeIndex(r)
+			m.imports = append(m.imports, Import{Module: module, Name: name, Type: m.types[index]})
+			m.funcTypes = append(m.funcTypes, index)
+		case exportTable:
+			r.unsupported("table import %s.%s", module, name)
+		case exportMemory:
+			r.unsupported("memory import %s.%s", module, name)
+		case exportGlobal:
+			r.unsupported("global import %s.%s", module, name)
+		default:
+			r.fail("unknown import kind 0x%x", kind)
+		}
+	}
+}
+
+func (m *Module) decodeExports(r *reader) {
+	for n := r.u32(); n > 0; n-- {
+		name := r.name()
+		e := export{kind: r.byte(), index: r.u32()}
+		var count int
+		switch e.kind {
+		case exportFunction:
+			count = len(m.funcTypes)
+		case exportTable:
+			count = len(m.tables)
+		case exportMemory:
+			if m.memory != nil {
+				count = 1
+			}
+		case exportGlobal:
+			count = len(m.globals)
+		default:
+			r.fail("unknown export kind 0x%x", e.kind)
+		}
+		if int(e.index) >= count {
+			r.fail("export '%s' refers to a missing definition", name)
+		}
+		if _, ok := m.exports[name]; ok {
+			r.fail("duplicate export '%s'", name)
+		}
+		m.exports[name] = e
+	}
+}
+
+func (m *Module) decodeElements(r *reader) {
+	for n := r.u32(); n > 0; n-- {
+		flags := r.u32()
+		if flags > 7 {
+			r.fail("unknown element segment flags %d", flags)
+		}
+		e := element{mode: segmentActive}
+		switch {
+		case flags&1 == 0:
+			if flags&2 != 0 {
+				e.table = r.u32()
+			}
+			e.offset = m.decodeConstExpr(r, len(m.globals))
+		case flags&2 == 0:
+			e.mode = segmentPassive
+		default:
+			e.mode = segmentDeclarative
+		}
+		expressions := flags&4 != 0
+		if flags&3 != 0 {
+			// passive, declarative and explicitly indexed segments declare the kind of their references.
+			if kind := r.byte(); expressions && ValueType(kind) != FuncRef && ValueType(kind) != ExternRef {
+				r.fail("unknown reference type 0x%x", kind)
+			} else if !expressions && kind != 0 {
+				r.fail("unknown element kind 0x%x", kind)
+			}
+		}
+		for count := r.u32(); count > 0; count-- {
+			if expressions {
+				e.init = append(e.init, m.decodeConstExpr(r, len(m.globals)))
+				continue
+			}
+			index := r.u32()
+			if int(index) >= len(m.funcTypes) {
+				r.fail("element segment refers to missing function %d", index)
+			}
+			e.init = append(e.init, constExpr{op: opRefFunc, value: uint64(index)})
+		}
+		m.elements = append(m.elements, e)
+	}
+}
+
+func (m *Module) decodeData(r *reader) {
+	for n := r.u32(); n > 0; n-- {
+		d := dataSegment{mode: segmentActive}
+		switch flags := r.u32(); flags {
+		case 0:
+			d.offset = m.decodeConstExpr(r, len(m.globals))
+		case 1:
+			d.mode = segmentPassive
+		case 2:
+			if memory := r.u32(); memory != 0 {
+				r.unsupported("multiple memories")
+			}
+			d.offset = m.decodeConstExpr(r, len(m.globals))
+		default:
+			r.fail("unknown data segment flags %d", flags)
+		}
+		d.init = r.bytes(int(r.u32()))
+		m.data = append(m.data, d)
+	}
+}
+
+// decodeConstExpr decodes a constant expression, which may read the globals defined before it.
+func (m *Module) decodeConstExpr(r *reader, globals int) constExpr {
+	e := constExpr{op: r.byte()}
+	switch e.op {
+	case opI32Const:
+		e.value = uint64(uint32(r.s32()))
+	case opI64Const:
+		e.value = uint64(r.s64())
+	case opF32Const:
+		e.value = uint64(r.u32le())
+	case opF64Const:
+		e.value = r.u64le()
+	case opGlobalGet:
+		e.value = uint64(r.u32())
+		if int(e.value) >= globals {
+			r.fail("constant expression refers to missing global %d", e.value)
+		}
+	case opRefNull:
+		r.byte()
+	case opRefFunc:
+		e.value = uint64(r.u32())
+		if int(e.value) >= len(m.funcTypes) {
+			r.fail("constant expression refers to missing function %d", e.value)
+		}
+	default:
+		r.unsupported("constant expression opcode 0x%x", e.op)
+	}
+	if end := r.byte(); end != opEnd {
+		r.unsupported("constant expressions of more than one instruction")
+	}
+	return e
+}
+
+// check checks the indices that can only be checked once every section has been decoded.
+func (m *Module) check(r *reader) {
+	if m.start != nil {
+		if int(*m.start) >= len(m.funcTypes) {
+			r.fail("start function %d is missing", *m.start)
+		}
+		if f := m.types[m.funcTypes[*m.start]]; len(f.Params) > 0 || len(f.Results) > 0 {
+			r.fail("start function must take and return nothing")
+		}
+	}
+	for _, e := range m.elements {
+		if e.mode == segmentActive && int(e.table) >= len(m.tables) {
+			r.fail("element segment refers to missing table %d", e.table)
+		}
+	}
+	for _, d := range m.data {
+		if d.mode == segmentActive && m.memory == nil {
+			r.fail("data segment without a memory")
+		}
+	}
+	if m.dataCount != nil && int(*m.dataCount) != len(m.data) {
+		r.fail("data count and data section have different lengths")
+	}
+}
+
+func (m *Module) typeIndex(r *reader) uint32 {
+	index := r.u32()
+	if int(index) >= len(m.types) {
+		r.fail("missing type %d", index)
+	}
+	return index
+}
+
+func decodeValueType(r *reader) ValueType {
+	switch t := ValueType(r.byte()); t {
+	case I32, I64, F32, F64, FuncRef, ExternRef:
+		return t
+	default:
+		r.unsupported("value type %s", t)
+	}
+	return 0
+}
+
+func decodeMutable(r *reader) bool {
+	switch mutable := r.byte(); mutable {
+	case 0:
+		return false
+	case 1:
+		return true
+	default:
+		r.fail("unknown global mutability 0x%x", mutable)
+	}
+	return false
+}
+
+func decodeTableType(r *reader) tableType {
+	t := tableType{ref: decodeValueType(r)}
+	if t.ref != FuncRef && t.ref != ExternRef {
+		r.fail("tables must hold references")
+	}
+	t.limits = decodeLimits(r, false)
+	if t.limits.min > maxTableSize {
+		r.fail("tables must hold at most %d elements", maxTableSize)
+	}
+	return t
+}
+
+func decodeLimits(r *reader, memory bool) limits {
+	var l limits
+	switch flags := r.byte(); flags {
+	case 0:
+		l.min = r.u32()
+	case 1:
+		l.min, l.max, l.hasMax = r.u32(), r.u32(), true
+		if l.max < l.min {
+			r.fail("limits maximum is below their minimum")
+		}
+	default:
+		if memory {
+			r.unsupported("shared or 64-bit memories")
+		}
+		r.fail("unknown limits flags 0x%x", flags)
+	}
+	return l
+}
+
+// reader reads the binary format. It panics with a decodeError when it fails, which Compile recovers.
+type reader struct {
+	data []byte
+	pos  int
+}
+
+func (r *reader) fail(format string, args ...any) {
+	panic(decodeError{fmt.Errorf("%w: %s", ErrInvalidModule, fmt.Sprintf(format, args...))})
+}
+
+func (r *reader) unsupported(format string, args ...any) {
+	panic(decodeError{fmt.Errorf("%w: %s", ErrUnsupported, fmt.Sprintf(format, args...))})
+}
+
+func (r *reader) done() bool {
+	return r.pos >= len(r.data)
+}
+
+func (r *reader) byte() byte {
+	if r.done() {
+		r.fail("unexpected end")
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *reader) bytes(n int) []byte {
+	if n < 0 || n > len(r.data)-r.pos {
+		r.fail("unexpected end")
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *reader) name() string {
+	name := r.bytes(int(r.u32()))
+	if !utf8.Valid(name) {
+		r.fail("names must be UTF-8")
+	}
+	return string(name)
+}
+
+// u32 reads an unsigned LEB128 number of at most 32 bits.
+func (r *reader) u32() uint32 {
+	var result uint64
+	for shift := 0; ; shift += 7 {
+		if shift >= 35 {
+			r.fail("integer too long")
+		}
+		b := r.byte()
+		result |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	if result > 0xffffffff {
+		r.fail("integer too large")
+	}
+	return uint32(result)
+}
+
+// signed reads a signed LEB128 number of at most a number of bits.
+func (r *reader) signed(bits int) int64 {
+	var result int64
+	shift, maxShift := 0, (bits+6)/7*7
+	for {
+		if shift >= maxShift {
+			r.fail("integer too long")
+		}
+		b := r.byte()
+		result |= int64(b&0x7f) << shift
+		shift += 7
+		if b&0x80 == 0 {
+			if shift < 64 && b&0x40 != 0 {
+				result |= -1 << shift
+			}
+			return result
+		}
+	}
+}
+
+func (r *reader) s32() int32 {
+	return int32(r.signed(32))
+}
+
+func (r *reader) s64() int64 {
+	return r.signed(64)
+}
+
+func (r *reader) u32le() uint32 {
+	b := r.bytes(4)
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16 | uint32(b[3])<<24
+}
+
+func (r *reader) u64le() uint64 {
+	return uint64(r.u32le()) | uint64(r.u32le())<<32
+}
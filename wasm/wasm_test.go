@@ -0,0 +1,362 @@
+// Copyright 2026 Princess B33f Heavy Industries / Dave Shanley
+// SPDX-License-Identifier: AGPL
+
+package wasm
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// leb encodes an unsigned LEB128 integer.
+func leb(n uint64) []byte {
+	var b []byte
+	for {
+		c := byte(n & 0x7f)
+		n >>= 7
+		if n == 0 {
+			return append(b, c)
+		}
+		b = append(b, c|0x80)
+	}
+}
+
+func vec(items ...[]byte) []byte {
+	return append(leb(uint64(len(items))), bytes.Join(items, nil)...)
+}
+
+func section(id byte, items ...[]byte) []byte {
+	contents := vec(items...)
+	return append(append([]byte{id}, leb(uint64(len(contents)))...), contents...)
+}
+
+func str(s string) []byte {
+	return append(leb(uint64(len(s))), s...)
+}
+
+func funcType(params, results []ValueType) []byte {
+	types := func(values []ValueType) []byte {
+		b := leb(uint64(len(values)))
+		for _, v := range values {
+			b = append(b, byte(v))
+		}
+		return b
+	}
+	return append(append([]byte{0x60}, types(params)...), types(results)...)
+}
+
+// body encodes a function body from its locals, a vector of (count, type) pairs, and its code.
+func body(locals []byte, code ...byte) []byte {
+	contents := append(append(locals, code...), byte(opEnd))
+	return append(leb(uint64(len(contents))), contents...)
+}
+
+func exportFunc(name string, index byte) []byte {
+	return append(str(name), 0x00, index)
+}
+
+func module(sections ...[]byte) []byte {
+	return append([]byte("\x00asm\x01\x00\x00\x00"), bytes.Join(sections, nil)...)
+}
+
+var noLocals = []byte{0}
+
+// calculator imports env.log, and exports a few functions and its memory.
+var calculator = module(
+	section(1,
+		funcType([]ValueType{I32, I32}, []ValueType{I32}),
+		funcType([]ValueType{I64}, []ValueType{I64}),
+		funcType(nil, nil),
+		funcType([]ValueType{I32}, []ValueType{I32}),
+		funcType([]ValueType{I32}, nil),
+	),
+	section(2, append(append(str("env"), str("log")...), 0x00, 4)),
+	section(3, []byte{0}, []byte{0}, []byte{1}, []byte{2}, []byte{2}, []byte{0}, []byte{3}, []byte{3}, []byte{3}),
+	section(4, []byte{0x70, 0x00, 2}),
+	section(5, []byte{0x01, 1, 1}),
+	section(7,
+		exportFunc("add", 1), exportFunc("div", 2), exportFunc("factorial", 3), exportFunc("spin", 4),
+		exportFunc("recurse", 5), exportFunc("store", 6), exportFunc("pick", 7), exportFunc("logged", 8),
+		exportFunc("indirect", 9), append(str("memory"), 0x02, 0),
+	),
+	// the table holds add and factorial.
+	section(9, []byte{0x00, byte(opI32Const), 0, byte(opEnd), 2, 1, 3}),
+	section(10,
+		body(noLocals, byte(opLocalGet), 0, byte(opLocalGet), 1, byte(opI32Add)),
+		body(noLocals, byte(opLocalGet), 0, byte(opLocalGet), 1, byte(opI32DivS)),
+		body([]byte{1, 1, byte(I64)},
+			byte(opI64Const), 1, byte(opLocalSet), 1,
+			byte(opBlock), 0x40, byte(opLoop), 0x40,
+			byte(opLocalGet), 0, byte(opI64Eqz), byte(opBrIf), 1,
+			byte(opLocalGet), 1, byte(opLocalGet), 0, byte(opI64Mul), byte(opLocalSet), 1,
+			byte(opLocalGet), 0, byte(opI64Const), 1, byte(opI64Sub), byte(opLocalSet), 0,
+			byte(opBr), 0,
+			byte(opEnd), byte(opEnd),
+			byte(opLocalGet), 1),
+		body(noLocals, byte(opLoop), 0x40, byte(opBr), 0, byte(opEnd)),
+		body(noLocals, byte(opCall), 5),
+		body(noLocals,
+			byte(opLocalGet), 0, byte(opLocalGet), 1, byte(opI32Store), 2, 0,
+			byte(opLocalGet), 0, byte(opI32Load), 2, 0),
+		body(noLocals,
+			byte(opBlock), 0x40, byte(opBlock), 0x40, byte(opBlock), 0x40,
+			byte(opLocalGet), 0, byte(opBrTable), 2, 0, 1, 2,
+			byte(opEnd), byte(opI32Const), 10, byte(opReturn),
+			byte(opEnd), byte(opI32Const), 20, byte(opReturn),
+			byte(opEnd), byte(opI32Const), 30),
+		body(noLocals,
+			byte(opLocalGet), 0, byte(opCall), 0,
+			byte(opLocalGet), 0, byte(opI32Const), 1, byte(opI32Add)),
+		body(noLocals,
+			byte(opI32Const), 2, byte(opI32Const), 3, byte(opLocalGet), 0, byte(opCallIndirect), 0, 0),
+	),
+)
+
+func instantiate(t *testing.T, log func(int32) error) *Instance {
+	m, err := Compile(calculator)
+	require.NoError(t, err)
+	in, err := m.Instantiate(Imports{"env": {"log": {
+		Type: FuncType{Params: []ValueType{I32}},
+		Call: func(_ *Instance, args []uint64) ([]uint64, error) {
+			return nil, log(int32(args[0]))
+		},
+	}}})
+	require.NoError(t, err)
+	return in
+}
+
+func TestInstance_Call(t *testing.T) {
+	var logged []int32
+	in := instantiate(t, func(v int32) error {
+		logged = append(logged, v)
+		return nil
+	})
+
+	call := func(name string, args ...uint64) uint64 {
+		results, err := in.Call(name, args...)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		return results[0]
+	}
+	assert.Equal(t, uint64(5), call("add", 2, 3))
+	assert.Equal(t, uint64(0xffffffff), call("add", 0xffffffff, 0), "i32 results are zero extended")
+	assert.Equal(t, uint64(0), call("add", 0xffffffff, 1))
+	assert.Equal(t, uint64(0xfffffffd), call("div", 0xfffffff7, 3))
+	assert.Equal(t, uint64(2432902008176640000), call("factorial", 20))
+	assert.Equal(t, uint64(7), call("store", 65532, 7))
+	assert.Equal(t, []byte{7, 0, 0, 0}, in.Memory()[65532:])
+	assert.Equal(t, uint64(10), call("pick", 0))
+	assert.Equal(t, uint64(20), call("pick", 1))
+	assert.Equal(t, uint64(30), call("pick", 2))
+	assert.Equal(t, uint64(30), call("pick", 1000), "out of range picks the default")
+	assert.Equal(t, uint64(6), call("logged", 5))
+	assert.Equal(t, []int32{5}, logged)
+	assert.Equal(t, uint64(5), call("indirect", 0))
+
+	_, err := in.Call("missing")
+	assert.EqualError(t, err, "wasm: no function is exported as 'missing'")
+	_, err = in.Call("add", 1)
+	assert.EqualError(t, err, "wasm: function takes 2 arguments, it was called with 1")
+}
+
+func TestInstance_CallTraps(t *testing.T) {
+	in := instantiate(t, func(int32) error { return nil })
+
+	for name, test := range map[string]struct {
+		function string
+		args     []uint64
+		trap     string
+	}{
+		"divide by zero":       {function: "div", args: []uint64{1, 0}, trap: "integer divide by zero"},
+		"overflowing division": {function: "div", args: []uint64{0x80000000, 0xffffffff}, trap: "integer overflow"},
+		"store out of bounds":  {function: "store", args: []uint64{65533, 1}, trap: "out of bounds memory access"},
+		"address wrapping":     {function: "store", args: []uint64{0xffffffff, 1}, trap: "out of bounds memory access"},
+		"runaway recursion":    {function: "recurse", trap: "call stack exhausted"},
+		"wrong type":           {function: "indirect", args: []uint64{1}, trap: "indirect call type mismatch"},
+		"missing element":      {function: "indirect", args: []uint64{2}, trap: "undefined element"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := in.Call(test.function, test.args...)
+			var trap *Trap
+			require.ErrorAs(t, err, &trap)
+			assert.Equal(t, test.trap, trap.Reason)
+		})
+	}
+
+	// traps leave the instance usable.
+	results, err := in.Call("add", 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{3}, results)
+}
+
+func TestInstance_CallReturnsHostErrors(t *testing.T) {
+	failure := errors.New("no logging today")
+	in := instantiate(t, func(int32) error { return failure })
+
+	_, err := in.Call("logged", 1)
+	assert.ErrorIs(t, err, failure)
+}
+
+func TestInstance_Interrupt(t *testing.T) {
+	in := instantiate(t, func(int32) error { return nil })
+
+	timer := time.AfterFunc(20*time.Millisecond, in.Interrupt)
+	defer timer.Stop()
+	_, err := in.Call("spin")
+	assert.ErrorIs(t, err, ErrInterrupted)
+
+	results, err := in.Call("add", 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{3}, results)
+}
+
+func TestModule_Instantiate(t *testing.T) {
+	m, err := Compile(calculator)
+	require.NoError(t, err)
+
+	_, err = m.Instantiate(nil)
+	assert.EqualError(t, err, "wasm: unresolved import env.log")
+
+	_, err = m.Instantiate(Imports{"env": {"log": {Type: FuncType{Params: []ValueType{I64}}}}})
+	assert.EqualError(t, err, "wasm: import env.log must be (i32) -> (), the host provides (i64) -> ()")
+
+	assert.Equal(t, []Import{{Module: "env", Name: "log", Type: FuncType{Params: []ValueType{I32}}}}, m.Imports())
+	typ, ok := m.ExportedFunction("add")
+	assert.True(t, ok)
+	assert.Equal(t, FuncType{Params: []ValueType{I32, I32}, Results: []ValueType{I32}}, typ)
+	_, ok = m.ExportedFunction("memory")
+	assert.False(t, ok)
+	assert.True(t, m.ExportedMemory("memory"))
+}
+
+func TestCompile_RefusesInvalidModules(t *testing.T) {
+	for name, test := range map[string]struct {
+		binary []byte
+		err    error
+	}{
+		"not wasm":         {binary: []byte("function validateRequest() {}"), err: ErrInvalidModule},
+		"later version":    {binary: []byte("\x00asm\x02\x00\x00\x00"), err: ErrUnsupported},
+		"truncated":        {binary: calculator[:len(calculator)-3], err: ErrInvalidModule},
+		"unknown section":  {binary: module(section(42)), err: ErrUnsupported},
+		"missing function": {binary: module(section(1, funcType(nil, nil)), section(3, []byte{0}), section(10, body(noLocals, byte(opCall), 1))), err: ErrInvalidModule},
+		"stack underflow":  {binary: module(section(1, funcType(nil, nil)), section(3, []byte{0}), section(10, body(noLocals, byte(opDrop)))), err: ErrInvalidModule},
+		"missing result":   {binary: module(section(1, funcType(nil, []ValueType{I32})), section(3, []byte{0}), section(10, body(noLocals))), err: ErrInvalidModule},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Compile(test.binary)
+			assert.ErrorIs(t, err, test.err)
+		})
+	}
+}
+
+// run compiles a function from its code, and calls it.
+func run(t *testing.T, params []ValueType, result ValueType, code []byte, args ...uint64) (uint64, error) {
+	binary := module(
+		section(1, funcType(params, []ValueType{result})),
+		section(3, []byte{0}),
+		section(7, exportFunc("run", 0)),
+		section(10, body(noLocals, code...)),
+	)
+	m, err := Compile(binary)
+	require.NoError(t, err)
+	in, err := m.Instantiate(nil)
+	require.NoError(t, err)
+	results, err := in.Call("run", args...)
+	if err != nil {
+		return 0, err
+	}
+	return results[0], nil
+}
+
+func TestInstance_Numbers(t *testing.T) {
+	f64 := math.Float64bits
+	f32 := func(f float32) uint64 { return uint64(math.Float32bits(f)) }
+	binary := func(op uint16) []byte {
+		return []byte{byte(opLocalGet), 0, byte(opLocalGet), 1, byte(op)}
+	}
+	unary := func(op uint16) []byte {
+		if op > 0xff {
+			return []byte{byte(opLocalGet), 0, 0xfc, byte(op)}
+		}
+		return []byte{byte(opLocalGet), 0, byte(op)}
+	}
+	i32s, i64s, f32s, f64s := []ValueType{I32, I32}, []ValueType{I64, I64}, []ValueType{F32, F32}, []ValueType{F64, F64}
+
+	for name, test := range map[string]struct {
+		params []ValueType
+		result ValueType
+		code   []byte
+		args   []uint64
+		want   uint64
+		trap   string
+	}{
+		"i32.rem_s of the smallest by -1": {params: i32s, result: I32, code: binary(opI32RemS), args: []uint64{0x80000000, 0xffffffff}, want: 0},
+		"i32.shl shifts modulo 32":        {params: i32s, result: I32, code: binary(opI32Shl), args: []uint64{1, 33}, want: 2},
+		"i32.shr_s keeps the sign":        {params: i32s, result: I32, code: binary(opI32ShrS), args: []uint64{0x80000000, 31}, want: 0xffffffff},
+		"i32.rotr":                        {params: i32s, result: I32, code: binary(opI32Rotr), args: []uint64{1, 1}, want: 0x80000000},
+		"i32.lt_s is signed":              {params: i32s, result: I32, code: binary(opI32LtS), args: []uint64{0xffffffff, 0}, want: 1},
+		"i32.lt_u is unsigned":            {params: i32s, result: I32, code: binary(opI32LtU), args: []uint64{0xffffffff, 0}, want: 0},
+		"i64.div_u":                       {params: i64s, result: I64, code: binary(opI64DivU), args: []uint64{math.MaxUint64, 2}, want: math.MaxUint64 / 2},
+		"i64.div_s overflow":              {params: i64s, result: I64, code: binary(opI64DivS), args: []uint64{1 << 63, math.MaxUint64}, trap: "integer overflow"},
+		"i64.rotl":                        {params: i64s, result: I64, code: binary(opI64Rotl), args: []uint64{1 << 63, 65}, want: 1},
+		"f64.min of zeros":                {params: f64s, result: F64, code: binary(opF64Min), args: []uint64{f64(0), f64(math.Copysign(0, -1))}, want: f64(math.Copysign(0, -1))},
+		"f64.copysign":                    {params: f64s, result: F64, code: binary(opF64Copysign), args: []uint64{f64(2), f64(-1)}, want: f64(-2)},
+		"f32.div":                         {params: f32s, result: F32, code: binary(opF32Div), args: []uint64{f32(1), f32(3)}, want: f32(float32(1) / 3)},
+		"f32.nearest rounds to even":      {params: []ValueType{F32}, result: F32, code: unary(opF32Nearest), args: []uint64{f32(2.5)}, want: f32(2)},
+		"f64.neg":                         {params: []ValueType{F64}, result: F64, code: unary(opF64Neg), args: []uint64{f64(1)}, want: f64(-1)},
+		"i64.extend_i32_s":                {params: []ValueType{I32}, result: I64, code: unary(opI64ExtendI32S), args: []uint64{0xfffffffe}, want: math.MaxUint64 - 1},
+		"i32.wrap_i64":                    {params: []ValueType{I64}, result: I32, code: unary(opI32WrapI64), args: []uint64{math.MaxUint64}, want: 0xffffffff},
+		"i32.trunc_f64_s":                 {params: []ValueType{F64}, result: I32, code: unary(opI32TruncF64S), args: []uint64{f64(-3.9)}, want: 0xfffffffd},
+		"i32.trunc_f64_s of not a number": {params: []ValueType{F64}, result: I32, code: unary(opI32TruncF64S), args: []uint64{f64(math.NaN())}, trap: "invalid conversion to integer"},
+		"i32.trunc_f64_s out of range":    {params: []ValueType{F64}, result: I32, code: unary(opI32TruncF64S), args: []uint64{f64(1 << 31)}, trap: "integer overflow"},
+		"i32.trunc_f64_u of almost -1":    {params: []ValueType{F64}, result: I32, code: unary(opI32TruncF64U), args: []uint64{f64(-0.9)}, want: 0},
+		"i64.trunc_f64_u above 2^63":      {params: []ValueType{F64}, result: I64, code: unary(opI64TruncF64U), args: []uint64{f64(1 << 63)}, want: 1 << 63},
+		"i32.trunc_sat_f64_u of -1":       {params: []ValueType{F64}, result: I32, code: unary(opI32TruncSatF64U), args: []uint64{f64(-1)}, want: 0},
+		"i32.trunc_sat_f64_s too large":   {params: []ValueType{F64}, result: I32, code: unary(opI32TruncSatF64S), args: []uint64{f64(1e10)}, want: math.MaxInt32},
+		"i64.trunc_sat_f64_s too small":   {params: []ValueType{F64}, result: I64, code: unary(opI64TruncSatF64S), args: []uint64{f64(-1e300)}, want: 1 << 63},
+		"f64.convert_i64_u":               {params: []ValueType{I64}, result: F64, code: unary(opF64ConvertI64U), args: []uint64{1 << 63}, want: f64(1 << 63)},
+		"f32.demote_f64":                  {params: []ValueType{F64}, result: F32, code: unary(opF32DemoteF64), args: []uint64{f64(0.1)}, want: f32(0.1)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := run(t, test.params, test.result, test.code, test.args...)
+			if test.trap != "" {
+				var trap *Trap
+				require.ErrorAs(t, err, &trap)
+				assert.Equal(t, test.trap, trap.Reason)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestInstance_MemoryGrow(t *testing.T) {
+	binary := module(
+		section(1, funcType([]ValueType{I32}, []ValueType{I32})),
+		section(3, []byte{0}),
+		section(5, []byte{0x01, 1, 3}),
+		section(7, exportFunc("grow", 0)),
+		section(10, body(noLocals, byte(opLocalGet), 0, byte(opMemoryGrow), 0)),
+	)
+	m, err := Compile(binary)
+	require.NoError(t, err)
+	in, err := m.Instantiate(nil)
+	require.NoError(t, err)
+
+	results, err := in.Call("grow", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{1}, results)
+	assert.Len(t, in.Memory(), 3*pageSize)
+
+	results, err = in.Call("grow", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{0xffffffff}, results, "the memory can't grow past its maximum")
+	assert.Len(t, in.Memory(), 3*pageSize)
+}